@@ -0,0 +1,54 @@
+package hypermcp
+
+import (
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ReadOnlyAnnotations returns annotations declaring that a tool does not
+// modify its environment, which lets clients call it without asking for
+// approval, and hypermcp coalesce its calls (see AddCoalescedTool).
+//
+//	hypermcp.AddTool(srv, &mcp.Tool{
+//	    Name:        "forecast",
+//	    Annotations: hypermcp.ReadOnlyAnnotations(),
+//	}, forecastHandler)
+func ReadOnlyAnnotations() *mcp.ToolAnnotations {
+	return &mcp.ToolAnnotations{ReadOnlyHint: true}
+}
+
+// WriteAnnotations returns annotations declaring that a tool modifies its
+// environment: destructively, by deleting or overwriting data, or only
+// additively; and idempotently, so repeating a call with the same arguments
+// has no further effect, or not.
+func WriteAnnotations(destructive, idempotent bool) *mcp.ToolAnnotations {
+	return &mcp.ToolAnnotations{DestructiveHint: &destructive, IdempotentHint: idempotent}
+}
+
+// IsReadOnlyTool reports whether tool declares itself read-only with
+// Annotations.ReadOnlyHint.
+func IsReadOnlyTool(tool *mcp.Tool) bool {
+	return tool.Annotations != nil && tool.Annotations.ReadOnlyHint
+}
+
+// IsDestructiveTool reports whether tool may make destructive updates,
+// applying the defaults of the MCP spec: a tool is destructive unless it is
+// read-only or sets Annotations.DestructiveHint to false, so a tool without
+// annotations is destructive.
+func IsDestructiveTool(tool *mcp.Tool) bool {
+	if tool.Annotations == nil {
+		return true
+	}
+	a := tool.Annotations
+	return !a.ReadOnlyHint && (a.DestructiveHint == nil || *a.DestructiveHint)
+}
+
+// validateAnnotations rejects annotations that contradict each other.
+func validateAnnotations(tool *mcp.Tool) error {
+	a := tool.Annotations
+	if a != nil && a.ReadOnlyHint && a.DestructiveHint != nil && *a.DestructiveHint {
+		return fmt.Errorf("%w: tool %q is both read-only and destructive", ErrInvalidAnnotations, tool.Name)
+	}
+	return nil
+}
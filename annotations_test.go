@@ -0,0 +1,130 @@
+package hypermcp
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestAddTool_PreservesAnnotations(t *testing.T) {
+	srv, err := New(Config{Name: "test-server", Version: "1.0.0"}, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	openWorld := false
+	annotations := WriteAnnotations(true, true)
+	annotations.OpenWorldHint = &openWorld
+	annotations.Title = "Delete record"
+	AddTool(srv, &mcp.Tool{Name: "delete", Annotations: annotations}, func(context.Context, *mcp.CallToolRequest, struct{}) (*mcp.CallToolResult, any, error) {
+		return nil, nil, nil
+	})
+	AddTool(srv, &mcp.Tool{Name: "get", Annotations: ReadOnlyAnnotations()}, func(context.Context, *mcp.CallToolRequest, struct{}) (*mcp.CallToolResult, any, error) {
+		return nil, nil, nil
+	})
+
+	result, err := connectTestClient(t, srv).ListTools(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("ListTools() error = %v", err)
+	}
+	got := make(map[string]*mcp.ToolAnnotations)
+	for _, tool := range result.Tools {
+		got[tool.Name] = tool.Annotations
+	}
+
+	del := got["delete"]
+	if del == nil || del.DestructiveHint == nil || !*del.DestructiveHint || !del.IdempotentHint ||
+		del.OpenWorldHint == nil || *del.OpenWorldHint || del.Title != "Delete record" || del.ReadOnlyHint {
+		t.Errorf("delete annotations = %+v, want them sent as registered", del)
+	}
+	if get := got["get"]; get == nil || !get.ReadOnlyHint {
+		t.Errorf("get annotations = %+v, want read-only", get)
+	}
+}
+
+func TestAddToolErr_InvalidAnnotations(t *testing.T) {
+	srv, err := New(Config{Name: "test-server", Version: "1.0.0"}, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	annotations := WriteAnnotations(true, false)
+	annotations.ReadOnlyHint = true
+
+	err = AddToolErr(srv, &mcp.Tool{Name: "confused", Annotations: annotations}, func(context.Context, *mcp.CallToolRequest, struct{}) (*mcp.CallToolResult, any, error) {
+		return nil, nil, nil
+	})
+	if !errors.Is(err, ErrInvalidAnnotations) {
+		t.Errorf("AddToolErr() error = %v, want ErrInvalidAnnotations", err)
+	}
+}
+
+func TestIsDestructiveTool(t *testing.T) {
+	tests := []struct {
+		name            string
+		annotations     *mcp.ToolAnnotations
+		wantReadOnly    bool
+		wantDestructive bool
+	}{
+		{name: "no annotations", wantDestructive: true},
+		{name: "empty annotations", annotations: &mcp.ToolAnnotations{}, wantDestructive: true},
+		{name: "read-only", annotations: ReadOnlyAnnotations(), wantReadOnly: true},
+		{name: "destructive write", annotations: WriteAnnotations(true, false), wantDestructive: true},
+		{name: "additive write", annotations: WriteAnnotations(false, true)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tool := &mcp.Tool{Name: "tool", Annotations: tt.annotations}
+			if got := IsReadOnlyTool(tool); got != tt.wantReadOnly {
+				t.Errorf("IsReadOnlyTool() = %v, want %v", got, tt.wantReadOnly)
+			}
+			if got := IsDestructiveTool(tool); got != tt.wantDestructive {
+				t.Errorf("IsDestructiveTool() = %v, want %v", got, tt.wantDestructive)
+			}
+		})
+	}
+}
+
+func TestAddCoalescedTool_RequiresReadOnly(t *testing.T) {
+	srv, err := New(Config{Name: "test-server", Version: "1.0.0"}, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	var executions atomic.Int32
+	release := make(chan struct{})
+	AddCoalescedTool(srv, &mcp.Tool{Name: "charge", Annotations: WriteAnnotations(false, false)}, func(context.Context, *mcp.CallToolRequest, struct{}) (*mcp.CallToolResult, any, error) {
+		executions.Add(1)
+		<-release
+		return TextResult("charged"), nil, nil
+	})
+
+	const callers = 2
+	var wg sync.WaitGroup
+	for range callers {
+		session := connectTestClient(t, srv)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "charge", Arguments: map[string]any{}}); err != nil {
+				t.Errorf("CallTool() error = %v", err)
+			}
+		}()
+	}
+
+	// Every identical call runs the handler, since the tool isn't read-only
+	deadline := time.Now().Add(5 * time.Second)
+	for executions.Load() < callers && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+	if got := executions.Load(); got != callers {
+		t.Errorf("handler ran %d times for %d identical calls, want each call executed", got, callers)
+	}
+}
@@ -0,0 +1,80 @@
+package hypermcp
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// APIKeyHeader is the header checked for an API key when a request carries
+// no bearer token.
+const APIKeyHeader = "X-API-Key"
+
+// AuthConfig protects network transports with bearer tokens or API keys.
+//
+// A request is authenticated if it presents a credential, as
+// "Authorization: Bearer <token>" or in the X-API-Key header, that matches one
+// of Tokens or is accepted by Validator. Other requests are rejected with
+// 401 Unauthorized before reaching the MCP session. Authentication is
+// disabled when both Tokens and Validator are empty.
+type AuthConfig struct {
+	// Tokens lists the credentials accepted verbatim. They are compared in
+	// constant time.
+	Tokens []string
+
+	// Validator, if set, decides whether a credential not found in Tokens is
+	// valid, for example by looking it up in a database. The request is
+	// provided for context such as the client address.
+	Validator func(r *http.Request, token string) bool
+}
+
+// enabled reports whether any credential check is configured.
+func (c AuthConfig) enabled() bool {
+	return len(c.Tokens) > 0 || c.Validator != nil
+}
+
+// valid reports whether token is an accepted credential.
+func (c AuthConfig) valid(r *http.Request, token string) bool {
+	var match bool
+	for _, t := range c.Tokens {
+		// Check every token so the time taken doesn't reveal which one matched
+		if subtle.ConstantTimeCompare([]byte(t), []byte(token)) == 1 {
+			match = true
+		}
+	}
+	if match {
+		return true
+	}
+	return c.Validator != nil && c.Validator(r, token)
+}
+
+// requestCredential extracts the bearer token or API key from r, preferring
+// the Authorization header.
+func requestCredential(r *http.Request) string {
+	if auth := r.Header.Get("Authorization"); auth != "" {
+		scheme, token, ok := strings.Cut(auth, " ")
+		if ok && strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(token)
+		}
+	}
+	return r.Header.Get(APIKeyHeader)
+}
+
+// requireAuth rejects requests without a valid credential with 401 Unauthorized.
+func (s *Server) requireAuth(cfg AuthConfig, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := requestCredential(r)
+		if token == "" || !cfg.valid(r, token) {
+			s.log().Debug("rejected unauthenticated request",
+				zap.String("remote_addr", r.RemoteAddr),
+				zap.Bool("credential_present", token != ""),
+			)
+			w.Header().Set("WWW-Authenticate", `Bearer realm="mcp"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,138 @@
+package hypermcp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// newAuthTestServer serves the SSE transport of a server protected by auth.
+func newAuthTestServer(t *testing.T, auth AuthConfig) *httptest.Server {
+	t.Helper()
+
+	srv := newSSETestServer(t)
+	srv.config.Transport.Auth = auth
+
+	handler, err := srv.HTTPHandler(TransportSSE)
+	if err != nil {
+		t.Fatalf("failed to build handler: %v", err)
+	}
+	httpServer := httptest.NewServer(handler)
+	t.Cleanup(httpServer.Close)
+	return httpServer
+}
+
+func TestHTTPHandler_Auth(t *testing.T) {
+	auth := AuthConfig{
+		Tokens: []string{"token-a", "token-b"},
+		Validator: func(r *http.Request, token string) bool {
+			return token == "from-validator"
+		},
+	}
+
+	tests := []struct {
+		name       string
+		header     string
+		value      string
+		wantStatus int
+	}{
+		{name: "valid bearer token", header: "Authorization", value: "Bearer token-a", wantStatus: http.StatusOK},
+		{name: "second token", header: "Authorization", value: "bearer token-b", wantStatus: http.StatusOK},
+		{name: "valid api key", header: APIKeyHeader, value: "token-b", wantStatus: http.StatusOK},
+		{name: "accepted by validator", header: APIKeyHeader, value: "from-validator", wantStatus: http.StatusOK},
+		{name: "invalid token", header: "Authorization", value: "Bearer wrong", wantStatus: http.StatusUnauthorized},
+		{name: "invalid api key", header: APIKeyHeader, value: "wrong", wantStatus: http.StatusUnauthorized},
+		{name: "wrong scheme", header: "Authorization", value: "Basic token-a", wantStatus: http.StatusUnauthorized},
+		{name: "missing credentials", wantStatus: http.StatusUnauthorized},
+	}
+
+	httpServer := newAuthTestServer(t, auth)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, httpServer.URL+DefaultSSEPath, nil)
+			if err != nil {
+				t.Fatalf("failed to build request: %v", err)
+			}
+			if tt.header != "" {
+				req.Header.Set(tt.header, tt.value)
+			}
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			// An authenticated GET opens the event stream; canceling ends it
+			cancel()
+			_ = resp.Body.Close()
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, resp.StatusCode)
+			}
+			if tt.wantStatus == http.StatusUnauthorized && resp.Header.Get("WWW-Authenticate") == "" {
+				t.Error("expected a WWW-Authenticate challenge")
+			}
+		})
+	}
+}
+
+func TestHTTPHandler_Auth_Session(t *testing.T) {
+	httpServer := newAuthTestServer(t, AuthConfig{Tokens: []string{"secret"}})
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "sse-client", Version: "1.0.0"}, nil)
+
+	// Without credentials the session cannot be established
+	if session, err := client.Connect(context.Background(), &mcp.SSEClientTransport{Endpoint: httpServer.URL + DefaultSSEPath}, nil); err == nil {
+		_ = session.Close()
+		t.Fatal("expected connecting without credentials to fail")
+	}
+
+	// With a token, both the event stream and the message POSTs are accepted
+	session, err := client.Connect(context.Background(), &mcp.SSEClientTransport{
+		Endpoint:   httpServer.URL + DefaultSSEPath,
+		HTTPClient: &http.Client{Transport: headerRoundTripper{header: "Authorization", value: "Bearer secret"}},
+	}, nil)
+	if err != nil {
+		t.Fatalf("failed to connect with credentials: %v", err)
+	}
+	defer func() { _ = session.Close() }()
+
+	if _, err := session.CallTool(context.Background(), &mcp.CallToolParams{
+		Name:      "echo",
+		Arguments: map[string]any{"message": "authorized"},
+	}); err != nil {
+		t.Errorf("tool call failed: %v", err)
+	}
+}
+
+func TestRequestCredential(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    string
+	}{
+		{name: "bearer token", headers: map[string]string{"Authorization": "Bearer abc"}, want: "abc"},
+		{name: "api key", headers: map[string]string{APIKeyHeader: "key"}, want: "key"},
+		{name: "bearer preferred over api key", headers: map[string]string{"Authorization": "Bearer abc", APIKeyHeader: "key"}, want: "abc"},
+		{name: "non-bearer authorization falls back to api key", headers: map[string]string{"Authorization": "Basic xyz", APIKeyHeader: "key"}, want: "key"},
+		{name: "none", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			for k, v := range tt.headers {
+				r.Header.Set(k, v)
+			}
+			if got := requestCredential(r); got != tt.want {
+				t.Errorf("requestCredential() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
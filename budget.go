@@ -0,0 +1,35 @@
+package hypermcp
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// DoWithBudget runs fn with a context that expires after budget.
+//
+// It standardizes per-operation time budgets inside handlers, such as a tool that
+// must finish its upstream call within a few seconds:
+//
+//	err := hypermcp.DoWithBudget(ctx, 3*time.Second, func(ctx context.Context) error {
+//	    return srv.HTTPClient().Get(ctx, url, &resp)
+//	})
+//	if errors.Is(err, hypermcp.ErrBudgetExceeded) {
+//	    // upstream too slow
+//	}
+//
+// If fn fails because the budget ran out, the returned error wraps both
+// ErrBudgetExceeded and fn's error. If the parent context is canceled or hits its
+// own deadline first, fn's error is returned unchanged, so callers can tell a slow
+// operation apart from a client that went away. A non-positive budget gives fn an
+// already expired context.
+func DoWithBudget(ctx context.Context, budget time.Duration, fn func(ctx context.Context) error) error {
+	budgetCtx, cancel := context.WithTimeoutCause(ctx, budget, ErrBudgetExceeded)
+	defer cancel()
+
+	err := fn(budgetCtx)
+	if err != nil && ctx.Err() == nil && context.Cause(budgetCtx) == ErrBudgetExceeded {
+		return fmt.Errorf("%w after %v: %w", ErrBudgetExceeded, budget, err)
+	}
+	return err
+}
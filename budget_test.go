@@ -0,0 +1,94 @@
+package hypermcp
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// waitFor blocks for d or until ctx is done.
+func waitFor(d time.Duration) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		select {
+		case <-time.After(d):
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func TestDoWithBudget(t *testing.T) {
+	errUpstream := errors.New("upstream failure")
+
+	tests := []struct {
+		fn         func(ctx context.Context) error
+		wantErr    error
+		name       string
+		budget     time.Duration
+		wantBudget bool
+	}{
+		{
+			name:   "completes under budget",
+			budget: time.Second,
+			fn:     waitFor(time.Millisecond),
+		},
+		{
+			name:       "exceeds budget",
+			budget:     10 * time.Millisecond,
+			fn:         waitFor(time.Second),
+			wantErr:    context.DeadlineExceeded,
+			wantBudget: true,
+		},
+		{
+			name:    "error under budget passes through",
+			budget:  time.Second,
+			fn:      func(ctx context.Context) error { return errUpstream },
+			wantErr: errUpstream,
+		},
+		{
+			name:       "non-positive budget",
+			budget:     0,
+			fn:         waitFor(time.Second),
+			wantErr:    context.DeadlineExceeded,
+			wantBudget: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := DoWithBudget(context.Background(), tt.budget, tt.fn)
+
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected error to wrap %v, got %v", tt.wantErr, err)
+			}
+			if got := errors.Is(err, ErrBudgetExceeded); got != tt.wantBudget {
+				t.Errorf("errors.Is(err, ErrBudgetExceeded) = %v, want %v (err: %v)", got, tt.wantBudget, err)
+			}
+		})
+	}
+}
+
+func TestDoWithBudget_ParentCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+
+	err := DoWithBudget(ctx, time.Second, waitFor(time.Second))
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if errors.Is(err, ErrBudgetExceeded) {
+		t.Error("parent cancellation must not be reported as a budget overrun")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/dgraph-io/ristretto"
@@ -21,8 +22,27 @@ var (
 
 	// ErrInvalidBufferItems indicates BufferItems is not positive.
 	ErrInvalidBufferItems = errors.New("BufferItems must be positive")
+
+	// ErrInvalidDefaultTTL indicates DefaultTTL is negative.
+	ErrInvalidDefaultTTL = errors.New("DefaultTTL cannot be negative")
+
+	// ErrInvalidKeyHighWater indicates KeyHighWater is negative.
+	ErrInvalidKeyHighWater = errors.New("KeyHighWater cannot be negative")
+
+	// ErrInvalidMemoryLimit indicates MemoryLimit is negative.
+	ErrInvalidMemoryLimit = errors.New("MemoryLimit cannot be negative")
+
+	// ErrInvalidMemoryCheckInterval indicates MemoryCheckInterval is negative.
+	ErrInvalidMemoryCheckInterval = errors.New("MemoryCheckInterval cannot be negative")
+
+	// ErrInvalidMaxBytes indicates MaxBytes is negative.
+	ErrInvalidMaxBytes = errors.New("MaxBytes cannot be negative")
 )
 
+// NoExpiration can be passed as a TTL to store a value that never expires,
+// even when Config.ZeroTTLUsesDefault is set.
+const NoExpiration time.Duration = -1
+
 // ValidationError wraps cache configuration validation errors with context.
 type ValidationError struct {
 	Err   error
@@ -40,21 +60,94 @@ func (e *ValidationError) Unwrap() error {
 
 // Cache provides a high-performance in-memory cache
 type Cache struct {
-	store  *ristretto.Cache[string, any]
-	ttls   map[string]time.Time
-	logger *zap.Logger
-	cancel context.CancelFunc
-	mu     sync.RWMutex
+	store      *ristretto.Cache[string, any]
+	ttls       map[string]time.Time
+	namespaces map[string]*NamespacedCache
+	logger     atomic.Pointer[zap.Logger]
+	clock      Clock
+	cancel     context.CancelFunc
+	done       chan struct{} // closed when the cleanup goroutine exits
+	memoryDone chan struct{} // closed when the memory monitor exits; nil without one
+	cost       func(value any) int64
+	tracer     func(Event) // nil unless WithTracer is set
+	config     Config
+	mu         sync.RWMutex
+	rejected   atomic.Int64
+
+	pressureClears atomic.Int64
+
+	// GetOrSetEx loads in progress, by stored key
+	flightsMu sync.Mutex
+	flights   map[string]*flight
+
+	// aboveHighWater records that the KeyHighWater warning has fired and not
+	// yet re-armed. Guarded by mu.
+	aboveHighWater bool
 }
 
 // Config holds cache configuration
 type Config struct {
-	// MaxCost is the maximum cost of cache entries (in bytes approximately)
+	// MaxCost is the maximum total cost of cache entries. By default every
+	// value costs a flat 64 bytes, so MaxCost bounds the number of entries
+	// rather than their size; set MaxBytes to budget real bytes.
 	MaxCost int64
+	// MaxBytes, if positive, bounds the cache to about this many bytes. It
+	// replaces MaxCost, and each value is charged its size as estimated by
+	// SizeOf unless WithCostFunc sets another cost. Ristretto also charges
+	// its own per-entry bookkeeping, and its admission policy and buffered
+	// Sets mean the total can briefly overshoot or stay well under the
+	// limit, so treat it as an approximate bound, not an exact one. Keys
+	// and TTL bookkeeping are not counted.
+	MaxBytes int64
 	// NumCounters is the number of keys to track frequency
 	NumCounters int64
 	// BufferItems is the size of the internal buffer
 	BufferItems int64
+	// DefaultTTL is the TTL used by SetDefault, and by Set when ZeroTTLUsesDefault
+	// is true. Zero means entries stored with the default never expire.
+	DefaultTTL time.Duration
+	// ZeroTTLUsesDefault makes Set apply DefaultTTL when called with a zero TTL,
+	// so a forgotten TTL doesn't keep entries forever. When false (the default),
+	// a zero TTL means the value never expires. Pass NoExpiration to store a
+	// value forever regardless of this setting.
+	ZeroTTLUsesDefault bool
+	// Clock supplies the time used to track and enforce TTLs. Defaults to
+	// SystemClock when nil.
+	Clock Clock
+	// KeyHighWater, if positive, logs a warning when the number of keys with a
+	// tracked TTL (see TrackedKeys) exceeds it. Ristretto bounds the cached
+	// values, but not the TTL bookkeeping, so steady growth past a sensible
+	// high-water mark usually means keys are built from unbounded input. The
+	// warning fires once per crossing and re-arms once the count drops back.
+	KeyHighWater int
+	// OnKeyHighWater, if set, is called with the tracked key count whenever
+	// the KeyHighWater warning fires, for alerting beyond the log.
+	OnKeyHighWater func(trackedKeys int)
+	// MemoryLimit, if positive, starts a background monitor that clears the
+	// whole cache whenever the process's heap (runtime.MemStats.HeapAlloc)
+	// exceeds this many bytes, for memory-constrained hosts. Each clear is
+	// logged and counted in PressureClears. A reading over the limit is
+	// confirmed after a garbage collection, and when clearing doesn't bring
+	// memory back under the limit, because the cache isn't what holds it,
+	// the monitor backs off before clearing again. Zero disables the monitor.
+	MemoryLimit int64
+	// MemoryCheckInterval is how often the memory monitor checks memory use.
+	// Defaults to DefaultMemoryCheckInterval.
+	MemoryCheckInterval time.Duration
+	// HashKeys stores, logs, and traces every key as its SHA-256 digest
+	// instead of the key itself, so keys built from user input stay short and
+	// don't leak into logs. Lookups hash the same way, so the cache is used
+	// exactly as without hashing. The digest is truncated to 128 bits: two
+	// distinct keys colliding, and so sharing one entry, is negligible (about
+	// one in 10^19 even at four billion keys), but hashed keys cannot be read
+	// back in logs, so debugging sees opaque keys.
+	HashKeys bool
+	// DeleteExpiredOnGet makes Get remove an expired entry as soon as it is
+	// read. By default Get only reports the miss and leaves the removal to
+	// the background cleanup, so concurrent readers of an expired key don't
+	// each take the write lock. Deleting on Get frees expired values sooner,
+	// which matters only for large values read after expiring.
+	DeleteExpiredOnGet bool
 }
 
 // DefaultConfig returns sensible defaults for the cache
@@ -67,8 +160,29 @@ func DefaultConfig() Config {
 }
 
 // New creates a new cache instance
-func New(cfg Config, logger *zap.Logger) (*Cache, error) {
+//
+// Options tune Ristretto beyond what Config covers; see Option.
+func New(cfg Config, logger *zap.Logger, opts ...Option) (*Cache, error) {
+	return NewWithContext(context.Background(), cfg, logger, opts...)
+}
+
+// NewWithContext creates a new cache whose background TTL cleanup stops when
+// ctx is canceled, even if Close is never called.
+//
+// Close is still needed to release Ristretto's own goroutines; canceling ctx
+// only guarantees the cache's cleanup doesn't outlive its owner.
+func NewWithContext(ctx context.Context, cfg Config, logger *zap.Logger, opts ...Option) (*Cache, error) {
 	// Validate configuration
+	if cfg.MaxBytes < 0 {
+		return nil, &ValidationError{
+			Err:   ErrInvalidMaxBytes,
+			Field: "MaxBytes",
+			Value: cfg.MaxBytes,
+		}
+	}
+	if cfg.MaxBytes > 0 {
+		cfg.MaxCost = cfg.MaxBytes
+	}
 	if cfg.MaxCost <= 0 {
 		return nil, &ValidationError{
 			Err:   ErrInvalidMaxCost,
@@ -90,99 +204,434 @@ func New(cfg Config, logger *zap.Logger) (*Cache, error) {
 			Value: cfg.BufferItems,
 		}
 	}
+	if cfg.DefaultTTL < 0 {
+		return nil, &ValidationError{
+			Err:   ErrInvalidDefaultTTL,
+			Field: "DefaultTTL",
+			Value: int64(cfg.DefaultTTL),
+		}
+	}
+	if cfg.KeyHighWater < 0 {
+		return nil, &ValidationError{
+			Err:   ErrInvalidKeyHighWater,
+			Field: "KeyHighWater",
+			Value: int64(cfg.KeyHighWater),
+		}
+	}
+	if cfg.MemoryLimit < 0 {
+		return nil, &ValidationError{
+			Err:   ErrInvalidMemoryLimit,
+			Field: "MemoryLimit",
+			Value: cfg.MemoryLimit,
+		}
+	}
+	if cfg.MemoryCheckInterval < 0 {
+		return nil, &ValidationError{
+			Err:   ErrInvalidMemoryCheckInterval,
+			Field: "MemoryCheckInterval",
+			Value: int64(cfg.MemoryCheckInterval),
+		}
+	}
 
+	// The rejection callback only fires for Sets, which can't happen before c
+	// is assigned below
+	var c *Cache
+	o := newOptions(opts)
+	if cfg.MaxBytes > 0 && o.cost == nil {
+		o.cost = SizeOf
+	}
+	var onEvict func(item *ristretto.Item[any])
+	if o.tracer != nil {
+		onEvict = func(item *ristretto.Item[any]) { c.onEvict(item) }
+	}
 	store, err := ristretto.NewCache(&ristretto.Config[string, any]{
-		MaxCost:     cfg.MaxCost,
-		NumCounters: cfg.NumCounters,
-		BufferItems: cfg.BufferItems,
-		Metrics:     true,
+		MaxCost:            cfg.MaxCost,
+		NumCounters:        cfg.NumCounters,
+		BufferItems:        cfg.BufferItems,
+		Metrics:            !o.disableMetrics,
+		IgnoreInternalCost: o.ignoreInternalCost,
+		KeyToHash:          o.keyToHash,
+		OnReject:           func(item *ristretto.Item[any]) { c.onReject(item) },
+		OnEvict:            onEvict,
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	ctx, cancel := context.WithCancel(context.Background())
-	c := &Cache{
-		store:  store,
-		logger: logger,
-		ttls:   make(map[string]time.Time),
-		cancel: cancel,
+	clock := cfg.Clock
+	if clock == nil {
+		clock = SystemClock()
 	}
 
+	ctx, cancel := context.WithCancel(ctx)
+	c = &Cache{
+		store:      store,
+		clock:      clock,
+		ttls:       make(map[string]time.Time),
+		namespaces: make(map[string]*NamespacedCache),
+		flights:    make(map[string]*flight),
+		cancel:     cancel,
+		done:       make(chan struct{}),
+		config:     cfg,
+		cost:       o.cost,
+		tracer:     o.tracer,
+	}
+	c.SetLogger(logger)
+
 	// Start background TTL cleanup
 	go c.cleanupExpired(ctx)
 
+	if cfg.MemoryLimit > 0 {
+		// The heap includes garbage, so confirm a default reading over the
+		// limit after a collection
+		usage, collect := o.memoryStats, false
+		if usage == nil {
+			usage, collect = heapAlloc, true
+		}
+		c.memoryDone = make(chan struct{})
+		go c.monitorMemory(ctx, usage, collect)
+	}
+
 	return c, nil
 }
 
 // Get retrieves a value from the cache and checks TTL expiration.
 //
 // This method performs both ristretto cache lookup and TTL validation.
-// If the value has expired based on its TTL, it's automatically deleted
-// and treated as a cache miss. The TTL check is performed atomically
-// to prevent race conditions.
+// A value whose TTL has elapsed is reported as a miss right away, even
+// before the background cleanup removes it; set Config.DeleteExpiredOnGet
+// to have Get remove it instead. The TTL check is performed atomically to
+// prevent race conditions.
 //
 // Returns the cached value and true if found and not expired,
 // or nil and false if not found or expired.
 func (c *Cache) Get(key string) (any, bool) {
+	key = c.storageKey(key)
+
 	c.mu.RLock()
 	expiry, hasExpiry := c.ttls[key]
 	c.mu.RUnlock()
 
-	if hasExpiry && time.Now().After(expiry) {
-		c.Delete(key)
+	if hasExpiry && c.clock.Now().After(expiry) {
+		if c.config.DeleteExpiredOnGet {
+			c.delete(key)
+			c.trace(Event{Type: EventExpire, Key: key, Reason: "ttl elapsed"})
+		}
+		c.trace(Event{Type: EventMiss, Key: key, Reason: "expired"})
 		return nil, false
 	}
 
 	value, found := c.store.Get(key)
 	if !found {
+		c.trace(Event{Type: EventMiss, Key: key, Reason: "not found"})
 		return nil, false
 	}
 
-	c.logger.Debug("cache hit", zap.String("key", key))
+	c.log().Debug("cache hit", zap.String("key", key))
+	c.trace(Event{Type: EventHit, Key: key})
 	return value, true
 }
 
+// Has reports whether key is present in the cache and not expired.
+//
+// Unlike Get, Has does not fetch the value and does not count as a hit or
+// miss in the Ristretto metrics: presence is checked with Ristretto's GetTTL,
+// which consults the store without touching the metrics or admission policy.
+// Expired entries report false but are left for the background cleanup to
+// remove.
+func (c *Cache) Has(key string) bool {
+	key = c.storageKey(key)
+
+	c.mu.RLock()
+	expiry, hasExpiry := c.ttls[key]
+	c.mu.RUnlock()
+
+	if hasExpiry && c.clock.Now().After(expiry) {
+		return false
+	}
+
+	_, found := c.store.GetTTL(key)
+	return found
+}
+
 // Set stores a value in the cache with TTL (time-to-live).
 //
-// The value is stored with an estimated cost (64 bytes base overhead).
+// The value is stored with a flat cost of 64 bytes, its SizeOf estimate when
+// Config.MaxBytes is set, or the cost reported by the WithCostFunc option.
 // If the cache is full and cannot evict items, the set operation may fail
 // silently. This is by design in Ristretto to maintain performance.
 //
 // TTL is tracked separately and enforced on Get() and by a background
 // cleanup goroutine that runs every 30 seconds. Setting ttl to 0 means
-// the value never expires (until explicitly deleted or evicted).
+// the value never expires (until explicitly deleted or evicted), unless
+// Config.ZeroTTLUsesDefault is set, in which case Config.DefaultTTL applies.
+// NoExpiration (or any negative ttl) always means the value never expires.
 //
 // This method is thread-safe and can be called concurrently.
 func (c *Cache) Set(key string, value any, ttl time.Duration) {
+	c.set(c.storageKey(key), value, ttl)
+}
+
+// SetWithResult stores a value like Set and reports whether the cache accepted it.
+//
+// It returns false when the value's cost exceeds Config.MaxCost, or when
+// Ristretto dropped the write under contention. Ristretto may still reject an
+// accepted value later, when its admission policy decides the value isn't
+// worth evicting others for; such rejections are only visible in Rejected.
+func (c *Cache) SetWithResult(key string, value any, ttl time.Duration) bool {
+	return c.set(c.storageKey(key), value, ttl)
+}
+
+// SetWithCost stores a value like SetWithResult, charging it cost against
+// Config.MaxCost instead of the estimated or WithCostFunc cost. Use it when the
+// caller knows the value's size, such as the length of cached content.
+func (c *Cache) SetWithCost(key string, value any, cost int64, ttl time.Duration) bool {
+	return c.setWithCost(c.storageKey(key), value, cost, ttl)
+}
+
+// SetDefault stores a value using Config.DefaultTTL.
+//
+// If no DefaultTTL is configured, the value never expires.
+func (c *Cache) SetDefault(key string, value any) {
+	ttl := c.config.DefaultTTL
+	if ttl == 0 {
+		ttl = NoExpiration
+	}
+	c.set(c.storageKey(key), value, ttl)
+}
+
+// SetWait stores a value like Set, then blocks until the write has been applied.
+//
+// Ristretto buffers Sets and applies them asynchronously, so a Get immediately
+// after Set may miss. SetWait waits for the buffer to drain, making the entry
+// immediately visible to Get without resorting to sleeps.
+//
+// Returns false if Ristretto rejected the entry (for example, due to contention
+// or cost admission), in which case the value is not cached.
+func (c *Cache) SetWait(key string, value any, ttl time.Duration) bool {
+	stored := c.set(c.storageKey(key), value, ttl)
+	c.store.Wait()
+	return stored
+}
+
+// Flush blocks until every Set issued before the call has been applied.
+//
+// Where SetWait waits for a single write, Flush settles a whole batch: call it
+// after warming the cache with many Sets, or before reading the cache back in
+// full, so every preceding write is visible to Get.
+func (c *Cache) Flush() {
+	c.store.Wait()
+}
+
+// GetOrSet returns the value cached under key, calling load to produce and
+// cache it on a miss.
+//
+// load receives ctx, so a slow loader such as an HTTP call observes the
+// caller's deadline and cancellation. Errors from load are returned and
+// nothing is cached. A value is only cached if load finished before ctx was
+// done: if ctx expires while load runs, GetOrSet returns ctx.Err() even when
+// load ignored the context and produced a value. A ctx that is already done
+// skips load entirely.
+//
+// The value is stored like SetWait, so it is visible to Get as soon as
+// GetOrSet returns. Concurrent misses for the same key each call load.
+func (c *Cache) GetOrSet(ctx context.Context, key string, ttl time.Duration, load func(ctx context.Context) (any, error)) (any, error) {
+	if value, found := c.Get(key); found {
+		return value, nil
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	value, err := load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	key = c.storageKey(key)
+	if err := ctx.Err(); err != nil {
+		c.log().Debug("not caching value loaded after the context was done",
+			zap.String("key", key),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+
+	c.set(key, value, ttl)
+	c.store.Wait()
+	return value, nil
+}
+
+// set stores the value at its estimated or WithCostFunc cost. Like the other
+// unexported methods taking a key, it expects the key returned by storageKey.
+func (c *Cache) set(key string, value any, ttl time.Duration) bool {
 	// Calculate cost (rough estimate based on type)
 	cost := int64(64) // base overhead
+	if c.cost != nil {
+		cost = c.cost(value)
+	}
+	return c.setWithCost(key, value, cost, ttl)
+}
+
+// setWithCost stores the value and tracks its TTL, reporting whether
+// Ristretto accepted it.
+func (c *Cache) setWithCost(key string, value any, cost int64, ttl time.Duration) bool {
+	if ttl == 0 && c.config.ZeroTTLUsesDefault {
+		ttl = c.config.DefaultTTL
+	}
+
+	// Ristretto would accept an oversized value only to silently reject it
+	// later; refuse it up front, and drop any older value so Get doesn't keep
+	// serving it
+	if cost > c.config.MaxCost {
+		c.delete(key)
+		c.rejected.Add(1)
+		c.log().Warn("cache value rejected: cost exceeds MaxCost",
+			zap.String("key", key),
+			zap.Int64("cost", cost),
+			zap.Int64("max_cost", c.config.MaxCost),
+		)
+		c.trace(Event{Type: EventReject, Key: key, Reason: "cost exceeds MaxCost", Cost: cost})
+		return false
+	}
 
 	// Store with cost
-	c.store.Set(key, value, cost)
+	stored := c.store.Set(key, value, cost)
+	if !stored {
+		c.rejected.Add(1)
+		c.log().Warn("cache value dropped under contention", zap.String("key", key))
+		c.trace(Event{Type: EventReject, Key: key, Reason: "dropped under contention", Cost: cost})
+	}
 
-	// Track TTL
+	// Track TTL, dropping any expiry left over from a previous Set of the key
+	c.mu.Lock()
 	if ttl > 0 {
-		c.mu.Lock()
-		c.ttls[key] = time.Now().Add(ttl)
-		c.mu.Unlock()
+		c.ttls[key] = c.clock.Now().Add(ttl)
+	} else {
+		delete(c.ttls, key)
+	}
+	tracked, crossed := len(c.ttls), c.checkHighWater()
+	c.mu.Unlock()
+
+	if crossed {
+		c.log().Warn("cache tracked keys exceeded high-water mark; check for keys built from unbounded input",
+			zap.Int("tracked_keys", tracked),
+			zap.Int("high_water", c.config.KeyHighWater),
+		)
+		if c.config.OnKeyHighWater != nil {
+			c.config.OnKeyHighWater(tracked)
+		}
 	}
 
-	c.logger.Debug("cache set",
+	c.log().Debug("cache set",
 		zap.String("key", key),
 		zap.Duration("ttl", ttl),
 	)
+	if stored {
+		c.trace(Event{Type: EventSet, Key: key, TTL: ttl, Cost: cost})
+	}
+
+	return stored
+}
+
+// onReject is called by Ristretto when its admission policy rejects a Set.
+func (c *Cache) onReject(item *ristretto.Item[any]) {
+	c.rejected.Add(1)
+
+	// Values too large to ever fit are a configuration problem worth a warning;
+	// admission rejections of ordinary values are routine under memory pressure
+	if item.Cost > c.config.MaxCost {
+		c.log().Warn("cache value rejected: cost exceeds MaxCost",
+			zap.Int64("cost", item.Cost),
+			zap.Int64("max_cost", c.config.MaxCost),
+		)
+		c.trace(Event{Type: EventReject, Reason: "cost exceeds MaxCost", Cost: item.Cost})
+		return
+	}
+	c.log().Debug("cache value rejected by admission policy", zap.Int64("cost", item.Cost))
+	c.trace(Event{Type: EventReject, Reason: "admission policy", Cost: item.Cost})
+}
+
+// MaxCost returns the cache's total cost budget: Config.MaxBytes if set, and
+// Config.MaxCost otherwise.
+func (c *Cache) MaxCost() int64 {
+	return c.config.MaxCost
 }
 
-// Delete removes a value from the cache
+// Rejected returns how many Sets the cache did not store: values whose cost
+// exceeded Config.MaxCost, writes dropped under contention, and values refused
+// by Ristretto's admission policy.
+func (c *Cache) Rejected() int64 {
+	return c.rejected.Load()
+}
+
+// checkHighWater reports whether the tracked key count just crossed
+// Config.KeyHighWater, re-arming the check once the count is back below it.
+// The caller must hold mu.
+func (c *Cache) checkHighWater() bool {
+	if c.config.KeyHighWater <= 0 {
+		return false
+	}
+	if len(c.ttls) <= c.config.KeyHighWater {
+		c.aboveHighWater = false
+		return false
+	}
+	if c.aboveHighWater {
+		return false
+	}
+	c.aboveHighWater = true
+	return true
+}
+
+// TrackedKeys returns the number of keys whose TTL the cache is tracking.
+//
+// Expired keys remain tracked until the background cleanup removes them (or
+// Get does, with Config.DeleteExpiredOnGet), and keys evicted by Ristretto
+// remain tracked until they expire, so the count can exceed the number of
+// cached values.
+func (c *Cache) TrackedKeys() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.ttls)
+}
+
+// contains reports whether key holds an unexpired value, without counting a
+// hit or miss.
+func (c *Cache) contains(key string) bool {
+	key = c.storageKey(key)
+	if _, ok := c.store.GetTTL(key); !ok {
+		return false
+	}
+
+	c.mu.RLock()
+	expiry, hasExpiry := c.ttls[key]
+	c.mu.RUnlock()
+	return !hasExpiry || !c.clock.Now().After(expiry)
+}
+
+// Delete removes a value from the cache, along with a GetOrSetEx record
+// that key was not found.
 func (c *Cache) Delete(key string) {
+	negativeKey := c.storageKey(absentKey(key))
+	c.store.Del(negativeKey)
+	c.mu.Lock()
+	delete(c.ttls, negativeKey)
+	c.mu.Unlock()
+
+	key = c.storageKey(key)
+	c.delete(key)
+	c.trace(Event{Type: EventDelete, Key: key})
+}
+
+// delete removes a value without tracing it, for callers that trace the
+// removal under their own reason.
+func (c *Cache) delete(key string) {
 	c.store.Del(key)
 
 	c.mu.Lock()
 	delete(c.ttls, key)
 	c.mu.Unlock()
 
-	c.logger.Debug("cache delete", zap.String("key", key))
+	c.log().Debug("cache delete", zap.String("key", key))
 }
 
 // Clear removes all entries from the cache
@@ -193,7 +642,7 @@ func (c *Cache) Clear() {
 	c.ttls = make(map[string]time.Time)
 	c.mu.Unlock()
 
-	c.logger.Info("cache cleared")
+	c.log().Info("cache cleared")
 }
 
 // Metrics returns cache performance metrics
@@ -203,16 +652,18 @@ func (c *Cache) Metrics() *ristretto.Metrics {
 
 // cleanupExpired runs a background goroutine to clean up expired entries
 func (c *Cache) cleanupExpired(ctx context.Context) {
+	defer close(c.done)
+
 	ticker := time.NewTicker(30 * time.Second)
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-ctx.Done():
-			c.logger.Debug("cache cleanup stopped")
+			c.log().Debug("cache cleanup stopped")
 			return
 		case <-ticker.C:
-			now := time.Now()
+			now := c.clock.Now()
 			var expired []string
 
 			c.mu.RLock()
@@ -224,20 +675,43 @@ func (c *Cache) cleanupExpired(ctx context.Context) {
 			c.mu.RUnlock()
 
 			for _, key := range expired {
-				c.Delete(key)
+				c.delete(key)
+				c.trace(Event{Type: EventExpire, Key: key, Reason: "background cleanup"})
 			}
 
 			if len(expired) > 0 {
-				c.logger.Debug("cleaned expired entries", zap.Int("count", len(expired)))
+				c.log().Debug("cleaned expired entries", zap.Int("count", len(expired)))
 			}
 		}
 	}
 }
 
-// Close shuts down the cache
+// SetLogger replaces the logger the cache writes to. It is safe to call
+// while the cache is in use; a nil logger discards logs.
+func (c *Cache) SetLogger(logger *zap.Logger) {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	c.logger.Store(logger)
+}
+
+// log returns the current logger.
+func (c *Cache) log() *zap.Logger {
+	return c.logger.Load()
+}
+
+// Close shuts down the cache, waiting for the background cleanup and memory
+// monitor to stop. It must not be called while loads started by GetOrSet or
+// GetOrSetEx are still in flight, since they store their values afterwards.
 func (c *Cache) Close() {
 	if c.cancel != nil {
 		c.cancel()
 	}
+	if c.done != nil {
+		<-c.done
+	}
+	if c.memoryDone != nil {
+		<-c.memoryDone
+	}
 	c.store.Close()
 }
@@ -1,12 +1,18 @@
 package cache
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 	"go.uber.org/zap/zaptest"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 func TestNew_InvalidConfig(t *testing.T) {
@@ -108,6 +114,36 @@ func runConfigTest(t *testing.T, logger *zap.Logger, cfg Config, wantError bool,
 	}
 }
 
+// fakeClock is a Clock that only moves when advanced.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestSystemClock(t *testing.T) {
+	before := time.Now()
+	now := SystemClock().Now()
+	if now.Before(before) || now.After(time.Now()) {
+		t.Errorf("expected system clock to report the current time, got %v", now)
+	}
+}
+
 func TestCache_GetSet(t *testing.T) {
 	logger := zaptest.NewLogger(t)
 	c, err := New(DefaultConfig(), logger)
@@ -137,7 +173,10 @@ func TestCache_GetSet(t *testing.T) {
 
 func TestCache_Expiration(t *testing.T) {
 	logger := zaptest.NewLogger(t)
-	c, err := New(DefaultConfig(), logger)
+	clock := newFakeClock()
+	cfg := DefaultConfig()
+	cfg.Clock = clock
+	c, err := New(cfg, logger)
 	if err != nil {
 		t.Fatalf("failed to create cache: %v", err)
 	}
@@ -145,26 +184,57 @@ func TestCache_Expiration(t *testing.T) {
 
 	key := "expire-key"
 	value := "expire-value"
-	c.Set(key, value, 50*time.Millisecond)
-
-	time.Sleep(10 * time.Millisecond)
+	c.SetWait(key, value, time.Minute)
 
 	// Should still be there
+	clock.Advance(59 * time.Second)
 	_, found := c.Get(key)
 	if !found {
 		t.Error("expected value to be found before expiration")
 	}
 
-	// Wait for expiration
-	time.Sleep(100 * time.Millisecond)
-
 	// Should be gone
+	clock.Advance(2 * time.Second)
 	_, found = c.Get(key)
 	if found {
 		t.Error("expected value to be expired")
 	}
 }
 
+func TestCache_GetExpiredLeavesRemovalToCleanup(t *testing.T) {
+	for _, deleteOnGet := range []bool{false, true} {
+		t.Run(fmt.Sprintf("DeleteExpiredOnGet=%v", deleteOnGet), func(t *testing.T) {
+			clock := newFakeClock()
+			cfg := DefaultConfig()
+			cfg.Clock = clock
+			cfg.DeleteExpiredOnGet = deleteOnGet
+			c, err := New(cfg, zaptest.NewLogger(t))
+			if err != nil {
+				t.Fatalf("failed to create cache: %v", err)
+			}
+			defer c.Close()
+
+			c.SetWait("key", "value", time.Minute)
+			clock.Advance(2 * time.Minute)
+
+			// The miss is reported immediately, by every reader
+			for i := 0; i < 3; i++ {
+				if _, found := c.Get("key"); found {
+					t.Fatalf("Get #%d found an expired value", i+1)
+				}
+			}
+
+			wantTracked := 1
+			if deleteOnGet {
+				wantTracked = 0
+			}
+			if got := c.TrackedKeys(); got != wantTracked {
+				t.Errorf("TrackedKeys() = %d, want %d", got, wantTracked)
+			}
+		})
+	}
+}
+
 func TestCache_Delete(t *testing.T) {
 	logger := zaptest.NewLogger(t)
 	c, err := New(DefaultConfig(), logger)
@@ -225,6 +295,43 @@ func BenchmarkCache_Set(b *testing.B) {
 	}
 }
 
+// BenchmarkCache_GetExpiredParallel reads distinct expired keys from
+// concurrent goroutines, the pattern that made every reader of an expired key
+// take the write lock when Get deleted it.
+func BenchmarkCache_GetExpiredParallel(b *testing.B) {
+	for _, deleteOnGet := range []bool{false, true} {
+		b.Run(fmt.Sprintf("DeleteExpiredOnGet=%v", deleteOnGet), func(b *testing.B) {
+			cfg := DefaultConfig()
+			cfg.DeleteExpiredOnGet = deleteOnGet
+			c, err := New(cfg, zap.NewNop())
+			if err != nil {
+				b.Fatalf("failed to create cache: %v", err)
+			}
+			defer c.Close()
+
+			// Track one expired key per read; the values themselves don't
+			// matter, since the expiry check comes first
+			keys := make([]string, b.N)
+			expired := time.Now().Add(-time.Minute)
+			c.mu.Lock()
+			for i := range keys {
+				keys[i] = fmt.Sprintf("key-%d", i)
+				c.ttls[keys[i]] = expired
+			}
+			c.mu.Unlock()
+
+			var next atomic.Int64
+			b.ReportAllocs()
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					c.Get(keys[next.Add(1)-1])
+				}
+			})
+		})
+	}
+}
+
 func TestCache_Clear(t *testing.T) {
 	logger := zaptest.NewLogger(t)
 	c, err := New(DefaultConfig(), logger)
@@ -294,3 +401,479 @@ func TestCache_Metrics(t *testing.T) {
 		t.Error("expected at least one cache miss to be recorded")
 	}
 }
+
+func TestCache_SetWait(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	c, err := New(DefaultConfig(), logger)
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	defer c.Close()
+
+	for i := 0; i < 100; i++ {
+		key := fmt.Sprintf("key-%d", i)
+		if !c.SetWait(key, i, time.Minute) {
+			t.Fatalf("expected %s to be stored", key)
+		}
+
+		// No sleep: the value must be visible immediately
+		value, found := c.Get(key)
+		if !found {
+			t.Fatalf("expected %s to be found immediately after SetWait", key)
+		}
+		if value != i {
+			t.Errorf("expected %d, got %v", i, value)
+		}
+	}
+}
+
+func TestCache_GetOrSet(t *testing.T) {
+	c, err := New(DefaultConfig(), zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	defer c.Close()
+
+	calls := 0
+	load := func(ctx context.Context) (any, error) {
+		calls++
+		return "loaded", nil
+	}
+
+	for i := 0; i < 3; i++ {
+		value, err := c.GetOrSet(context.Background(), "key", time.Minute, load)
+		if err != nil {
+			t.Fatalf("GetOrSet returned error: %v", err)
+		}
+		if value != "loaded" {
+			t.Errorf("expected loaded, got %v", value)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("expected the loader to run once, ran %d times", calls)
+	}
+}
+
+func TestCache_GetOrSet_NotCached(t *testing.T) {
+	errUpstream := errors.New("upstream failed")
+
+	// slowLoad blocks until ctx is done unless it returns first
+	slowLoad := func(ctx context.Context) (any, error) {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(time.Second):
+			return "too late", nil
+		}
+	}
+	// stubbornLoad ignores ctx and returns after the deadline
+	stubbornLoad := func(ctx context.Context) (any, error) {
+		<-ctx.Done()
+		return "too late", nil
+	}
+
+	tests := []struct {
+		name    string
+		ctx     func() (context.Context, context.CancelFunc)
+		load    func(ctx context.Context) (any, error)
+		wantErr error
+	}{
+		{
+			name: "loader error",
+			ctx:  func() (context.Context, context.CancelFunc) { return context.WithCancel(context.Background()) },
+			load: func(ctx context.Context) (any, error) {
+				return nil, errUpstream
+			},
+			wantErr: errUpstream,
+		},
+		{
+			name: "loader honors deadline",
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithTimeout(context.Background(), 10*time.Millisecond)
+			},
+			load:    slowLoad,
+			wantErr: context.DeadlineExceeded,
+		},
+		{
+			name: "loader ignores deadline",
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithTimeout(context.Background(), 10*time.Millisecond)
+			},
+			load:    stubbornLoad,
+			wantErr: context.DeadlineExceeded,
+		},
+		{
+			name: "context already canceled",
+			ctx: func() (context.Context, context.CancelFunc) {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				return ctx, cancel
+			},
+			load: func(ctx context.Context) (any, error) {
+				t.Error("loader called with a canceled context")
+				return "unexpected", nil
+			},
+			wantErr: context.Canceled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := New(DefaultConfig(), zaptest.NewLogger(t))
+			if err != nil {
+				t.Fatalf("failed to create cache: %v", err)
+			}
+			defer c.Close()
+
+			ctx, cancel := tt.ctx()
+			defer cancel()
+
+			value, err := c.GetOrSet(ctx, "key", time.Minute, tt.load)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if value != nil {
+				t.Errorf("expected no value, got %v", value)
+			}
+
+			c.Flush()
+			if value, found := c.Get("key"); found {
+				t.Errorf("expected nothing cached, got %v", value)
+			}
+		})
+	}
+}
+
+func TestCache_Has(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	clock := newFakeClock()
+	cfg := DefaultConfig()
+	cfg.Clock = clock
+	c, err := New(cfg, logger)
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	defer c.Close()
+
+	c.SetWait("present", "value", time.Hour)
+	c.SetWait("expiring", "value", time.Minute)
+
+	tests := []struct {
+		name    string
+		key     string
+		advance time.Duration
+		want    bool
+	}{
+		{name: "present key", key: "present", want: true},
+		{name: "absent key", key: "absent", want: false},
+		{name: "expired key", key: "expiring", advance: 2 * time.Minute, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock.Advance(tt.advance)
+			if got := c.Has(tt.key); got != tt.want {
+				t.Errorf("Has(%q) = %v, want %v", tt.key, got, tt.want)
+			}
+		})
+	}
+
+	// Has must not be recorded as a hit or miss
+	metrics := c.Metrics()
+	if metrics.Hits() != 0 || metrics.Misses() != 0 {
+		t.Errorf("expected Has to leave metrics untouched, got %d hits and %d misses", metrics.Hits(), metrics.Misses())
+	}
+}
+
+func TestCache_DefaultTTL(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+
+	tests := []struct {
+		name               string
+		set                func(c *Cache)
+		defaultTTL         time.Duration
+		zeroTTLUsesDefault bool
+		wantExpired        bool
+	}{
+		{
+			name:        "SetDefault applies DefaultTTL",
+			defaultTTL:  20 * time.Millisecond,
+			set:         func(c *Cache) { c.SetDefault("key", "value") },
+			wantExpired: true,
+		},
+		{
+			name:        "SetDefault without DefaultTTL never expires",
+			set:         func(c *Cache) { c.SetDefault("key", "value") },
+			wantExpired: false,
+		},
+		{
+			name:               "zero TTL applies DefaultTTL when configured",
+			defaultTTL:         20 * time.Millisecond,
+			zeroTTLUsesDefault: true,
+			set:                func(c *Cache) { c.Set("key", "value", 0) },
+			wantExpired:        true,
+		},
+		{
+			name:        "zero TTL means forever by default",
+			defaultTTL:  20 * time.Millisecond,
+			set:         func(c *Cache) { c.Set("key", "value", 0) },
+			wantExpired: false,
+		},
+		{
+			name:               "NoExpiration overrides DefaultTTL",
+			defaultTTL:         20 * time.Millisecond,
+			zeroTTLUsesDefault: true,
+			set:                func(c *Cache) { c.Set("key", "value", NoExpiration) },
+			wantExpired:        false,
+		},
+		{
+			name: "forever Set replaces an earlier expiry",
+			set: func(c *Cache) {
+				c.Set("key", "value", 20*time.Millisecond)
+				c.Set("key", "value", 0)
+			},
+			wantExpired: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := newFakeClock()
+			cfg := DefaultConfig()
+			cfg.DefaultTTL = tt.defaultTTL
+			cfg.ZeroTTLUsesDefault = tt.zeroTTLUsesDefault
+			cfg.Clock = clock
+			c, err := New(cfg, logger)
+			if err != nil {
+				t.Fatalf("failed to create cache: %v", err)
+			}
+			defer c.Close()
+
+			tt.set(c)
+			c.store.Wait()
+			clock.Advance(time.Second)
+
+			_, found := c.Get("key")
+			if found == tt.wantExpired {
+				t.Errorf("expected expired=%v, but found=%v", tt.wantExpired, found)
+			}
+		})
+	}
+}
+
+func TestNew_InvalidDefaultTTL(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	cfg := DefaultConfig()
+	cfg.DefaultTTL = -time.Second
+
+	runConfigTest(t, logger, cfg, true, ErrInvalidDefaultTTL)
+}
+
+func TestCache_Flush(t *testing.T) {
+	// Keep the per-key debug logs of the batch out of the test output
+	c, err := New(DefaultConfig(), zap.NewNop())
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	defer c.Close()
+
+	const n = 500
+	for i := 0; i < n; i++ {
+		c.Set(fmt.Sprintf("key-%d", i), i, time.Minute)
+	}
+	c.Flush()
+
+	var missing int
+	for i := 0; i < n; i++ {
+		if _, found := c.Get(fmt.Sprintf("key-%d", i)); !found {
+			missing++
+		}
+	}
+	// Ristretto may drop Sets under contention, but never a meaningful share
+	// of an uncontended batch
+	if missing > n/100 {
+		t.Errorf("expected flushed keys to be readable, %d of %d missing", missing, n)
+	}
+}
+
+func TestCache_RejectsOversizedValues(t *testing.T) {
+	tests := []struct {
+		name       string
+		maxCost    int64
+		wantResult bool
+	}{
+		{
+			// Smaller than the cost of any value: refused before reaching Ristretto
+			name:       "cost exceeds MaxCost",
+			maxCost:    32,
+			wantResult: false,
+		},
+		{
+			// Fits on its own, but not with Ristretto's internal per-item cost:
+			// accepted, then rejected by the admission policy
+			name:       "cost with overhead exceeds MaxCost",
+			maxCost:    100,
+			wantResult: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, logs := observer.New(zapcore.WarnLevel)
+			cfg := DefaultConfig()
+			cfg.MaxCost = tt.maxCost
+			c, err := New(cfg, zap.New(core))
+			if err != nil {
+				t.Fatalf("failed to create cache: %v", err)
+			}
+			defer c.Close()
+
+			if got := c.SetWithResult("big", "value", time.Minute); got != tt.wantResult {
+				t.Errorf("SetWithResult() = %v, want %v", got, tt.wantResult)
+			}
+			c.Flush()
+
+			if _, found := c.Get("big"); found {
+				t.Error("expected the oversized value not to be stored")
+			}
+			if got := c.Rejected(); got != 1 {
+				t.Errorf("expected 1 rejection, got %d", got)
+			}
+			if n := logs.FilterMessage("cache value rejected: cost exceeds MaxCost").Len(); n != 1 {
+				t.Errorf("expected 1 rejection warning, got %d", n)
+			}
+		})
+	}
+}
+
+func TestCache_SetWithResult_Accepted(t *testing.T) {
+	c, err := New(DefaultConfig(), zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	defer c.Close()
+
+	if !c.SetWithResult("key", "value", time.Minute) {
+		t.Fatal("expected the value to be accepted")
+	}
+	c.Flush()
+
+	if _, found := c.Get("key"); !found {
+		t.Error("expected the value to be stored")
+	}
+	if got := c.Rejected(); got != 0 {
+		t.Errorf("expected no rejections, got %d", got)
+	}
+}
+
+func TestCache_SetWithCost(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.MaxCost = 1000
+	c, err := New(cfg, zaptest.NewLogger(t), WithCostFunc(func(any) int64 { return 1 }))
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	defer c.Close()
+
+	if c.SetWithCost("large", "value", 2000, time.Minute) {
+		t.Error("expected a cost above MaxCost to be rejected, despite the cost func")
+	}
+	if !c.SetWithCost("small", "value", 10, time.Minute) {
+		t.Fatal("expected the value to be accepted")
+	}
+	c.Flush()
+
+	if _, found := c.Get("small"); !found {
+		t.Error("expected the value to be stored")
+	}
+	if got := c.Rejected(); got != 1 {
+		t.Errorf("expected 1 rejection, got %d", got)
+	}
+	if got := c.MaxCost(); got != 1000 {
+		t.Errorf("MaxCost() = %d, want 1000", got)
+	}
+}
+
+func TestNewWithContext_CancelStopsCleanup(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	c, err := NewWithContext(ctx, DefaultConfig(), zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	defer c.Close()
+
+	select {
+	case <-c.done:
+		t.Fatal("cleanup stopped before the context was canceled")
+	default:
+	}
+
+	cancel()
+
+	select {
+	case <-c.done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("cleanup goroutine did not exit after the context was canceled")
+	}
+}
+
+func TestCache_KeyHighWater(t *testing.T) {
+	core, logs := observer.New(zapcore.WarnLevel)
+	var hookCalls []int
+	cfg := DefaultConfig()
+	cfg.KeyHighWater = 10
+	cfg.OnKeyHighWater = func(trackedKeys int) { hookCalls = append(hookCalls, trackedKeys) }
+	c, err := New(cfg, zap.New(core))
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	defer c.Close()
+
+	const highWaterMsg = "cache tracked keys exceeded high-water mark; check for keys built from unbounded input"
+
+	for i := 0; i < 10; i++ {
+		c.Set(fmt.Sprintf("key-%d", i), i, time.Minute)
+	}
+	if got := c.TrackedKeys(); got != 10 {
+		t.Errorf("expected 10 tracked keys, got %d", got)
+	}
+	if n := logs.FilterMessage(highWaterMsg).Len(); n != 0 {
+		t.Fatalf("expected no warning at the high-water mark, got %d", n)
+	}
+
+	// Crossing the mark warns once, however far past it the count goes
+	for i := 10; i < 25; i++ {
+		c.Set(fmt.Sprintf("key-%d", i), i, time.Minute)
+	}
+	if got := c.TrackedKeys(); got != 25 {
+		t.Errorf("expected 25 tracked keys, got %d", got)
+	}
+	entries := logs.FilterMessage(highWaterMsg).All()
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 warning, got %d", len(entries))
+	}
+	if got := entries[0].ContextMap()["tracked_keys"]; got != int64(11) {
+		t.Errorf("expected warning at 11 tracked keys, got %v", got)
+	}
+	if len(hookCalls) != 1 || hookCalls[0] != 11 {
+		t.Errorf("expected hook called once with 11, got %v", hookCalls)
+	}
+
+	// Dropping back below the mark re-arms the warning
+	c.Clear()
+	c.Set("after-clear", 0, time.Minute)
+	for i := 0; i < 11; i++ {
+		c.Set(fmt.Sprintf("again-%d", i), i, time.Minute)
+	}
+	if n := logs.FilterMessage(highWaterMsg).Len(); n != 2 {
+		t.Errorf("expected the warning to fire again after re-arming, got %d warnings", n)
+	}
+}
+
+func TestNew_InvalidKeyHighWater(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	cfg := DefaultConfig()
+	cfg.KeyHighWater = -1
+
+	runConfigTest(t, logger, cfg, true, ErrInvalidKeyHighWater)
+}
@@ -0,0 +1,22 @@
+package cache
+
+import "time"
+
+// Clock supplies the current time to the cache.
+//
+// The cache reads the time through a Clock when tracking and enforcing TTLs,
+// so tests can substitute a fake clock and advance it instead of sleeping.
+type Clock interface {
+	Now() time.Time
+}
+
+// systemClock is the Clock backed by time.Now.
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+// SystemClock returns the Clock backed by time.Now. It is used when
+// Config.Clock is nil.
+func SystemClock() Clock {
+	return systemClock{}
+}
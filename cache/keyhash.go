@@ -0,0 +1,21 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// storageKey returns the key under which key is stored, traced, and logged:
+// key itself, or its truncated SHA-256 digest when Config.HashKeys is set.
+func (c *Cache) storageKey(key string) string {
+	if !c.config.HashKeys {
+		return key
+	}
+	return hashKey(key)
+}
+
+// hashKey returns the first 128 bits of key's SHA-256 digest, hex encoded.
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:16])
+}
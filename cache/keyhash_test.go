@@ -0,0 +1,103 @@
+package cache
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestCache_HashKeys(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	cfg := DefaultConfig()
+	cfg.HashKeys = true
+	c, err := New(cfg, zap.New(core))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer c.Close()
+
+	const key = "weather:alice@example.com"
+	if !c.SetWait(key, "sunny", time.Minute) {
+		t.Fatal("SetWait() = false")
+	}
+	if value, found := c.Get(key); !found || value != "sunny" {
+		t.Errorf("Get() = %v, %v; want sunny, true", value, found)
+	}
+	if !c.Has(key) {
+		t.Error("Has() = false, want true")
+	}
+	if _, found := c.Get("weather:bob@example.com"); found {
+		t.Error("Get() of another key found a value")
+	}
+
+	value, err := c.GetOrSet(context.Background(), "weather:carol@example.com", time.Minute, func(context.Context) (any, error) {
+		return "rainy", nil
+	})
+	if err != nil || value != "rainy" {
+		t.Fatalf("GetOrSet() = %v, %v", value, err)
+	}
+	if value, found := c.Get("weather:carol@example.com"); !found || value != "rainy" {
+		t.Errorf("Get() after GetOrSet() = %v, %v; want rainy, true", value, found)
+	}
+
+	c.Delete(key)
+	if _, found := c.Get(key); found {
+		t.Error("Get() after Delete() found a value")
+	}
+
+	hashed := hashKey(key)
+	if len(hashed) != 32 {
+		t.Errorf("hashed key %q has length %d, want 32", hashed, len(hashed))
+	}
+	var sawHashed bool
+	for _, entry := range logs.All() {
+		logged, ok := entry.ContextMap()["key"].(string)
+		if !ok {
+			continue
+		}
+		if strings.Contains(logged, "@example.com") {
+			t.Errorf("%q log shows the raw key %q", entry.Message, logged)
+		}
+		if logged == hashed {
+			sawHashed = true
+		}
+	}
+	if !sawHashed {
+		t.Errorf("no debug log shows the hashed key %q", hashed)
+	}
+}
+
+func TestCache_HashKeysTrace(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		events []Event
+	)
+	cfg := DefaultConfig()
+	cfg.HashKeys = true
+	c, err := New(cfg, zap.NewNop(), WithTracer(func(e Event) {
+		mu.Lock()
+		events = append(events, e)
+		mu.Unlock()
+	}))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer c.Close()
+
+	c.SetWait("user:42", "value", time.Minute)
+	c.Get("user:42")
+
+	mu.Lock()
+	defer mu.Unlock()
+	for _, e := range events {
+		if e.Key != "" && e.Key != hashKey("user:42") {
+			t.Errorf("%s event key = %q, want the hashed key", e.Type, e.Key)
+		}
+	}
+}
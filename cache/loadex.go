@@ -0,0 +1,126 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// errLoadPanicked is returned to callers waiting on a GetOrSetEx loader that
+// panicked.
+var errLoadPanicked = errors.New("cache loader panicked")
+
+// absent is the value GetOrSetEx caches for keys its loader found nothing
+// for.
+type absent struct{}
+
+// absentKey returns the key under which GetOrSetEx records that key was not
+// found, kept apart from key so Get and Has never see the marker.
+func absentKey(key string) string {
+	return "\x00absent:" + key
+}
+
+// flight is a GetOrSetEx load in progress, shared by the callers missing the
+// same key.
+type flight struct {
+	done  chan struct{}
+	value any
+	found bool
+	err   error
+}
+
+// GetOrSetEx returns the value cached under key, calling load on a miss, for
+// expensive lookups that sometimes find nothing. load reports whether it
+// found a value: found values are cached for ttl, and absent ones are
+// remembered for negativeTTL, so repeated lookups of a missing key don't call
+// load again until negativeTTL passes. Errors from load are returned and
+// nothing is cached.
+//
+// Concurrent misses for the same key share a single call to load; the other
+// callers wait for its result, or until their own ctx is done. Contexts are
+// handled as by GetOrSet, except that waiting callers receive the loading
+// caller's result, including an error from its context.
+//
+// Absent results are invisible to Get and Has, and Delete clears them along
+// with the value. TTLs follow Set: zero never expires unless
+// Config.ZeroTTLUsesDefault is set.
+func (c *Cache) GetOrSetEx(ctx context.Context, key string, ttl, negativeTTL time.Duration, load func(ctx context.Context) (value any, found bool, err error)) (any, bool, error) {
+	if value, found := c.Get(key); found {
+		return value, true, nil
+	}
+	negativeKey := c.storageKey(absentKey(key))
+	if c.stored(negativeKey) {
+		c.trace(Event{Type: EventHit, Key: c.storageKey(key), Reason: "cached as absent"})
+		return nil, false, nil
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, false, err
+	}
+
+	storedKey := c.storageKey(key)
+	c.flightsMu.Lock()
+	f, loading := c.flights[storedKey]
+	if !loading {
+		f = &flight{done: make(chan struct{}), err: errLoadPanicked}
+		c.flights[storedKey] = f
+	}
+	c.flightsMu.Unlock()
+
+	if loading {
+		select {
+		case <-f.done:
+			return f.value, f.found, f.err
+		case <-ctx.Done():
+			return nil, false, ctx.Err()
+		}
+	}
+
+	defer func() {
+		c.flightsMu.Lock()
+		delete(c.flights, storedKey)
+		c.flightsMu.Unlock()
+		close(f.done)
+	}()
+
+	value, found, err := load(ctx)
+	if err == nil {
+		err = ctx.Err()
+		if err != nil {
+			c.log().Debug("not caching value loaded after the context was done",
+				zap.String("key", storedKey),
+				zap.Error(err),
+			)
+		}
+	}
+	if err != nil {
+		f.value, f.found, f.err = nil, false, err
+		return nil, false, err
+	}
+
+	if found {
+		c.delete(negativeKey)
+		c.set(storedKey, value, ttl)
+	} else {
+		value = nil
+		c.set(negativeKey, absent{}, negativeTTL)
+	}
+	c.store.Wait()
+	f.value, f.found, f.err = value, found, nil
+	return value, found, nil
+}
+
+// stored reports whether the stored key holds an unexpired value, without
+// tracing or logging the lookup.
+func (c *Cache) stored(key string) bool {
+	c.mu.RLock()
+	expiry, hasExpiry := c.ttls[key]
+	c.mu.RUnlock()
+
+	if hasExpiry && c.clock.Now().After(expiry) {
+		return false
+	}
+	_, found := c.store.Get(key)
+	return found
+}
@@ -0,0 +1,165 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zaptest"
+)
+
+func TestGetOrSetEx(t *testing.T) {
+	errLookup := errors.New("lookup failed")
+
+	tests := []struct {
+		name      string
+		value     any
+		found     bool
+		err       error
+		wantCalls int64 // load calls after the second round of lookups
+	}{
+		{name: "found", value: "alice", found: true, wantCalls: 1},
+		{name: "not found is cached", found: false, wantCalls: 1},
+		{name: "error is not cached", err: errLookup, wantCalls: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := newFakeClock()
+			cfg := DefaultConfig()
+			cfg.Clock = clock
+			c, err := New(cfg, zaptest.NewLogger(t))
+			if err != nil {
+				t.Fatalf("failed to create cache: %v", err)
+			}
+			defer c.Close()
+
+			var calls atomic.Int64
+			release := make(chan struct{})
+			load := func(context.Context) (any, bool, error) {
+				calls.Add(1)
+				<-release
+				return tt.value, tt.found, tt.err
+			}
+
+			lookup := func() {
+				const callers = 10
+				var wg sync.WaitGroup
+				for range callers {
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						value, found, err := c.GetOrSetEx(context.Background(), "user:1", time.Minute, 10*time.Second, load)
+						if !errors.Is(err, tt.err) || found != tt.found || value != tt.value {
+							t.Errorf("GetOrSetEx() = %v, %v, %v, want %v, %v, %v", value, found, err, tt.value, tt.found, tt.err)
+						}
+					}()
+				}
+				// Let every caller reach the cache before the load finishes
+				time.Sleep(20 * time.Millisecond)
+				close(release)
+				wg.Wait()
+				release = make(chan struct{})
+			}
+
+			lookup()
+			if got := calls.Load(); got != 1 {
+				t.Fatalf("concurrent misses called load %d times, want 1", got)
+			}
+			lookup()
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Errorf("load called %d times after a second lookup, want %d", got, tt.wantCalls)
+			}
+			if _, found := c.Get("user:1"); found != tt.found {
+				t.Errorf("Get() found = %v, want %v", found, tt.found)
+			}
+		})
+	}
+}
+
+func TestGetOrSetEx_NegativeTTL(t *testing.T) {
+	clock := newFakeClock()
+	cfg := DefaultConfig()
+	cfg.Clock = clock
+	c, err := New(cfg, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	defer c.Close()
+	ctx := context.Background()
+
+	exists := false
+	calls := 0
+	load := func(context.Context) (any, bool, error) {
+		calls++
+		return "alice", exists, nil
+	}
+
+	if _, found, _ := c.GetOrSetEx(ctx, "user:1", time.Minute, 10*time.Second, load); found {
+		t.Fatal("expected the first lookup to find nothing")
+	}
+	if c.Has("user:1") {
+		t.Error("Has() reports a key cached as absent")
+	}
+
+	// The absence is remembered until the negative TTL passes
+	exists = true
+	if _, found, _ := c.GetOrSetEx(ctx, "user:1", time.Minute, 10*time.Second, load); found || calls != 1 {
+		t.Errorf("expected the cached absence, got found = %v after %d loads", found, calls)
+	}
+	clock.Advance(11 * time.Second)
+	if value, found, _ := c.GetOrSetEx(ctx, "user:1", time.Minute, 10*time.Second, load); !found || value != "alice" || calls != 2 {
+		t.Errorf("expected a reload after the negative TTL, got %v, %v after %d loads", value, found, calls)
+	}
+
+	// Delete clears a cached absence too
+	exists = false
+	c.Delete("user:1")
+	if _, found, _ := c.GetOrSetEx(ctx, "user:1", time.Minute, 10*time.Second, load); found || calls != 3 {
+		t.Fatalf("expected a reload after Delete, got found = %v after %d loads", found, calls)
+	}
+	c.Delete("user:1")
+	exists = true
+	if _, found, _ := c.GetOrSetEx(ctx, "user:1", time.Minute, 10*time.Second, load); !found || calls != 4 {
+		t.Errorf("expected Delete to clear the cached absence, got found = %v after %d loads", found, calls)
+	}
+}
+
+func TestGetOrSetEx_WaiterContext(t *testing.T) {
+	c, err := New(DefaultConfig(), zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	defer c.Close()
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	loaded := make(chan struct{})
+	go func() {
+		defer close(loaded)
+		_, _, _ = c.GetOrSetEx(context.Background(), "slow", time.Minute, time.Minute, func(context.Context) (any, bool, error) {
+			close(started)
+			<-release
+			return "done", true, nil
+		})
+	}()
+	<-started
+	// Let the load finish storing its value before the deferred Close
+	defer func() {
+		close(release)
+		<-loaded
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, _, err = c.GetOrSetEx(ctx, "slow", time.Minute, time.Minute, func(context.Context) (any, bool, error) {
+		t.Error("waiting caller ran its own load")
+		return nil, false, nil
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("waiting caller error = %v, want context.DeadlineExceeded", err)
+	}
+}
@@ -0,0 +1,116 @@
+package cache
+
+import (
+	"context"
+	"runtime"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// DefaultMemoryCheckInterval is how often the memory monitor checks memory
+// use when Config.MemoryCheckInterval is zero.
+const DefaultMemoryCheckInterval = 10 * time.Second
+
+// WithMemoryStats replaces the source of the memory use the monitor enabled
+// by Config.MemoryLimit compares against the limit. fn returns a byte count;
+// the default reads runtime.MemStats.HeapAlloc. It is mainly useful in tests,
+// or to watch a different figure such as a cgroup's usage.
+func WithMemoryStats(fn func() uint64) Option {
+	return func(o *options) {
+		o.memoryStats = fn
+	}
+}
+
+// heapAlloc returns the bytes of allocated heap objects.
+func heapAlloc() uint64 {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	return stats.HeapAlloc
+}
+
+// PressureClears returns how many times the memory monitor cleared the cache
+// because memory use exceeded Config.MemoryLimit.
+func (c *Cache) PressureClears() int64 {
+	return c.pressureClears.Load()
+}
+
+// maxMemoryBackoff caps how many checks the memory monitor skips after a
+// clear that didn't bring memory use under the limit.
+const maxMemoryBackoff = 64
+
+// monitorMemory clears the cache whenever memory use exceeds
+// Config.MemoryLimit, checking every Config.MemoryCheckInterval until ctx is
+// canceled. If collect is set, a reading over the limit is taken again after a
+// garbage collection, so garbage doesn't count.
+//
+// When clearing leaves memory use over the limit, the memory is held
+// elsewhere and clearing again right away would only empty the cache for
+// nothing. The monitor then skips a number of checks that doubles with every
+// such clear, up to maxMemoryBackoff, and logs the later clears of the
+// episode at debug level. The backoff resets once memory use is under the
+// limit again.
+func (c *Cache) monitorMemory(ctx context.Context, usage func() uint64, collect bool) {
+	defer close(c.memoryDone)
+
+	interval := c.config.MemoryCheckInterval
+	if interval == 0 {
+		interval = DefaultMemoryCheckInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	limit := uint64(c.config.MemoryLimit) // #nosec G115 -- validated positive
+	measure := func() uint64 {
+		used := usage()
+		if used > limit && collect {
+			runtime.GC()
+			used = usage()
+		}
+		return used
+	}
+
+	var backoff, skip int
+	for {
+		select {
+		case <-ctx.Done():
+			c.log().Debug("cache memory monitor stopped")
+			return
+		case <-ticker.C:
+			used := measure()
+			if used <= limit {
+				backoff, skip = 0, 0
+				continue
+			}
+			if skip > 0 {
+				skip--
+				continue
+			}
+
+			c.Clear()
+			c.pressureClears.Add(1)
+			log := c.log().Warn
+			if backoff > 0 {
+				log = c.log().Debug
+			}
+			log("cache cleared under memory pressure",
+				zap.Uint64("memory_bytes", used),
+				zap.Int64("memory_limit", c.config.MemoryLimit),
+			)
+
+			after := measure()
+			if after <= limit {
+				backoff = 0
+				continue
+			}
+			if backoff == 0 {
+				c.log().Warn("clearing the cache did not bring memory use under the limit, backing off",
+					zap.Uint64("memory_bytes", after),
+					zap.Int64("memory_limit", c.config.MemoryLimit),
+				)
+			}
+			backoff = min(max(2*backoff, 1), maxMemoryBackoff)
+			skip = backoff
+		}
+	}
+}
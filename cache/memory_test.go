@@ -0,0 +1,149 @@
+package cache
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestCache_MemoryPressureClears(t *testing.T) {
+	var usage atomic.Uint64
+	usage.Store(100)
+
+	cfg := DefaultConfig()
+	cfg.MemoryLimit = 1000
+	cfg.MemoryCheckInterval = time.Millisecond
+	core, logs := observer.New(zapcore.WarnLevel)
+	c, err := New(cfg, zap.New(core), WithMemoryStats(usage.Load))
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	defer c.Close()
+
+	c.SetWait("report", "large value", time.Hour)
+
+	// Below the limit, the monitor leaves the cache alone
+	time.Sleep(20 * time.Millisecond)
+	if c.PressureClears() != 0 {
+		t.Fatalf("expected no clears below the limit, got %d", c.PressureClears())
+	}
+	if _, found := c.Get("report"); !found {
+		t.Fatal("expected the value to survive below the limit")
+	}
+
+	usage.Store(5000)
+	deadline := time.Now().Add(5 * time.Second)
+	for c.PressureClears() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("memory monitor did not clear the cache above the limit")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if _, found := c.Get("report"); found {
+		t.Error("expected the cache to be cleared")
+	}
+	if c.TrackedKeys() != 0 {
+		t.Errorf("expected no tracked keys after clearing, got %d", c.TrackedKeys())
+	}
+	entries := logs.FilterMessage("cache cleared under memory pressure").All()
+	if len(entries) == 0 {
+		t.Fatal("expected the clear to be logged")
+	}
+	if got := entries[0].ContextMap()["memory_bytes"]; got != uint64(5000) {
+		t.Errorf("logged memory_bytes = %v, want 5000", got)
+	}
+}
+
+func TestCache_MemoryPressureBacksOff(t *testing.T) {
+	// Memory held outside the cache: clearing never brings it under the limit
+	var usage, checks atomic.Uint64
+	usage.Store(5000)
+	stats := func() uint64 {
+		checks.Add(1)
+		return usage.Load()
+	}
+
+	cfg := DefaultConfig()
+	cfg.MemoryLimit = 1000
+	cfg.MemoryCheckInterval = time.Millisecond
+	core, logs := observer.New(zapcore.WarnLevel)
+	c, err := New(cfg, zap.New(core), WithMemoryStats(stats))
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	defer c.Close()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for checks.Load() < 300 {
+		if time.Now().After(deadline) {
+			t.Fatalf("only %d memory checks ran", checks.Load())
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	clears := c.PressureClears()
+	if clears == 0 || uint64(clears)*10 > checks.Load() {
+		t.Errorf("expected the monitor to back off, got %d clears in %d checks", clears, checks.Load())
+	}
+	if n := logs.FilterMessage("cache cleared under memory pressure").Len(); n != 1 {
+		t.Errorf("expected 1 warning for the episode's clears, got %d", n)
+	}
+	if n := logs.FilterMessage("clearing the cache did not bring memory use under the limit, backing off").Len(); n != 1 {
+		t.Errorf("expected 1 backoff warning, got %d", n)
+	}
+
+	// Once memory use is back under the limit, the backoff resets and the
+	// next excess clears at once
+	usage.Store(100)
+	for seen := checks.Load(); checks.Load() < seen+5; {
+		if time.Now().After(deadline) {
+			t.Fatal("memory checks stopped")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	before := c.PressureClears()
+	usage.Store(5000)
+	for c.PressureClears() == before {
+		if time.Now().After(deadline) {
+			t.Fatal("memory monitor did not clear the cache after the backoff reset")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestCache_MemoryMonitorDisabled(t *testing.T) {
+	var calls atomic.Int32
+	cfg := DefaultConfig()
+	cfg.MemoryCheckInterval = time.Millisecond
+	c, err := New(cfg, zaptest.NewLogger(t), WithMemoryStats(func() uint64 {
+		calls.Add(1)
+		return 1 << 40
+	}))
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	defer c.Close()
+
+	time.Sleep(20 * time.Millisecond)
+	if calls.Load() != 0 || c.PressureClears() != 0 {
+		t.Errorf("expected no monitor without MemoryLimit, got %d checks and %d clears", calls.Load(), c.PressureClears())
+	}
+}
+
+func TestNew_InvalidMemoryConfig(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+
+	cfg := DefaultConfig()
+	cfg.MemoryLimit = -1
+	runConfigTest(t, logger, cfg, true, ErrInvalidMemoryLimit)
+
+	cfg = DefaultConfig()
+	cfg.MemoryCheckInterval = -time.Second
+	runConfigTest(t, logger, cfg, true, ErrInvalidMemoryCheckInterval)
+}
@@ -0,0 +1,124 @@
+package cache
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// NamespacedCache is a view of a Cache that scopes all keys under a prefix.
+//
+// Multiple logical caches (weather, users, sessions) can share one Cache and
+// its cost budget without key collisions. Clear only removes the keys written
+// through this namespace, leaving other namespaces and un-namespaced keys intact.
+type NamespacedCache struct {
+	parent *Cache
+	keys   map[string]struct{}
+	prefix string
+	mu     sync.Mutex
+
+	// pruneAt is the size of keys at which the next Set drops the keys no
+	// longer in the parent cache
+	pruneAt int
+}
+
+// minNamespacePrune is the smallest key count at which a namespace prunes the
+// keys that expired or were evicted.
+const minNamespacePrune = 64
+
+// Namespace returns the namespaced view of the cache for prefix.
+//
+// Calling Namespace repeatedly with the same prefix returns the same instance,
+// so key tracking (and therefore Clear) is shared by all callers.
+func (c *Cache) Namespace(prefix string) *NamespacedCache {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if ns, ok := c.namespaces[prefix]; ok {
+		return ns
+	}
+
+	ns := &NamespacedCache{
+		parent:  c,
+		prefix:  prefix,
+		keys:    make(map[string]struct{}),
+		pruneAt: minNamespacePrune,
+	}
+	c.namespaces[prefix] = ns
+	return ns
+}
+
+// Prefix returns the namespace prefix.
+func (n *NamespacedCache) Prefix() string {
+	return n.prefix
+}
+
+// key returns the fully qualified key in the parent cache. The prefix is
+// length-prefixed so no prefix and key pair can produce another's: without
+// it, prefix "a:b" with key "c" and prefix "a" with key "b:c" would share an
+// entry.
+func (n *NamespacedCache) key(key string) string {
+	return strconv.Itoa(len(n.prefix)) + ":" + n.prefix + ":" + key
+}
+
+// Get retrieves a value from the namespace.
+func (n *NamespacedCache) Get(key string) (any, bool) {
+	return n.parent.Get(n.key(key))
+}
+
+// Set stores a value in the namespace with TTL. See Cache.Set for details.
+func (n *NamespacedCache) Set(key string, value any, ttl time.Duration) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if len(n.keys) >= n.pruneAt {
+		n.prune()
+	}
+	n.keys[key] = struct{}{}
+	n.parent.Set(n.key(key), value, ttl)
+}
+
+// prune drops the tracked keys whose entries expired or were evicted from the
+// parent cache, so the namespace doesn't remember every key ever written. It
+// runs whenever the tracked keys double, keeping Set amortized constant time.
+// n.mu must be held.
+func (n *NamespacedCache) prune() {
+	// Apply buffered Sets first so recent keys aren't mistaken for evicted
+	n.parent.Flush()
+	for key := range n.keys {
+		if !n.parent.contains(n.key(key)) {
+			delete(n.keys, key)
+		}
+	}
+	n.pruneAt = max(minNamespacePrune, 2*len(n.keys))
+}
+
+// Delete removes a value from the namespace.
+func (n *NamespacedCache) Delete(key string) {
+	n.mu.Lock()
+	delete(n.keys, key)
+	n.mu.Unlock()
+
+	n.parent.Delete(n.key(key))
+}
+
+// Clear removes every entry written through this namespace.
+//
+// Entries in other namespaces, or set directly on the parent Cache, are not affected.
+func (n *NamespacedCache) Clear() {
+	n.mu.Lock()
+	keys := n.keys
+	n.keys = make(map[string]struct{})
+	n.mu.Unlock()
+
+	for key := range keys {
+		n.parent.Delete(n.key(key))
+	}
+
+	n.parent.log().Info("cache namespace cleared",
+		zap.String("namespace", n.prefix),
+		zap.Int("keys", len(keys)),
+	)
+}
@@ -0,0 +1,141 @@
+package cache
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zaptest"
+)
+
+func TestNamespace_NoCollision(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	c, err := New(DefaultConfig(), logger)
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	defer c.Close()
+
+	weather := c.Namespace("weather")
+	users := c.Namespace("users")
+
+	weather.Set("42", "sunny", time.Minute)
+	users.Set("42", "alice", time.Minute)
+
+	// Wait for ristretto to process
+	time.Sleep(10 * time.Millisecond)
+
+	if v, ok := weather.Get("42"); !ok || v != "sunny" {
+		t.Errorf("expected weather value %q, got %v (found=%v)", "sunny", v, ok)
+	}
+	if v, ok := users.Get("42"); !ok || v != "alice" {
+		t.Errorf("expected users value %q, got %v (found=%v)", "alice", v, ok)
+	}
+
+	// The un-namespaced key must not be affected either
+	if _, ok := c.Get("42"); ok {
+		t.Error("expected bare key to be absent from the parent cache")
+	}
+}
+
+func TestNamespace_SameInstance(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	c, err := New(DefaultConfig(), logger)
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	defer c.Close()
+
+	if c.Namespace("sessions") != c.Namespace("sessions") {
+		t.Error("expected Namespace to return the same instance for the same prefix")
+	}
+}
+
+func TestNamespace_ClearIsolated(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	c, err := New(DefaultConfig(), logger)
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	defer c.Close()
+
+	weather := c.Namespace("weather")
+	users := c.Namespace("users")
+
+	weather.Set("a", 1, time.Minute)
+	weather.Set("b", 2, time.Minute)
+	users.Set("a", 3, time.Minute)
+	c.Set("global", 4, time.Minute)
+
+	time.Sleep(10 * time.Millisecond)
+
+	weather.Clear()
+
+	if _, ok := weather.Get("a"); ok {
+		t.Error("expected weather:a to be cleared")
+	}
+	if _, ok := weather.Get("b"); ok {
+		t.Error("expected weather:b to be cleared")
+	}
+	if v, ok := users.Get("a"); !ok || v != 3 {
+		t.Errorf("expected users:a to survive, got %v (found=%v)", v, ok)
+	}
+	if v, ok := c.Get("global"); !ok || v != 4 {
+		t.Errorf("expected global key to survive, got %v (found=%v)", v, ok)
+	}
+}
+
+func TestNamespace_PrefixCollision(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	c, err := New(DefaultConfig(), logger)
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	defer c.Close()
+
+	// Without length-prefixing both would be stored under "a:b:c"
+	c.Namespace("a:b").Set("c", "first", time.Minute)
+	c.Namespace("a").Set("b:c", "second", time.Minute)
+	c.Flush()
+
+	if v, ok := c.Namespace("a:b").Get("c"); !ok || v != "first" {
+		t.Errorf("expected %q, got %v (found=%v)", "first", v, ok)
+	}
+	if v, ok := c.Namespace("a").Get("b:c"); !ok || v != "second" {
+		t.Errorf("expected %q, got %v (found=%v)", "second", v, ok)
+	}
+}
+
+func TestNamespace_PrunesExpiredKeys(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	clock := newFakeClock()
+	cfg := DefaultConfig()
+	cfg.Clock = clock
+	c, err := New(cfg, logger)
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	defer c.Close()
+
+	ns := c.Namespace("sessions")
+	for i := range 1000 {
+		ns.Set(fmt.Sprintf("short-%d", i), i, time.Second)
+		if i%50 == 0 {
+			clock.Advance(2 * time.Second)
+		}
+	}
+	ns.Set("long", "kept", time.Hour)
+
+	ns.mu.Lock()
+	tracked := len(ns.keys)
+	ns.mu.Unlock()
+	if tracked > 2*minNamespacePrune {
+		t.Errorf("namespace tracks %d keys, want expired ones pruned", tracked)
+	}
+
+	c.Flush()
+	ns.Clear()
+	if _, ok := ns.Get("long"); ok {
+		t.Error("expected Clear to remove the unexpired key")
+	}
+}
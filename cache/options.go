@@ -0,0 +1,62 @@
+package cache
+
+// Option tunes how the cache drives Ristretto beyond what Config covers.
+//
+// Most servers never need these; they exist for power users tuning eviction
+// behavior without the cache exposing Ristretto's own configuration types.
+type Option func(*options)
+
+// options holds the settings applied by Option values.
+type options struct {
+	ignoreInternalCost bool
+	disableMetrics     bool
+	keyToHash          func(key string) (uint64, uint64)
+	cost               func(value any) int64
+	tracer             func(Event)
+	memoryStats        func() uint64
+}
+
+func newOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+	return o
+}
+
+// WithIgnoreInternalCost stops Ristretto from adding its own per-entry
+// bookkeeping overhead to each entry's cost, so MaxCost budgets only the costs
+// the cache assigns.
+func WithIgnoreInternalCost() Option {
+	return func(o *options) {
+		o.ignoreInternalCost = true
+	}
+}
+
+// WithoutMetrics disables Ristretto's metrics, trading the statistics reported
+// by Metrics (which then read as zero) for slightly faster Gets and Sets.
+func WithoutMetrics() Option {
+	return func(o *options) {
+		o.disableMetrics = true
+	}
+}
+
+// WithKeyToHash replaces the function used to hash keys. It must return two
+// independent hashes of the key: one to locate the entry and one to detect
+// collisions.
+func WithKeyToHash(fn func(key string) (uint64, uint64)) Option {
+	return func(o *options) {
+		o.keyToHash = fn
+	}
+}
+
+// WithCostFunc sets the cost of each value, replacing the flat per-entry cost
+// the cache assigns by default. Values whose cost exceeds Config.MaxCost are
+// rejected.
+func WithCostFunc(fn func(value any) int64) Option {
+	return func(o *options) {
+		o.cost = fn
+	}
+}
@@ -0,0 +1,111 @@
+package cache
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zaptest"
+)
+
+func TestOptions(t *testing.T) {
+	tests := []struct {
+		name   string
+		cfg    func(*Config)
+		opts   func(t *testing.T) []Option
+		verify func(t *testing.T, c *Cache)
+	}{
+		{
+			// A 64-cost value only fits a MaxCost of 100 without Ristretto's
+			// internal per-item overhead
+			name: "ignore internal cost",
+			cfg:  func(cfg *Config) { cfg.MaxCost = 100 },
+			opts: func(*testing.T) []Option { return []Option{WithIgnoreInternalCost()} },
+			verify: func(t *testing.T, c *Cache) {
+				c.SetWait("key", "value", time.Minute)
+				if _, found := c.Get("key"); !found {
+					t.Error("expected the value to fit without internal cost")
+				}
+				if got := c.Rejected(); got != 0 {
+					t.Errorf("expected no rejections, got %d", got)
+				}
+			},
+		},
+		{
+			name: "without metrics",
+			opts: func(*testing.T) []Option { return []Option{WithoutMetrics()} },
+			verify: func(t *testing.T, c *Cache) {
+				c.SetWait("key", "value", time.Minute)
+				if _, found := c.Get("key"); !found {
+					t.Fatal("expected the value to be stored")
+				}
+				if hits := c.Metrics().Hits(); hits != 0 {
+					t.Errorf("expected metrics to be disabled, got %d hits", hits)
+				}
+			},
+		},
+		{
+			name: "cost function",
+			cfg:  func(cfg *Config) { cfg.MaxCost = 1024 },
+			opts: func(*testing.T) []Option {
+				return []Option{
+					WithIgnoreInternalCost(),
+					WithCostFunc(func(value any) int64 { return int64(len(value.(string))) }),
+				}
+			},
+			verify: func(t *testing.T, c *Cache) {
+				if !c.SetWithResult("small", "value", time.Minute) {
+					t.Error("expected a small value to be accepted")
+				}
+				if c.SetWithResult("large", string(make([]byte, 2048)), time.Minute) {
+					t.Error("expected a value costing more than MaxCost to be rejected")
+				}
+				c.Flush()
+				if got := c.Metrics().CostAdded(); got != uint64(len("value")) {
+					t.Errorf("expected cost %d to be added, got %d", len("value"), got)
+				}
+			},
+		},
+		{
+			name: "key hash function",
+			opts: func(t *testing.T) []Option {
+				var calls atomic.Int64
+				t.Cleanup(func() {
+					if calls.Load() == 0 {
+						t.Error("expected the custom key hash to be used")
+					}
+				})
+				return []Option{WithKeyToHash(func(key string) (uint64, uint64) {
+					calls.Add(1)
+					var h uint64 = 14695981039346656037
+					for i := 0; i < len(key); i++ {
+						h = (h ^ uint64(key[i])) * 1099511628211
+					}
+					return h, uint64(len(key))
+				})}
+			},
+			verify: func(t *testing.T, c *Cache) {
+				c.SetWait("key", "value", time.Minute)
+				if got, found := c.Get("key"); !found || got != "value" {
+					t.Errorf("expected stored value, got %v (found=%v)", got, found)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			if tt.cfg != nil {
+				tt.cfg(&cfg)
+			}
+			c, err := New(cfg, zaptest.NewLogger(t), tt.opts(t)...)
+			if err != nil {
+				t.Fatalf("failed to create cache: %v", err)
+			}
+			defer c.Close()
+
+			tt.verify(t, c)
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package cache
+
+import (
+	"reflect"
+)
+
+// SizeOf estimates the bytes of memory held by v, following pointers, slices,
+// maps, strings, and interfaces. It is the cost function used when
+// Config.MaxBytes is set.
+//
+// The estimate is approximate: it counts the capacity of slices and the
+// length of strings and map entries, but not allocator rounding, map bucket
+// overhead beyond the entries, or memory shared with values outside v, which
+// is charged to every value referencing it. Memory reachable from one pointer
+// several times within v is counted once. Channels and functions count as
+// their pointer size only.
+func SizeOf(v any) int64 {
+	if v == nil {
+		return 0
+	}
+	value := reflect.ValueOf(v)
+	seen := make(map[uintptr]bool)
+	return int64(value.Type().Size()) + indirectSize(value, seen)
+}
+
+// indirectSize returns the bytes v references outside its own inline size.
+func indirectSize(v reflect.Value, seen map[uintptr]bool) int64 {
+	switch v.Kind() {
+	case reflect.String:
+		return int64(v.Len())
+
+	case reflect.Pointer:
+		if v.IsNil() || seen[v.Pointer()] {
+			return 0
+		}
+		seen[v.Pointer()] = true
+		elem := v.Elem()
+		return int64(elem.Type().Size()) + indirectSize(elem, seen)
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return 0
+		}
+		elem := v.Elem()
+		return int64(elem.Type().Size()) + indirectSize(elem, seen)
+
+	case reflect.Slice:
+		if v.IsNil() || seen[v.Pointer()] {
+			return 0
+		}
+		seen[v.Pointer()] = true
+		size := int64(v.Cap()) * int64(v.Type().Elem().Size())
+		for i := 0; i < v.Len(); i++ {
+			size += indirectSize(v.Index(i), seen)
+		}
+		return size
+
+	case reflect.Array:
+		var size int64
+		for i := 0; i < v.Len(); i++ {
+			size += indirectSize(v.Index(i), seen)
+		}
+		return size
+
+	case reflect.Map:
+		if v.IsNil() || seen[v.Pointer()] {
+			return 0
+		}
+		seen[v.Pointer()] = true
+		entry := int64(v.Type().Key().Size() + v.Type().Elem().Size())
+		size := int64(v.Len()) * entry
+		iter := v.MapRange()
+		for iter.Next() {
+			size += indirectSize(iter.Key(), seen) + indirectSize(iter.Value(), seen)
+		}
+		return size
+
+	case reflect.Struct:
+		var size int64
+		for i := 0; i < v.NumField(); i++ {
+			size += indirectSize(v.Field(i), seen)
+		}
+		return size
+	}
+	return 0
+}
@@ -0,0 +1,128 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zaptest"
+)
+
+func TestSizeOf(t *testing.T) {
+	type record struct {
+		Name string
+		Tags []string
+	}
+	shared := make([]byte, 100)
+
+	tests := []struct {
+		name  string
+		value any
+		want  int64
+	}{
+		{name: "nil", value: nil, want: 0},
+		{name: "int", value: 42, want: 8},
+		{name: "string", value: "hello", want: 16 + 5},
+		{name: "byte slice counts capacity", value: make([]byte, 10, 64), want: 24 + 64},
+		{
+			name:  "struct with nested slices",
+			value: record{Name: "abc", Tags: []string{"x", "yz"}},
+			// 16+24 inline, 3 name bytes, 2 string headers, 3 tag bytes
+			want: 40 + 3 + 32 + 3,
+		},
+		{name: "pointer", value: &record{Name: "abc"}, want: 8 + 40 + 3},
+		{name: "map", value: map[string]int{"a": 1, "bc": 2}, want: 8 + 2*(16+8) + 3},
+		{name: "shared memory counted once", value: [][]byte{shared, shared}, want: 24 + 2*24 + 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SizeOf(tt.value); got != tt.want {
+				t.Errorf("SizeOf() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSizeOf_Cycle(t *testing.T) {
+	type node struct {
+		Next *node
+	}
+	n := &node{}
+	n.Next = n
+
+	if got := SizeOf(n); got != 16 {
+		t.Errorf("SizeOf() = %d, want 16", got)
+	}
+}
+
+func TestCache_MaxBytes(t *testing.T) {
+	const (
+		maxBytes  = 1 << 20
+		valueSize = 1024
+	)
+	cfg := DefaultConfig()
+	cfg.MaxCost = 0 // replaced by MaxBytes
+	cfg.MaxBytes = maxBytes
+	c, err := New(cfg, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	defer c.Close()
+
+	if got := c.MaxCost(); got != maxBytes {
+		t.Errorf("MaxCost() = %d, want MaxBytes %d", got, maxBytes)
+	}
+
+	fill := func(from, to int) {
+		for i := from; i < to; i++ {
+			c.Set(fmt.Sprintf("key-%d", i), make([]byte, valueSize), time.Minute)
+		}
+		c.Flush()
+	}
+
+	// Half the budget fits without evicting anything
+	fill(0, maxBytes/2/valueSize)
+	m := c.Metrics()
+	if m.KeysEvicted() != 0 || c.Rejected() != 0 {
+		t.Fatalf("expected no evictions or rejections below MaxBytes, got %d evicted, %d rejected",
+			m.KeysEvicted(), c.Rejected())
+	}
+	if used := m.CostAdded(); used < maxBytes/2 {
+		t.Errorf("cost added = %d, want at least %d: values must be charged their size", used, maxBytes/2)
+	}
+
+	// Twice the budget must push entries out, keeping usage within MaxBytes
+	fill(maxBytes/2/valueSize, 2*maxBytes/valueSize)
+	if m.KeysEvicted() == 0 && c.Rejected() == 0 {
+		t.Error("expected evictions or rejections above MaxBytes")
+	}
+	if used := m.CostAdded() - m.CostEvicted(); used > maxBytes {
+		t.Errorf("cost in use = %d, want at most MaxBytes %d", used, maxBytes)
+	}
+}
+
+func TestCache_MaxBytes_CostFuncWins(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.MaxBytes = 1000
+	c, err := New(cfg, zaptest.NewLogger(t), WithCostFunc(func(any) int64 { return 1 }))
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	defer c.Close()
+
+	// SizeOf would charge more than MaxBytes and reject the value
+	if !c.SetWithResult("big", make([]byte, 2000), time.Minute) {
+		t.Error("expected WithCostFunc to override the SizeOf cost")
+	}
+}
+
+func TestNew_InvalidMaxBytes(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.MaxBytes = -1
+	_, err := New(cfg, zaptest.NewLogger(t))
+	if !errors.Is(err, ErrInvalidMaxBytes) {
+		t.Errorf("expected ErrInvalidMaxBytes, got %v", err)
+	}
+}
@@ -0,0 +1,106 @@
+package cache
+
+import (
+	"time"
+
+	"github.com/dgraph-io/ristretto"
+	"go.uber.org/zap"
+)
+
+// EventType identifies the cache operation an Event records.
+type EventType string
+
+const (
+	// EventHit is a Get that found a value.
+	EventHit EventType = "hit"
+
+	// EventMiss is a Get that found nothing. Reason is "not found" or
+	// "expired".
+	EventMiss EventType = "miss"
+
+	// EventSet is a Set accepted by the cache. Ristretto's admission policy
+	// may still reject it later, which is traced as EventReject.
+	EventSet EventType = "set"
+
+	// EventReject is a Set the cache did not store. Reason says why.
+	EventReject EventType = "reject"
+
+	// EventEvict is a value Ristretto evicted to stay within MaxCost. Key is
+	// empty because Ristretto only reports the key's hash.
+	EventEvict EventType = "evict"
+
+	// EventExpire is a value removed because its TTL elapsed, by the
+	// background cleanup or, with Config.DeleteExpiredOnGet, on Get.
+	EventExpire EventType = "expire"
+
+	// EventDelete is an explicit Delete.
+	EventDelete EventType = "delete"
+)
+
+// Event describes a single traced cache operation.
+type Event struct {
+	Type EventType
+	Time time.Time
+
+	// Key is the key operated on, or empty when Ristretto did not report it
+	// (evictions and admission rejections).
+	Key string
+
+	// Reason explains misses, rejections, evictions, and expirations.
+	Reason string
+
+	// TTL is the TTL applied by a Set.
+	TTL time.Duration
+
+	// Cost is the cost of the value set, rejected, or evicted.
+	Cost int64
+}
+
+// WithTracer calls fn with an Event for every cache operation: get hits and
+// misses, sets, rejections, evictions, expirations, and deletes. Tracing is
+// off by default.
+//
+// fn runs synchronously on the operation's goroutine, and for evictions and
+// admission rejections on Ristretto's internal goroutine, so it must be safe
+// for concurrent use and fast. Use LogTracer for detailed debug logs, or
+// forward events to span events of your tracing system.
+func WithTracer(fn func(Event)) Option {
+	return func(o *options) {
+		o.tracer = fn
+	}
+}
+
+// LogTracer returns a tracer for WithTracer that logs every event at debug
+// level to logger.
+func LogTracer(logger *zap.Logger) func(Event) {
+	return func(e Event) {
+		fields := []zap.Field{zap.String("op", string(e.Type))}
+		if e.Key != "" {
+			fields = append(fields, zap.String("key", e.Key))
+		}
+		if e.Reason != "" {
+			fields = append(fields, zap.String("reason", e.Reason))
+		}
+		if e.Type == EventSet {
+			fields = append(fields, zap.Duration("ttl", e.TTL))
+		}
+		if e.Cost != 0 {
+			fields = append(fields, zap.Int64("cost", e.Cost))
+		}
+		logger.Debug("cache trace", fields...)
+	}
+}
+
+// trace records e if a tracer is configured.
+func (c *Cache) trace(e Event) {
+	if c.tracer == nil {
+		return
+	}
+	e.Time = c.clock.Now()
+	c.tracer(e)
+}
+
+// onEvict is called by Ristretto when it evicts a value to make room.
+func (c *Cache) onEvict(item *ristretto.Item[any]) {
+	c.trace(Event{Type: EventEvict, Reason: "capacity", Cost: item.Cost})
+}
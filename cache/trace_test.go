@@ -0,0 +1,156 @@
+package cache
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// eventRecorder is a test exporter collecting traced events.
+type eventRecorder struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+func (r *eventRecorder) record(e Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, e)
+}
+
+// take returns the recorded events with Time cleared, and resets the recorder.
+func (r *eventRecorder) take() []Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	events := r.events
+	r.events = nil
+	for i := range events {
+		events[i].Time = time.Time{}
+	}
+	return events
+}
+
+func TestCache_Tracer(t *testing.T) {
+	clock := newFakeClock()
+	rec := &eventRecorder{}
+	cfg := DefaultConfig()
+	cfg.Clock = clock
+	c, err := New(cfg, zaptest.NewLogger(t), WithTracer(rec.record))
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	defer c.Close()
+
+	steps := []struct {
+		name string
+		op   func()
+		want []Event
+	}{
+		{
+			name: "get miss then set",
+			op: func() {
+				c.Get("city")
+				c.SetWait("city", "Paris", time.Minute)
+			},
+			want: []Event{
+				{Type: EventMiss, Key: "city", Reason: "not found"},
+				{Type: EventSet, Key: "city", TTL: time.Minute, Cost: 64},
+			},
+		},
+		{
+			name: "get hit",
+			op:   func() { c.Get("city") },
+			want: []Event{{Type: EventHit, Key: "city"}},
+		},
+		{
+			name: "get after ttl",
+			op: func() {
+				clock.Advance(2 * time.Minute)
+				c.Get("city")
+			},
+			want: []Event{{Type: EventMiss, Key: "city", Reason: "expired"}},
+		},
+		{
+			name: "set without ttl",
+			op:   func() { c.SetWait("country", "France", 0) },
+			want: []Event{{Type: EventSet, Key: "country", Cost: 64}},
+		},
+		{
+			name: "delete",
+			op:   func() { c.Delete("country") },
+			want: []Event{{Type: EventDelete, Key: "country"}},
+		},
+	}
+
+	for _, step := range steps {
+		step.op()
+		if got := rec.take(); !reflect.DeepEqual(got, step.want) {
+			t.Errorf("%s: events = %+v, want %+v", step.name, got, step.want)
+		}
+	}
+}
+
+func TestCache_Tracer_Reject(t *testing.T) {
+	rec := &eventRecorder{}
+	cfg := DefaultConfig()
+	cfg.MaxCost = 100
+	c, err := New(cfg, zaptest.NewLogger(t), WithTracer(rec.record), WithCostFunc(func(any) int64 { return 1000 }))
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	defer c.Close()
+
+	c.Set("huge", "value", time.Minute)
+
+	want := []Event{{Type: EventReject, Key: "huge", Reason: "cost exceeds MaxCost", Cost: 1000}}
+	if got := rec.take(); !reflect.DeepEqual(got, want) {
+		t.Errorf("events = %+v, want %+v", got, want)
+	}
+}
+
+func TestCache_Tracer_EventTime(t *testing.T) {
+	clock := newFakeClock()
+	var got Event
+	cfg := DefaultConfig()
+	cfg.Clock = clock
+	c, err := New(cfg, zaptest.NewLogger(t), WithTracer(func(e Event) { got = e }))
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	defer c.Close()
+
+	c.Get("missing")
+	if !got.Time.Equal(clock.Now()) {
+		t.Errorf("event time = %v, want the cache clock's %v", got.Time, clock.Now())
+	}
+}
+
+func TestLogTracer(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	c, err := New(DefaultConfig(), zaptest.NewLogger(t), WithTracer(LogTracer(zap.New(core))))
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	defer c.Close()
+
+	c.Get("city")
+	c.SetWait("city", "Paris", time.Minute)
+
+	entries := logs.FilterMessage("cache trace").All()
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 trace log entries, got %d", len(entries))
+	}
+	miss, set := entries[0].ContextMap(), entries[1].ContextMap()
+	if miss["op"] != "miss" || miss["key"] != "city" || miss["reason"] != "not found" {
+		t.Errorf("unexpected miss fields %v", miss)
+	}
+	if set["op"] != "set" || set["ttl"] != time.Minute {
+		t.Errorf("unexpected set fields %v", set)
+	}
+}
@@ -0,0 +1,45 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// ErrTypeMismatch indicates a cached value is not of the type LoadTyped
+// expects, usually because two callers store different types under one key.
+var ErrTypeMismatch = errors.New("cached value has unexpected type")
+
+// LoadTyped is GetOrSet for values of type T: it returns the value cached
+// under key, calling loader to produce and cache it on a miss, without the
+// caller asserting the type.
+//
+//	forecast, err := cache.LoadTyped(c, ctx, "weather:"+city, 10*time.Minute,
+//	    func(ctx context.Context) (Forecast, error) {
+//	        return fetchForecast(ctx, city)
+//	    })
+//
+// If the value cached under key is not a T, LoadTyped returns an error
+// wrapping ErrTypeMismatch and leaves the entry in place. Errors from loader
+// and the context are returned as by GetOrSet.
+func LoadTyped[T any](c *Cache, ctx context.Context, key string, ttl time.Duration, loader func(ctx context.Context) (T, error)) (T, error) {
+	var zero T
+	value, err := c.GetOrSet(ctx, key, ttl, func(ctx context.Context) (any, error) {
+		return loader(ctx)
+	})
+	if err != nil {
+		return zero, err
+	}
+
+	typed, ok := value.(T)
+	if !ok {
+		// A nil interface value is stored as a nil any, which asserts to no type
+		if value == nil && reflect.TypeFor[T]().Kind() == reflect.Interface {
+			return zero, nil
+		}
+		return zero, fmt.Errorf("%w: key %q holds %T, want %v", ErrTypeMismatch, key, value, reflect.TypeFor[T]())
+	}
+	return typed, nil
+}
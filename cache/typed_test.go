@@ -0,0 +1,78 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zaptest"
+)
+
+type forecast struct {
+	City string
+	Temp float64
+}
+
+func TestLoadTyped(t *testing.T) {
+	c, err := New(DefaultConfig(), zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	defer c.Close()
+	ctx := context.Background()
+
+	calls := 0
+	load := func(context.Context) (forecast, error) {
+		calls++
+		return forecast{City: "Oslo", Temp: 4.5}, nil
+	}
+
+	// Miss, then hit
+	for i := range 2 {
+		got, err := LoadTyped(c, ctx, "weather:oslo", time.Minute, load)
+		if err != nil {
+			t.Fatalf("LoadTyped() call %d error = %v", i, err)
+		}
+		if got != (forecast{City: "Oslo", Temp: 4.5}) {
+			t.Errorf("LoadTyped() call %d = %+v", i, got)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("loader called %d times, want 1", calls)
+	}
+
+	// Type mismatch
+	c.SetWait("weather:paris", "sunny", time.Minute)
+	got, err := LoadTyped(c, ctx, "weather:paris", time.Minute, load)
+	if !errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("LoadTyped() of a string error = %v, want ErrTypeMismatch", err)
+	}
+	if got != (forecast{}) {
+		t.Errorf("LoadTyped() on mismatch = %+v, want the zero value", got)
+	}
+	if value, _ := c.Get("weather:paris"); value != "sunny" {
+		t.Errorf("mismatched entry = %v, want it left in place", value)
+	}
+
+	// Loader error
+	errLoad := errors.New("upstream down")
+	_, err = LoadTyped(c, ctx, "weather:rome", time.Minute, func(context.Context) (forecast, error) {
+		return forecast{}, errLoad
+	})
+	if !errors.Is(err, errLoad) {
+		t.Errorf("LoadTyped() error = %v, want loader error", err)
+	}
+	if c.Has("weather:rome") {
+		t.Error("failed load was cached")
+	}
+
+	// A nil interface value round-trips
+	var nilErr error
+	gotErr, err := LoadTyped(c, ctx, "nil-interface", time.Minute, func(context.Context) (error, error) {
+		return nil, nil
+	})
+	if err != nil || gotErr != nilErr {
+		t.Errorf("LoadTyped() of a nil interface = %v, %v", gotErr, err)
+	}
+}
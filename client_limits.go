@@ -0,0 +1,339 @@
+package hypermcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ClientLimit bounds the requests of one client.
+type ClientLimit struct {
+	// RequestsPerSecond is the sustained rate of requests the client may
+	// send. Requests above it are rejected with ErrRateLimited. Zero means
+	// unlimited.
+	RequestsPerSecond float64
+
+	// DestructiveToolsOnly applies RequestsPerSecond only to calls of tools
+	// that may make destructive updates (see IsDestructiveTool), leaving
+	// read-only and additive tools, and other requests, unthrottled.
+	DestructiveToolsOnly bool
+
+	// Burst is how many requests the client may send at once before the rate
+	// applies. Defaults to RequestsPerSecond rounded up.
+	Burst int
+
+	// MaxRequestBytes, if positive, rejects requests whose parameters encode
+	// to more than this many bytes of JSON with ErrRequestTooLarge.
+	MaxRequestBytes int
+}
+
+func (l ClientLimit) burst() float64 {
+	if l.Burst > 0 {
+		return float64(l.Burst)
+	}
+	return math.Ceil(l.RequestsPerSecond)
+}
+
+// ClientLimitsConfig sets the request limits of each client, identified by
+// the name in the ClientInfo it sent when initializing. Each client is
+// throttled on its own, so one client sending too much can't starve others.
+//
+// The client name is reported by the client itself, so the limits protect
+// against misbehaving clients, not malicious ones: a client can evade them by
+// changing its name. Clients that report no name share one set of limits.
+// Initialize and ping requests are never limited.
+type ClientLimitsConfig struct {
+	// Default applies to clients not listed in Clients.
+	Default ClientLimit
+
+	// Clients sets the limits of specific clients, keyed by client name,
+	// replacing Default for them.
+	Clients map[string]ClientLimit
+}
+
+func (c ClientLimitsConfig) enabled() bool {
+	if c.Default != (ClientLimit{}) {
+		return true
+	}
+	for _, limit := range c.Clients {
+		if limit != (ClientLimit{}) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c ClientLimitsConfig) limit(client string) ClientLimit {
+	if limit, ok := c.Clients[client]; ok {
+		return limit
+	}
+	return c.Default
+}
+
+// validate checks limit, reporting errors against the named field.
+func (l ClientLimit) validate(field string) error {
+	if l.RequestsPerSecond < 0 {
+		return NewConfigError(field+".RequestsPerSecond", fmt.Errorf("cannot be negative"))
+	}
+	if l.Burst < 0 {
+		return NewConfigError(field+".Burst", fmt.Errorf("cannot be negative"))
+	}
+	if l.MaxRequestBytes < 0 {
+		return NewConfigError(field+".MaxRequestBytes", fmt.Errorf("cannot be negative"))
+	}
+	if l.DestructiveToolsOnly && l.RequestsPerSecond == 0 {
+		return NewConfigError(field+".DestructiveToolsOnly", fmt.Errorf("is set but RequestsPerSecond is zero"))
+	}
+	return nil
+}
+
+func (c ClientLimitsConfig) validate() error {
+	if err := c.Default.validate("ClientLimits.Default"); err != nil {
+		return err
+	}
+	for name, limit := range c.Clients {
+		if err := limit.validate(fmt.Sprintf("ClientLimits.Clients[%q]", name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ClientSnapshot is the traffic of one client in MetricsSnapshot.
+type ClientSnapshot struct {
+	Requests          int64 `json:"requests"`          // Requests received, including rejected ones
+	RateLimited       int64 `json:"rateLimited"`       // Requests rejected with ErrRateLimited
+	OversizedRequests int64 `json:"oversizedRequests"` // Requests rejected with ErrRequestTooLarge
+}
+
+// clientStats counts the requests of one client.
+type clientStats struct {
+	requests    atomic.Int64
+	rateLimited atomic.Int64
+	oversized   atomic.Int64
+}
+
+// maxTrackedClients bounds how many client names clientUsage tracks on their
+// own. Clients report their own names, so without a bound a client rotating
+// through names would grow the statistics without limit.
+const maxTrackedClients = 256
+
+// otherClients is the name under which the traffic of clients beyond
+// maxTrackedClients is counted.
+const otherClients = "(other)"
+
+// clientUsage holds the request statistics of every client.
+type clientUsage struct {
+	clients sync.Map // client name -> *clientStats
+
+	mu      sync.Mutex // serializes adding clients
+	tracked int
+	max     int // overrides maxTrackedClients in tests
+}
+
+// stats returns the statistics of client, or those shared by all clients
+// past the first maxTrackedClients.
+func (u *clientUsage) stats(client string) *clientStats {
+	if stats, ok := u.clients.Load(client); ok {
+		return stats.(*clientStats)
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if stats, ok := u.clients.Load(client); ok {
+		return stats.(*clientStats)
+	}
+	limit := u.max
+	if limit <= 0 {
+		limit = maxTrackedClients
+	}
+	if u.tracked >= limit {
+		client = otherClients
+		if stats, ok := u.clients.Load(client); ok {
+			return stats.(*clientStats)
+		}
+	} else {
+		u.tracked++
+	}
+	stats := &clientStats{}
+	u.clients.Store(client, stats)
+	return stats
+}
+
+// snapshot returns the traffic of every client seen so far, or nil if none has been.
+func (u *clientUsage) snapshot() map[string]ClientSnapshot {
+	var snaps map[string]ClientSnapshot
+	u.clients.Range(func(key, value any) bool {
+		if snaps == nil {
+			snaps = make(map[string]ClientSnapshot)
+		}
+		stats := value.(*clientStats)
+		snaps[key.(string)] = ClientSnapshot{
+			Requests:          stats.requests.Load(),
+			RateLimited:       stats.rateLimited.Load(),
+			OversizedRequests: stats.oversized.Load(),
+		}
+		return true
+	})
+	return snaps
+}
+
+// tokenBucket holds the rate limit state of one client.
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+	full   time.Time // when the bucket refills completely if left idle
+}
+
+// bucketSweepInterval is how often clientLimiter drops the buckets of idle
+// clients.
+const bucketSweepInterval = time.Minute
+
+// clientLimiter enforces ClientLimitsConfig.
+type clientLimiter struct {
+	config   ClientLimitsConfig
+	clock    Clock
+	metrics  *Metrics
+	registry *registry
+
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+func newClientLimiter(config ClientLimitsConfig, metrics *Metrics, registry *registry) *clientLimiter {
+	return &clientLimiter{
+		config:    config,
+		clock:     metrics.clock,
+		metrics:   metrics,
+		registry:  registry,
+		buckets:   make(map[string]*tokenBucket),
+		lastSweep: metrics.clock.Now(),
+	}
+}
+
+// allow takes a token from client's bucket, refilled at limit's rate, and
+// reports whether one was available.
+func (l *clientLimiter) allow(client string, limit ClientLimit) bool {
+	if limit.RequestsPerSecond <= 0 {
+		return true
+	}
+	now := l.clock.Now()
+	burst := limit.burst()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+
+	b, ok := l.buckets[client]
+	if !ok {
+		b = &tokenBucket{tokens: burst, last: now}
+		l.buckets[client] = b
+	}
+	b.tokens = min(burst, b.tokens+now.Sub(b.last).Seconds()*limit.RequestsPerSecond)
+	b.last = now
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	b.full = now.Add(time.Duration((burst - b.tokens) / limit.RequestsPerSecond * float64(time.Second)))
+	return true
+}
+
+// sweep drops the buckets that have refilled completely since their client's
+// last request, at most once per bucketSweepInterval. A full bucket is the
+// same as a fresh one, so dropping it changes no decision, and buckets of
+// clients that stopped sending (or of names used once) don't accumulate.
+// l.mu must be held.
+func (l *clientLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < bucketSweepInterval {
+		return
+	}
+	l.lastSweep = now
+	for client, b := range l.buckets {
+		if !now.Before(b.full) {
+			delete(l.buckets, client)
+		}
+	}
+}
+
+// middleware returns receiving middleware that rejects requests exceeding
+// their client's limits.
+func (l *clientLimiter) middleware() mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			if method == "initialize" || method == "ping" || strings.HasPrefix(method, "notifications/") {
+				return next(ctx, method, req)
+			}
+
+			client := clientName(req)
+			limit := l.config.limit(client)
+			stats := l.metrics.clientUsage.stats(client)
+			stats.requests.Add(1)
+
+			if limit.MaxRequestBytes > 0 {
+				if size := paramsSize(req); size > limit.MaxRequestBytes {
+					stats.oversized.Add(1)
+					return nil, fmt.Errorf("%w: %s request from client %q is %d bytes, limit %d",
+						ErrRequestTooLarge, method, client, size, limit.MaxRequestBytes)
+				}
+			}
+			if l.throttled(method, req, limit) && !l.allow(client, limit) {
+				stats.rateLimited.Add(1)
+				return nil, fmt.Errorf("%w: client %q exceeded %g requests per second",
+					ErrRateLimited, client, limit.RequestsPerSecond)
+			}
+
+			return next(ctx, method, req)
+		}
+	}
+}
+
+// throttled reports whether limit's rate applies to req. Calls of tools that
+// aren't registered are throttled, as they would be if destructive.
+func (l *clientLimiter) throttled(method string, req mcp.Request, limit ClientLimit) bool {
+	if !limit.DestructiveToolsOnly {
+		return true
+	}
+	call, ok := req.(*mcp.CallToolRequest)
+	if method != "tools/call" || !ok || call.Params == nil {
+		return false
+	}
+	tool, ok := l.registry.tool(call.Params.Name)
+	return !ok || IsDestructiveTool(tool)
+}
+
+// clientName returns the name the client sending req reported when
+// initializing.
+func clientName(req mcp.Request) string {
+	if session, ok := req.GetSession().(*mcp.ServerSession); ok && session != nil {
+		if init := session.InitializeParams(); init != nil && init.ClientInfo != nil {
+			return init.ClientInfo.Name
+		}
+	}
+	return ""
+}
+
+// paramsSize returns the size of req's parameters encoded as JSON.
+func paramsSize(req mcp.Request) int {
+	// Params is an interface over pointer types; guard against typed nils
+	params := req.GetParams()
+	if params == nil || reflect.ValueOf(params).IsNil() {
+		return 0
+	}
+	data, err := json.Marshal(params)
+	if err != nil {
+		return 0
+	}
+	return len(data)
+}
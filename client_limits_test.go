@@ -0,0 +1,236 @@
+package hypermcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap/zaptest"
+)
+
+// connectNamedClient connects an in-memory MCP client reporting name as its
+// ClientInfo name to srv.
+func connectNamedClient(t *testing.T, srv *Server, name string) *mcp.ClientSession {
+	t.Helper()
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+
+	serverSession, err := srv.MCP().Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("failed to connect server: %v", err)
+	}
+	t.Cleanup(func() { _ = serverSession.Close() })
+
+	client := mcp.NewClient(&mcp.Implementation{Name: name, Version: "1.0.0"}, nil)
+	clientSession, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("failed to connect client %s: %v", name, err)
+	}
+	t.Cleanup(func() { _ = clientSession.Close() })
+
+	return clientSession
+}
+
+func newClientLimitsTestServer(t *testing.T, limits ClientLimitsConfig, clock Clock) *Server {
+	t.Helper()
+
+	srv, err := New(Config{Name: "test-server", Version: "1.0.0", ClientLimits: limits, Clock: clock}, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	AddTool(srv, &mcp.Tool{Name: "echo"}, func(_ context.Context, _ *mcp.CallToolRequest, in struct {
+		Message string `json:"message"`
+	}) (*mcp.CallToolResult, any, error) {
+		return TextResult(in.Message), nil, nil
+	})
+	return srv
+}
+
+func callEcho(session *mcp.ClientSession, message string) error {
+	_, err := session.CallTool(context.Background(), &mcp.CallToolParams{
+		Name:      "echo",
+		Arguments: map[string]any{"message": message},
+	})
+	return err
+}
+
+func TestClientLimits_RateLimitPerClient(t *testing.T) {
+	clock := newFakeClock()
+	srv := newClientLimitsTestServer(t, ClientLimitsConfig{
+		Default: ClientLimit{RequestsPerSecond: 1, Burst: 2},
+		Clients: map[string]ClientLimit{"trusted": {}},
+	}, clock)
+
+	greedy := connectNamedClient(t, srv, "greedy")
+	polite := connectNamedClient(t, srv, "polite")
+	trusted := connectNamedClient(t, srv, "trusted")
+
+	for i := range 2 {
+		if err := callEcho(greedy, "hi"); err != nil {
+			t.Fatalf("greedy call %d error = %v", i, err)
+		}
+	}
+	if err := callEcho(greedy, "hi"); err == nil || !strings.Contains(err.Error(), ErrRateLimited.Error()) {
+		t.Fatalf("greedy call over the burst error = %v, want rate limited", err)
+	}
+
+	// The other clients have their own budgets
+	for i := range 2 {
+		if err := callEcho(polite, "hi"); err != nil {
+			t.Errorf("polite call %d error = %v", i, err)
+		}
+	}
+	for i := range 5 {
+		if err := callEcho(trusted, "hi"); err != nil {
+			t.Errorf("trusted call %d error = %v", i, err)
+		}
+	}
+
+	// The greedy client's budget refills over time
+	clock.Advance(time.Second)
+	if err := callEcho(greedy, "hi"); err != nil {
+		t.Errorf("greedy call after refill error = %v", err)
+	}
+
+	clients := srv.GetMetrics().Clients
+	if got := clients["greedy"]; got.Requests != 4 || got.RateLimited != 1 {
+		t.Errorf("greedy metrics = %+v, want 4 requests, 1 rate limited", got)
+	}
+	if got := clients["polite"]; got.Requests != 2 || got.RateLimited != 0 {
+		t.Errorf("polite metrics = %+v, want 2 requests, none rate limited", got)
+	}
+}
+
+func TestClientLimits_DestructiveToolsOnly(t *testing.T) {
+	srv := newClientLimitsTestServer(t, ClientLimitsConfig{
+		Default: ClientLimit{RequestsPerSecond: 1, DestructiveToolsOnly: true},
+	}, newFakeClock())
+	AddTool(srv, &mcp.Tool{Name: "lookup", Annotations: ReadOnlyAnnotations()}, func(context.Context, *mcp.CallToolRequest, struct{}) (*mcp.CallToolResult, any, error) {
+		return TextResult("found"), nil, nil
+	})
+	session := connectNamedClient(t, srv, "agent")
+	ctx := context.Background()
+
+	// Read-only calls and other requests are not throttled
+	for i := range 3 {
+		if _, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "lookup", Arguments: map[string]any{}}); err != nil {
+			t.Fatalf("read-only call %d error = %v", i, err)
+		}
+		if _, err := session.ListTools(ctx, nil); err != nil {
+			t.Fatalf("ListTools() %d error = %v", i, err)
+		}
+	}
+
+	// echo has no annotations, so it is destructive by default
+	if err := callEcho(session, "hi"); err != nil {
+		t.Fatalf("first destructive call error = %v", err)
+	}
+	if err := callEcho(session, "hi"); err == nil || !strings.Contains(err.Error(), ErrRateLimited.Error()) {
+		t.Errorf("second destructive call error = %v, want rate limited", err)
+	}
+}
+
+func TestClientLimits_MaxRequestBytes(t *testing.T) {
+	srv := newClientLimitsTestServer(t, ClientLimitsConfig{
+		Default: ClientLimit{MaxRequestBytes: 256},
+		Clients: map[string]ClientLimit{"bulk": {MaxRequestBytes: 4096}},
+	}, nil)
+
+	small := connectNamedClient(t, srv, "small")
+	bulk := connectNamedClient(t, srv, "bulk")
+
+	large := strings.Repeat("x", 1024)
+	if err := callEcho(small, "hi"); err != nil {
+		t.Errorf("small request error = %v", err)
+	}
+	if err := callEcho(small, large); err == nil || !strings.Contains(err.Error(), ErrRequestTooLarge.Error()) {
+		t.Errorf("large request error = %v, want request too large", err)
+	}
+	if err := callEcho(bulk, large); err != nil {
+		t.Errorf("large request from bulk client error = %v", err)
+	}
+
+	if got := srv.GetMetrics().Clients["small"]; got.OversizedRequests != 1 {
+		t.Errorf("small metrics = %+v, want 1 oversized request", got)
+	}
+
+	body := scrapePrometheus(t, srv)
+	if !strings.Contains(body, `hypermcp_client_oversized_requests_total{client="small"} 1`) {
+		t.Errorf("Prometheus output missing per-client counter:\n%s", body)
+	}
+}
+
+func TestClientLimits_Validate(t *testing.T) {
+	tests := []struct {
+		name   string
+		limits ClientLimitsConfig
+		field  string
+	}{
+		{"negative rate", ClientLimitsConfig{Default: ClientLimit{RequestsPerSecond: -1}}, "ClientLimits.Default.RequestsPerSecond"},
+		{"negative burst", ClientLimitsConfig{Default: ClientLimit{Burst: -1}}, "ClientLimits.Default.Burst"},
+		{"negative size", ClientLimitsConfig{Clients: map[string]ClientLimit{"a": {MaxRequestBytes: -1}}}, `ClientLimits.Clients["a"].MaxRequestBytes`},
+		{"destructive only without rate", ClientLimitsConfig{Default: ClientLimit{DestructiveToolsOnly: true}}, "ClientLimits.Default.DestructiveToolsOnly"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Config{Name: "test", Version: "1.0.0", ClientLimits: tt.limits}.Validate()
+			var cfgErr *ConfigError
+			if !errors.As(err, &cfgErr) || cfgErr.Field != tt.field {
+				t.Errorf("Validate() error = %v, want ConfigError for %s", err, tt.field)
+			}
+		})
+	}
+}
+
+func TestClientUsage_NameRotation(t *testing.T) {
+	usage := clientUsage{max: 3}
+
+	// A client rotating through names only adds up to the limit
+	for i := range 10 {
+		usage.stats(fmt.Sprintf("client-%d", i)).requests.Add(1)
+	}
+	usage.stats("client-0").requests.Add(1)
+
+	snaps := usage.snapshot()
+	if len(snaps) != 4 {
+		t.Errorf("tracked %d clients, want 3 and %q", len(snaps), otherClients)
+	}
+	if got := snaps["client-0"].Requests; got != 2 {
+		t.Errorf("client-0 requests = %d, want 2", got)
+	}
+	if got := snaps[otherClients].Requests; got != 7 {
+		t.Errorf("%s requests = %d, want 7", otherClients, got)
+	}
+}
+
+func TestClientLimiter_EvictsIdleBuckets(t *testing.T) {
+	clock := newFakeClock()
+	limiter := newClientLimiter(ClientLimitsConfig{}, newMetricsWithClock(clock), newRegistry())
+	limit := ClientLimit{RequestsPerSecond: 1, Burst: 2}
+
+	for i := range 100 {
+		limiter.allow(fmt.Sprintf("client-%d", i), limit)
+	}
+	limiter.allow("busy", limit)
+	limiter.allow("busy", limit)
+
+	// After the sweep interval, only the busy client's bucket is still
+	// refilling; the others are full and dropped
+	clock.Advance(bucketSweepInterval - time.Second)
+	limiter.allow("busy", limit)
+	limiter.allow("busy", limit)
+	clock.Advance(time.Second)
+	limiter.allow("busy", limit)
+
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+	if len(limiter.buckets) != 1 || limiter.buckets["busy"] == nil {
+		t.Errorf("buckets = %v, want only the busy client's", limiter.buckets)
+	}
+}
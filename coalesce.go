@@ -0,0 +1,105 @@
+package hypermcp
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap"
+)
+
+// errCoalescedCallPanicked is returned to the calls that shared an execution
+// whose handler panicked.
+var errCoalescedCallPanicked = errors.New("coalesced tool call did not complete")
+
+// coalescedCall is an execution shared by identical concurrent calls.
+type coalescedCall[Out any] struct {
+	done   chan struct{} // closed once the fields below are set
+	result *mcp.CallToolResult
+	output Out
+	err    error
+}
+
+// AddCoalescedTool registers a tool whose identical concurrent calls share one
+// execution of handler.
+//
+// While a call is running, further calls with the same input wait for it
+// instead of running the handler again, and all receive its result, error
+// included. Once it finishes, the next call executes the handler afresh; use
+// AddIdempotentTool or the cache to reuse results over time. Inputs are
+// compared by their JSON encoding.
+//
+// Sharing an execution is only safe for handlers that don't modify anything,
+// so calls are only coalesced for tools declaring Annotations.ReadOnlyHint
+// (see ReadOnlyAnnotations). Any other tool is registered without coalescing,
+// and a warning is logged. The handler's result should also depend on the
+// input alone, not on the caller: the handler runs with the first call's
+// context and request, so progress notifications and cancellation follow that
+// call. A waiting call whose own context is canceled stops waiting and
+// returns the context's error.
+func AddCoalescedTool[In, Out any](s *Server, tool *mcp.Tool, handler mcp.ToolHandlerFor[In, Out]) {
+	if !IsReadOnlyTool(tool) {
+		s.log().Warn("tool is not declared read-only, registering without coalescing",
+			zap.String("tool", tool.Name),
+		)
+		AddTool(s, tool, handler)
+		return
+	}
+
+	toolName := tool.Name
+
+	var mu sync.Mutex
+	calls := make(map[string]*coalescedCall[Out])
+
+	wrapped := func(ctx context.Context, req *mcp.CallToolRequest, input In) (*mcp.CallToolResult, Out, error) {
+		data, err := json.Marshal(input)
+		if err != nil {
+			return handler(ctx, req, input)
+		}
+		sum := sha256.Sum256(data)
+		key := hex.EncodeToString(sum[:])
+
+		mu.Lock()
+		if call, ok := calls[key]; ok {
+			mu.Unlock()
+			s.log().Debug("coalescing tool call", zap.String("tool", toolName))
+
+			select {
+			case <-call.done:
+				return copyResult(call.result), call.output, call.err
+			case <-ctx.Done():
+				var zero Out
+				return nil, zero, ctx.Err()
+			}
+		}
+		call := &coalescedCall[Out]{done: make(chan struct{}), err: errCoalescedCallPanicked}
+		calls[key] = call
+		mu.Unlock()
+
+		defer func() {
+			mu.Lock()
+			delete(calls, key)
+			mu.Unlock()
+			close(call.done)
+		}()
+
+		call.result, call.output, call.err = handler(ctx, req, input)
+		return copyResult(call.result), call.output, call.err
+	}
+
+	AddTool(s, tool, wrapped)
+}
+
+// copyResult returns a shallow copy of result, since the SDK fills in the
+// structured content of each call's result.
+func copyResult(result *mcp.CallToolResult) *mcp.CallToolResult {
+	if result == nil {
+		return nil
+	}
+	c := *result
+	return &c
+}
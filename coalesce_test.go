@@ -0,0 +1,109 @@
+package hypermcp
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestAddCoalescedTool(t *testing.T) {
+	srv, err := New(Config{Name: "test-server", Version: "1.0.0"}, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	type Input struct {
+		City string `json:"city"`
+	}
+	type Output struct {
+		Forecast string `json:"forecast"`
+	}
+
+	var arrived, executions atomic.Int32
+	srv.MCP().AddReceivingMiddleware(func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			if method == "tools/call" {
+				arrived.Add(1)
+			}
+			return next(ctx, method, req)
+		}
+	})
+	release := make(chan struct{})
+	AddCoalescedTool(srv, &mcp.Tool{Name: "forecast", Annotations: ReadOnlyAnnotations()}, func(_ context.Context, _ *mcp.CallToolRequest, in Input) (*mcp.CallToolResult, Output, error) {
+		executions.Add(1)
+		<-release
+		return nil, Output{Forecast: "sunny in " + in.City}, nil
+	})
+
+	const callers = 5
+	sessions := make([]*mcp.ClientSession, callers+1)
+	for i := range sessions {
+		sessions[i] = connectTestClient(t, srv)
+	}
+
+	call := func(session *mcp.ClientSession, city string) (*mcp.CallToolResult, error) {
+		return session.CallTool(context.Background(), &mcp.CallToolParams{
+			Name:      "forecast",
+			Arguments: map[string]any{"city": city},
+		})
+	}
+
+	var wg sync.WaitGroup
+	results := make([]*mcp.CallToolResult, callers)
+	errs := make([]error, callers)
+	for i := range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = call(sessions[i], "Oslo")
+		}()
+	}
+
+	// A call with a different input runs on its own
+	otherDone := make(chan error, 1)
+	go func() {
+		_, err := call(sessions[callers], "Rome")
+		otherDone <- err
+	}()
+
+	// Wait for every call to reach the server before letting the handler finish
+	deadline := time.Now().Add(5 * time.Second)
+	for arrived.Load() < callers+1 || executions.Load() < 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("calls did not arrive: %d arrived, %d executions", arrived.Load(), executions.Load())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if err := <-otherDone; err != nil {
+		t.Errorf("call with other input error = %v", err)
+	}
+	if got := executions.Load(); got != 2 {
+		t.Errorf("handler executed %d times, want 2 (one per distinct input)", got)
+	}
+	for i := range callers {
+		if errs[i] != nil {
+			t.Fatalf("call %d error = %v", i, errs[i])
+		}
+		var out Output
+		if err := remarshalForTest(results[i].StructuredContent, &out); err != nil || out.Forecast != "sunny in Oslo" {
+			t.Errorf("call %d output = %+v (%v), want the shared result", i, out, err)
+		}
+	}
+
+	// Later calls execute afresh
+	if _, err := call(sessions[0], "Oslo"); err != nil {
+		t.Fatalf("later call error = %v", err)
+	}
+	if got := executions.Load(); got != 3 {
+		t.Errorf("handler executed %d times after a later call, want 3", got)
+	}
+}
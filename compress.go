@@ -0,0 +1,149 @@
+package hypermcp
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ContentEncodingMeta is the _meta key GzipResource sets on resource contents
+// it compressed, with the value "gzip".
+const ContentEncodingMeta = "contentEncoding"
+
+// AcceptEncodingMeta is the _meta key of a resources/read request through
+// which a client accepts gzipped contents from GzipResource for that read,
+// with a value such as "gzip", in the syntax of the HTTP Accept-Encoding
+// header.
+const AcceptEncodingMeta = "acceptEncoding"
+
+// GzipCapability is the experimental client capability through which a
+// client accepts gzipped contents from GzipResource for every read of its
+// session. Clients declare it in ClientCapabilities.Experimental:
+//
+//	mcp.NewClient(impl, &mcp.ClientOptions{
+//	    Capabilities: &mcp.ClientCapabilities{
+//	        Experimental: map[string]any{hypermcp.GzipCapability: map[string]any{}},
+//	    },
+//	})
+const GzipCapability = "hypermcp/gzip"
+
+// GzipResource wraps a resource handler so that its contents are gzipped for
+// clients that accept it, saving bandwidth for large text resources:
+//
+//	srv.AddResource(&mcp.Resource{URI: "myapp://report", Name: "Report"},
+//	    hypermcp.GzipResource(reportHandler))
+//
+// Compression is negotiated at the MCP level, never from transport headers:
+// a client opts in for its whole session with the GzipCapability experimental
+// capability, or for a single read by setting AcceptEncodingMeta in the
+// request's _meta. A compressed content has its gzipped bytes in Blob, no
+// Text, its original MIMEType, and _meta.contentEncoding set to "gzip";
+// clients decompress it themselves. Clients that don't opt in, which includes
+// every standard MCP client, get the content as is, as does content that
+// compression would not make smaller.
+func GzipResource(handler mcp.ResourceHandler) mcp.ResourceHandler {
+	return func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+		result, err := handler(ctx, req)
+		if err != nil || result == nil || !acceptsGzip(req) {
+			return result, err
+		}
+
+		// Handlers may return shared results, so compress into copies
+		compressed := *result
+		compressed.Contents = make([]*mcp.ResourceContents, len(result.Contents))
+		for i, contents := range result.Contents {
+			if compressed.Contents[i], err = gzipContents(contents); err != nil {
+				return nil, err
+			}
+		}
+		return &compressed, nil
+	}
+}
+
+// acceptsGzip reports whether the client that sent req opted in to gzipped
+// contents, for the request or for its session.
+func acceptsGzip(req *mcp.ReadResourceRequest) bool {
+	if req == nil {
+		return false
+	}
+	if req.Params != nil {
+		if value, ok := req.Params.Meta[AcceptEncodingMeta].(string); ok {
+			return acceptsGzipEncoding(value)
+		}
+	}
+	if req.Session == nil {
+		return false
+	}
+	init := req.Session.InitializeParams()
+	if init == nil || init.Capabilities == nil {
+		return false
+	}
+	_, ok := init.Capabilities.Experimental[GzipCapability]
+	return ok
+}
+
+// acceptsGzipEncoding reports whether value, a list of content codings in the
+// syntax of the HTTP Accept-Encoding header, allows gzip. Only an explicit
+// gzip counts; a wildcard does not.
+func acceptsGzipEncoding(value string) bool {
+	for _, part := range strings.Split(value, ",") {
+		coding, params, _ := strings.Cut(part, ";")
+		if !strings.EqualFold(strings.TrimSpace(coding), "gzip") {
+			continue
+		}
+		// A weight of zero means "not acceptable"
+		if name, q, ok := strings.Cut(strings.TrimSpace(params), "="); ok && strings.TrimSpace(name) == "q" {
+			if weight, err := strconv.ParseFloat(strings.TrimSpace(q), 64); err == nil && weight == 0 {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// gzipContents returns contents with its data gzipped, or contents itself if
+// it is already encoded or compression doesn't make it smaller.
+func gzipContents(contents *mcp.ResourceContents) (*mcp.ResourceContents, error) {
+	if contents == nil {
+		return nil, nil
+	}
+	if _, encoded := contents.Meta[ContentEncodingMeta]; encoded {
+		return contents, nil
+	}
+
+	data := contents.Blob
+	if contents.Text != "" {
+		data = []byte(contents.Text)
+	}
+	if len(data) == 0 {
+		return contents, nil
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	if buf.Len() >= len(data) {
+		return contents, nil
+	}
+
+	meta := mcp.Meta{ContentEncodingMeta: "gzip"}
+	for k, v := range contents.Meta {
+		meta[k] = v
+	}
+	return &mcp.ResourceContents{
+		URI:      contents.URI,
+		MIMEType: contents.MIMEType,
+		Blob:     buf.Bytes(),
+		Meta:     meta,
+	}, nil
+}
@@ -0,0 +1,177 @@
+package hypermcp
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// report is a large, compressible text resource.
+var report = strings.Repeat("quarterly revenue grew steadily\n", 200)
+
+// newGzipTestServer returns an SSE server with a gzip-wrapped text resource,
+// a gzip-wrapped resource too small to benefit, and a plain resource.
+func newGzipTestServer(t *testing.T) *Server {
+	t.Helper()
+
+	srv := newSSETestServer(t)
+	textHandler := func(text string) mcp.ResourceHandler {
+		return func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+			return &mcp.ReadResourceResult{Contents: []*mcp.ResourceContents{
+				{URI: req.Params.URI, MIMEType: "text/plain", Text: text},
+			}}, nil
+		}
+	}
+	srv.AddResource(&mcp.Resource{URI: "test://report", Name: "Report"}, GzipResource(textHandler(report)))
+	srv.AddResource(&mcp.Resource{URI: "test://tiny", Name: "Tiny"}, GzipResource(textHandler("ok")))
+	srv.AddResource(&mcp.Resource{URI: "test://plain", Name: "Plain"}, textHandler(report))
+	return srv
+}
+
+// connectGzipSSEClient connects an SSE client using httpClient, or the default
+// client if nil, exactly as a standard go-sdk client would.
+func connectGzipSSEClient(t *testing.T, srv *Server, httpClient *http.Client) *mcp.ClientSession {
+	t.Helper()
+
+	handler, err := srv.HTTPHandler(TransportSSE)
+	if err != nil {
+		t.Fatalf("failed to build handler: %v", err)
+	}
+	httpServer := httptest.NewServer(handler)
+	t.Cleanup(httpServer.Close)
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "sse-client", Version: "1.0.0"}, nil)
+	session, err := client.Connect(context.Background(), &mcp.SSEClientTransport{
+		Endpoint:   httpServer.URL + DefaultSSEPath,
+		HTTPClient: httpClient,
+	}, nil)
+	if err != nil {
+		t.Fatalf("failed to connect sse client: %v", err)
+	}
+	t.Cleanup(func() { _ = session.Close() })
+	return session
+}
+
+func readContents(t *testing.T, session *mcp.ClientSession, uri string) *mcp.ResourceContents {
+	t.Helper()
+	res, err := session.ReadResource(context.Background(), &mcp.ReadResourceParams{URI: uri})
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", uri, err)
+	}
+	if len(res.Contents) != 1 {
+		t.Fatalf("expected 1 content block, got %d", len(res.Contents))
+	}
+	return res.Contents[0]
+}
+
+// assertGzipped checks that contents holds report, gzipped.
+func assertGzipped(t *testing.T, contents *mcp.ResourceContents) {
+	t.Helper()
+
+	if contents.Meta[ContentEncodingMeta] != "gzip" {
+		t.Fatalf("expected _meta.%s = gzip, got %v", ContentEncodingMeta, contents.Meta)
+	}
+	if contents.Text != "" || contents.MIMEType != "text/plain" {
+		t.Errorf("expected a blob keeping the text/plain MIME type, got %+v", contents)
+	}
+	if len(contents.Blob) >= len(report) {
+		t.Errorf("expected compressed size below %d, got %d", len(report), len(contents.Blob))
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(contents.Blob))
+	if err != nil {
+		t.Fatalf("blob is not gzipped: %v", err)
+	}
+	if data, err := io.ReadAll(zr); err != nil || string(data) != report {
+		t.Errorf("decompressed content does not match the original (err %v)", err)
+	}
+}
+
+func TestGzipResource_StandardHTTPClient(t *testing.T) {
+	clients := map[string]*http.Client{
+		// net/http sends "Accept-Encoding: gzip" on its own
+		"default client":  nil,
+		"explicit header": {Transport: headerRoundTripper{header: "Accept-Encoding", value: "gzip, deflate"}},
+	}
+	for name, httpClient := range clients {
+		t.Run(name, func(t *testing.T) {
+			session := connectGzipSSEClient(t, newGzipTestServer(t), httpClient)
+			if contents := readContents(t, session, "test://report"); contents.Meta != nil || contents.Text != report {
+				t.Errorf("expected uncompressed text for a client that didn't opt in, got meta %v", contents.Meta)
+			}
+		})
+	}
+}
+
+func TestGzipResource_Capability(t *testing.T) {
+	session := connectTestClientWithOptions(t, newGzipTestServer(t), &mcp.ClientOptions{
+		Capabilities: &mcp.ClientCapabilities{
+			Experimental: map[string]any{GzipCapability: map[string]any{}},
+		},
+	})
+
+	assertGzipped(t, readContents(t, session, "test://report"))
+
+	// Content that doesn't shrink, and resources not wrapped, are left as is
+	for _, uri := range []string{"test://tiny", "test://plain"} {
+		if contents := readContents(t, session, uri); contents.Meta != nil || contents.Text == "" {
+			t.Errorf("expected %s to be served uncompressed, got %+v", uri, contents)
+		}
+	}
+}
+
+func TestGzipResource_RequestMeta(t *testing.T) {
+	session := connectTestClient(t, newGzipTestServer(t))
+	read := func(acceptEncoding string) *mcp.ResourceContents {
+		t.Helper()
+		res, err := session.ReadResource(context.Background(), &mcp.ReadResourceParams{
+			Meta: mcp.Meta{AcceptEncodingMeta: acceptEncoding},
+			URI:  "test://report",
+		})
+		if err != nil {
+			t.Fatalf("failed to read: %v", err)
+		}
+		return res.Contents[0]
+	}
+
+	assertGzipped(t, read("gzip, deflate"))
+	for _, encoding := range []string{"identity", "gzip;q=0, deflate", "*"} {
+		if contents := read(encoding); contents.Meta != nil || contents.Text != report {
+			t.Errorf("%q: expected uncompressed text, got meta %v", encoding, contents.Meta)
+		}
+	}
+}
+
+func TestGzipResource_NoOptIn(t *testing.T) {
+	session := connectTestClient(t, newGzipTestServer(t))
+	if contents := readContents(t, session, "test://report"); contents.Meta != nil || contents.Text != report {
+		t.Errorf("expected uncompressed text without an opt-in, got meta %v", contents.Meta)
+	}
+}
+
+func TestAcceptsGzipEncoding(t *testing.T) {
+	tests := []struct {
+		value string
+		want  bool
+	}{
+		{value: "", want: false},
+		{value: "gzip", want: true},
+		{value: "deflate, GZIP;q=0.5", want: true},
+		{value: "*", want: false},
+		{value: "gzip;q=0", want: false},
+		{value: "gzip; q=0.0", want: false},
+		{value: "identity", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := acceptsGzipEncoding(tt.value); got != tt.want {
+			t.Errorf("acceptsGzipEncoding(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
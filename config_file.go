@@ -0,0 +1,392 @@
+package hypermcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/rayprogramming/hypermcp/cache"
+	"github.com/rayprogramming/hypermcp/httpx"
+)
+
+// LoadConfigFile reads a server configuration from a JSON file.
+//
+// Keys are the camelCase names of the Config fields, with the HTTP client and
+// cache settings nested under "http" and "cache" and the network settings
+// under "transport":
+//
+//	{
+//	    "name": "my-server",
+//	    "version": "1.0.0",
+//	    "cacheEnabled": true,
+//	    "slowToolThreshold": "2s",
+//	    "http": {"requestTimeout": "6s", "maxRetries": 5},
+//	    "cache": {"maxCost": 1048576, "defaultTTL": "5m"},
+//	    "transport": {"addr": ":8080", "auth": {"tokens": ["secret"]}}
+//	}
+//
+// Durations are strings accepted by time.ParseDuration, such as "6s" or
+// "1m30s". Omitted HTTP and cache settings take the values of
+// httpx.DefaultConfig and cache.DefaultConfig. Unknown keys are rejected so
+// typos don't go unnoticed. Fields that hold Go values, such as BaseContext,
+// Clock, or the auth Validator, cannot be set from a file.
+//
+// The loaded configuration is validated before it is returned; validation
+// failures wrap ErrInvalidConfig. YAML is not supported.
+func LoadConfigFile(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("load config file: %w", err)
+	}
+
+	file := fileConfig{
+		HTTP:  newFileHTTPConfig(httpx.DefaultConfig()),
+		Cache: newFileCacheConfig(cache.DefaultConfig()),
+	}
+	if err := decodeConfigFile(data, &file); err != nil {
+		return Config{}, fmt.Errorf("load config file %s: %w", path, err)
+	}
+
+	cfg := file.config()
+	if err := cfg.Validate(); err != nil {
+		return Config{}, fmt.Errorf("load config file %s: %w: %w", path, ErrInvalidConfig, err)
+	}
+	if err := cfg.HTTPConfig.Validate(); err != nil {
+		return Config{}, fmt.Errorf("load config file %s: %w: %w", path, ErrInvalidConfig, NewConfigError("HTTPConfig", err))
+	}
+	return cfg, nil
+}
+
+// decodeConfigFile decodes a single JSON object into file, reporting syntax
+// and type errors with their line and column.
+func decodeConfigFile(data []byte, file *fileConfig) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+
+	err := dec.Decode(file)
+	if err == nil && dec.More() {
+		err = errors.New("unexpected data after the configuration object")
+	}
+
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		line, col := position(data, syntaxErr.Offset)
+		return fmt.Errorf("line %d, column %d: %w", line, col, err)
+	case errors.As(err, &typeErr):
+		line, col := position(data, typeErr.Offset)
+		return fmt.Errorf("line %d, column %d: %q must be %s, not %s", line, col, typeErr.Field, typeErr.Type, typeErr.Value)
+	}
+	return err
+}
+
+// position converts the offset reported by encoding/json, the number of
+// bytes read before the error, to the 1-based line and column of the last
+// byte read.
+func position(data []byte, offset int64) (line, col int) {
+	offset = min(max(offset-1, 0), int64(len(data)))
+	before := data[:offset]
+	line = bytes.Count(before, []byte("\n")) + 1
+	col = len(before) - bytes.LastIndexByte(before, '\n')
+	return line, col
+}
+
+// duration is a time.Duration written in a config file as a string such as "6s".
+type duration time.Duration
+
+func (d *duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string such as \"6s\", got %s", data)
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return fmt.Errorf("invalid duration %q: %w", s, err)
+	}
+	*d = duration(v)
+	return nil
+}
+
+// fileConfig is the JSON layout read by LoadConfigFile.
+type fileConfig struct {
+	Name                       string              `json:"name"`
+	Version                    string              `json:"version"`
+	Commit                     string              `json:"commit"`
+	BuildDate                  string              `json:"buildDate"`
+	CacheEnabled               bool                `json:"cacheEnabled"`
+	CacheRequired              *bool               `json:"cacheRequired"`
+	MaxConcurrentTools         int                 `json:"maxConcurrentTools"`
+	QueueSize                  int                 `json:"queueSize"`
+	MaxConcurrentResourceReads int                 `json:"maxConcurrentResourceReads"`
+	SlowToolThreshold          duration            `json:"slowToolThreshold"`
+	TrackTemplateParams        int                 `json:"trackTemplateParams"`
+	MetricsLogInterval         duration            `json:"metricsLogInterval"`
+	RecentErrors               int                 `json:"recentErrors"`
+	ResourceCacheMaxBytes      int64               `json:"resourceCacheMaxBytes"`
+	CheckSDK                   bool                `json:"checkSDK"`
+	StrictRegistration         bool                `json:"strictRegistration"`
+	ValidateToolOutput         bool                `json:"validateToolOutput"`
+	MaxToolOutputBytes         int64               `json:"maxToolOutputBytes"`
+	TruncateToolOutput         bool                `json:"truncateToolOutput"`
+	DisableStartupLog          bool                `json:"disableStartupLog"`
+	Transport                  fileTransportConfig `json:"transport"`
+	Degraded                   fileDegradedConfig  `json:"degraded"`
+	ClientLimits               fileClientLimits    `json:"clientLimits"`
+	Health                     fileHealthConfig    `json:"health"`
+	Stdio                      fileStdioConfig     `json:"stdio"`
+	HTTP                       fileHTTPConfig      `json:"http"`
+	Cache                      fileCacheConfig     `json:"cache"`
+}
+
+func (f fileConfig) config() Config {
+	httpConfig := f.HTTP.config()
+	cfg := Config{
+		Name:                       f.Name,
+		Version:                    f.Version,
+		Commit:                     f.Commit,
+		BuildDate:                  f.BuildDate,
+		CacheEnabled:               f.CacheEnabled,
+		CacheRequired:              f.CacheRequired,
+		MaxConcurrentTools:         f.MaxConcurrentTools,
+		QueueSize:                  f.QueueSize,
+		MaxConcurrentResourceReads: f.MaxConcurrentResourceReads,
+		SlowToolThreshold:          time.Duration(f.SlowToolThreshold),
+		TrackTemplateParams:        f.TrackTemplateParams,
+		MetricsLogInterval:         time.Duration(f.MetricsLogInterval),
+		RecentErrors:               f.RecentErrors,
+		ResourceCacheMaxBytes:      f.ResourceCacheMaxBytes,
+		CheckSDK:                   f.CheckSDK,
+		StrictRegistration:         f.StrictRegistration,
+		ValidateToolOutput:         f.ValidateToolOutput,
+		MaxToolOutputBytes:         f.MaxToolOutputBytes,
+		TruncateToolOutput:         f.TruncateToolOutput,
+		DisableStartupLog:          f.DisableStartupLog,
+		Transport:                  f.Transport.config(),
+		Degraded:                   f.Degraded.config(),
+		ClientLimits:               f.ClientLimits.config(),
+		Health:                     f.Health.config(),
+		Stdio:                      f.Stdio.config(),
+		HTTPConfig:                 &httpConfig,
+	}
+	// Leave CacheConfig zero when caching is off, unless the file set it, so
+	// Validate can report the meaningless combination
+	if f.CacheEnabled || f.Cache != newFileCacheConfig(cache.DefaultConfig()) {
+		cfg.CacheConfig = f.Cache.config()
+	}
+	return cfg
+}
+
+type fileTransportConfig struct {
+	Addr        string   `json:"addr"`
+	SocketPath  string   `json:"socketPath"`
+	SSEPath     string   `json:"ssePath"`
+	IdleTimeout duration `json:"idleTimeout"`
+	Auth        struct {
+		Tokens []string `json:"tokens"`
+	} `json:"auth"`
+}
+
+func (f fileTransportConfig) config() TransportConfig {
+	return TransportConfig{
+		Addr:        f.Addr,
+		SocketPath:  f.SocketPath,
+		SSEPath:     f.SSEPath,
+		IdleTimeout: time.Duration(f.IdleTimeout),
+		Auth:        AuthConfig{Tokens: f.Auth.Tokens},
+	}
+}
+
+type fileDegradedConfig struct {
+	ErrorRate         float64  `json:"errorRate"`
+	Window            duration `json:"window"`
+	MinCalls          int      `json:"minCalls"`
+	NonEssentialTools []string `json:"nonEssentialTools"`
+}
+
+func (f fileDegradedConfig) config() DegradedConfig {
+	return DegradedConfig{
+		ErrorRate:         f.ErrorRate,
+		Window:            time.Duration(f.Window),
+		MinCalls:          f.MinCalls,
+		NonEssentialTools: f.NonEssentialTools,
+	}
+}
+
+type fileClientLimit struct {
+	RequestsPerSecond    float64 `json:"requestsPerSecond"`
+	DestructiveToolsOnly bool    `json:"destructiveToolsOnly"`
+	Burst                int     `json:"burst"`
+	MaxRequestBytes      int     `json:"maxRequestBytes"`
+}
+
+func (f fileClientLimit) config() ClientLimit {
+	return ClientLimit{
+		RequestsPerSecond:    f.RequestsPerSecond,
+		DestructiveToolsOnly: f.DestructiveToolsOnly,
+		Burst:                f.Burst,
+		MaxRequestBytes:      f.MaxRequestBytes,
+	}
+}
+
+type fileClientLimits struct {
+	Default fileClientLimit            `json:"default"`
+	Clients map[string]fileClientLimit `json:"clients"`
+}
+
+func (f fileClientLimits) config() ClientLimitsConfig {
+	cfg := ClientLimitsConfig{Default: f.Default.config()}
+	if len(f.Clients) > 0 {
+		cfg.Clients = make(map[string]ClientLimit, len(f.Clients))
+		for name, limit := range f.Clients {
+			cfg.Clients[name] = limit.config()
+		}
+	}
+	return cfg
+}
+
+type fileHealthConfig struct {
+	CacheEvictionRatio float64 `json:"cacheEvictionRatio"`
+	CacheMinSets       int     `json:"cacheMinSets"`
+}
+
+func (f fileHealthConfig) config() HealthConfig {
+	return HealthConfig{
+		CacheEvictionRatio: f.CacheEvictionRatio,
+		CacheMinSets:       f.CacheMinSets,
+	}
+}
+
+type fileStdioConfig struct {
+	WriteBufferSize           int  `json:"writeBufferSize"`
+	DropNotificationsWhenFull bool `json:"dropNotificationsWhenFull"`
+}
+
+func (f fileStdioConfig) config() StdioConfig {
+	return StdioConfig{
+		WriteBufferSize:           f.WriteBufferSize,
+		DropNotificationsWhenFull: f.DropNotificationsWhenFull,
+	}
+}
+
+type fileHTTPConfig struct {
+	DialTimeout           duration `json:"dialTimeout"`
+	TLSHandshakeTimeout   duration `json:"tlsHandshakeTimeout"`
+	ResponseHeaderTimeout duration `json:"responseHeaderTimeout"`
+	RequestTimeout        duration `json:"requestTimeout"`
+	MaxRetries            int      `json:"maxRetries"`
+	RetryBudget           int      `json:"retryBudget"`
+	RetryBudgetInterval   duration `json:"retryBudgetInterval"`
+	InitialInterval       duration `json:"initialInterval"`
+	MaxInterval           duration `json:"maxInterval"`
+	HedgeAfter            duration `json:"hedgeAfter"`
+	MaxHedges             int      `json:"maxHedges"`
+	MaxResponseSize       int64    `json:"maxResponseSize"`
+	ErrorBodyLimit        int64    `json:"errorBodyLimit"`
+	MaxIdleConns          int      `json:"maxIdleConns"`
+	MaxIdleConnsPerHost   int      `json:"maxIdleConnsPerHost"`
+	IdleConnTimeout       duration `json:"idleConnTimeout"`
+	UserAgent             string   `json:"userAgent"`
+	DisableCompression    bool     `json:"disableCompression"`
+	ForceAttemptHTTP2     bool     `json:"forceAttemptHTTP2"`
+	DisableKeepAlives     bool     `json:"disableKeepAlives"`
+}
+
+func newFileHTTPConfig(c httpx.Config) fileHTTPConfig {
+	return fileHTTPConfig{
+		DialTimeout:           duration(c.DialTimeout),
+		TLSHandshakeTimeout:   duration(c.TLSHandshakeTimeout),
+		ResponseHeaderTimeout: duration(c.ResponseHeaderTimeout),
+		RequestTimeout:        duration(c.RequestTimeout),
+		MaxRetries:            c.MaxRetries,
+		RetryBudget:           c.RetryBudget,
+		RetryBudgetInterval:   duration(c.RetryBudgetInterval),
+		InitialInterval:       duration(c.InitialInterval),
+		MaxInterval:           duration(c.MaxInterval),
+		HedgeAfter:            duration(c.HedgeAfter),
+		MaxHedges:             c.MaxHedges,
+		MaxResponseSize:       c.MaxResponseSize,
+		ErrorBodyLimit:        c.ErrorBodyLimit,
+		MaxIdleConns:          c.MaxIdleConns,
+		MaxIdleConnsPerHost:   c.MaxIdleConnsPerHost,
+		IdleConnTimeout:       duration(c.IdleConnTimeout),
+		UserAgent:             c.UserAgent,
+		DisableCompression:    c.DisableCompression,
+		ForceAttemptHTTP2:     c.ForceAttemptHTTP2,
+		DisableKeepAlives:     c.DisableKeepAlives,
+	}
+}
+
+func (f fileHTTPConfig) config() httpx.Config {
+	return httpx.Config{
+		DialTimeout:           time.Duration(f.DialTimeout),
+		TLSHandshakeTimeout:   time.Duration(f.TLSHandshakeTimeout),
+		ResponseHeaderTimeout: time.Duration(f.ResponseHeaderTimeout),
+		RequestTimeout:        time.Duration(f.RequestTimeout),
+		MaxRetries:            f.MaxRetries,
+		RetryBudget:           f.RetryBudget,
+		RetryBudgetInterval:   time.Duration(f.RetryBudgetInterval),
+		InitialInterval:       time.Duration(f.InitialInterval),
+		MaxInterval:           time.Duration(f.MaxInterval),
+		HedgeAfter:            time.Duration(f.HedgeAfter),
+		MaxHedges:             f.MaxHedges,
+		MaxResponseSize:       f.MaxResponseSize,
+		ErrorBodyLimit:        f.ErrorBodyLimit,
+		MaxIdleConns:          f.MaxIdleConns,
+		MaxIdleConnsPerHost:   f.MaxIdleConnsPerHost,
+		IdleConnTimeout:       time.Duration(f.IdleConnTimeout),
+		UserAgent:             f.UserAgent,
+		DisableCompression:    f.DisableCompression,
+		ForceAttemptHTTP2:     f.ForceAttemptHTTP2,
+		DisableKeepAlives:     f.DisableKeepAlives,
+	}
+}
+
+type fileCacheConfig struct {
+	MaxCost             int64    `json:"maxCost"`
+	MaxBytes            int64    `json:"maxBytes"`
+	NumCounters         int64    `json:"numCounters"`
+	BufferItems         int64    `json:"bufferItems"`
+	DefaultTTL          duration `json:"defaultTTL"`
+	ZeroTTLUsesDefault  bool     `json:"zeroTTLUsesDefault"`
+	KeyHighWater        int      `json:"keyHighWater"`
+	MemoryLimit         int64    `json:"memoryLimit"`
+	MemoryCheckInterval duration `json:"memoryCheckInterval"`
+	HashKeys            bool     `json:"hashKeys"`
+	DeleteExpiredOnGet  bool     `json:"deleteExpiredOnGet"`
+}
+
+func newFileCacheConfig(c cache.Config) fileCacheConfig {
+	return fileCacheConfig{
+		MaxCost:             c.MaxCost,
+		MaxBytes:            c.MaxBytes,
+		NumCounters:         c.NumCounters,
+		BufferItems:         c.BufferItems,
+		DefaultTTL:          duration(c.DefaultTTL),
+		ZeroTTLUsesDefault:  c.ZeroTTLUsesDefault,
+		KeyHighWater:        c.KeyHighWater,
+		MemoryLimit:         c.MemoryLimit,
+		MemoryCheckInterval: duration(c.MemoryCheckInterval),
+		HashKeys:            c.HashKeys,
+		DeleteExpiredOnGet:  c.DeleteExpiredOnGet,
+	}
+}
+
+func (f fileCacheConfig) config() cache.Config {
+	return cache.Config{
+		MaxCost:             f.MaxCost,
+		MaxBytes:            f.MaxBytes,
+		NumCounters:         f.NumCounters,
+		BufferItems:         f.BufferItems,
+		DefaultTTL:          time.Duration(f.DefaultTTL),
+		ZeroTTLUsesDefault:  f.ZeroTTLUsesDefault,
+		KeyHighWater:        f.KeyHighWater,
+		MemoryLimit:         f.MemoryLimit,
+		MemoryCheckInterval: time.Duration(f.MemoryCheckInterval),
+		HashKeys:            f.HashKeys,
+		DeleteExpiredOnGet:  f.DeleteExpiredOnGet,
+	}
+}
@@ -0,0 +1,231 @@
+package hypermcp
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rayprogramming/hypermcp/cache"
+	"github.com/rayprogramming/hypermcp/httpx"
+	"go.uber.org/zap/zaptest"
+)
+
+// writeConfigFile writes content to a file in a temporary directory and returns its path.
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write config file: %v", err)
+	}
+	return path
+}
+
+func TestLoadConfigFile(t *testing.T) {
+	path := writeConfigFile(t, `{
+		"name": "file-server",
+		"version": "2.0.0",
+		"cacheEnabled": true,
+		"maxConcurrentTools": 4,
+		"queueSize": 8,
+		"slowToolThreshold": "1500ms",
+		"http": {
+			"requestTimeout": "6s",
+			"maxRetries": 5,
+			"hedgeAfter": "150ms",
+			"maxHedges": 2,
+			"userAgent": "file-agent"
+		},
+		"cache": {
+			"maxCost": 2048,
+			"defaultTTL": "5m"
+		},
+		"degraded": {
+			"errorRate": 0.5,
+			"window": "30s",
+			"nonEssentialTools": ["report"]
+		},
+		"transport": {
+			"addr": ":8080",
+			"idleTimeout": "1m",
+			"auth": {"tokens": ["secret"]}
+		}
+	}`)
+
+	cfg, err := LoadConfigFile(path)
+	if err != nil {
+		t.Fatalf("LoadConfigFile() error = %v", err)
+	}
+
+	if cfg.Name != "file-server" || cfg.Version != "2.0.0" {
+		t.Errorf("Name, Version = %q, %q", cfg.Name, cfg.Version)
+	}
+	if !cfg.CacheEnabled || cfg.MaxConcurrentTools != 4 || cfg.QueueSize != 8 {
+		t.Errorf("CacheEnabled, MaxConcurrentTools, QueueSize = %v, %d, %d", cfg.CacheEnabled, cfg.MaxConcurrentTools, cfg.QueueSize)
+	}
+	if cfg.SlowToolThreshold != 1500*time.Millisecond {
+		t.Errorf("SlowToolThreshold = %v, want 1.5s", cfg.SlowToolThreshold)
+	}
+
+	if cfg.Degraded.ErrorRate != 0.5 || cfg.Degraded.Window != 30*time.Second || len(cfg.Degraded.NonEssentialTools) != 1 {
+		t.Errorf("Degraded = %+v", cfg.Degraded)
+	}
+
+	if cfg.HTTPConfig == nil {
+		t.Fatal("HTTPConfig is nil")
+	}
+	if cfg.HTTPConfig.RequestTimeout != 6*time.Second || cfg.HTTPConfig.MaxRetries != 5 || cfg.HTTPConfig.UserAgent != "file-agent" {
+		t.Errorf("HTTPConfig = %+v", *cfg.HTTPConfig)
+	}
+	if cfg.HTTPConfig.HedgeAfter != 150*time.Millisecond || cfg.HTTPConfig.MaxHedges != 2 {
+		t.Errorf("HedgeAfter, MaxHedges = %v, %d", cfg.HTTPConfig.HedgeAfter, cfg.HTTPConfig.MaxHedges)
+	}
+
+	if cfg.CacheConfig.MaxCost != 2048 || cfg.CacheConfig.DefaultTTL != 5*time.Minute {
+		t.Errorf("CacheConfig = %+v", cfg.CacheConfig)
+	}
+
+	if cfg.Transport.Addr != ":8080" || cfg.Transport.IdleTimeout != time.Minute {
+		t.Errorf("Transport = %+v", cfg.Transport)
+	}
+	if len(cfg.Transport.Auth.Tokens) != 1 || cfg.Transport.Auth.Tokens[0] != "secret" {
+		t.Errorf("Transport.Auth.Tokens = %v", cfg.Transport.Auth.Tokens)
+	}
+
+	srv, err := New(cfg, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("New() with loaded config error = %v", err)
+	}
+	_ = srv.Shutdown(context.Background())
+}
+
+func TestLoadConfigFile_Defaults(t *testing.T) {
+	path := writeConfigFile(t, `{
+		"name": "file-server",
+		"version": "1.0.0",
+		"cacheEnabled": true,
+		"http": {"maxRetries": 1},
+		"cache": {"defaultTTL": "30s"}
+	}`)
+
+	cfg, err := LoadConfigFile(path)
+	if err != nil {
+		t.Fatalf("LoadConfigFile() error = %v", err)
+	}
+
+	wantHTTP := httpx.DefaultConfig()
+	wantHTTP.MaxRetries = 1
+	if !reflect.DeepEqual(*cfg.HTTPConfig, wantHTTP) {
+		t.Errorf("HTTPConfig = %+v, want defaults with MaxRetries 1: %+v", *cfg.HTTPConfig, wantHTTP)
+	}
+
+	wantCache := cache.DefaultConfig()
+	wantCache.DefaultTTL = 30 * time.Second
+	if cfg.CacheConfig.MaxCost != wantCache.MaxCost ||
+		cfg.CacheConfig.NumCounters != wantCache.NumCounters ||
+		cfg.CacheConfig.BufferItems != wantCache.BufferItems ||
+		cfg.CacheConfig.DefaultTTL != wantCache.DefaultTTL {
+		t.Errorf("CacheConfig = %+v, want defaults with DefaultTTL 30s", cfg.CacheConfig)
+	}
+}
+
+func TestLoadConfigFile_CacheDisabled(t *testing.T) {
+	path := writeConfigFile(t, `{"name": "file-server", "version": "1.0.0"}`)
+
+	cfg, err := LoadConfigFile(path)
+	if err != nil {
+		t.Fatalf("LoadConfigFile() error = %v", err)
+	}
+	if cfg.CacheEnabled || !reflect.ValueOf(cfg.CacheConfig).IsZero() {
+		t.Errorf("cache settings = %v, %+v, want disabled and zero", cfg.CacheEnabled, cfg.CacheConfig)
+	}
+}
+
+func TestLoadConfigFile_Errors(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		wantErr     string
+		wantInvalid bool
+	}{
+		{
+			name:    "malformed JSON",
+			content: "{\n\t\"name\": \"file-server\",\n\t\"version\": \"1.0.0\"\n\t\"cacheEnabled\": true\n}",
+			wantErr: "line 4, column 2",
+		},
+		{
+			name:    "wrong type",
+			content: `{"name": "file-server", "version": "1.0.0", "queueSize": "ten"}`,
+			wantErr: `"queueSize" must be int, not string`,
+		},
+		{
+			name:    "unknown field",
+			content: `{"name": "file-server", "version": "1.0.0", "cacheEnabeld": true}`,
+			wantErr: `unknown field "cacheEnabeld"`,
+		},
+		{
+			name:    "invalid duration",
+			content: `{"name": "file-server", "version": "1.0.0", "slowToolThreshold": "6 seconds"}`,
+			wantErr: `invalid duration "6 seconds"`,
+		},
+		{
+			name:    "numeric duration",
+			content: `{"name": "file-server", "version": "1.0.0", "http": {"requestTimeout": 6}}`,
+			wantErr: `duration must be a string such as "6s"`,
+		},
+		{
+			name:    "trailing data",
+			content: `{"name": "file-server", "version": "1.0.0"} {}`,
+			wantErr: "unexpected data after the configuration object",
+		},
+		{
+			name:        "missing name",
+			content:     `{"version": "1.0.0"}`,
+			wantErr:     `field "Name"`,
+			wantInvalid: true,
+		},
+		{
+			name:        "cache settings without cache",
+			content:     `{"name": "file-server", "version": "1.0.0", "cache": {"maxCost": 10}}`,
+			wantErr:     `field "CacheConfig"`,
+			wantInvalid: true,
+		},
+		{
+			name:        "invalid HTTP settings",
+			content:     `{"name": "file-server", "version": "1.0.0", "http": {"maxRetries": -1}}`,
+			wantErr:     `field "MaxRetries"`,
+			wantInvalid: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeConfigFile(t, tt.content)
+
+			_, err := LoadConfigFile(path)
+			if err == nil {
+				t.Fatal("LoadConfigFile() error = nil")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %q, want it to contain %q", err, tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), path) {
+				t.Errorf("error = %q, want it to name the file", err)
+			}
+			if got := errors.Is(err, ErrInvalidConfig); got != tt.wantInvalid {
+				t.Errorf("errors.Is(err, ErrInvalidConfig) = %v, want %v", got, tt.wantInvalid)
+			}
+		})
+	}
+}
+
+func TestLoadConfigFile_MissingFile(t *testing.T) {
+	_, err := LoadConfigFile(filepath.Join(t.TempDir(), "missing.json"))
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("error = %v, want os.ErrNotExist", err)
+	}
+}
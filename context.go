@@ -0,0 +1,218 @@
+package hypermcp
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rayprogramming/hypermcp/cache"
+	"github.com/rayprogramming/hypermcp/httpx"
+	"go.uber.org/zap"
+)
+
+// baseValueContext layers the values of a base context beneath a request context.
+//
+// Deadlines, cancellation, and errors come from the request context, so handler
+// invocations still stop when the run context or the client cancels. Values are
+// looked up in the request context first and fall back to the base context.
+type baseValueContext struct {
+	context.Context
+	base context.Context
+}
+
+func (c baseValueContext) Value(key any) any {
+	if v := c.Context.Value(key); v != nil {
+		return v
+	}
+	return c.base.Value(key)
+}
+
+// baseContextMiddleware returns receiving middleware that makes the values of
+// the context returned by baseContext visible to every handler invocation.
+func baseContextMiddleware(baseContext func() context.Context) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			if base := baseContext(); base != nil {
+				ctx = baseValueContext{Context: ctx, base: base}
+			}
+			return next(ctx, method, req)
+		}
+	}
+}
+
+// RequestMetadata describes the transport-level context of an MCP request.
+//
+// Which fields are populated depends on the transport:
+//
+//	Field          stdio, unix  SSE                        streamable HTTP          in-memory
+//	Method         yes          yes                        yes                      yes
+//	SessionID      no           no                         yes                      no
+//	ClientName     yes          yes                        yes                      yes
+//	ClientVersion  yes          yes                        yes                      yes
+//	ProgressToken  yes          yes                        yes                      yes
+//	Header         no           headers of the stream GET  headers of each request  no
+//
+// Streamable HTTP applies when the server is served with the SDK's
+// mcp.NewStreamableHTTPHandler over MCP. The SDK's SSE transport assigns
+// session IDs but does not expose them to handlers, so SessionID is empty
+// there. ClientName and ClientVersion are empty until the client has
+// initialized, and ProgressToken is nil unless the client asked for progress
+// notifications.
+type RequestMetadata struct {
+	// Header holds the HTTP headers associated with the request. For the SSE
+	// transport these are the headers of the GET request that opened the
+	// session's event stream, which is where clients send credentials.
+	Header http.Header
+
+	// ProgressToken is the token the client supplied for progress notifications, if any.
+	ProgressToken any
+
+	// Method is the MCP method being handled, e.g. "tools/call".
+	Method string
+
+	// SessionID identifies the client session on transports that assign IDs.
+	SessionID string
+
+	// ClientName and ClientVersion identify the client as reported during initialization.
+	ClientName    string
+	ClientVersion string
+}
+
+type requestMetaKey struct{}
+
+type connectionHeaderKey struct{}
+
+// RequestMeta returns the transport metadata for the request being handled.
+//
+// It is available inside any tool, resource, or prompt handler registered on
+// the server, so a tool can, for example, read an API key sent by the client:
+//
+//	apiKey := hypermcp.RequestMeta(ctx).Header.Get("X-API-Key")
+//
+// Outside a handler it returns the zero value.
+func RequestMeta(ctx context.Context) RequestMetadata {
+	meta, _ := ctx.Value(requestMetaKey{}).(RequestMetadata)
+	return meta
+}
+
+type sessionKey struct{}
+
+// sessionFromContext returns the session of the client whose request is being
+// handled, or nil outside a handler.
+func sessionFromContext(ctx context.Context) *mcp.ServerSession {
+	session, _ := ctx.Value(sessionKey{}).(*mcp.ServerSession)
+	return session
+}
+
+// withConnectionHeader records the HTTP headers of the request that established a
+// session, so handlers for that session can see them via RequestMeta.
+func withConnectionHeader(ctx context.Context, header http.Header) context.Context {
+	return context.WithValue(ctx, connectionHeaderKey{}, header)
+}
+
+// requestMetaMiddleware returns receiving middleware that attaches RequestMetadata
+// and the client's session to the context of every incoming request.
+func requestMetaMiddleware() mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			ctx = context.WithValue(ctx, requestMetaKey{}, newRequestMetadata(ctx, method, req))
+			if session, ok := req.GetSession().(*mcp.ServerSession); ok && session != nil {
+				ctx = context.WithValue(ctx, sessionKey{}, session)
+			}
+			return next(ctx, method, req)
+		}
+	}
+}
+
+// newRequestMetadata collects the metadata for req from the request, its session, and ctx.
+func newRequestMetadata(ctx context.Context, method string, req mcp.Request) RequestMetadata {
+	meta := RequestMetadata{Method: method}
+
+	if extra := req.GetExtra(); extra != nil && extra.Header != nil {
+		meta.Header = extra.Header
+	} else if header, ok := ctx.Value(connectionHeaderKey{}).(http.Header); ok {
+		meta.Header = header
+	}
+
+	// Params is an interface over pointer types; guard against typed nils
+	if params := req.GetParams(); params != nil && !reflect.ValueOf(params).IsNil() {
+		meta.ProgressToken = params.GetMeta()["progressToken"]
+	}
+
+	if session, ok := req.GetSession().(*mcp.ServerSession); ok && session != nil {
+		meta.SessionID = session.ID()
+		if init := session.InitializeParams(); init != nil && init.ClientInfo != nil {
+			meta.ClientName = init.ClientInfo.Name
+			meta.ClientVersion = init.ClientInfo.Version
+		}
+	}
+
+	return meta
+}
+
+type serverKey struct{}
+
+// serverContextMiddleware returns receiving middleware that makes s available to
+// handlers through ServerFromContext and the related accessors.
+func serverContextMiddleware(s *Server) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			return next(context.WithValue(ctx, serverKey{}, s), method, req)
+		}
+	}
+}
+
+// ServerFromContext returns the Server handling the current request, or nil
+// outside a handler.
+//
+// Every tool, resource, and prompt handler receives the server in its context,
+// so handlers defined far from the server (in another package, or a Module)
+// can reach the shared infrastructure without a closure over the server:
+//
+//	func getWeather(ctx context.Context, req *mcp.CallToolRequest, in Input) (*mcp.CallToolResult, Output, error) {
+//	    var resp WeatherResponse
+//	    err := hypermcp.HTTPClientFromContext(ctx).Get(ctx, url, &resp)
+//	    ...
+//	}
+func ServerFromContext(ctx context.Context) *Server {
+	s, _ := ctx.Value(serverKey{}).(*Server)
+	return s
+}
+
+// HTTPClientFromContext returns the shared HTTP client of the server handling
+// the current request, or nil outside a handler.
+func HTTPClientFromContext(ctx context.Context) *httpx.Client {
+	if s := ServerFromContext(ctx); s != nil {
+		return s.HTTPClient()
+	}
+	return nil
+}
+
+// CacheFromContext returns the cache of the server handling the current
+// request, or nil outside a handler.
+func CacheFromContext(ctx context.Context) *cache.Cache {
+	if s := ServerFromContext(ctx); s != nil {
+		return s.Cache()
+	}
+	return nil
+}
+
+// MetricsFromContext returns the metrics of the server handling the current
+// request, or nil outside a handler.
+func MetricsFromContext(ctx context.Context) *Metrics {
+	if s := ServerFromContext(ctx); s != nil {
+		return s.Metrics()
+	}
+	return nil
+}
+
+// LoggerFromContext returns the logger of the server handling the current
+// request. Outside a handler it returns a no-op logger, so it is always safe
+// to use.
+func LoggerFromContext(ctx context.Context) *zap.Logger {
+	if s := ServerFromContext(ctx); s != nil {
+		return s.Logger()
+	}
+	return zap.NewNop()
+}
@@ -0,0 +1,288 @@
+package hypermcp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap/zaptest"
+)
+
+type testContextKey struct{}
+
+func TestConfig_BaseContext(t *testing.T) {
+	// The base context is already canceled: its values must still be visible,
+	// but its cancellation must not leak into handler invocations.
+	base, cancel := context.WithCancel(context.WithValue(context.Background(), testContextKey{}, "db-handle"))
+	cancel()
+
+	srv, err := New(Config{
+		Name:        "test-server",
+		Version:     "1.0.0",
+		BaseContext: func() context.Context { return base },
+	}, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+
+	var gotValue any
+	var gotErr error
+	AddTool(srv, &mcp.Tool{
+		Name:        "read_value",
+		Description: "Reads a value from the context",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input struct{}) (*mcp.CallToolResult, any, error) {
+		gotValue = ctx.Value(testContextKey{})
+		gotErr = ctx.Err()
+		return &mcp.CallToolResult{}, nil, nil
+	})
+
+	session := connectTestClient(t, srv)
+	if _, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "read_value"}); err != nil {
+		t.Fatalf("tool call failed: %v", err)
+	}
+
+	if gotValue != "db-handle" {
+		t.Errorf("expected base context value %q, got %v", "db-handle", gotValue)
+	}
+	if gotErr != nil {
+		t.Errorf("expected handler context to be live, got %v", gotErr)
+	}
+}
+
+func TestBaseValueContext_RequestValuesTakePrecedence(t *testing.T) {
+	base := context.WithValue(context.Background(), testContextKey{}, "base")
+	req, cancel := context.WithCancel(context.WithValue(context.Background(), testContextKey{}, "request"))
+
+	ctx := baseValueContext{Context: req, base: base}
+	if got := ctx.Value(testContextKey{}); got != "request" {
+		t.Errorf("expected request value to win, got %v", got)
+	}
+
+	cancel()
+	if ctx.Err() == nil {
+		t.Error("expected cancellation of the request context to propagate")
+	}
+}
+
+// headerRoundTripper adds a fixed header to every outgoing request.
+type headerRoundTripper struct {
+	header, value string
+}
+
+func (rt headerRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set(rt.header, rt.value)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// addRequestMetaTool registers a tool that records the RequestMeta it sees.
+func addRequestMetaTool(srv *Server, got *RequestMetadata) {
+	AddTool(srv, &mcp.Tool{
+		Name:        "whoami",
+		Description: "Records the request metadata",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input struct{}) (*mcp.CallToolResult, any, error) {
+		*got = RequestMeta(ctx)
+		return &mcp.CallToolResult{}, nil, nil
+	})
+}
+
+func TestRequestMeta_SSEHeaders(t *testing.T) {
+	srv := newSSETestServer(t)
+
+	var got RequestMetadata
+	addRequestMetaTool(srv, &got)
+
+	handler, err := srv.HTTPHandler(TransportSSE)
+	if err != nil {
+		t.Fatalf("failed to build handler: %v", err)
+	}
+	httpServer := httptest.NewServer(handler)
+	t.Cleanup(httpServer.Close)
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "sse-client", Version: "2.0.0"}, nil)
+	session, err := client.Connect(context.Background(), &mcp.SSEClientTransport{
+		Endpoint:   httpServer.URL + DefaultSSEPath,
+		HTTPClient: &http.Client{Transport: headerRoundTripper{header: "X-API-Key", value: "secret"}},
+	}, nil)
+	if err != nil {
+		t.Fatalf("failed to connect sse client: %v", err)
+	}
+	t.Cleanup(func() { _ = session.Close() })
+
+	if _, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "whoami"}); err != nil {
+		t.Fatalf("tool call failed: %v", err)
+	}
+
+	if key := got.Header.Get("X-API-Key"); key != "secret" {
+		t.Errorf("expected X-API-Key header %q, got %q", "secret", key)
+	}
+	if got.Method != "tools/call" {
+		t.Errorf("expected method tools/call, got %q", got.Method)
+	}
+	if got.ClientName != "sse-client" || got.ClientVersion != "2.0.0" {
+		t.Errorf("unexpected client identity %q %q", got.ClientName, got.ClientVersion)
+	}
+	// The SDK's SSE transport keeps its session IDs to itself
+	if got.SessionID != "" {
+		t.Errorf("expected no session ID over SSE, got %q", got.SessionID)
+	}
+}
+
+func TestRequestMeta_StreamableHTTP(t *testing.T) {
+	srv := newSSETestServer(t)
+
+	var got RequestMetadata
+	addRequestMetaTool(srv, &got)
+
+	handler := mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return srv.MCP() }, nil)
+	httpServer := httptest.NewServer(handler)
+	t.Cleanup(httpServer.Close)
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "http-client", Version: "1.0.0"}, nil)
+	session, err := client.Connect(context.Background(), &mcp.StreamableClientTransport{
+		Endpoint:   httpServer.URL,
+		HTTPClient: &http.Client{Transport: headerRoundTripper{header: "X-API-Key", value: "secret"}},
+	}, nil)
+	if err != nil {
+		t.Fatalf("failed to connect streamable client: %v", err)
+	}
+	t.Cleanup(func() { _ = session.Close() })
+
+	if _, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "whoami"}); err != nil {
+		t.Fatalf("tool call failed: %v", err)
+	}
+
+	if got.SessionID == "" || got.SessionID != session.ID() {
+		t.Errorf("expected session ID %q, got %q", session.ID(), got.SessionID)
+	}
+	if key := got.Header.Get("X-API-Key"); key != "secret" {
+		t.Errorf("expected X-API-Key header %q, got %q", "secret", key)
+	}
+}
+
+func TestRequestMeta_InMemory(t *testing.T) {
+	srv, err := New(Config{
+		Name:    "test-server",
+		Version: "1.0.0",
+	}, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+
+	var got RequestMetadata
+	addRequestMetaTool(srv, &got)
+
+	session := connectTestClient(t, srv)
+	params := &mcp.CallToolParams{
+		Meta: mcp.Meta{"progressToken": "progress-1"},
+		Name: "whoami",
+	}
+	if _, err := session.CallTool(context.Background(), params); err != nil {
+		t.Fatalf("tool call failed: %v", err)
+	}
+
+	if got.ProgressToken != "progress-1" {
+		t.Errorf("expected progress token %q, got %v", "progress-1", got.ProgressToken)
+	}
+	if got.ClientName != "test-client" || got.ClientVersion != "1.0.0" {
+		t.Errorf("unexpected client identity %q %q", got.ClientName, got.ClientVersion)
+	}
+	if got.Header != nil {
+		t.Errorf("expected no headers over an in-memory transport, got %v", got.Header)
+	}
+	if got.SessionID != "" {
+		t.Errorf("expected no session ID over an in-memory transport, got %q", got.SessionID)
+	}
+}
+
+func TestRequestMeta_OutsideHandler(t *testing.T) {
+	meta := RequestMeta(context.Background())
+	if meta.Method != "" || meta.Header != nil || meta.ProgressToken != nil {
+		t.Errorf("expected zero RequestMetadata, got %+v", meta)
+	}
+}
+
+// lookupWeather is defined without access to the server, as a handler in
+// another package would be.
+func lookupWeather(ctx context.Context, req *mcp.CallToolRequest, input struct{}) (*mcp.CallToolResult, any, error) {
+	var resp map[string]string
+	if err := HTTPClientFromContext(ctx).Get(ctx, req.Params.Meta["url"].(string), &resp); err != nil {
+		return nil, nil, err
+	}
+	LoggerFromContext(ctx).Info("fetched weather")
+	MetricsFromContext(ctx).IncrementToolInvocations()
+	CacheFromContext(ctx).SetWait("weather", resp["weather"], time.Minute)
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: resp["weather"]}},
+	}, nil, nil
+}
+
+func TestFromContext_InsideHandler(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"weather":"sunny"}`))
+	}))
+	defer upstream.Close()
+
+	srv, err := New(Config{
+		Name:    "test-server",
+		Version: "1.0.0",
+	}, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	AddTool(srv, &mcp.Tool{Name: "weather", Description: "Looks up the weather"}, lookupWeather)
+
+	var gotServer *Server
+	AddTool(srv, &mcp.Tool{
+		Name:        "which_server",
+		Description: "Records the server from the context",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input struct{}) (*mcp.CallToolResult, any, error) {
+		gotServer = ServerFromContext(ctx)
+		return &mcp.CallToolResult{}, nil, nil
+	})
+
+	session := connectTestClient(t, srv)
+	res, err := session.CallTool(context.Background(), &mcp.CallToolParams{
+		Meta: mcp.Meta{"url": upstream.URL},
+		Name: "weather",
+	})
+	if err != nil {
+		t.Fatalf("tool call failed: %v", err)
+	}
+	if res.IsError {
+		t.Fatalf("tool returned error: %+v", res.Content)
+	}
+	if text := res.Content[0].(*mcp.TextContent).Text; text != "sunny" {
+		t.Errorf("expected %q, got %q", "sunny", text)
+	}
+	if got := srv.GetMetrics().ToolInvocations; got != 1 {
+		t.Errorf("expected the handler to update the server metrics, got %d invocations", got)
+	}
+	if cached, ok := srv.Cache().Get("weather"); !ok || cached != "sunny" {
+		t.Errorf("expected the handler to write the server cache, got %v", cached)
+	}
+
+	if _, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "which_server"}); err != nil {
+		t.Fatalf("tool call failed: %v", err)
+	}
+	if gotServer != srv {
+		t.Errorf("expected ServerFromContext to return the handling server")
+	}
+}
+
+func TestFromContext_OutsideHandler(t *testing.T) {
+	ctx := context.Background()
+
+	if ServerFromContext(ctx) != nil || HTTPClientFromContext(ctx) != nil ||
+		CacheFromContext(ctx) != nil || MetricsFromContext(ctx) != nil {
+		t.Error("expected nil values outside a handler")
+	}
+	if LoggerFromContext(ctx) == nil {
+		t.Error("expected a usable no-op logger outside a handler")
+	}
+}
@@ -0,0 +1,155 @@
+package hypermcp
+
+import (
+	"regexp"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+)
+
+// CustomMetricType says how a custom metric behaves.
+type CustomMetricType string
+
+const (
+	// CustomCounter is a value that only goes up, registered with
+	// Server.RegisterCounter.
+	CustomCounter CustomMetricType = "counter"
+
+	// CustomGauge is a value read when metrics are collected, registered with
+	// Server.RegisterGauge.
+	CustomGauge CustomMetricType = "gauge"
+)
+
+// CustomMetric is the value of an application metric in MetricsSnapshot.Custom.
+type CustomMetric struct {
+	Type  CustomMetricType `json:"type"`
+	Value float64          `json:"value"`
+}
+
+// customMetricName is the pattern custom metric names must match: a valid
+// Prometheus metric name without the colons reserved for recording rules.
+var customMetricName = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// customMetrics holds the metrics registered by the application.
+type customMetrics struct {
+	mu       sync.RWMutex
+	counters map[string]*atomic.Int64
+	gauges   map[string]func() float64
+}
+
+// counter returns the counter registered as name, registering it if needed.
+// It returns false if name is taken by a gauge.
+func (c *customMetrics) counter(name string) (*atomic.Int64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.gauges[name]; ok {
+		return nil, false
+	}
+	if counter, ok := c.counters[name]; ok {
+		return counter, true
+	}
+	if c.counters == nil {
+		c.counters = make(map[string]*atomic.Int64)
+	}
+	counter := new(atomic.Int64)
+	c.counters[name] = counter
+	return counter, true
+}
+
+// gauge registers fn as name. It returns false if name is already taken.
+func (c *customMetrics) gauge(name string, fn func() float64) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.counters[name]; ok {
+		return false
+	}
+	if _, ok := c.gauges[name]; ok {
+		return false
+	}
+	if c.gauges == nil {
+		c.gauges = make(map[string]func() float64)
+	}
+	c.gauges[name] = fn
+	return true
+}
+
+// snapshot returns the current value of every custom metric, or nil if none
+// are registered. Gauges are read without holding the lock, so a gauge may
+// itself register metrics.
+func (c *customMetrics) snapshot() map[string]CustomMetric {
+	c.mu.RLock()
+	if len(c.counters) == 0 && len(c.gauges) == 0 {
+		c.mu.RUnlock()
+		return nil
+	}
+	out := make(map[string]CustomMetric, len(c.counters)+len(c.gauges))
+	for name, counter := range c.counters {
+		out[name] = CustomMetric{Type: CustomCounter, Value: float64(counter.Load())}
+	}
+	gauges := make(map[string]func() float64, len(c.gauges))
+	for name, fn := range c.gauges {
+		gauges[name] = fn
+	}
+	c.mu.RUnlock()
+
+	for name, fn := range gauges {
+		out[name] = CustomMetric{Type: CustomGauge, Value: fn()}
+	}
+	return out
+}
+
+// RegisterCounter returns a counter for an application metric, such as
+// records processed by a tool, reported in MetricsSnapshot.Custom and by
+// PrometheusHandler as hypermcp_custom_<name>. Increment it with Add; the
+// value should only go up.
+//
+// Registering the same name again returns the same counter, so tools can
+// share one. The name must consist of letters, digits, and underscores, not
+// starting with a digit, and must not be taken by a gauge. Otherwise a
+// warning is logged and the returned counter is not reported.
+func (s *Server) RegisterCounter(name string) *atomic.Int64 {
+	if !customMetricName.MatchString(name) {
+		s.log().Warn("invalid custom metric name, not reporting it", zap.String("metric", name))
+		return new(atomic.Int64)
+	}
+	counter, ok := s.metrics.custom.counter(name)
+	if !ok {
+		s.log().Warn("custom metric already registered as a gauge, not reporting the counter", zap.String("metric", name))
+		return new(atomic.Int64)
+	}
+	return counter
+}
+
+// RegisterGauge reports the value returned by fn as an application metric,
+// such as the size of a work queue, in MetricsSnapshot.Custom and by
+// PrometheusHandler as hypermcp_custom_<name>. fn is called every time
+// metrics are collected, so it must be fast and safe for concurrent use.
+//
+// The name follows the rules of RegisterCounter. A name that is invalid or
+// already registered is logged as a warning and ignored.
+func (s *Server) RegisterGauge(name string, fn func() float64) {
+	if fn == nil {
+		return
+	}
+	if !customMetricName.MatchString(name) {
+		s.log().Warn("invalid custom metric name, not reporting it", zap.String("metric", name))
+		return
+	}
+	if !s.metrics.custom.gauge(name, fn) {
+		s.log().Warn("custom metric already registered, ignoring the gauge", zap.String("metric", name))
+	}
+}
+
+// sortedCustomNames returns the names of custom in sorted order.
+func sortedCustomNames(custom map[string]CustomMetric) []string {
+	names := make([]string, 0, len(custom))
+	for name := range custom {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,90 @@
+package hypermcp
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestServer_RegisterCounter(t *testing.T) {
+	srv := newShutdownTestServer(t)
+
+	if custom := srv.GetMetrics().Custom; custom != nil {
+		t.Errorf("expected no custom metrics before registration, got %v", custom)
+	}
+
+	records := srv.RegisterCounter("records_processed_total")
+	records.Add(40)
+	// Registering again shares the counter
+	srv.RegisterCounter("records_processed_total").Add(2)
+
+	queued := 7.0
+	srv.RegisterGauge("queue_length", func() float64 { return queued })
+
+	custom := srv.GetMetrics().Custom
+	if got := custom["records_processed_total"]; got != (CustomMetric{Type: CustomCounter, Value: 42}) {
+		t.Errorf("counter = %+v, want counter with value 42", got)
+	}
+	if got := custom["queue_length"]; got != (CustomMetric{Type: CustomGauge, Value: 7}) {
+		t.Errorf("gauge = %+v, want gauge with value 7", got)
+	}
+
+	queued = 3
+	body := scrapePrometheus(t, srv)
+	for _, want := range []string{
+		"# TYPE hypermcp_custom_records_processed_total counter\nhypermcp_custom_records_processed_total 42\n",
+		"# TYPE hypermcp_custom_queue_length gauge\nhypermcp_custom_queue_length 3\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected exposition to contain %q\n%s", want, body)
+		}
+	}
+}
+
+func TestServer_RegisterCounter_Rejected(t *testing.T) {
+	core, logs := observer.New(zapcore.WarnLevel)
+	srv, err := New(Config{Name: "test-server", Version: "1.0.0"}, zap.New(core))
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+
+	srv.RegisterGauge("in_flight", func() float64 { return 1 })
+
+	srv.RegisterCounter("bytes-fetched").Add(1)
+	srv.RegisterCounter("9lives").Add(1)
+	srv.RegisterCounter("in_flight").Add(1)
+	srv.RegisterGauge("in_flight", func() float64 { return 2 })
+
+	custom := srv.GetMetrics().Custom
+	if len(custom) != 1 || custom["in_flight"] != (CustomMetric{Type: CustomGauge, Value: 1}) {
+		t.Errorf("expected only the first in_flight gauge to be reported, got %v", custom)
+	}
+	if got := logs.Len(); got != 4 {
+		t.Errorf("expected 4 warnings, got %d", got)
+	}
+}
+
+func TestServer_RegisterCounter_Concurrent(t *testing.T) {
+	srv := newShutdownTestServer(t)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				srv.RegisterCounter("calls_total").Add(1)
+				_ = srv.GetMetrics()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := srv.GetMetrics().Custom["calls_total"].Value; got != 800 {
+		t.Errorf("calls_total = %v, want 800", got)
+	}
+}
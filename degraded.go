@@ -0,0 +1,231 @@
+package hypermcp
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap"
+)
+
+// Defaults for DegradedConfig fields left zero.
+const (
+	DefaultDegradedWindow   = time.Minute
+	DefaultDegradedMinCalls = 10
+)
+
+// errorWindowBuckets is how many buckets the error rate window is divided
+// into. Calls leave the window one bucket at a time.
+const errorWindowBuckets = 10
+
+// DegradedConfig configures degraded mode, which the server enters while the
+// tool error rate is too high.
+//
+// While degraded, calls to the tools listed in NonEssentialTools are rejected
+// with ErrDegraded without running, shedding load so that essential tools
+// keep working. Handlers can check Server.Degraded to cut back themselves,
+// for example by serving only cached data. The server leaves degraded mode
+// once the error rate over Window drops back below ErrorRate.
+//
+// The error rate counts every tool call, failing when the handler returns an
+// error or a result with IsError set. Calls rejected in degraded mode are not
+// counted.
+type DegradedConfig struct {
+	// ErrorRate is the fraction of failed tool calls, between 0 and 1, at or
+	// above which the server is degraded. Zero disables degraded mode.
+	ErrorRate float64
+
+	// Window is how far back the error rate looks. Defaults to
+	// DefaultDegradedWindow.
+	Window time.Duration
+
+	// MinCalls is how many tool calls the window must contain before the error
+	// rate is trusted, so a single failure on an idle server doesn't degrade
+	// it. Defaults to DefaultDegradedMinCalls.
+	MinCalls int
+
+	// NonEssentialTools names the tools rejected while degraded.
+	NonEssentialTools []string
+}
+
+func (c DegradedConfig) enabled() bool {
+	return c.ErrorRate > 0
+}
+
+func (c DegradedConfig) window() time.Duration {
+	if c.Window <= 0 {
+		return DefaultDegradedWindow
+	}
+	return c.Window
+}
+
+func (c DegradedConfig) minCalls() int64 {
+	if c.MinCalls <= 0 {
+		return DefaultDegradedMinCalls
+	}
+	return int64(c.MinCalls)
+}
+
+// errorWindow counts tool call outcomes over a sliding window, divided into
+// buckets so old outcomes expire without storing each call.
+type errorWindow struct {
+	clock Clock
+	width time.Duration // span of each bucket
+
+	mu      sync.Mutex
+	buckets [errorWindowBuckets]outcomeBucket
+}
+
+// outcomeBucket holds the outcomes of one span of the window. Buckets are
+// reused as the window slides; slot identifies the span a bucket holds.
+type outcomeBucket struct {
+	slot   int64
+	calls  int64
+	errors int64
+}
+
+func newErrorWindow(window time.Duration, clock Clock) *errorWindow {
+	return &errorWindow{
+		clock: clock,
+		width: max(window/errorWindowBuckets, 1),
+	}
+}
+
+// slot returns the index of the bucket span containing the current time.
+func (w *errorWindow) slot() int64 {
+	return w.clock.Now().UnixNano() / int64(w.width)
+}
+
+func (w *errorWindow) observe(failed bool) {
+	slot := w.slot()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	b := &w.buckets[slot%errorWindowBuckets]
+	if b.slot != slot {
+		*b = outcomeBucket{slot: slot}
+	}
+	b.calls++
+	if failed {
+		b.errors++
+	}
+}
+
+// counts returns the calls and failures within the window.
+func (w *errorWindow) counts() (calls, errors int64) {
+	slot := w.slot()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, b := range w.buckets {
+		if b.slot > slot-errorWindowBuckets && b.slot <= slot {
+			calls += b.calls
+			errors += b.errors
+		}
+	}
+	return calls, errors
+}
+
+// degradedMode decides whether the server is degraded and enforces it on tool calls.
+type degradedMode struct {
+	config       DegradedConfig
+	nonEssential map[string]bool
+	metrics      *Metrics
+	logger       *atomic.Pointer[zap.Logger]
+
+	// active is the state last observed, used to log transitions
+	active atomic.Bool
+}
+
+func newDegradedMode(cfg DegradedConfig, metrics *Metrics, logger *atomic.Pointer[zap.Logger]) *degradedMode {
+	nonEssential := make(map[string]bool, len(cfg.NonEssentialTools))
+	for _, name := range cfg.NonEssentialTools {
+		nonEssential[name] = true
+	}
+	return &degradedMode{
+		config:       cfg,
+		nonEssential: nonEssential,
+		metrics:      metrics,
+		logger:       logger,
+	}
+}
+
+// check reports whether the server is degraded, logging when that changes.
+func (d *degradedMode) check() bool {
+	if !d.config.enabled() {
+		return false
+	}
+
+	calls, errors := d.metrics.toolOutcomes.counts()
+	var rate float64
+	if calls > 0 {
+		rate = float64(errors) / float64(calls)
+	}
+	degraded := calls >= d.config.minCalls() && rate >= d.config.ErrorRate
+
+	if d.active.CompareAndSwap(!degraded, degraded) {
+		fields := []zap.Field{
+			zap.Float64("error_rate", rate),
+			zap.Float64("threshold", d.config.ErrorRate),
+			zap.Int64("calls", calls),
+			zap.Duration("window", d.config.window()),
+		}
+		if degraded {
+			d.logger.Load().Warn("server entered degraded mode", fields...)
+		} else {
+			d.logger.Load().Info("server left degraded mode", fields...)
+		}
+	}
+	return degraded
+}
+
+// middleware rejects non-essential tool calls while degraded and records the
+// outcome of every other tool call, keeping failures for RecentErrors.
+func (d *degradedMode) middleware() mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			if method != "tools/call" {
+				return next(ctx, method, req)
+			}
+
+			if call, ok := req.(*mcp.CallToolRequest); ok && call.Params != nil && d.nonEssential[call.Params.Name] && d.check() {
+				return nil, fmt.Errorf("%w: tool %q is unavailable until the error rate recovers", ErrDegraded, call.Params.Name)
+			}
+
+			result, err := next(ctx, method, req)
+			failed := err != nil
+			if r, ok := result.(*mcp.CallToolResult); ok && r != nil && r.IsError {
+				failed = true
+			}
+			d.metrics.ObserveToolResult(failed)
+			if failed {
+				var tool string
+				if call, ok := req.(*mcp.CallToolRequest); ok && call.Params != nil {
+					tool = call.Params.Name
+				}
+				d.metrics.recordError(tool, toolErrorMessage(result, err))
+			}
+			return result, err
+		}
+	}
+}
+
+// Degraded reports whether the server is in degraded mode, because the tool
+// error rate over Config.Degraded.Window is at or above
+// Config.Degraded.ErrorRate. It is always false when degraded mode is not
+// configured.
+//
+// Handlers can use it to fall back to cheaper behavior, such as serving only
+// cached data instead of calling a failing upstream:
+//
+//	if srv := hypermcp.ServerFromContext(ctx); srv != nil && srv.Degraded() {
+//	    return cachedResult(ctx, input)
+//	}
+func (s *Server) Degraded() bool {
+	return s.degraded.check()
+}
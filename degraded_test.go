@@ -0,0 +1,181 @@
+package hypermcp
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestErrorWindow(t *testing.T) {
+	clock := newFakeClock()
+	w := newErrorWindow(10*time.Second, clock)
+
+	w.observe(true)
+	w.observe(false)
+	clock.Advance(5 * time.Second)
+	w.observe(true)
+
+	if calls, errs := w.counts(); calls != 3 || errs != 2 {
+		t.Errorf("counts() = %d, %d, want 3, 2", calls, errs)
+	}
+
+	// The first two outcomes slide out of the window
+	clock.Advance(6 * time.Second)
+	if calls, errs := w.counts(); calls != 1 || errs != 1 {
+		t.Errorf("counts() after 11s = %d, %d, want 1, 1", calls, errs)
+	}
+
+	clock.Advance(10 * time.Second)
+	if calls, errs := w.counts(); calls != 0 || errs != 0 {
+		t.Errorf("counts() after 21s = %d, %d, want 0, 0", calls, errs)
+	}
+}
+
+func TestServer_DegradedMode(t *testing.T) {
+	clock := newFakeClock()
+	core, logs := observer.New(zapcore.InfoLevel)
+	srv, err := New(Config{
+		Name:    "test-server",
+		Version: "1.0.0",
+		Clock:   clock,
+		Degraded: DegradedConfig{
+			ErrorRate:         0.5,
+			Window:            10 * time.Second,
+			MinCalls:          4,
+			NonEssentialTools: []string{"report"},
+		},
+	}, zap.New(core))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	var failing atomic.Bool
+	AddTool(srv, &mcp.Tool{Name: "lookup"}, func(context.Context, *mcp.CallToolRequest, struct{}) (*mcp.CallToolResult, any, error) {
+		if failing.Load() {
+			return nil, nil, errors.New("upstream unavailable")
+		}
+		return &mcp.CallToolResult{}, nil, nil
+	})
+	var reports atomic.Int64
+	AddTool(srv, &mcp.Tool{Name: "report"}, func(context.Context, *mcp.CallToolRequest, struct{}) (*mcp.CallToolResult, any, error) {
+		reports.Add(1)
+		return &mcp.CallToolResult{}, nil, nil
+	})
+
+	session := connectTestClient(t, srv)
+	call := func(name string) (*mcp.CallToolResult, error) {
+		return session.CallTool(context.Background(), &mcp.CallToolParams{Name: name, Arguments: map[string]any{}})
+	}
+
+	// Too few calls to judge, even though they all fail
+	failing.Store(true)
+	for range 3 {
+		_, _ = call("lookup")
+	}
+	if srv.Degraded() {
+		t.Fatal("Degraded() = true below MinCalls")
+	}
+
+	_, _ = call("lookup")
+	if !srv.Degraded() {
+		t.Fatal("Degraded() = false after the error rate exceeded the threshold")
+	}
+	if logs.FilterMessage("server entered degraded mode").Len() != 1 {
+		t.Error("entering degraded mode was not logged")
+	}
+
+	_, err = call("report")
+	if err == nil || !strings.Contains(err.Error(), `tool "report" is unavailable`) {
+		t.Errorf("non-essential call error = %v, want degraded rejection", err)
+	}
+	if reports.Load() != 0 {
+		t.Error("non-essential tool ran while degraded")
+	}
+
+	// Essential tools still run
+	failing.Store(false)
+	if result, err := call("lookup"); err != nil || result.IsError {
+		t.Errorf("essential call while degraded = %v, %v, want success", result, err)
+	}
+
+	snap := srv.GetMetrics()
+	if !snap.Degraded || snap.RecentToolCalls != 5 || snap.RecentToolErrorRate != 0.8 {
+		t.Errorf("snapshot = degraded %v, %d calls, rate %v, want true, 5, 0.8", snap.Degraded, snap.RecentToolCalls, snap.RecentToolErrorRate)
+	}
+
+	// Failures slide out of the window and the server recovers
+	clock.Advance(11 * time.Second)
+	if srv.Degraded() {
+		t.Fatal("Degraded() = true after the failures left the window")
+	}
+	if logs.FilterMessage("server left degraded mode").Len() != 1 {
+		t.Error("leaving degraded mode was not logged")
+	}
+	if _, err := call("report"); err != nil {
+		t.Errorf("non-essential call after recovery error = %v", err)
+	}
+	if reports.Load() != 1 {
+		t.Errorf("non-essential tool ran %d times after recovery, want 1", reports.Load())
+	}
+}
+
+func TestServer_DegradedDisabled(t *testing.T) {
+	srv, err := New(Config{Name: "test-server", Version: "1.0.0"}, zap.NewNop())
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	AddTool(srv, &mcp.Tool{Name: "broken"}, func(context.Context, *mcp.CallToolRequest, struct{}) (*mcp.CallToolResult, any, error) {
+		return nil, nil, errors.New("always fails")
+	})
+	session := connectTestClient(t, srv)
+
+	for range DefaultDegradedMinCalls * 2 {
+		_, _ = session.CallTool(context.Background(), &mcp.CallToolParams{Name: "broken", Arguments: map[string]any{}})
+	}
+
+	if srv.Degraded() {
+		t.Error("Degraded() = true with degraded mode disabled")
+	}
+	if rate := srv.GetMetrics().RecentToolErrorRate; rate != 1 {
+		t.Errorf("RecentToolErrorRate = %v, want 1", rate)
+	}
+}
+
+func TestConfig_Validate_Degraded(t *testing.T) {
+	tests := []struct {
+		name      string
+		degraded  DegradedConfig
+		wantField string
+	}{
+		{name: "rate above one", degraded: DegradedConfig{ErrorRate: 1.5}, wantField: "Degraded.ErrorRate"},
+		{name: "negative rate", degraded: DegradedConfig{ErrorRate: -0.1}, wantField: "Degraded.ErrorRate"},
+		{name: "negative window", degraded: DegradedConfig{ErrorRate: 0.5, Window: -time.Second}, wantField: "Degraded.Window"},
+		{name: "negative min calls", degraded: DegradedConfig{ErrorRate: 0.5, MinCalls: -1}, wantField: "Degraded.MinCalls"},
+		{name: "tools without rate", degraded: DegradedConfig{NonEssentialTools: []string{"report"}}, wantField: "Degraded.NonEssentialTools"},
+		{name: "valid", degraded: DegradedConfig{ErrorRate: 0.5, NonEssentialTools: []string{"report"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Config{Name: "test-server", Version: "1.0.0", Degraded: tt.degraded}.Validate()
+			if tt.wantField == "" {
+				if err != nil {
+					t.Errorf("Validate() error = %v", err)
+				}
+				return
+			}
+			var cfgErr *ConfigError
+			if !errors.As(err, &cfgErr) || cfgErr.Field != tt.wantField {
+				t.Errorf("Validate() error = %v, want ConfigError for %s", err, tt.wantField)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package hypermcp
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Names of the tools registered by AddDiagnosticTools. They carry a
+// "hypermcp_" prefix so they cannot collide with a server's own tools.
+const (
+	DiagnosticPingTool = "hypermcp_ping"
+	DiagnosticEchoTool = "hypermcp_echo"
+)
+
+// PingResult is the output of the diagnostic ping tool.
+type PingResult struct {
+	Server        ServerInfo `json:"server"`
+	Uptime        string     `json:"uptime" jsonschema:"Time since the server started, e.g. 1h2m3s"`
+	UptimeSeconds float64    `json:"uptimeSeconds"`
+}
+
+// EchoMessage is the input and output of the diagnostic echo tool.
+type EchoMessage struct {
+	Message string `json:"message" jsonschema:"Text to send back unchanged"`
+}
+
+// AddDiagnosticTools registers two tools for smoke-testing a deployed server
+// without writing any code:
+//
+//   - hypermcp_ping returns the server's name, version, build information,
+//     and uptime.
+//   - hypermcp_echo returns its message unchanged.
+//
+// They are registered with AddTool, so they are counted, instrumented, and
+// listed by Describe like any other tool. Nothing is registered unless this
+// is called.
+func (s *Server) AddDiagnosticTools() {
+	AddTool(s, &mcp.Tool{
+		Name:        DiagnosticPingTool,
+		Description: "Diagnostic: report the server's version and uptime to verify connectivity",
+	}, func(_ context.Context, _ *mcp.CallToolRequest, _ struct{}) (*mcp.CallToolResult, PingResult, error) {
+		uptime := s.metrics.clock.Now().Sub(s.metrics.startTime)
+		return nil, PingResult{
+			Server:        s.serverInfo(),
+			Uptime:        uptime.String(),
+			UptimeSeconds: uptime.Seconds(),
+		}, nil
+	})
+
+	AddTool(s, &mcp.Tool{
+		Name:        DiagnosticEchoTool,
+		Description: "Diagnostic: return the given message unchanged",
+	}, func(_ context.Context, _ *mcp.CallToolRequest, in EchoMessage) (*mcp.CallToolResult, EchoMessage, error) {
+		return nil, in, nil
+	})
+}
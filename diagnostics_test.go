@@ -0,0 +1,83 @@
+package hypermcp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestServer_AddDiagnosticTools(t *testing.T) {
+	clock := newFakeClock()
+	srv, err := New(Config{Name: "diag-server", Version: "3.1.4", Clock: clock}, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	srv.AddDiagnosticTools()
+	if srv.toolCount.Load() != 2 {
+		t.Errorf("toolCount = %d, want 2", srv.toolCount.Load())
+	}
+
+	session := connectTestClient(t, srv)
+	ctx := context.Background()
+
+	tools, err := session.ListTools(ctx, nil)
+	if err != nil {
+		t.Fatalf("ListTools() error = %v", err)
+	}
+	names := make(map[string]bool)
+	for _, tool := range tools.Tools {
+		names[tool.Name] = true
+	}
+	if !names[DiagnosticPingTool] || !names[DiagnosticEchoTool] {
+		t.Errorf("tools = %v, want %s and %s", names, DiagnosticPingTool, DiagnosticEchoTool)
+	}
+
+	clock.Advance(90 * time.Second)
+
+	result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: DiagnosticPingTool, Arguments: map[string]any{}})
+	if err != nil {
+		t.Fatalf("CallTool(ping) error = %v", err)
+	}
+	var ping PingResult
+	if err := remarshalForTest(result.StructuredContent, &ping); err != nil {
+		t.Fatalf("decode ping result: %v", err)
+	}
+	if ping.Server.Name != "diag-server" || ping.Server.Version != "3.1.4" {
+		t.Errorf("ping server = %+v, want diag-server 3.1.4", ping.Server)
+	}
+	if ping.Uptime != "1m30s" || ping.UptimeSeconds != 90 {
+		t.Errorf("ping uptime = %q (%vs), want 1m30s (90s)", ping.Uptime, ping.UptimeSeconds)
+	}
+
+	result, err = session.CallTool(ctx, &mcp.CallToolParams{
+		Name:      DiagnosticEchoTool,
+		Arguments: map[string]any{"message": "hello"},
+	})
+	if err != nil {
+		t.Fatalf("CallTool(echo) error = %v", err)
+	}
+	var echo EchoMessage
+	if err := remarshalForTest(result.StructuredContent, &echo); err != nil {
+		t.Fatalf("decode echo result: %v", err)
+	}
+	if echo.Message != "hello" {
+		t.Errorf("echo message = %q, want %q", echo.Message, "hello")
+	}
+}
+
+func TestServer_DiagnosticToolsOptIn(t *testing.T) {
+	srv, err := New(Config{Name: "diag-server", Version: "1.0.0"}, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	for _, tool := range srv.Describe().Tools {
+		if tool.Name == DiagnosticPingTool || tool.Name == DiagnosticEchoTool {
+			t.Errorf("diagnostic tool %s registered without AddDiagnosticTools", tool.Name)
+		}
+	}
+}
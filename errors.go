@@ -13,11 +13,90 @@ var (
 	// ErrServerNotRunning indicates an operation was attempted on a non-running server.
 	ErrServerNotRunning = errors.New("server not running")
 
+	// ErrServerAlreadyStarted indicates Server.Start was called on a server
+	// that was already started.
+	ErrServerAlreadyStarted = errors.New("server already started")
+
 	// ErrShutdownTimeout indicates the server shutdown exceeded the timeout.
 	ErrShutdownTimeout = errors.New("shutdown timeout exceeded")
 
 	// ErrTransportNotSupported indicates the requested transport type is not implemented.
 	ErrTransportNotSupported = errors.New("transport not supported")
+
+	// ErrUnknownTransport indicates a transport type that is not one of the
+	// TransportType constants.
+	ErrUnknownTransport = errors.New("unknown transport type")
+
+	// ErrServerBusy indicates a tool call was rejected because all workers were
+	// busy and the invocation queue was full.
+	ErrServerBusy = errors.New("server busy")
+
+	// ErrRateLimited indicates a request was rejected because its client
+	// exceeded its rate limit. See ClientLimitsConfig.
+	ErrRateLimited = errors.New("rate limited")
+
+	// ErrRequestTooLarge indicates a request was rejected because its
+	// parameters exceeded its client's MaxRequestBytes.
+	ErrRequestTooLarge = errors.New("request too large")
+
+	// ErrBudgetExceeded indicates an operation run with DoWithBudget ran out of time.
+	ErrBudgetExceeded = errors.New("time budget exceeded")
+
+	// ErrIncompatibleSDK indicates the MCP SDK in the build does not behave the
+	// way hypermcp relies on. It is returned by New when Config.CheckSDK is set.
+	ErrIncompatibleSDK = errors.New("incompatible MCP SDK")
+
+	// ErrDegraded indicates a non-essential tool call was rejected because the
+	// server is in degraded mode. See DegradedConfig.
+	ErrDegraded = errors.New("server degraded")
+
+	// ErrToolDisabled indicates a call to a tool disabled with
+	// Server.SetToolEnabled.
+	ErrToolDisabled = errors.New("tool temporarily unavailable")
+
+	// ErrResourceTooLarge indicates a resource's content exceeded the size
+	// limit given to ReadResourceContent or AddStreamingResource.
+	ErrResourceTooLarge = errors.New("resource too large")
+
+	// ErrDuplicateRegistration indicates a tool, resource, or resource
+	// template was registered under a name or URI already in use.
+	ErrDuplicateRegistration = errors.New("duplicate registration")
+
+	// ErrInvalidSchema indicates a tool's input or output schema is not a
+	// valid JSON schema of type "object".
+	ErrInvalidSchema = errors.New("invalid tool schema")
+
+	// ErrInvalidAnnotations indicates a tool's annotations contradict each
+	// other, such as a tool both read-only and destructive.
+	ErrInvalidAnnotations = errors.New("invalid tool annotations")
+
+	// ErrIncompleteTool indicates a tool registered under
+	// Config.StrictRegistration has no description or input schema.
+	ErrIncompleteTool = errors.New("incomplete tool definition")
+
+	// ErrInvalidOutput indicates a tool returned output that does not match
+	// its output schema. See Config.ValidateToolOutput.
+	ErrInvalidOutput = errors.New("tool output does not match output schema")
+
+	// ErrToolOutputTooLarge indicates a tool returned a result larger than
+	// Config.MaxToolOutputBytes.
+	ErrToolOutputTooLarge = errors.New("tool output too large")
+
+	// ErrNoSession indicates a request to the client was made outside a
+	// handler, where there is no client session to send it through.
+	ErrNoSession = errors.New("no client session")
+
+	// ErrSamplingNotSupported indicates RequestSampling was called for a
+	// client that did not declare the sampling capability.
+	ErrSamplingNotSupported = errors.New("client does not support sampling")
+
+	// ErrRootsNotSupported indicates Server.Roots was called for a client
+	// that did not declare the roots capability.
+	ErrRootsNotSupported = errors.New("client does not support roots")
+
+	// ErrPathOutsideRoots indicates a path is not within any of the client's
+	// roots. See PathInRoots.
+	ErrPathOutsideRoots = errors.New("path outside client roots")
 )
 
 // ConfigError wraps configuration validation errors with context.
@@ -66,3 +145,48 @@ func NewTransportError(transport TransportType, err error) *TransportError {
 		Err:       err,
 	}
 }
+
+// ModuleError wraps an error returned by a Module during Install.
+type ModuleError struct {
+	Err    error
+	Module string
+}
+
+func (e *ModuleError) Error() string {
+	return fmt.Sprintf("module %s: %v", e.Module, e.Err)
+}
+
+func (e *ModuleError) Unwrap() error {
+	return e.Err
+}
+
+// NewModuleError creates a new module error.
+func NewModuleError(module string, err error) *ModuleError {
+	return &ModuleError{
+		Module: module,
+		Err:    err,
+	}
+}
+
+// ShutdownError wraps an error from one step of Server.Shutdown, such as a
+// shutdown hook.
+type ShutdownError struct {
+	Err  error
+	Step string
+}
+
+func (e *ShutdownError) Error() string {
+	return fmt.Sprintf("shutdown %s: %v", e.Step, e.Err)
+}
+
+func (e *ShutdownError) Unwrap() error {
+	return e.Err
+}
+
+// NewShutdownError creates a new shutdown error.
+func NewShutdownError(step string, err error) *ShutdownError {
+	return &ShutdownError{
+		Step: step,
+		Err:  err,
+	}
+}
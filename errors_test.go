@@ -88,6 +88,21 @@ func TestTransportError(t *testing.T) {
 	}
 }
 
+func TestModuleError(t *testing.T) {
+	inner := errors.New("duplicate tool")
+	err := NewModuleError("weather", inner)
+
+	if want := "module weather: duplicate tool"; err.Error() != want {
+		t.Errorf("ModuleError.Error() = %q, want %q", err.Error(), want)
+	}
+	if unwrapped := err.Unwrap(); unwrapped != inner {
+		t.Errorf("ModuleError.Unwrap() = %v, want %v", unwrapped, inner)
+	}
+	if !errors.Is(err, inner) {
+		t.Error("errors.Is() should match wrapped error")
+	}
+}
+
 func TestErrorVariables(t *testing.T) {
 	// Test that error variables are defined
 	if ErrInvalidConfig == nil {
@@ -102,6 +117,9 @@ func TestErrorVariables(t *testing.T) {
 	if ErrTransportNotSupported == nil {
 		t.Error("ErrTransportNotSupported should not be nil")
 	}
+	if ErrBudgetExceeded == nil {
+		t.Error("ErrBudgetExceeded should not be nil")
+	}
 }
 
 func TestConfigValidationErrors(t *testing.T) {
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
@@ -60,7 +61,7 @@ func main() {
 		Description: "Read a specific file from the examples directory",
 		MIMEType:    "text/plain",
 	}, func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
-		return readExampleFile(srv, baseDir, req)
+		return readExampleFile(ctx, srv, baseDir, req)
 	})
 
 	// Log registration stats
@@ -128,8 +129,11 @@ func readExamplesDir(srv *hypermcp.Server, baseDir string, req *mcp.ReadResource
 	}, nil
 }
 
+// maxExampleFileSize caps how large a file readExampleFile serves.
+const maxExampleFileSize = 1 << 20 // 1MB
+
 // readExampleFile reads a specific example file safely within allowed base directory
-func readExampleFile(srv *hypermcp.Server, baseDir string, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+func readExampleFile(ctx context.Context, srv *hypermcp.Server, baseDir string, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
 	// Extract filename from URI (simplified)
 	uri := req.Params.URI
 	filename := filepath.Base(uri)
@@ -154,20 +158,25 @@ func readExampleFile(srv *hypermcp.Server, baseDir string, req *mcp.ReadResource
 		return nil, fmt.Errorf("invalid path")
 	}
 
-	content, readFileErr := os.ReadFile(fullPath)
-	if readFileErr != nil {
-		return nil, fmt.Errorf("failed to read file: %w", readFileErr)
+	// Clients that declare roots must also have approved the file's directory
+	roots, rootsErr := srv.Roots(ctx)
+	switch {
+	case rootsErr == nil:
+		if _, pathErr := hypermcp.PathInRoots(fullPath, roots); pathErr != nil {
+			return nil, pathErr
+		}
+	case !errors.Is(rootsErr, hypermcp.ErrRootsNotSupported):
+		return nil, fmt.Errorf("failed to list roots: %w", rootsErr)
+	}
+
+	file, openErr := os.Open(fullPath)
+	if openErr != nil {
+		return nil, fmt.Errorf("failed to open file: %w", openErr)
 	}
+	defer func() { _ = file.Close() }()
 
 	srv.Metrics().IncrementResourceReads()
 
-	return &mcp.ReadResourceResult{
-		Contents: []*mcp.ResourceContents{
-			{
-				URI:      req.Params.URI,
-				MIMEType: "text/plain",
-				Text:     string(content),
-			},
-		},
-	}, nil
+	// Bound how much of the file is read into memory
+	return hypermcp.ReadResourceContent(req.Params.URI, file, "text/plain", maxExampleFileSize)
 }
@@ -45,28 +45,12 @@ func main() {
 	hypermcp.AddTool(srv, &mcp.Tool{
 		Name:        "hello",
 		Description: "Say hello to someone",
-		InputSchema: map[string]any{
-			"type": "object",
-			"properties": map[string]any{
-				"name": map[string]any{
-					"type":        "string",
-					"description": "Name of the person to greet",
-				},
-			},
-			"required": []string{"name"},
-		},
 	}, func(ctx context.Context, req *mcp.CallToolRequest, input struct {
-		Name string `json:"name"`
+		Name string `json:"name" jsonschema:"Name of the person to greet"`
 	}) (*mcp.CallToolResult, any, error) {
 		greeting := fmt.Sprintf("Hello, %s! 👋", input.Name)
 
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				&mcp.TextContent{
-					Text: greeting,
-				},
-			},
-		}, nil, nil
+		return hypermcp.TextResult(greeting), nil, nil
 	})
 
 	// Log registration stats
@@ -38,6 +38,8 @@ func main() {
 			NumCounters: 10_000,
 			BufferItems: 64,
 		},
+		// Log a metrics snapshot every 30 seconds until shutdown
+		MetricsLogInterval: 30 * time.Second,
 	}
 
 	srv, err := hypermcp.New(cfg, logger)
@@ -49,18 +51,8 @@ func main() {
 	hypermcp.AddTool(srv, &mcp.Tool{
 		Name:        "process_data",
 		Description: "Process data with caching and metrics tracking",
-		InputSchema: map[string]any{
-			"type": "object",
-			"properties": map[string]any{
-				"key": map[string]any{
-					"type":        "string",
-					"description": "Data key to process",
-				},
-			},
-			"required": []string{"key"},
-		},
 	}, func(ctx context.Context, req *mcp.CallToolRequest, input struct {
-		Key string `json:"key"`
+		Key string `json:"key" jsonschema:"Data key to process"`
 	}) (*mcp.CallToolResult, any, error) {
 		cacheKey := fmt.Sprintf("data:%s", input.Key)
 
@@ -72,13 +64,7 @@ func main() {
 			srv.Metrics().IncrementCacheHits()
 			logger.Debug("cache hit", zap.String("key", cacheKey))
 
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					&mcp.TextContent{
-						Text: fmt.Sprintf("Cached result: %v", cached),
-					},
-				},
-			}, nil, nil
+			return hypermcp.TextResult(fmt.Sprintf("Cached result: %v", cached)), nil, nil
 		}
 
 		srv.Metrics().IncrementCacheMisses()
@@ -89,13 +75,7 @@ func main() {
 		// Cache result for 2 minutes
 		srv.Cache().Set(cacheKey, result, 2*time.Minute)
 
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				&mcp.TextContent{
-					Text: result,
-				},
-			},
-		}, nil, nil
+		return hypermcp.TextResult(result), nil, nil
 	})
 
 	// Register a tool to retrieve current metrics
@@ -122,13 +102,7 @@ func main() {
 			metrics.Errors,
 		)
 
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				&mcp.TextContent{
-					Text: metricsText,
-				},
-			},
-		}, nil, nil
+		return hypermcp.TextResult(metricsText), nil, nil
 	})
 
 	// Log registration stats
@@ -141,29 +115,6 @@ func main() {
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 
-	// Start periodic metrics logging
-	go func() {
-		ticker := time.NewTicker(30 * time.Second)
-		defer ticker.Stop()
-
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			case <-ticker.C:
-				metrics := srv.GetMetrics()
-				logger.Info("periodic metrics",
-					zap.Duration("uptime", metrics.Uptime),
-					zap.Int64("tool_invocations", metrics.ToolInvocations),
-					zap.Int64("cache_hits", metrics.CacheHits),
-					zap.Int64("cache_misses", metrics.CacheMisses),
-					zap.Float64("cache_hit_rate", metrics.CacheHitRate),
-					zap.Int64("errors", metrics.Errors),
-				)
-			}
-		}
-	}()
-
 	go func() {
 		<-sigChan
 		logger.Info("shutting down...")
@@ -43,18 +43,8 @@ func main() {
 	hypermcp.AddTool(srv, &mcp.Tool{
 		Name:        "get_weather",
 		Description: "Get current weather for a city (demo - returns mock data)",
-		InputSchema: map[string]interface{}{
-			"type": "object",
-			"properties": map[string]interface{}{
-				"city": map[string]interface{}{
-					"type":        "string",
-					"description": "City name",
-				},
-			},
-			"required": []string{"city"},
-		},
 	}, func(ctx context.Context, req *mcp.CallToolRequest, input struct {
-		City string `json:"city"`
+		City string `json:"city" jsonschema:"City name"`
 	}) (*mcp.CallToolResult, any, error) {
 		// Check cache first
 		cacheKey := fmt.Sprintf("weather:%s", input.City)
@@ -62,13 +52,7 @@ func main() {
 			logger.Debug("returning cached weather", zap.String("city", input.City))
 			srv.Metrics().IncrementCacheHits()
 
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					&mcp.TextContent{
-						Text: fmt.Sprintf("%v", cached),
-					},
-				},
-			}, nil, nil
+			return hypermcp.TextResult(fmt.Sprintf("%v", cached)), nil, nil
 		}
 		srv.Metrics().IncrementCacheMisses()
 
@@ -82,31 +66,15 @@ func main() {
 		// Track metric
 		srv.Metrics().IncrementToolInvocations()
 
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				&mcp.TextContent{
-					Text: weather,
-				},
-			},
-		}, nil, nil
+		return hypermcp.TextResult(weather), nil, nil
 	})
 
 	// Register forecast tool
 	hypermcp.AddTool(srv, &mcp.Tool{
 		Name:        "get_forecast",
 		Description: "Get 3-day weather forecast (demo - returns mock data)",
-		InputSchema: map[string]interface{}{
-			"type": "object",
-			"properties": map[string]interface{}{
-				"city": map[string]interface{}{
-					"type":        "string",
-					"description": "City name",
-				},
-			},
-			"required": []string{"city"},
-		},
 	}, func(ctx context.Context, req *mcp.CallToolRequest, input struct {
-		City string `json:"city"`
+		City string `json:"city" jsonschema:"City name"`
 	}) (*mcp.CallToolResult, any, error) {
 		forecast := fmt.Sprintf("3-day forecast for %s:\n"+
 			"Day 1: ☀️ Sunny, High: 75°F\n"+
@@ -115,13 +83,7 @@ func main() {
 
 		srv.Metrics().IncrementToolInvocations()
 
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				&mcp.TextContent{
-					Text: forecast,
-				},
-			},
-		}, nil, nil
+		return hypermcp.TextResult(forecast), nil, nil
 	})
 
 	// Log registration stats
@@ -0,0 +1,119 @@
+package hypermcp
+
+import (
+	"fmt"
+)
+
+// Defaults for HealthConfig fields left zero.
+const (
+	DefaultCacheEvictionRatio = 0.5
+	DefaultCacheHealthMinSets = 100
+)
+
+// HealthStatus is the outcome of a health check.
+type HealthStatus string
+
+const (
+	// HealthOK means the check found nothing wrong.
+	HealthOK HealthStatus = "ok"
+
+	// HealthWarning means the server works, but something needs an
+	// operator's attention.
+	HealthWarning HealthStatus = "warning"
+)
+
+// HealthConfig tunes the checks reported by Server.Health.
+type HealthConfig struct {
+	// CacheEvictionRatio is the fraction of cache sets, between 0 and 1,
+	// that Ristretto may evict before the cache check warns that the cache
+	// is thrashing, usually because CacheConfig.MaxCost is too small.
+	// Defaults to DefaultCacheEvictionRatio.
+	CacheEvictionRatio float64
+
+	// CacheMinSets is how many sets the cache must have seen before the
+	// eviction ratio is trusted, so a handful of evictions on a fresh server
+	// doesn't warn. Defaults to DefaultCacheHealthMinSets.
+	CacheMinSets int
+}
+
+func (c HealthConfig) cacheEvictionRatio() float64 {
+	if c.CacheEvictionRatio <= 0 {
+		return DefaultCacheEvictionRatio
+	}
+	return c.CacheEvictionRatio
+}
+
+func (c HealthConfig) cacheMinSets() uint64 {
+	if c.CacheMinSets <= 0 {
+		return DefaultCacheHealthMinSets
+	}
+	return uint64(c.CacheMinSets)
+}
+
+// HealthCheck is the result of one check in a Health report.
+type HealthCheck struct {
+	Name    string       `json:"name"`
+	Status  HealthStatus `json:"status"`
+	Message string       `json:"message,omitempty"` // Explains the status; empty when OK
+}
+
+// Health is a point-in-time health report returned by Server.Health.
+type Health struct {
+	// Status is HealthWarning if any check warns, and HealthOK otherwise.
+	Status HealthStatus  `json:"status"`
+	Checks []HealthCheck `json:"checks"`
+}
+
+// Health runs the server's health checks:
+//
+//   - "degraded" warns while the server is in degraded mode (see
+//     Config.Degraded).
+//   - "cache" warns when the cache is thrashing: Ristretto has evicted more
+//     than Config.Health.CacheEvictionRatio of the keys set since the server
+//     started. It is only reported when caching is enabled.
+//
+// The report is cheap to compute, so it can back a health endpoint polled by
+// a load balancer or orchestrator.
+func (s *Server) Health() Health {
+	checks := []HealthCheck{s.degradedHealth()}
+	if s.cacheEnabled() {
+		checks = append(checks, s.cacheHealth())
+	}
+
+	health := Health{Status: HealthOK, Checks: checks}
+	for _, check := range checks {
+		if check.Status != HealthOK {
+			health.Status = HealthWarning
+		}
+	}
+	return health
+}
+
+// degradedHealth reports whether the server is in degraded mode.
+func (s *Server) degradedHealth() HealthCheck {
+	check := HealthCheck{Name: "degraded", Status: HealthOK}
+	if s.Degraded() {
+		check.Status = HealthWarning
+		check.Message = "tool error rate is above Degraded.ErrorRate"
+	}
+	return check
+}
+
+// cacheHealth compares the keys Ristretto evicted with the keys set.
+func (s *Server) cacheHealth() HealthCheck {
+	check := HealthCheck{Name: "cache", Status: HealthOK}
+
+	m := s.cache.Metrics()
+	sets := m.KeysAdded() + m.KeysUpdated()
+	if sets < s.config.Health.cacheMinSets() {
+		return check
+	}
+
+	ratio := float64(m.KeysEvicted()) / float64(sets)
+	if threshold := s.config.Health.cacheEvictionRatio(); ratio > threshold {
+		check.Status = HealthWarning
+		check.Message = fmt.Sprintf("%.0f%% of cache sets were evicted (threshold %.0f%%); consider raising CacheConfig.MaxCost",
+			ratio*100, threshold*100)
+	}
+	return check
+}
@@ -0,0 +1,135 @@
+package hypermcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rayprogramming/hypermcp/cache"
+	"go.uber.org/zap/zaptest"
+)
+
+// findCheck returns the named check of h, failing the test if it is missing.
+func findCheck(t *testing.T, h Health, name string) HealthCheck {
+	t.Helper()
+	for _, check := range h.Checks {
+		if check.Name == name {
+			return check
+		}
+	}
+	t.Fatalf("health report %+v has no %q check", h, name)
+	return HealthCheck{}
+}
+
+func TestServer_Health_CacheThrashing(t *testing.T) {
+	cacheConfig := cache.DefaultConfig()
+	cacheConfig.MaxCost = 10
+	srv, err := New(Config{
+		Name:         "test-server",
+		Version:      "1.0.0",
+		CacheEnabled: true,
+		CacheConfig:  cacheConfig,
+		CacheOptions: []cache.Option{
+			cache.WithCostFunc(func(any) int64 { return 1 }),
+			cache.WithIgnoreInternalCost(),
+		},
+		Health: HealthConfig{CacheEvictionRatio: 0.5, CacheMinSets: 20},
+	}, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	t.Cleanup(func() { _ = srv.Shutdown(context.Background()) })
+
+	// Below CacheMinSets, and well within MaxCost
+	for i := 0; i < 10; i++ {
+		srv.Cache().SetWait(fmt.Sprintf("key-%d", i), i, time.Hour)
+	}
+	if h := srv.Health(); h.Status != HealthOK || findCheck(t, h, "cache").Status != HealthOK {
+		t.Fatalf("expected a healthy cache before it fills up, got %+v", h)
+	}
+
+	// Each further key evicts another from the full cache
+	for i := 10; i < 200; i++ {
+		srv.Cache().SetWait(fmt.Sprintf("key-%d", i), i, time.Hour)
+	}
+	h := srv.Health()
+	if h.Status != HealthWarning {
+		t.Errorf("expected overall status %q, got %+v", HealthWarning, h)
+	}
+	check := findCheck(t, h, "cache")
+	if check.Status != HealthWarning || !strings.Contains(check.Message, "MaxCost") {
+		t.Errorf("expected a cache warning mentioning MaxCost, got %+v", check)
+	}
+}
+
+func TestServer_Health_CacheWithinBudget(t *testing.T) {
+	srv, err := New(Config{
+		Name:         "test-server",
+		Version:      "1.0.0",
+		CacheEnabled: true,
+		CacheConfig:  cache.DefaultConfig(),
+	}, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	t.Cleanup(func() { _ = srv.Shutdown(context.Background()) })
+
+	for i := 0; i < 2*DefaultCacheHealthMinSets; i++ {
+		srv.Cache().SetWait(fmt.Sprintf("key-%d", i), i, time.Hour)
+	}
+	if check := findCheck(t, srv.Health(), "cache"); check.Status != HealthOK {
+		t.Errorf("expected a healthy cache, got %+v", check)
+	}
+}
+
+func TestServer_Health_Degraded(t *testing.T) {
+	srv, err := New(Config{
+		Name:     "test-server",
+		Version:  "1.0.0",
+		Degraded: DegradedConfig{ErrorRate: 0.5, MinCalls: 2},
+	}, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+
+	h := srv.Health()
+	if h.Status != HealthOK || findCheck(t, h, "degraded").Status != HealthOK {
+		t.Errorf("expected a healthy server, got %+v", h)
+	}
+	for _, check := range h.Checks {
+		if check.Name == "cache" {
+			t.Errorf("expected no cache check while caching is disabled, got %+v", check)
+		}
+	}
+
+	srv.Metrics().ObserveToolResult(true)
+	srv.Metrics().ObserveToolResult(true)
+	if h := srv.Health(); h.Status != HealthWarning || findCheck(t, h, "degraded").Status != HealthWarning {
+		t.Errorf("expected a degraded warning, got %+v", h)
+	}
+}
+
+func TestConfig_Validate_Health(t *testing.T) {
+	tests := []struct {
+		name      string
+		health    HealthConfig
+		wantField string
+	}{
+		{name: "ratio above one", health: HealthConfig{CacheEvictionRatio: 1.5}, wantField: "Health.CacheEvictionRatio"},
+		{name: "negative ratio", health: HealthConfig{CacheEvictionRatio: -0.1}, wantField: "Health.CacheEvictionRatio"},
+		{name: "negative min sets", health: HealthConfig{CacheMinSets: -1}, wantField: "Health.CacheMinSets"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{Name: "test-server", Version: "1.0.0", Health: tt.health}
+			var cfgErr *ConfigError
+			if err := cfg.Validate(); !errors.As(err, &cfgErr) || cfgErr.Field != tt.wantField {
+				t.Errorf("Validate() error = %v, want ConfigError for %s", err, tt.wantField)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package httpx
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultRetryBudgetInterval is the interval Config.RetryBudget applies to
+// when Config.RetryBudgetInterval is zero.
+const DefaultRetryBudgetInterval = time.Second
+
+// retryBudget is a token bucket of retries shared by every request a client
+// makes. It holds up to max tokens and refills max tokens per interval.
+type retryBudget struct {
+	max      float64
+	interval time.Duration
+	now      func() time.Time
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// newRetryBudget returns a budget of retries per interval, or nil (unlimited)
+// if retries is not positive.
+func newRetryBudget(retries int, interval time.Duration) *retryBudget {
+	if retries <= 0 {
+		return nil
+	}
+	if interval <= 0 {
+		interval = DefaultRetryBudgetInterval
+	}
+	return &retryBudget{
+		max:      float64(retries),
+		interval: interval,
+		now:      time.Now,
+		tokens:   float64(retries),
+		last:     time.Now(),
+	}
+}
+
+// take spends one retry, reporting false if the budget is exhausted. A nil
+// budget is unlimited.
+func (b *retryBudget) take() bool {
+	if b == nil {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	elapsed := now.Sub(b.last)
+	b.last = now
+	b.tokens = min(b.max, b.tokens+b.max*float64(elapsed)/float64(b.interval))
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
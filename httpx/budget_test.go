@@ -0,0 +1,151 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zaptest"
+)
+
+func TestClient_RetryBudget(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	cfg := DefaultConfig()
+	cfg.MaxRetries = 3
+	cfg.InitialInterval = time.Millisecond
+	cfg.MaxInterval = 5 * time.Millisecond
+	cfg.RetryBudget = 4
+	cfg.RetryBudgetInterval = time.Hour // no meaningful refill during the test
+	client, err := NewWithConfig(cfg, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		wantAttempts  int
+		wantExhausted bool
+	}{
+		{name: "all retries within budget", wantAttempts: 4},
+		{name: "budget runs out mid-request", wantAttempts: 2, wantExhausted: true},
+		{name: "budget spent, no retries", wantAttempts: 1, wantExhausted: true},
+	}
+
+	for _, tt := range tests {
+		calls.Store(0)
+
+		err := client.Get(context.Background(), server.URL, nil)
+
+		var httpErr *HTTPError
+		if !errors.As(err, &httpErr) {
+			t.Fatalf("%s: expected *HTTPError, got %T: %v", tt.name, err, err)
+		}
+		if httpErr.Attempts != tt.wantAttempts || int(calls.Load()) != tt.wantAttempts {
+			t.Errorf("%s: attempts = %d, server saw %d, want %d", tt.name, httpErr.Attempts, calls.Load(), tt.wantAttempts)
+		}
+		if got := errors.Is(err, ErrRetryBudgetExhausted); got != tt.wantExhausted {
+			t.Errorf("%s: errors.Is(err, ErrRetryBudgetExhausted) = %v, want %v", tt.name, got, tt.wantExhausted)
+		}
+	}
+}
+
+func TestClient_RetryBudget_SpentOnlyOnRetry(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	cfg := DefaultConfig()
+	cfg.MaxRetries = 1
+	cfg.InitialInterval = time.Second
+	cfg.RetryBudget = 1
+	cfg.RetryBudgetInterval = time.Hour
+	client, err := NewWithConfig(cfg, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	// The deadline passes during the backoff, so the retry is never sent
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := client.Get(ctx, server.URL, nil); err == nil {
+		t.Fatal("expected the request to fail")
+	}
+
+	client.budget.mu.Lock()
+	tokens := client.budget.tokens
+	client.budget.mu.Unlock()
+	if tokens < 1 {
+		t.Errorf("budget has %v tokens, want the unsent retry not to spend one", tokens)
+	}
+}
+
+func TestRetryBudget_Refills(t *testing.T) {
+	now := time.Now()
+	b := newRetryBudget(2, time.Second)
+	b.now = func() time.Time { return now }
+	b.last = now
+
+	if !b.take() || !b.take() {
+		t.Fatal("take() = false within the initial budget")
+	}
+	if b.take() {
+		t.Fatal("take() = true with the budget spent")
+	}
+
+	// Half an interval refills half the budget
+	now = now.Add(500 * time.Millisecond)
+	if !b.take() {
+		t.Error("take() = false after a partial refill")
+	}
+	if b.take() {
+		t.Error("take() = true beyond the partial refill")
+	}
+
+	// Unused retries accumulate only up to the budget
+	now = now.Add(time.Hour)
+	for i := range 2 {
+		if !b.take() {
+			t.Errorf("take() %d = false after a full refill", i)
+		}
+	}
+	if b.take() {
+		t.Error("take() = true beyond the budget's capacity")
+	}
+}
+
+func TestRetryBudget_Unlimited(t *testing.T) {
+	var b *retryBudget = newRetryBudget(0, 0)
+	for range 100 {
+		if !b.take() {
+			t.Fatal("take() = false on an unlimited budget")
+		}
+	}
+}
+
+func TestConfig_Validate_RetryBudget(t *testing.T) {
+	for _, field := range []string{"RetryBudget", "RetryBudgetInterval"} {
+		cfg := DefaultConfig()
+		if field == "RetryBudget" {
+			cfg.RetryBudget = -1
+		} else {
+			cfg.RetryBudgetInterval = -time.Second
+		}
+
+		var cfgErr *ConfigError
+		err := cfg.Validate()
+		if !errors.As(err, &cfgErr) || cfgErr.Field != field || !errors.Is(err, ErrInvalidRetryBudget) {
+			t.Errorf("negative %s: Validate() error = %v, want ErrInvalidRetryBudget", field, err)
+		}
+	}
+}
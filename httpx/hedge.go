@@ -0,0 +1,127 @@
+package httpx
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// DefaultMaxHedges is the number of hedged requests sent per attempt when
+// Config.HedgeAfter is set and Config.MaxHedges is zero.
+const DefaultMaxHedges = 1
+
+// hedgeable reports whether req may be hedged: a GET or HEAD without a body,
+// which is safe to have in flight more than once.
+func hedgeable(req *http.Request) bool {
+	if req.Method != "" && req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return false
+	}
+	return req.Body == nil || req.Body == http.NoBody
+}
+
+// maxHedges returns the number of hedged requests allowed per attempt.
+func (c Config) maxHedges() int {
+	if c.MaxHedges > 0 {
+		return c.MaxHedges
+	}
+	return DefaultMaxHedges
+}
+
+// hedgeResult is the outcome of one of the requests of a hedged attempt.
+type hedgeResult struct {
+	index int // position of the request in the order they were sent
+	resp  *http.Response
+	err   error
+}
+
+// do sends req, hedging it as configured by Config.HedgeAfter.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	if c.config.HedgeAfter <= 0 || !hedgeable(req) {
+		return c.client.Do(req)
+	}
+	return c.doHedged(req)
+}
+
+// doHedged sends req, and another copy of it each time HedgeAfter passes
+// without a response, up to MaxHedges copies. The first response wins and the
+// other requests are canceled. If every request fails, the last error is
+// returned.
+func (c *Client) doHedged(req *http.Request) (*http.Response, error) {
+	results := make(chan hedgeResult)
+	var cancels []context.CancelFunc
+	send := func() {
+		ctx, cancel := context.WithCancel(req.Context())
+		index := len(cancels)
+		cancels = append(cancels, cancel)
+		go func() {
+			resp, err := c.client.Do(req.Clone(ctx))
+			results <- hedgeResult{index: index, resp: resp, err: err}
+		}()
+	}
+
+	send()
+	pending := 1
+	timer := time.NewTimer(c.config.HedgeAfter)
+	defer timer.Stop()
+
+	var lastErr error
+	for pending > 0 {
+		select {
+		case <-timer.C:
+			if len(cancels) > c.config.maxHedges() {
+				continue
+			}
+			c.log().Debug("sending hedged request",
+				zap.String("url", req.URL.String()),
+				zap.Int("hedge", len(cancels)),
+			)
+			send()
+			pending++
+			timer.Reset(c.config.HedgeAfter)
+
+		case result := <-results:
+			pending--
+			if result.err != nil {
+				cancels[result.index]()
+				lastErr = result.err
+				continue
+			}
+			// Cancel the losers and discard whatever they still return; the
+			// winner's request lives until its body is closed
+			for i, cancel := range cancels {
+				if i != result.index {
+					cancel()
+				}
+			}
+			go discardResponses(results, pending)
+			result.resp.Body = &cancelOnClose{ReadCloser: result.resp.Body, cancel: cancels[result.index]}
+			return result.resp, nil
+		}
+	}
+	return nil, lastErr
+}
+
+// discardResponses closes the responses of the pending requests of a hedged
+// attempt that was already won.
+func discardResponses(results <-chan hedgeResult, pending int) {
+	for range pending {
+		if result := <-results; result.resp != nil {
+			_ = result.resp.Body.Close()
+		}
+	}
+}
+
+// cancelOnClose cancels a request's context once its response body is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
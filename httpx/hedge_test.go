@@ -0,0 +1,137 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zaptest"
+)
+
+func newHedgingClient(t *testing.T, hedgeAfter time.Duration, maxHedges int) *Client {
+	t.Helper()
+	cfg := DefaultConfig()
+	cfg.HedgeAfter = hedgeAfter
+	cfg.MaxHedges = maxHedges
+	cfg.MaxRetries = 0
+	client, err := NewWithConfig(cfg, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	return client
+}
+
+func TestClient_HedgeAfter(t *testing.T) {
+	var requests atomic.Int64
+	slowCanceled := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if requests.Add(1) == 1 {
+			select {
+			case <-r.Context().Done():
+				close(slowCanceled)
+				return
+			case <-time.After(5 * time.Second):
+			}
+			_, _ = w.Write([]byte(`{"from":"first"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"from":"hedge"}`))
+	}))
+	defer server.Close()
+
+	client := newHedgingClient(t, 20*time.Millisecond, 0)
+
+	start := time.Now()
+	var result map[string]string
+	if err := client.Get(context.Background(), server.URL, &result); err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if result["from"] != "hedge" {
+		t.Errorf("expected the hedged request to win, got response from %q", result["from"])
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("request took %v, expected the hedge to answer quickly", elapsed)
+	}
+
+	select {
+	case <-slowCanceled:
+	case <-time.After(2 * time.Second):
+		t.Error("slow request was not canceled")
+	}
+	if got := requests.Load(); got != 2 {
+		t.Errorf("expected 2 requests, got %d", got)
+	}
+}
+
+func TestClient_HedgeAfter_MaxHedges(t *testing.T) {
+	var requests atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		select {
+		case <-r.Context().Done():
+			return
+		case <-time.After(200 * time.Millisecond):
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := newHedgingClient(t, 10*time.Millisecond, 2)
+
+	var result map[string]any
+	if err := client.Get(context.Background(), server.URL, &result); err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if got := requests.Load(); got != 3 {
+		t.Errorf("expected the request and 2 hedges, got %d requests", got)
+	}
+}
+
+func TestClient_HedgeAfter_NotHedged(t *testing.T) {
+	var requests atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		time.Sleep(50 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := newHedgingClient(t, 5*time.Millisecond, 0)
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, server.URL, strings.NewReader(`{}`))
+	if err != nil {
+		t.Fatalf("create request: %v", err)
+	}
+	var result map[string]any
+	if err := client.DoJSON(context.Background(), req, &result); err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if got := requests.Load(); got != 1 {
+		t.Errorf("expected a POST not to be hedged, got %d requests", got)
+	}
+}
+
+func TestConfig_Validate_Hedge(t *testing.T) {
+	for _, field := range []string{"HedgeAfter", "MaxHedges"} {
+		cfg := DefaultConfig()
+		if field == "HedgeAfter" {
+			cfg.HedgeAfter = -time.Second
+		} else {
+			cfg.MaxHedges = -1
+		}
+
+		var cfgErr *ConfigError
+		err := cfg.Validate()
+		if !errors.As(err, &cfgErr) || cfgErr.Field != field || !errors.Is(err, ErrInvalidHedge) {
+			t.Errorf("negative %s: Validate() error = %v, want ErrInvalidHedge", field, err)
+		}
+	}
+}
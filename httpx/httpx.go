@@ -3,15 +3,21 @@ package httpx
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
+	"sync"
+	"sync/atomic"
 	"time"
+	"unicode/utf8"
 
 	"github.com/cenkalti/backoff/v4"
+	"github.com/google/jsonschema-go/jsonschema"
 	"go.uber.org/zap"
 )
 
@@ -31,8 +37,24 @@ var (
 
 	// ErrInvalidRetryInterval indicates retry interval is not positive.
 	ErrInvalidRetryInterval = errors.New("retry interval must be positive")
+
+	// ErrInvalidRetryBudget indicates RetryBudget or RetryBudgetInterval is negative.
+	ErrInvalidRetryBudget = errors.New("retry budget cannot be negative")
+
+	// ErrInvalidErrorBodyLimit indicates ErrorBodyLimit is negative.
+	ErrInvalidErrorBodyLimit = errors.New("ErrorBodyLimit cannot be negative")
+
+	// ErrInvalidHedge indicates HedgeAfter or MaxHedges is negative.
+	ErrInvalidHedge = errors.New("hedging settings cannot be negative")
 )
 
+// ErrRetryBudgetExhausted is wrapped around a request's error when it was not
+// retried because the client's retry budget (Config.RetryBudget) was spent.
+var ErrRetryBudgetExhausted = errors.New("retry budget exhausted")
+
+// ErrNilSchema is returned by GetValidated when it is given a nil schema.
+var ErrNilSchema = errors.New("schema is nil")
+
 // ConfigError wraps httpx configuration validation errors with context.
 type ConfigError struct {
 	Err   error
@@ -47,6 +69,43 @@ func (e *ConfigError) Unwrap() error {
 	return e.Err
 }
 
+// SchemaValidationError indicates a response body did not conform to the
+// JSON schema supplied to GetValidated.
+type SchemaValidationError struct {
+	Err error
+	URL string
+}
+
+func (e *SchemaValidationError) Error() string {
+	return fmt.Sprintf("response from %s failed schema validation: %v", e.URL, e.Err)
+}
+
+func (e *SchemaValidationError) Unwrap() error {
+	return e.Err
+}
+
+// DefaultErrorBodyLimit is the number of bytes of an error response body
+// kept in HTTPError.Body when Config.ErrorBodyLimit is zero.
+const DefaultErrorBodyLimit = 4 * 1024
+
+// HTTPError is returned by DoJSON when the final attempt received a non-2xx
+// response.
+type HTTPError struct {
+	URL        string
+	StatusCode int
+
+	// Body is the start of the response body, at most Config.ErrorBodyLimit
+	// bytes. Truncated bodies end with "... (truncated)".
+	Body string
+
+	// Attempts is the number of requests sent, including the final one.
+	Attempts int
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("http %d from %s after %d attempt(s): %s", e.StatusCode, e.URL, e.Attempts, e.Body)
+}
+
 // Config holds HTTP client configuration options.
 type Config struct {
 	// Timeouts
@@ -60,9 +119,41 @@ type Config struct {
 	InitialInterval time.Duration
 	MaxInterval     time.Duration
 
+	// RetryBudget, if positive, caps the retries made across all requests
+	// of the client to this many per RetryBudgetInterval, so a burst of
+	// failing requests can't each retry MaxRetries times against a struggling
+	// upstream. Unused retries accumulate up to RetryBudget. When the budget
+	// is spent, requests fail after their first attempt with an error
+	// wrapping ErrRetryBudgetExhausted. Defaults to 0 (unlimited).
+	RetryBudget int
+
+	// RetryBudgetInterval is the period over which RetryBudget retries are
+	// allowed. Defaults to DefaultRetryBudgetInterval.
+	RetryBudgetInterval time.Duration
+
+	// HedgeAfter, if positive, sends a second copy of a GET or HEAD request
+	// without a body when the first has not responded within this delay, and
+	// uses whichever response arrives first, canceling the other request.
+	// This trades extra upstream load for lower tail latency on reads. Each
+	// attempt, including retries, is hedged on its own. The Timing of a
+	// hedged attempt mixes events from all its requests. Defaults to 0 (no
+	// hedging).
+	HedgeAfter time.Duration
+
+	// MaxHedges caps the copies of a request sent per attempt when HedgeAfter
+	// is set, one more each time HedgeAfter passes without a response.
+	// Defaults to DefaultMaxHedges.
+	MaxHedges int
+
 	// Request limits
 	MaxResponseSize int64
 
+	// ErrorBodyLimit caps how much of a non-2xx response body is read into
+	// HTTPError.Body, independent of MaxResponseSize, so a large error page
+	// doesn't end up in error messages and logs. Defaults to
+	// DefaultErrorBodyLimit.
+	ErrorBodyLimit int64
+
 	// Connection pooling
 	MaxIdleConns        int
 	MaxIdleConnsPerHost int
@@ -80,6 +171,25 @@ type Config struct {
 	// Dial, DialTLS, or DialContext func or TLSClientConfig is provided.
 	// Defaults to true.
 	ForceAttemptHTTP2 bool
+
+	// RetryableError decides whether a transport error (one returned before any
+	// HTTP response was received) should be retried. If nil, DefaultRetryableError
+	// is used, which fails fast on errors that cannot succeed on retry, such as
+	// untrusted certificates or unknown hosts. Status-code retries are unaffected.
+	RetryableError func(error) bool
+
+	// DisableKeepAlives, if true, disables HTTP keep-alives so every request
+	// uses a fresh connection. Useful for one-shot tools or when debugging
+	// connection issues. When set, the idle connection pooling settings
+	// (MaxIdleConns, MaxIdleConnsPerHost, IdleConnTimeout) are ignored.
+	// Defaults to false.
+	DisableKeepAlives bool
+
+	// OnTiming, if set, is called with the Timing of every request attempt,
+	// for example to feed connection setup and time-to-first-byte into
+	// metrics. It runs synchronously on the request path and must be fast.
+	// Timings are also logged at debug level.
+	OnTiming func(req *http.Request, timing Timing)
 }
 
 // DefaultConfig returns sensible default configuration for the HTTP client.
@@ -93,12 +203,14 @@ func DefaultConfig() Config {
 		InitialInterval:       100 * time.Millisecond,
 		MaxInterval:           2 * time.Second,
 		MaxResponseSize:       10 * 1024 * 1024, // 10MB
+		ErrorBodyLimit:        DefaultErrorBodyLimit,
 		MaxIdleConns:          100,
 		MaxIdleConnsPerHost:   10,
 		IdleConnTimeout:       90 * time.Second,
 		UserAgent:             "hypermcp",
 		DisableCompression:    false, // Enable gzip compression
 		ForceAttemptHTTP2:     true,  // Enable HTTP/2
+		DisableKeepAlives:     false, // Reuse connections
 	}
 }
 
@@ -148,12 +260,46 @@ func (c Config) Validate() error {
 			Field: "MaxInterval",
 		}
 	}
+	if c.RetryBudget < 0 {
+		return &ConfigError{
+			Err:   ErrInvalidRetryBudget,
+			Field: "RetryBudget",
+		}
+	}
+	if c.RetryBudgetInterval < 0 {
+		return &ConfigError{
+			Err:   ErrInvalidRetryBudget,
+			Field: "RetryBudgetInterval",
+		}
+	}
 	if c.MaxResponseSize <= 0 {
 		return &ConfigError{
 			Err:   ErrInvalidMaxResponseSize,
 			Field: "MaxResponseSize",
 		}
 	}
+	if c.ErrorBodyLimit < 0 {
+		return &ConfigError{
+			Err:   ErrInvalidErrorBodyLimit,
+			Field: "ErrorBodyLimit",
+		}
+	}
+	if c.HedgeAfter < 0 {
+		return &ConfigError{
+			Err:   ErrInvalidHedge,
+			Field: "HedgeAfter",
+		}
+	}
+	if c.MaxHedges < 0 {
+		return &ConfigError{
+			Err:   ErrInvalidHedge,
+			Field: "MaxHedges",
+		}
+	}
+	// Connection pooling settings are irrelevant without keep-alives
+	if c.DisableKeepAlives {
+		return nil
+	}
 	if c.MaxIdleConns <= 0 {
 		return &ConfigError{
 			Err:   ErrInvalidMaxIdleConns,
@@ -175,11 +321,49 @@ func (c Config) Validate() error {
 	return nil
 }
 
+// RequestOption customizes a single request made with DoJSON, Get, or GetValidated.
+type RequestOption func(*requestOptions)
+
+// requestOptions holds the effective settings for one request.
+type requestOptions struct {
+	maxResponseSize int64
+	timing          *Timing
+	idempotent      bool
+	idempotencyKey  string
+}
+
+// WithMaxResponseSize overrides Config.MaxResponseSize for a single request.
+//
+// Use it when one client talks to endpoints with very different response sizes,
+// e.g. a tiny status endpoint and a large data export. Non-positive values are
+// ignored and the configured default applies. A response exceeding the limit
+// fails with an error wrapping *http.MaxBytesError.
+func WithMaxResponseSize(n int64) RequestOption {
+	return func(o *requestOptions) {
+		if n > 0 {
+			o.maxResponseSize = n
+		}
+	}
+}
+
+// requestOptions resolves opts against the client's configured defaults.
+func (c *Client) requestOptions(opts []RequestOption) requestOptions {
+	o := requestOptions{maxResponseSize: c.config.MaxResponseSize}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
 // Client wraps an HTTP client with retry logic and performance optimizations
 type Client struct {
 	client *http.Client
-	logger *zap.Logger
+	logger atomic.Pointer[zap.Logger]
 	config Config
+	budget *retryBudget // nil when retries are unlimited
+
+	// schemas caches GetValidated's resolved schemas, so each is resolved once
+	schemas sync.Map // *jsonschema.Schema -> *jsonschema.Resolved
 }
 
 // New creates a new HTTP client with default configuration.
@@ -208,16 +392,46 @@ func NewWithConfig(cfg Config, logger *zap.Logger) (*Client, error) {
 		IdleConnTimeout:       cfg.IdleConnTimeout,
 		DisableCompression:    cfg.DisableCompression,
 		ForceAttemptHTTP2:     cfg.ForceAttemptHTTP2,
+		DisableKeepAlives:     cfg.DisableKeepAlives,
 	}
 
-	return &Client{
+	if cfg.DisableKeepAlives {
+		logger.Debug("http keep-alives disabled, ignoring idle connection pooling settings",
+			zap.Int("max_idle_conns", cfg.MaxIdleConns),
+			zap.Int("max_idle_conns_per_host", cfg.MaxIdleConnsPerHost),
+			zap.Duration("idle_conn_timeout", cfg.IdleConnTimeout),
+		)
+	}
+
+	c := &Client{
 		client: &http.Client{
 			Transport: transport,
 			Timeout:   cfg.RequestTimeout,
 		},
-		logger: logger,
 		config: cfg,
-	}, nil
+		budget: newRetryBudget(cfg.RetryBudget, cfg.RetryBudgetInterval),
+	}
+	c.SetLogger(logger)
+	return c, nil
+}
+
+// SetLogger replaces the logger the client writes to. It is safe to call
+// while requests are in flight; a nil logger discards logs.
+func (c *Client) SetLogger(logger *zap.Logger) {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	c.logger.Store(logger)
+}
+
+// log returns the current logger.
+func (c *Client) log() *zap.Logger {
+	return c.logger.Load()
+}
+
+// Config returns the configuration the client was created with.
+func (c *Client) Config() Config {
+	return c.config
 }
 
 // DoJSON performs an HTTP request and unmarshals the JSON response.
@@ -226,53 +440,99 @@ func NewWithConfig(cfg Config, logger *zap.Logger) (*Client, error) {
 // The method automatically handles:
 // - Exponential backoff with jitter for retryable errors (429, 5xx)
 // - Request timeouts to prevent hanging
-// - Response size limits to prevent memory exhaustion (10MB default)
+// - Response size limits to prevent memory exhaustion (10MB default, see WithMaxResponseSize)
 // - Context cancellation for early termination
 //
 // Retryable status codes: 429 (Too Many Requests), 500-504 (Server Errors)
-// Non-retryable errors: 4xx (except 429), JSON decode errors, network errors
+// Retryable transport errors: as decided by Config.RetryableError (see DefaultRetryableError)
+// Non-retryable errors: 4xx (except 429), JSON decode errors, permanent network errors
 //
 // The request context controls the overall timeout, while individual retry
 // attempts have their own timeouts configured via Config.RequestTimeout.
-func (c *Client) DoJSON(ctx context.Context, req *http.Request, result interface{}) error {
+// When Config.RetryBudget is set, each retry also spends from the client-wide
+// budget; once it is exhausted the request fails without retrying.
+//
+// Only requests that are safe to repeat are retried: GET, HEAD, OPTIONS, and
+// TRACE requests, and requests made with WithIdempotent, WithIdempotencyKey,
+// or an Idempotency-Key header. Other requests, such as a plain POST, are
+// attempted once. A request body is replayed with req.GetBody, which
+// http.NewRequest sets for common body types; without it, the request is not
+// retried.
+func (c *Client) DoJSON(ctx context.Context, req *http.Request, result interface{}, opts ...RequestOption) error {
+	options := c.requestOptions(opts)
 	reqID := fmt.Sprintf("%p", req)
 	startTime := time.Now()
 
-	operation := func() error {
+	// Clamp MaxRetries to zero if negative before converting to uint64
+	maxRetries := c.config.MaxRetries
+	if maxRetries < 0 {
+		maxRetries = 0
+	}
+	if maxRetries > 0 && !options.retryable(req) {
+		c.log().Debug("request is not idempotent, not retrying",
+			zap.String("method", req.Method),
+			zap.String("url", req.URL.String()),
+		)
+		maxRetries = 0
+	}
+
+	var attempts, lastStatus int
+	attempt := func() error {
+		attempts++
+
 		// Clone request for retry safety
 		clonedReq := req.Clone(ctx)
+		if options.idempotencyKey != "" {
+			clonedReq.Header.Set(IdempotencyKeyHeader, options.idempotencyKey)
+		}
+		if attempts > 1 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return backoff.Permanent(fmt.Errorf("rewind request body: %w", err))
+			}
+			clonedReq.Body = body
+		}
 
-		resp, err := c.client.Do(clonedReq)
+		if trace := c.startTrace(options); trace != nil {
+			clonedReq = clonedReq.WithContext(trace.withContext(clonedReq.Context()))
+			defer func() { c.finishTrace(req, trace, options, attempts) }()
+		}
+
+		resp, err := c.do(clonedReq)
 		if err != nil {
-			c.logger.Debug("http request failed",
+			retryable := c.retryableError(err)
+			c.log().Debug("http request failed",
 				zap.String("url", req.URL.String()),
+				zap.Bool("retryable", retryable),
 				zap.Error(err),
 			)
+			if !retryable {
+				return backoff.Permanent(err)
+			}
 			return err
 		}
 		defer func() {
-			if closeErr := resp.Body.Close(); closeErr != nil {
-				c.logger.Warn("failed to close response body", zap.Error(closeErr))
+			if closeErr := drainAndClose(resp, options.maxResponseSize); closeErr != nil {
+				c.log().Warn("failed to close response body", zap.Error(closeErr))
 			}
 		}()
+		lastStatus = resp.StatusCode
 
 		// Limit response size to prevent memory exhaustion
-		limitedReader := io.LimitReader(resp.Body, c.config.MaxResponseSize)
+		limitedReader := http.MaxBytesReader(nil, resp.Body, options.maxResponseSize)
 
 		// Check for retryable HTTP status codes
 		if shouldRetry(resp.StatusCode) {
-			bodyBytes, _ := io.ReadAll(limitedReader)
-			c.logger.Debug("retryable http status",
+			c.log().Debug("retryable http status",
 				zap.Int("status", resp.StatusCode),
 				zap.String("url", req.URL.String()),
 			)
-			return fmt.Errorf("retryable status %d: %s", resp.StatusCode, string(bodyBytes))
+			return &HTTPError{URL: req.URL.String(), StatusCode: resp.StatusCode, Body: c.readErrorBody(limitedReader)}
 		}
 
 		// Non-2xx status that shouldn't retry
 		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-			bodyBytes, _ := io.ReadAll(limitedReader)
-			return backoff.Permanent(fmt.Errorf("http %d: %s", resp.StatusCode, string(bodyBytes)))
+			return backoff.Permanent(&HTTPError{URL: req.URL.String(), StatusCode: resp.StatusCode, Body: c.readErrorBody(limitedReader)})
 		}
 
 		// Decode JSON response
@@ -284,17 +544,27 @@ func (c *Client) DoJSON(ctx context.Context, req *http.Request, result interface
 		return nil
 	}
 
+	var lastErr error
+	operation := func() error {
+		// A retry must fit in the shared budget. Take from it only now that
+		// the retry is about to be sent, not when the previous attempt
+		// failed: the backoff may still give up before then.
+		if attempts > 0 && !c.budget.take() {
+			c.log().Debug("retry budget exhausted, not retrying",
+				zap.String("url", req.URL.String()),
+			)
+			return backoff.Permanent(fmt.Errorf("%w: %w", ErrRetryBudgetExhausted, lastErr))
+		}
+		lastErr = attempt()
+		return lastErr
+	}
+
 	// Configure exponential backoff with jitter
 	expBackoff := backoff.NewExponentialBackOff()
 	expBackoff.InitialInterval = c.config.InitialInterval
 	expBackoff.MaxInterval = c.config.MaxInterval
 	expBackoff.MaxElapsedTime = c.config.RequestTimeout
 
-	// Clamp MaxRetries to zero if negative before converting to uint64
-	maxRetries := c.config.MaxRetries
-	if maxRetries < 0 {
-		maxRetries = 0
-	}
 	backoffWithRetries := backoff.WithMaxRetries(expBackoff, uint64(maxRetries)) // #nosec G115
 	backoffWithContext := backoff.WithContext(backoffWithRetries, ctx)
 
@@ -303,16 +573,22 @@ func (c *Client) DoJSON(ctx context.Context, req *http.Request, result interface
 	duration := time.Since(startTime)
 
 	if err != nil {
-		c.logger.Warn("http request failed after retries",
+		var httpErr *HTTPError
+		if errors.As(err, &httpErr) {
+			httpErr.Attempts = attempts
+		}
+		c.log().Warn("http request failed after retries",
 			zap.String("req_id", reqID),
 			zap.String("url", req.URL.String()),
 			zap.Duration("duration", duration),
+			zap.Int("attempts", attempts),
+			zap.Int("last_status", lastStatus),
 			zap.Error(err),
 		)
 		return err
 	}
 
-	c.logger.Debug("http request completed",
+	c.log().Debug("http request completed",
 		zap.String("req_id", reqID),
 		zap.String("url", req.URL.String()),
 		zap.Duration("duration", duration),
@@ -321,6 +597,132 @@ func (c *Client) DoJSON(ctx context.Context, req *http.Request, result interface
 	return nil
 }
 
+// DefaultRetryableError reports whether a transport error is worth retrying.
+//
+// Transient failures such as connection resets and timeouts are retryable.
+// Errors that will fail the same way on every attempt are not: TLS certificate
+// verification failures and DNS lookups for hosts that do not exist.
+func DefaultRetryableError(err error) bool {
+	var (
+		unknownAuthority x509.UnknownAuthorityError
+		certInvalid      x509.CertificateInvalidError
+		hostname         x509.HostnameError
+		certVerification *tls.CertificateVerificationError
+		dnsErr           *net.DNSError
+	)
+
+	switch {
+	case errors.As(err, &unknownAuthority),
+		errors.As(err, &certInvalid),
+		errors.As(err, &hostname),
+		errors.As(err, &certVerification):
+		return false
+	case errors.As(err, &dnsErr) && dnsErr.IsNotFound:
+		return false
+	default:
+		return true
+	}
+}
+
+// startTrace returns a timingTrace for one attempt, or nil when nothing would
+// consume the timing.
+func (c *Client) startTrace(options requestOptions) *timingTrace {
+	if options.timing == nil && c.config.OnTiming == nil && !c.log().Core().Enabled(zap.DebugLevel) {
+		return nil
+	}
+	return newTimingTrace()
+}
+
+// finishTrace reports the timing of a finished attempt.
+func (c *Client) finishTrace(req *http.Request, trace *timingTrace, options requestOptions, attempt int) {
+	timing := trace.finish()
+
+	c.log().Debug("http request timing", append([]zap.Field{
+		zap.String("url", req.URL.String()),
+		zap.Int("attempt", attempt),
+	}, timingFields(timing)...)...)
+
+	if c.config.OnTiming != nil {
+		c.config.OnTiming(req, timing)
+	}
+	if options.timing != nil {
+		*options.timing = timing
+	}
+}
+
+// retryableError applies the configured RetryableError predicate.
+func (c *Client) retryableError(err error) bool {
+	if c.config.RetryableError != nil {
+		return c.config.RetryableError(err)
+	}
+	return DefaultRetryableError(err)
+}
+
+// readErrorBody reads at most Config.ErrorBodyLimit bytes of an error
+// response body, marking the result when the body was longer. The rest of the
+// body is drained when the response is closed.
+func (c *Client) readErrorBody(r io.Reader) string {
+	limit := c.config.ErrorBodyLimit
+	if limit == 0 {
+		limit = DefaultErrorBodyLimit
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(r, limit+1))
+	if int64(len(body)) <= limit {
+		return string(body)
+	}
+
+	// Don't leave half a UTF-8 sequence before the marker
+	body = body[:limit]
+	for i := 0; i < utf8.UTFMax-1 && len(body) > 0; i++ {
+		if r, size := utf8.DecodeLastRune(body); r != utf8.RuneError || size > 1 {
+			break
+		}
+		body = body[:len(body)-1]
+	}
+	return string(body) + "... (truncated)"
+}
+
+// maxDrainSize bounds how much of a leftover response body DrainAndClose reads.
+// Requests sent by a Client drain up to their MaxResponseSize instead.
+const maxDrainSize = 256 * 1024
+
+// DrainAndClose reads and discards what remains of resp's body, up to 256KB, and
+// closes it.
+//
+// Go's http.Transport only returns a connection to the idle pool for reuse once
+// the previous response body has been read to EOF and closed. Closing a body
+// with unread bytes, as happens when an error response is ignored or a JSON
+// decoder stops before trailing data, forces the connection to be torn down and
+// the next request to pay for a new TCP and TLS handshake. Draining is capped
+// because reading a very large leftover body costs more than a new connection.
+// Recent Go releases drain small bodies on Close themselves; DrainAndClose gives
+// the same behavior on every supported Go version.
+//
+// Callers making their own requests should defer it in place of resp.Body.Close:
+//
+//	resp, err := client.Do(req)
+//	if err != nil {
+//	    return err
+//	}
+//	defer httpx.DrainAndClose(resp)
+//
+// It returns the error from closing the body, and is a no-op for a nil response.
+func DrainAndClose(resp *http.Response) error {
+	return drainAndClose(resp, maxDrainSize)
+}
+
+// drainAndClose is DrainAndClose with the drain capped at limit bytes. The
+// Client passes the request's MaxResponseSize: a leftover body no larger than
+// the client would have accepted is worth reading to keep the connection.
+func drainAndClose(resp *http.Response, limit int64) error {
+	if resp == nil || resp.Body == nil {
+		return nil
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, limit))
+	return resp.Body.Close()
+}
+
 // shouldRetry determines if an HTTP status code warrants a retry
 func shouldRetry(statusCode int) bool {
 	switch statusCode {
@@ -336,7 +738,7 @@ func shouldRetry(statusCode int) bool {
 }
 
 // Get is a convenience wrapper for GET requests
-func (c *Client) Get(ctx context.Context, url string, result interface{}) error {
+func (c *Client) Get(ctx context.Context, url string, result interface{}, opts ...RequestOption) error {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return fmt.Errorf("create request: %w", err)
@@ -352,5 +754,63 @@ func (c *Client) Get(ctx context.Context, url string, result interface{}) error
 	// Don't set Accept-Encoding manually - let Go's Transport handle gzip automatically
 	// when DisableCompression is false
 
-	return c.DoJSON(ctx, req, result)
+	return c.DoJSON(ctx, req, result, opts...)
+}
+
+// GetValidated performs a GET request and validates the raw JSON response
+// against schema before decoding it into result.
+//
+// This guards tools against malformed upstream responses: if the body does not
+// conform to the schema, a *SchemaValidationError is returned and result is left
+// untouched. Transport errors, retries, and size limits behave exactly like Get.
+//
+// A nil schema returns ErrNilSchema without sending the request. Each schema
+// is resolved on first use and the result reused by later calls, so a schema
+// must not be modified once passed to GetValidated.
+func (c *Client) GetValidated(ctx context.Context, url string, schema *jsonschema.Schema, result interface{}, opts ...RequestOption) error {
+	resolved, err := c.resolveSchema(schema)
+	if err != nil {
+		return err
+	}
+
+	var raw json.RawMessage
+	if err := c.Get(ctx, url, &raw, opts...); err != nil {
+		return err
+	}
+
+	var instance any
+	if err := json.Unmarshal(raw, &instance); err != nil {
+		return fmt.Errorf("json decode error: %w", err)
+	}
+	if err := resolved.Validate(instance); err != nil {
+		c.log().Debug("response failed schema validation",
+			zap.String("url", url),
+			zap.Error(err),
+		)
+		return &SchemaValidationError{
+			Err: err,
+			URL: url,
+		}
+	}
+
+	if err := json.Unmarshal(raw, result); err != nil {
+		return fmt.Errorf("json decode error: %w", err)
+	}
+	return nil
+}
+
+// resolveSchema returns schema resolved, resolving it only on first use.
+func (c *Client) resolveSchema(schema *jsonschema.Schema) (*jsonschema.Resolved, error) {
+	if schema == nil {
+		return nil, ErrNilSchema
+	}
+	if resolved, ok := c.schemas.Load(schema); ok {
+		return resolved.(*jsonschema.Resolved), nil
+	}
+	resolved, err := schema.Resolve(nil)
+	if err != nil {
+		return nil, fmt.Errorf("resolve schema: %w", err)
+	}
+	actual, _ := c.schemas.LoadOrStore(schema, resolved)
+	return actual.(*jsonschema.Resolved), nil
 }
@@ -2,14 +2,29 @@ package httpx
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"net/http/httptrace"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"syscall"
 	"testing"
 	"time"
 
+	"github.com/google/jsonschema-go/jsonschema"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 	"go.uber.org/zap/zaptest"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 func TestClient_DoJSON_Success(t *testing.T) {
@@ -222,6 +237,16 @@ func TestConfig_Validate(t *testing.T) {
 			wantError:     true,
 			expectedError: ErrInvalidTimeout,
 		},
+		{
+			name: "negative ErrorBodyLimit",
+			cfg: func() Config {
+				c := DefaultConfig()
+				c.ErrorBodyLimit = -1
+				return c
+			}(),
+			wantError:     true,
+			expectedError: ErrInvalidErrorBodyLimit,
+		},
 		{
 			name: "negative MaxRetries",
 			cfg: Config{
@@ -290,3 +315,625 @@ func TestConfig_Validate(t *testing.T) {
 		})
 	}
 }
+
+func TestClient_DisableKeepAlives(t *testing.T) {
+	tests := []struct {
+		name              string
+		disableKeepAlives bool
+		wantReused        bool
+	}{
+		{
+			name:              "keep-alives enabled reuses connections",
+			disableKeepAlives: false,
+			wantReused:        true,
+		},
+		{
+			name:              "keep-alives disabled uses fresh connections",
+			disableKeepAlives: true,
+			wantReused:        false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				if err := json.NewEncoder(w).Encode(map[string]string{"status": "ok"}); err != nil {
+					http.Error(w, err.Error(), http.StatusInternalServerError)
+				}
+			}))
+			defer server.Close()
+
+			logger := zaptest.NewLogger(t)
+			cfg := DefaultConfig()
+			cfg.DisableKeepAlives = tt.disableKeepAlives
+			client, err := NewWithConfig(cfg, logger)
+			if err != nil {
+				t.Fatalf("failed to create client: %v", err)
+			}
+
+			var newConns, reusedConns int
+			trace := &httptrace.ClientTrace{
+				GotConn: func(info httptrace.GotConnInfo) {
+					if info.Reused {
+						reusedConns++
+					} else {
+						newConns++
+					}
+				},
+			}
+			ctx := httptrace.WithClientTrace(context.Background(), trace)
+
+			const requests = 3
+			for i := 0; i < requests; i++ {
+				var result map[string]string
+				if err := client.Get(ctx, server.URL, &result); err != nil {
+					t.Fatalf("request %d failed: %v", i, err)
+				}
+			}
+
+			if tt.wantReused {
+				if reusedConns == 0 {
+					t.Errorf("expected connections to be reused, got %d new and %d reused", newConns, reusedConns)
+				}
+				return
+			}
+
+			if reusedConns != 0 || newConns != requests {
+				t.Errorf("expected %d new connections and none reused, got %d new and %d reused", requests, newConns, reusedConns)
+			}
+		})
+	}
+}
+
+func TestConfig_Validate_DisableKeepAlivesIgnoresPooling(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.DisableKeepAlives = true
+	cfg.MaxIdleConns = 0
+	cfg.MaxIdleConnsPerHost = 0
+	cfg.IdleConnTimeout = 0
+
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("expected pooling settings to be ignored when keep-alives are disabled, got %v", err)
+	}
+}
+
+func TestClient_GetValidated(t *testing.T) {
+	schema := &jsonschema.Schema{
+		Type: "object",
+		Properties: map[string]*jsonschema.Schema{
+			"name":  {Type: "string"},
+			"count": {Type: "integer"},
+		},
+		Required: []string{"name", "count"},
+	}
+
+	tests := []struct {
+		name       string
+		body       string
+		wantSchema bool
+	}{
+		{
+			name:       "conforming response",
+			body:       `{"name":"widget","count":3}`,
+			wantSchema: false,
+		},
+		{
+			name:       "missing required field",
+			body:       `{"name":"widget"}`,
+			wantSchema: true,
+		},
+		{
+			name:       "wrong field type",
+			body:       `{"name":"widget","count":"three"}`,
+			wantSchema: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			logger := zaptest.NewLogger(t)
+			client, err := New(logger)
+			if err != nil {
+				t.Fatalf("failed to create client: %v", err)
+			}
+
+			var result struct {
+				Name  string `json:"name"`
+				Count int    `json:"count"`
+			}
+			err = client.GetValidated(context.Background(), server.URL, schema, &result)
+
+			if !tt.wantSchema {
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				if result.Name != "widget" || result.Count != 3 {
+					t.Errorf("unexpected decoded result: %+v", result)
+				}
+				return
+			}
+
+			var schemaErr *SchemaValidationError
+			if !errors.As(err, &schemaErr) {
+				t.Fatalf("expected SchemaValidationError, got %T: %v", err, err)
+			}
+			if schemaErr.URL != server.URL {
+				t.Errorf("expected URL %q, got %q", server.URL, schemaErr.URL)
+			}
+			if result.Name != "" {
+				t.Errorf("expected result to be untouched on validation failure, got %+v", result)
+			}
+		})
+	}
+}
+
+func TestClient_GetValidated_NilSchema(t *testing.T) {
+	var requests atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client, err := New(zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	var result map[string]any
+	if err := client.GetValidated(context.Background(), server.URL, nil, &result); !errors.Is(err, ErrNilSchema) {
+		t.Fatalf("expected ErrNilSchema, got %v", err)
+	}
+	if n := requests.Load(); n != 0 {
+		t.Errorf("expected no request to be sent, got %d", n)
+	}
+}
+
+func TestClient_GetValidated_ResolvesOnce(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"name":"widget"}`))
+	}))
+	defer server.Close()
+
+	client, err := New(zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	schema := &jsonschema.Schema{Type: "object"}
+	var result map[string]any
+	for i := range 2 {
+		if err := client.GetValidated(context.Background(), server.URL, schema, &result); err != nil {
+			t.Fatalf("call %d: expected no error, got %v", i, err)
+		}
+	}
+
+	count := 0
+	client.schemas.Range(func(_, _ any) bool {
+		count++
+		return true
+	})
+	if count != 1 {
+		t.Errorf("expected one resolved schema cached, got %d", count)
+	}
+}
+
+func TestClient_WithMaxResponseSize(t *testing.T) {
+	// ~64KB payload, well under the default limit
+	payload := map[string]string{"data": strings.Repeat("x", 64*1024)}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(payload); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	logger := zaptest.NewLogger(t)
+	client, err := New(logger)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		opts    []RequestOption
+		wantErr bool
+	}{
+		{name: "config default", wantErr: false},
+		{name: "larger override", opts: []RequestOption{WithMaxResponseSize(1024 * 1024)}, wantErr: false},
+		{name: "small override", opts: []RequestOption{WithMaxResponseSize(1024)}, wantErr: true},
+		{name: "non-positive override ignored", opts: []RequestOption{WithMaxResponseSize(0)}, wantErr: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var result map[string]string
+			err := client.Get(context.Background(), server.URL, &result, tt.opts...)
+
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				if len(result["data"]) != len(payload["data"]) {
+					t.Errorf("expected %d bytes of data, got %d", len(payload["data"]), len(result["data"]))
+				}
+				return
+			}
+
+			var maxErr *http.MaxBytesError
+			if !errors.As(err, &maxErr) {
+				t.Fatalf("expected MaxBytesError, got %v", err)
+			}
+			if maxErr.Limit != 1024 {
+				t.Errorf("expected limit 1024, got %d", maxErr.Limit)
+			}
+		})
+	}
+}
+
+func TestClient_ErrorBodyLimit(t *testing.T) {
+	// 1MB error page, far over any error body limit
+	body := strings.Repeat("e", 1024*1024)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = io.WriteString(w, r.URL.Query().Get("prefix")+body)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		limit    int64
+		prefix   string
+		wantBody string
+	}{
+		{name: "default limit", wantBody: body[:DefaultErrorBodyLimit] + "... (truncated)"},
+		{name: "custom limit", limit: 10, wantBody: "eeeeeeeeee... (truncated)"},
+		// "é" is two bytes, so a 9-byte limit splits the fifth one
+		{name: "split rune dropped", limit: 9, prefix: "ééééé", wantBody: "éééé... (truncated)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.MaxRetries = 0
+			if tt.limit != 0 {
+				cfg.ErrorBodyLimit = tt.limit
+			}
+			client, err := NewWithConfig(cfg, zaptest.NewLogger(t))
+			if err != nil {
+				t.Fatalf("failed to create client: %v", err)
+			}
+
+			err = client.Get(context.Background(), server.URL+"?prefix="+url.QueryEscape(tt.prefix), nil)
+			var httpErr *HTTPError
+			if !errors.As(err, &httpErr) {
+				t.Fatalf("expected *HTTPError, got %T: %v", err, err)
+			}
+			if httpErr.Body != tt.wantBody {
+				t.Errorf("expected %d byte body ending %q, got %d bytes ending %q",
+					len(tt.wantBody), tt.wantBody[max(0, len(tt.wantBody)-24):],
+					len(httpErr.Body), httpErr.Body[max(0, len(httpErr.Body)-24):])
+			}
+			if len(err.Error()) > DefaultErrorBodyLimit+256 {
+				t.Errorf("expected a bounded error message, got %d bytes", len(err.Error()))
+			}
+		})
+	}
+}
+
+func TestClient_PermanentTLSErrorNotRetried(t *testing.T) {
+	var connections atomic.Int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			connections.Add(1)
+		}
+	}
+	// The failed handshakes are expected; keep them out of the test output
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.StartTLS()
+	defer server.Close()
+
+	logger := zaptest.NewLogger(t)
+	cfg := DefaultConfig()
+	cfg.MaxRetries = 3
+	cfg.InitialInterval = time.Millisecond
+	client, err := NewWithConfig(cfg, logger)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	// The client does not trust the test server's self-signed certificate
+	var result map[string]any
+	err = client.Get(context.Background(), server.URL, &result)
+
+	var certErr *tls.CertificateVerificationError
+	if !errors.As(err, &certErr) {
+		t.Fatalf("expected certificate verification error, got %v", err)
+	}
+	if got := connections.Load(); got != 1 {
+		t.Errorf("expected 1 connection attempt, got %d", got)
+	}
+}
+
+func TestDefaultRetryableError(t *testing.T) {
+	tests := []struct {
+		err  error
+		name string
+		want bool
+	}{
+		{name: "connection reset", err: syscall.ECONNRESET, want: true},
+		{name: "dns timeout", err: &net.DNSError{Err: "timeout", IsTimeout: true}, want: true},
+		{name: "dns not found", err: &net.DNSError{Err: "no such host", IsNotFound: true}, want: false},
+		{name: "unknown authority", err: fmt.Errorf("wrapped: %w", x509.UnknownAuthorityError{}), want: false},
+		{name: "hostname mismatch", err: x509.HostnameError{Host: "example.com"}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DefaultRetryableError(tt.err); got != tt.want {
+				t.Errorf("DefaultRetryableError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_CustomRetryableError(t *testing.T) {
+	// Grab a free port, then close it so connections are refused
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	addr := ln.Addr().String()
+	_ = ln.Close()
+
+	var calls atomic.Int32
+	logger := zaptest.NewLogger(t)
+	cfg := DefaultConfig()
+	cfg.MaxRetries = 3
+	cfg.InitialInterval = time.Millisecond
+	cfg.RetryableError = func(err error) bool {
+		calls.Add(1)
+		return false
+	}
+	client, err := NewWithConfig(cfg, logger)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	var result map[string]any
+	if err := client.Get(context.Background(), "http://"+addr, &result); err == nil {
+		t.Fatal("expected error but got nil")
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("expected the predicate to stop retries after 1 attempt, got %d", got)
+	}
+}
+
+// countConnReuse returns a context that records whether each request reused a connection.
+func countConnReuse(newConns, reusedConns *int) context.Context {
+	return httptrace.WithClientTrace(context.Background(), &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			if info.Reused {
+				*reusedConns++
+			} else {
+				*newConns++
+			}
+		},
+	})
+}
+
+func TestDrainAndClose_ReusesConnection(t *testing.T) {
+	errorBody := strings.Repeat("e", 32*1024)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, errorBody, http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	httpClient := &http.Client{Transport: &http.Transport{}}
+	defer httpClient.CloseIdleConnections()
+
+	var newConns, reusedConns int
+	ctx := countConnReuse(&newConns, &reusedConns)
+
+	// Leave the error body unread, as a caller ignoring it would
+	for i := 0; i < 2; i++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+		if err != nil {
+			t.Fatalf("failed to create request: %v", err)
+		}
+		resp, err := httpClient.Do(req)
+		if err != nil {
+			t.Fatalf("request %d failed: %v", i, err)
+		}
+		if err := DrainAndClose(resp); err != nil {
+			t.Errorf("DrainAndClose failed: %v", err)
+		}
+	}
+
+	if reusedConns != 1 {
+		t.Errorf("expected the second request to reuse the connection, got %d new and %d reused", newConns, reusedConns)
+	}
+}
+
+func TestDrainAndClose_NilResponse(t *testing.T) {
+	if err := DrainAndClose(nil); err != nil {
+		t.Errorf("expected nil error, got %v", err)
+	}
+}
+
+func TestClient_DoJSON_ReusesConnectionAfterTrailingData(t *testing.T) {
+	// Trailing whitespace after the JSON value is left unread by the decoder
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"status":"ok"}`+strings.Repeat(" ", 16*1024))
+	}))
+	defer server.Close()
+
+	client, err := New(zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	var newConns, reusedConns int
+	ctx := countConnReuse(&newConns, &reusedConns)
+	for i := 0; i < 2; i++ {
+		var result map[string]string
+		if err := client.Get(ctx, server.URL, &result); err != nil {
+			t.Fatalf("request %d failed: %v", i, err)
+		}
+	}
+
+	if reusedConns != 1 {
+		t.Errorf("expected the second request to reuse the connection, got %d new and %d reused", newConns, reusedConns)
+	}
+}
+
+func TestClient_DoJSON_DrainsUpToMaxResponseSize(t *testing.T) {
+	// Beyond what DrainAndClose and the transport's own post-close drain read,
+	// yet within MaxResponseSize
+	trailing := strings.Repeat(" ", 4*maxDrainSize)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"status":"ok"}`+trailing)
+	}))
+	defer server.Close()
+
+	client, err := New(zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	var newConns, reusedConns int
+	ctx := countConnReuse(&newConns, &reusedConns)
+	for i := 0; i < 2; i++ {
+		var result map[string]string
+		if err := client.Get(ctx, server.URL, &result); err != nil {
+			t.Fatalf("request %d failed: %v", i, err)
+		}
+	}
+
+	if reusedConns != 1 {
+		t.Errorf("expected the second request to reuse the connection, got %d new and %d reused", newConns, reusedConns)
+	}
+}
+
+func TestClient_DoJSON_ReportsAttempts(t *testing.T) {
+	tests := []struct {
+		name         string
+		failures     int32
+		status       int
+		maxRetries   int
+		wantAttempts int
+	}{
+		{
+			name:         "retries exhausted on retryable status",
+			failures:     10,
+			status:       http.StatusServiceUnavailable,
+			maxRetries:   2,
+			wantAttempts: 3,
+		},
+		{
+			name:         "permanent status after retries",
+			failures:     2,
+			status:       http.StatusNotFound,
+			maxRetries:   3,
+			wantAttempts: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if calls.Add(1) <= tt.failures {
+					http.Error(w, "upstream failure", tt.status)
+					return
+				}
+				_ = json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+			}))
+			defer server.Close()
+
+			core, logs := observer.New(zapcore.WarnLevel)
+			cfg := DefaultConfig()
+			cfg.MaxRetries = tt.maxRetries
+			cfg.InitialInterval = time.Millisecond
+			cfg.MaxInterval = 5 * time.Millisecond
+			client, err := NewWithConfig(cfg, zap.New(core))
+			if err != nil {
+				t.Fatalf("failed to create client: %v", err)
+			}
+
+			var result map[string]string
+			err = client.Get(context.Background(), server.URL, &result)
+
+			var httpErr *HTTPError
+			if !errors.As(err, &httpErr) {
+				t.Fatalf("expected *HTTPError, got %T: %v", err, err)
+			}
+			if httpErr.Attempts != tt.wantAttempts {
+				t.Errorf("expected %d attempts, got %d", tt.wantAttempts, httpErr.Attempts)
+			}
+			if httpErr.StatusCode != tt.status {
+				t.Errorf("expected status %d, got %d", tt.status, httpErr.StatusCode)
+			}
+			if got := int(calls.Load()); got != tt.wantAttempts {
+				t.Errorf("server saw %d requests, want %d", got, tt.wantAttempts)
+			}
+
+			entries := logs.FilterMessage("http request failed after retries").All()
+			if len(entries) != 1 {
+				t.Fatalf("expected 1 failure log, got %d", len(entries))
+			}
+			fields := entries[0].ContextMap()
+			if fields["attempts"] != int64(tt.wantAttempts) {
+				t.Errorf("expected attempts=%d in log, got %v", tt.wantAttempts, fields["attempts"])
+			}
+			if fields["last_status"] != int64(tt.status) {
+				t.Errorf("expected last_status=%d in log, got %v", tt.status, fields["last_status"])
+			}
+		})
+	}
+}
+
+func TestClient_DoJSON_RecoversAfterFailures(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= 2 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+	}))
+	defer server.Close()
+
+	core, logs := observer.New(zapcore.WarnLevel)
+	cfg := DefaultConfig()
+	cfg.InitialInterval = time.Millisecond
+	cfg.MaxInterval = 5 * time.Millisecond
+	client, err := NewWithConfig(cfg, zap.New(core))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	var result map[string]string
+	if err := client.Get(context.Background(), server.URL, &result); err != nil {
+		t.Fatalf("expected success after retries, got %v", err)
+	}
+	if got := calls.Load(); got != 3 {
+		t.Errorf("expected 3 requests, got %d", got)
+	}
+	if n := logs.FilterMessage("http request failed after retries").Len(); n != 0 {
+		t.Errorf("expected no failure log, got %d", n)
+	}
+}
@@ -0,0 +1,45 @@
+package httpx
+
+import (
+	"net/http"
+)
+
+// IdempotencyKeyHeader is the header WithIdempotencyKey sets, which servers
+// supporting it use to deduplicate retried requests.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// WithIdempotent asserts that a request is safe to send more than once, so
+// DoJSON retries it even though its method is not GET, HEAD, OPTIONS, or
+// TRACE. Use it for operations such as a PUT replacing a resource, where
+// repeating the request has the same effect as sending it once.
+func WithIdempotent() RequestOption {
+	return func(o *requestOptions) {
+		o.idempotent = true
+	}
+}
+
+// WithIdempotencyKey sets the Idempotency-Key header of a request to key and
+// allows DoJSON to retry it, relying on the server to apply a request with the
+// same key only once. Use a key unique to the operation, such as a UUID
+// generated by the caller. An empty key is ignored.
+func WithIdempotencyKey(key string) RequestOption {
+	return func(o *requestOptions) {
+		if key != "" {
+			o.idempotencyKey = key
+		}
+	}
+}
+
+// retryable reports whether req may be sent again after a failed attempt:
+// its method must be safe, or the caller must have asserted idempotency or
+// supplied an idempotency key, and a body must be replayable with GetBody.
+func (o requestOptions) retryable(req *http.Request) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+	switch req.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return o.idempotent || o.idempotencyKey != "" || req.Header.Get(IdempotencyKeyHeader) != ""
+}
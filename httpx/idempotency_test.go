@@ -0,0 +1,117 @@
+package httpx
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zaptest"
+)
+
+func TestClient_DoJSON_Idempotency(t *testing.T) {
+	tests := []struct {
+		name         string
+		method       string
+		body         func() io.Reader
+		opts         []RequestOption
+		header       string
+		wantAttempts int32
+		wantKey      string
+	}{
+		{
+			name:         "GET retries",
+			method:       http.MethodGet,
+			wantAttempts: 3,
+		},
+		{
+			name:         "POST without flag does not retry",
+			method:       http.MethodPost,
+			body:         func() io.Reader { return strings.NewReader(`{"n":1}`) },
+			wantAttempts: 1,
+		},
+		{
+			name:         "POST with WithIdempotent retries",
+			method:       http.MethodPost,
+			body:         func() io.Reader { return strings.NewReader(`{"n":1}`) },
+			opts:         []RequestOption{WithIdempotent()},
+			wantAttempts: 3,
+		},
+		{
+			name:         "POST with WithIdempotencyKey retries",
+			method:       http.MethodPost,
+			body:         func() io.Reader { return strings.NewReader(`{"n":1}`) },
+			opts:         []RequestOption{WithIdempotencyKey("op-123")},
+			wantAttempts: 3,
+			wantKey:      "op-123",
+		},
+		{
+			name:         "POST with Idempotency-Key header retries",
+			method:       http.MethodPost,
+			body:         func() io.Reader { return strings.NewReader(`{"n":1}`) },
+			header:       "op-456",
+			wantAttempts: 3,
+			wantKey:      "op-456",
+		},
+		{
+			name:   "POST with unreplayable body does not retry",
+			method: http.MethodPost,
+			// io.MultiReader hides the type, so http.NewRequest can't set GetBody
+			body:         func() io.Reader { return io.MultiReader(strings.NewReader(`{"n":1}`)) },
+			opts:         []RequestOption{WithIdempotent()},
+			wantAttempts: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				attempts.Add(1)
+				if tt.wantKey != "" && r.Header.Get(IdempotencyKeyHeader) != tt.wantKey {
+					t.Errorf("%s = %q, want %q", IdempotencyKeyHeader, r.Header.Get(IdempotencyKeyHeader), tt.wantKey)
+				}
+				if tt.body != nil {
+					if body, _ := io.ReadAll(r.Body); string(body) != `{"n":1}` {
+						t.Errorf("attempt %d body = %q, want the original body", attempts.Load(), body)
+					}
+				}
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}))
+			defer server.Close()
+
+			cfg := DefaultConfig()
+			cfg.MaxRetries = 2
+			cfg.InitialInterval = time.Millisecond
+			cfg.MaxInterval = time.Millisecond
+			client, err := NewWithConfig(cfg, zaptest.NewLogger(t))
+			if err != nil {
+				t.Fatalf("failed to create client: %v", err)
+			}
+
+			var body io.Reader
+			if tt.body != nil {
+				body = tt.body()
+			}
+			req, err := http.NewRequestWithContext(context.Background(), tt.method, server.URL, body)
+			if err != nil {
+				t.Fatalf("failed to create request: %v", err)
+			}
+			if tt.header != "" {
+				req.Header.Set(IdempotencyKeyHeader, tt.header)
+			}
+
+			var result map[string]any
+			if err := client.DoJSON(context.Background(), req, &result, tt.opts...); err == nil {
+				t.Fatal("expected error from 503 responses")
+			}
+			if got := attempts.Load(); got != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", got, tt.wantAttempts)
+			}
+		})
+	}
+}
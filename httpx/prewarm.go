@@ -0,0 +1,71 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Prewarm opens a connection to the host of each of urls and leaves it in the
+// client's pool, so the first real request to the host skips DNS resolution
+// and TCP and TLS setup. Call it while the server starts up, for example
+// before RunWithTransport, with the upstream APIs the tools call.
+//
+// Each URL is warmed with a single HEAD request, sent concurrently and without
+// retries; any response counts as success, whatever its status. Errors
+// reaching a host are joined into the returned error, and the other hosts are
+// still warmed. Connections stay pooled for Config.IdleConnTimeout, and at most
+// Config.MaxIdleConnsPerHost per host. With Config.DisableKeepAlives set,
+// connections can't be kept, so Prewarm does nothing.
+func (c *Client) Prewarm(ctx context.Context, urls ...string) error {
+	if c.config.DisableKeepAlives {
+		c.log().Debug("http keep-alives disabled, skipping connection prewarm")
+		return nil
+	}
+
+	errs := make([]error, len(urls))
+	var wg sync.WaitGroup
+	for i, url := range urls {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = c.prewarm(ctx, url)
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// prewarm sends one HEAD request to url and returns its connection to the pool.
+func (c *Client) prewarm(ctx context.Context, url string) error {
+	start := time.Now()
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return fmt.Errorf("prewarm %s: create request: %w", url, err)
+	}
+	userAgent := c.config.UserAgent
+	if userAgent == "" {
+		userAgent = "hypermcp"
+	}
+	req.Header.Set("User-Agent", userAgent)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("prewarm %s: %w", url, err)
+	}
+	if err := drainAndClose(resp, c.config.MaxResponseSize); err != nil {
+		c.log().Warn("failed to close response body", zap.Error(err))
+	}
+
+	c.log().Debug("prewarmed connection",
+		zap.String("host", req.URL.Host),
+		zap.Int("status", resp.StatusCode),
+		zap.Duration("duration", time.Since(start)),
+	)
+	return nil
+}
@@ -0,0 +1,110 @@
+package httpx
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"go.uber.org/zap/zaptest"
+)
+
+func TestClient_Prewarm(t *testing.T) {
+	var newConns, heads atomic.Int64
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			heads.Add(1)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			newConns.Add(1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	client, err := New(zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	if err := client.Prewarm(context.Background(), server.URL); err != nil {
+		t.Fatalf("Prewarm() error = %v", err)
+	}
+	if heads.Load() != 1 || newConns.Load() != 1 {
+		t.Fatalf("expected one HEAD request on one connection, got %d requests on %d connections", heads.Load(), newConns.Load())
+	}
+
+	var result map[string]bool
+	var timing Timing
+	if err := client.Get(context.Background(), server.URL, &result, WithTiming(&timing)); err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if !timing.ReusedConn {
+		t.Error("expected the first request to reuse the prewarmed connection")
+	}
+	if got := newConns.Load(); got != 1 {
+		t.Errorf("expected no new connection after prewarming, got %d connections", got)
+	}
+}
+
+func TestClient_Prewarm_Errors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	// Reserve a port nothing listens on
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	closed := "http://" + ln.Addr().String()
+	_ = ln.Close()
+
+	client, err := New(zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	// A 404 still leaves a warm connection, so only the closed port fails
+	err = client.Prewarm(context.Background(), server.URL, closed, "://bad")
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	msg := err.Error()
+	if strings.Contains(msg, server.URL) {
+		t.Errorf("error %q reports the reachable host", msg)
+	}
+	if !strings.Contains(msg, closed) || !strings.Contains(msg, "://bad") {
+		t.Errorf("error %q does not report every failed URL", msg)
+	}
+}
+
+func TestClient_Prewarm_DisableKeepAlives(t *testing.T) {
+	var requests atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+	}))
+	defer server.Close()
+
+	cfg := DefaultConfig()
+	cfg.DisableKeepAlives = true
+	client, err := NewWithConfig(cfg, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	if err := client.Prewarm(context.Background(), server.URL); err != nil {
+		t.Fatalf("Prewarm() error = %v", err)
+	}
+	if requests.Load() != 0 {
+		t.Errorf("expected no requests without keep-alives, got %d", requests.Load())
+	}
+}
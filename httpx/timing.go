@@ -0,0 +1,140 @@
+package httpx
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Timing breaks down the latency of a single request attempt, separating
+// connection setup from the time the server took to respond.
+//
+// Phases that did not happen are zero: DNS is zero for IP literals, and DNS,
+// Connect, and TLSHandshake are all zero when a pooled connection was reused.
+type Timing struct {
+	// DNS is the time spent resolving the host name.
+	DNS time.Duration
+
+	// Connect is the time spent establishing the TCP connection.
+	Connect time.Duration
+
+	// TLSHandshake is the time spent on the TLS handshake.
+	TLSHandshake time.Duration
+
+	// TimeToFirstByte is the time from the request being fully written to the
+	// first byte of the response arriving: the server's think time plus one
+	// network round trip.
+	TimeToFirstByte time.Duration
+
+	// Total is the time from the start of the attempt until the response body
+	// was read, or until the attempt failed.
+	Total time.Duration
+
+	// ReusedConn reports whether the attempt used a pooled connection.
+	ReusedConn bool
+}
+
+// WithTiming records the Timing of the request's final attempt in t.
+//
+// t is written before DoJSON returns, whether or not the request succeeded.
+// To observe every attempt of every request, set Config.OnTiming instead.
+func WithTiming(t *Timing) RequestOption {
+	return func(o *requestOptions) {
+		o.timing = t
+	}
+}
+
+// timingTrace collects the httptrace events of one attempt into a Timing.
+//
+// Hooks may fire from the transport's dialing goroutines, so the fields are
+// guarded by mu.
+type timingTrace struct {
+	mu           sync.Mutex
+	start        time.Time
+	dnsStart     time.Time
+	connectStart time.Time
+	tlsStart     time.Time
+	wroteRequest time.Time
+	timing       Timing
+}
+
+func newTimingTrace() *timingTrace {
+	return &timingTrace{start: time.Now()}
+}
+
+// withContext returns ctx with the trace's hooks installed, composed with any
+// ClientTrace already present in ctx.
+func (t *timingTrace) withContext(ctx context.Context) context.Context {
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			t.mark(&t.dnsStart)
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			t.since(&t.timing.DNS, &t.dnsStart)
+		},
+		ConnectStart: func(string, string) {
+			t.mark(&t.connectStart)
+		},
+		ConnectDone: func(string, string, error) {
+			t.since(&t.timing.Connect, &t.connectStart)
+		},
+		TLSHandshakeStart: func() {
+			t.mark(&t.tlsStart)
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			t.since(&t.timing.TLSHandshake, &t.tlsStart)
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.mu.Lock()
+			t.timing.ReusedConn = info.Reused
+			t.mu.Unlock()
+		},
+		WroteRequest: func(httptrace.WroteRequestInfo) {
+			t.mark(&t.wroteRequest)
+		},
+		GotFirstResponseByte: func() {
+			t.since(&t.timing.TimeToFirstByte, &t.wroteRequest)
+		},
+	})
+}
+
+// mark records the current time in *at.
+func (t *timingTrace) mark(at *time.Time) {
+	t.mu.Lock()
+	*at = time.Now()
+	t.mu.Unlock()
+}
+
+// since stores the time elapsed since *start in *d, if *start was recorded.
+func (t *timingTrace) since(d *time.Duration, start *time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !start.IsZero() {
+		*d = time.Since(*start)
+	}
+}
+
+// finish returns the collected Timing with Total set.
+func (t *timingTrace) finish() Timing {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	timing := t.timing
+	timing.Total = time.Since(t.start)
+	return timing
+}
+
+// timingFields returns the zap fields logging timing.
+func timingFields(timing Timing) []zap.Field {
+	return []zap.Field{
+		zap.Duration("dns", timing.DNS),
+		zap.Duration("connect", timing.Connect),
+		zap.Duration("tls_handshake", timing.TLSHandshake),
+		zap.Duration("ttfb", timing.TimeToFirstByte),
+		zap.Duration("total", timing.Total),
+		zap.Bool("reused_conn", timing.ReusedConn),
+	}
+}
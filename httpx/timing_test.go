@@ -0,0 +1,131 @@
+package httpx
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestClient_WithTiming(t *testing.T) {
+	const thinkTime = 20 * time.Millisecond
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(thinkTime)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	client, err := New(zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	// Trust the test server's certificate, which is issued for example.com
+	tlsConfig := server.Client().Transport.(*http.Transport).TLSClientConfig.Clone()
+	tlsConfig.ServerName = "example.com"
+	client.client.Transport.(*http.Transport).TLSClientConfig = tlsConfig
+
+	// Keep the IP literal: how long resolving "localhost" takes depends on the
+	// host and can round to zero
+	url := server.URL
+
+	var result map[string]bool
+	var first Timing
+	if err := client.Get(context.Background(), url, &result, WithTiming(&first)); err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if first.ReusedConn {
+		t.Error("expected a new connection for the first request")
+	}
+	if first.Connect <= 0 || first.TLSHandshake <= 0 {
+		t.Errorf("expected connection setup phases to be recorded, got %+v", first)
+	}
+	if first.DNS != 0 {
+		t.Errorf("expected no DNS phase for an IP literal, got %v", first.DNS)
+	}
+	if first.TimeToFirstByte < thinkTime {
+		t.Errorf("expected time to first byte >= %v, got %v", thinkTime, first.TimeToFirstByte)
+	}
+	if setup := first.DNS + first.Connect + first.TLSHandshake + first.TimeToFirstByte; first.Total < setup {
+		t.Errorf("expected total %v to cover the phases (%v)", first.Total, setup)
+	}
+
+	var second Timing
+	if err := client.Get(context.Background(), url, &result, WithTiming(&second)); err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if !second.ReusedConn {
+		t.Error("expected the second request to reuse the connection")
+	}
+	if second.DNS != 0 || second.Connect != 0 || second.TLSHandshake != 0 {
+		t.Errorf("expected no connection setup on a reused connection, got %+v", second)
+	}
+	if second.TimeToFirstByte < thinkTime {
+		t.Errorf("expected time to first byte >= %v, got %v", thinkTime, second.TimeToFirstByte)
+	}
+}
+
+func TestClient_OnTiming(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	var (
+		mu      sync.Mutex
+		timings []Timing
+	)
+	cfg := DefaultConfig()
+	cfg.InitialInterval = time.Millisecond
+	cfg.OnTiming = func(req *http.Request, timing Timing) {
+		mu.Lock()
+		defer mu.Unlock()
+		timings = append(timings, timing)
+	}
+	core, logs := observer.New(zapcore.DebugLevel)
+	client, err := NewWithConfig(cfg, zap.New(core))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	var result map[string]any
+	var final Timing
+	if err := client.Get(context.Background(), server.URL, &result, WithTiming(&final)); err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(timings) != 2 {
+		t.Fatalf("expected OnTiming for each of 2 attempts, got %d", len(timings))
+	}
+	for i, timing := range timings {
+		if timing.TimeToFirstByte <= 0 || timing.Total <= 0 {
+			t.Errorf("attempt %d: expected populated timing, got %+v", i+1, timing)
+		}
+	}
+	if final != timings[1] {
+		t.Errorf("expected WithTiming to hold the final attempt %+v, got %+v", timings[1], final)
+	}
+
+	entries := logs.FilterMessage("http request timing").All()
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 timing log entries, got %d", len(entries))
+	}
+	if _, ok := entries[1].ContextMap()["ttfb"]; !ok {
+		t.Errorf("expected ttfb in timing log fields, got %v", entries[1].ContextMap())
+	}
+}
@@ -0,0 +1,171 @@
+package hypermcp
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap"
+)
+
+const (
+	// IdempotencyKeyMeta is the request "_meta" field consulted for an idempotency key.
+	IdempotencyKeyMeta = "idempotencyKey"
+
+	// IdempotencyKeyHeader is the HTTP header consulted for an idempotency key
+	// when the request arrived over an HTTP-based transport.
+	IdempotencyKeyHeader = "Idempotency-Key"
+)
+
+// IdempotencyKeyer can be implemented by a tool's input type to supply the
+// idempotency key from a field in the tool arguments.
+type IdempotencyKeyer interface {
+	IdempotencyKey() string
+}
+
+// idempotentResult is the cached outcome of a successful tool invocation.
+type idempotentResult[Out any] struct {
+	result *mcp.CallToolResult
+	output Out
+}
+
+// idempotentCall is a keyed invocation in progress, shared by the duplicate
+// calls that arrive before it finishes.
+type idempotentCall[Out any] struct {
+	done   chan struct{}
+	result *mcp.CallToolResult
+	output Out
+	err    error
+}
+
+// AddIdempotentTool registers a tool whose results are deduplicated by idempotency key.
+//
+// MCP clients may resend a tool call over a flaky connection. When a call carries an
+// idempotency key, the first successful result is cached for window, and any repeat
+// call with the same key within that window returns the cached result instead of
+// executing the handler again. Failed invocations are not cached, so a retry after
+// an error runs the handler normally. Calls without a key are always executed.
+//
+// The key is read, in order of precedence, from:
+//  1. the input, if it implements IdempotencyKeyer
+//  2. the request's "_meta" field named by IdempotencyKeyMeta
+//  3. the HTTP header named by IdempotencyKeyHeader (HTTP-based transports only)
+//
+// Keys are scoped by tool name and session, so clients can't collide with, or
+// read, each other's results, and are bound to the input: a repeat call with
+// the same key but different arguments runs the handler. A duplicate arriving
+// while the first call is still running waits for it and shares its outcome,
+// error included. Results are stored in the server's cache.
+func AddIdempotentTool[In, Out any](s *Server, tool *mcp.Tool, window time.Duration, handler mcp.ToolHandlerFor[In, Out]) {
+	toolName := tool.Name
+
+	var (
+		mu       sync.Mutex
+		inFlight = make(map[string]*idempotentCall[Out])
+	)
+
+	wrapped := func(ctx context.Context, req *mcp.CallToolRequest, input In) (*mcp.CallToolResult, Out, error) {
+		key := idempotencyKey(req, input)
+		if key == "" {
+			return handler(ctx, req, input)
+		}
+
+		cacheKey := idempotencyCacheKey(toolName, req, key, input)
+		if cached, ok := s.cache.Get(cacheKey); ok {
+			if prev, ok := cached.(idempotentResult[Out]); ok {
+				s.log().Debug("returning cached idempotent result",
+					zap.String("tool", toolName),
+					zap.String("idempotency_key", key),
+				)
+				return copyResult(prev.result), prev.output, nil
+			}
+		}
+
+		mu.Lock()
+		if call, ok := inFlight[cacheKey]; ok {
+			mu.Unlock()
+			select {
+			case <-call.done:
+				return copyResult(call.result), call.output, call.err
+			case <-ctx.Done():
+				var zero Out
+				return nil, zero, ctx.Err()
+			}
+		}
+		call := &idempotentCall[Out]{done: make(chan struct{})}
+		inFlight[cacheKey] = call
+		mu.Unlock()
+
+		defer func() {
+			mu.Lock()
+			delete(inFlight, cacheKey)
+			mu.Unlock()
+			close(call.done)
+		}()
+
+		call.result, call.output, call.err = handler(ctx, req, input)
+		if call.err == nil {
+			// Wait for the write so an immediate retry already sees the result
+			s.cache.SetWait(cacheKey, idempotentResult[Out]{result: call.result, output: call.output}, window)
+		}
+		// Every caller gets its own copy, leaving call.result, which is
+		// shared and cached, untouched by the SDK
+		return copyResult(call.result), call.output, call.err
+	}
+
+	AddTool(s, tool, wrapped)
+}
+
+// idempotencyKey extracts the idempotency key for a tool call, or "" if none was supplied.
+func idempotencyKey[In any](req *mcp.CallToolRequest, input In) string {
+	if keyer, ok := any(input).(IdempotencyKeyer); ok {
+		if key := keyer.IdempotencyKey(); key != "" {
+			return key
+		}
+	}
+
+	if req == nil {
+		return ""
+	}
+
+	if req.Params != nil {
+		if key, ok := req.Params.Meta[IdempotencyKeyMeta].(string); ok && key != "" {
+			return key
+		}
+	}
+
+	if req.Extra != nil && req.Extra.Header != nil {
+		return req.Extra.Header.Get(IdempotencyKeyHeader)
+	}
+
+	return ""
+}
+
+// idempotencyCacheKey returns the cache key for a call of tool with the given
+// idempotency key: scoped by the calling session and bound to a hash of the
+// input.
+func idempotencyCacheKey[In any](tool string, req *mcp.CallToolRequest, key string, input In) string {
+	var session string
+	if req != nil && req.Session != nil {
+		// Sessions of transports without session IDs (stdio, in-memory)
+		// are told apart by identity
+		if session = req.Session.ID(); session == "" {
+			session = fmt.Sprintf("%p", req.Session)
+		}
+	}
+
+	h := sha256.New()
+	if err := json.NewEncoder(h).Encode(input); err != nil {
+		// Unencodable inputs fall back to the key alone
+		h.Reset()
+	}
+	digest := hex.EncodeToString(h.Sum(nil))
+
+	// Length-prefix the free-form parts so they can't run into each other
+	return fmt.Sprintf("idempotency:%d:%s:%d:%s:%d:%s:%s", len(tool), tool, len(session), session, len(key), key, digest)
+}
@@ -0,0 +1,250 @@
+package hypermcp
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap/zaptest"
+)
+
+type idempotentInput struct {
+	RequestID string `json:"request_id,omitempty"`
+	Amount    int    `json:"amount"`
+}
+
+func (in idempotentInput) IdempotencyKey() string {
+	return in.RequestID
+}
+
+type idempotentOutput struct {
+	Charged int `json:"charged"`
+}
+
+func newIdempotentTestServer(t *testing.T, calls *atomic.Int64) *Server {
+	t.Helper()
+
+	srv, err := New(Config{
+		Name:         "test-server",
+		Version:      "1.0.0",
+		CacheEnabled: false,
+	}, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+
+	AddIdempotentTool(srv, &mcp.Tool{
+		Name:        "charge",
+		Description: "Charges an amount",
+	}, time.Minute, func(ctx context.Context, req *mcp.CallToolRequest, input idempotentInput) (*mcp.CallToolResult, idempotentOutput, error) {
+		calls.Add(1)
+		return nil, idempotentOutput{Charged: input.Amount}, nil
+	})
+
+	return srv
+}
+
+func TestAddIdempotentTool_InputKey(t *testing.T) {
+	var calls atomic.Int64
+	srv := newIdempotentTestServer(t, &calls)
+	session := connectTestClient(t, srv)
+	ctx := context.Background()
+
+	params := &mcp.CallToolParams{
+		Name:      "charge",
+		Arguments: map[string]any{"request_id": "abc-123", "amount": 5},
+	}
+
+	for i := 0; i < 2; i++ {
+		res, err := session.CallTool(ctx, params)
+		if err != nil {
+			t.Fatalf("call %d failed: %v", i, err)
+		}
+		if res.IsError {
+			t.Fatalf("call %d returned tool error: %+v", i, res.Content)
+		}
+	}
+
+	if got := calls.Load(); got != 1 {
+		t.Errorf("expected handler to run once, ran %d times", got)
+	}
+}
+
+func TestAddIdempotentTool_MetaKey(t *testing.T) {
+	var calls atomic.Int64
+	srv := newIdempotentTestServer(t, &calls)
+	session := connectTestClient(t, srv)
+	ctx := context.Background()
+
+	params := &mcp.CallToolParams{
+		Meta:      mcp.Meta{IdempotencyKeyMeta: "meta-key"},
+		Name:      "charge",
+		Arguments: map[string]any{"amount": 5},
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := session.CallTool(ctx, params); err != nil {
+			t.Fatalf("call %d failed: %v", i, err)
+		}
+	}
+
+	if got := calls.Load(); got != 1 {
+		t.Errorf("expected handler to run once, ran %d times", got)
+	}
+}
+
+func TestAddIdempotentTool_DistinctOrMissingKeys(t *testing.T) {
+	var calls atomic.Int64
+	srv := newIdempotentTestServer(t, &calls)
+	session := connectTestClient(t, srv)
+	ctx := context.Background()
+
+	argsList := []map[string]any{
+		{"request_id": "first", "amount": 1},
+		{"request_id": "second", "amount": 1},
+		{"amount": 1},
+		{"amount": 1},
+	}
+
+	for i, args := range argsList {
+		if _, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "charge", Arguments: args}); err != nil {
+			t.Fatalf("call %d failed: %v", i, err)
+		}
+	}
+
+	if got := calls.Load(); got != int64(len(argsList)) {
+		t.Errorf("expected handler to run %d times, ran %d times", len(argsList), got)
+	}
+}
+
+func TestAddIdempotentTool_KeyBoundToInputAndSession(t *testing.T) {
+	var calls atomic.Int64
+	srv := newIdempotentTestServer(t, &calls)
+	first := connectTestClient(t, srv)
+	second := connectTestClient(t, srv)
+	ctx := context.Background()
+
+	sequence := []struct {
+		session *mcp.ClientSession
+		amount  int
+	}{
+		{first, 5},
+		{first, 5},  // duplicate: cached
+		{first, 50}, // same key, different input: runs
+		{second, 5}, // same key and input, other session: runs
+	}
+	for i, c := range sequence {
+		res, err := c.session.CallTool(ctx, &mcp.CallToolParams{
+			Name:      "charge",
+			Arguments: map[string]any{"request_id": "abc-123", "amount": c.amount},
+		})
+		if err != nil {
+			t.Fatalf("call %d failed: %v", i, err)
+		}
+		if got := res.StructuredContent.(map[string]any)["charged"]; got != float64(c.amount) {
+			t.Errorf("call %d charged %v, want %d", i, got, c.amount)
+		}
+	}
+
+	if got := calls.Load(); got != 3 {
+		t.Errorf("expected handler to run 3 times, ran %d times", got)
+	}
+}
+
+func TestAddIdempotentTool_SharesInFlightCall(t *testing.T) {
+	srv, err := New(Config{Name: "test-server", Version: "1.0.0"}, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+
+	var calls atomic.Int64
+	started := make(chan struct{})
+	release := make(chan struct{})
+	AddIdempotentTool(srv, &mcp.Tool{Name: "charge"}, time.Minute,
+		func(ctx context.Context, req *mcp.CallToolRequest, input idempotentInput) (*mcp.CallToolResult, idempotentOutput, error) {
+			if calls.Add(1) == 1 {
+				close(started)
+			}
+			<-release
+			return nil, idempotentOutput{Charged: input.Amount}, nil
+		})
+	session := connectTestClient(t, srv)
+
+	params := &mcp.CallToolParams{
+		Name:      "charge",
+		Arguments: map[string]any{"request_id": "abc-123", "amount": 5},
+	}
+	errs := make(chan error, 2)
+	go func() {
+		_, err := session.CallTool(context.Background(), params)
+		errs <- err
+	}()
+	<-started
+	go func() {
+		_, err := session.CallTool(context.Background(), params)
+		errs <- err
+	}()
+
+	// Give the duplicate time to arrive and join the running call
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	for range 2 {
+		if err := <-errs; err != nil {
+			t.Fatalf("call failed: %v", err)
+		}
+	}
+
+	if got := calls.Load(); got != 1 {
+		t.Errorf("expected handler to run once, ran %d times", got)
+	}
+}
+
+func TestAddIdempotentTool_CallersGetOwnResult(t *testing.T) {
+	srv, err := New(Config{Name: "test-server", Version: "1.0.0"}, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+
+	release := make(chan struct{})
+	AddIdempotentTool(srv, &mcp.Tool{Name: "charge"}, time.Minute,
+		func(ctx context.Context, req *mcp.CallToolRequest, input idempotentInput) (*mcp.CallToolResult, idempotentOutput, error) {
+			<-release
+			// The SDK fills in the content of the result it is handed
+			return &mcp.CallToolResult{}, idempotentOutput{Charged: input.Amount}, nil
+		})
+	session := connectTestClient(t, srv)
+
+	params := &mcp.CallToolParams{
+		Name:      "charge",
+		Arguments: map[string]any{"request_id": "abc-123", "amount": 5},
+	}
+	call := func() error {
+		result, err := session.CallTool(context.Background(), params)
+		if err == nil && len(result.Content) != 1 {
+			err = fmt.Errorf("got %d content blocks, want 1", len(result.Content))
+		}
+		return err
+	}
+
+	// Concurrent duplicates share the running call, later ones the cached result
+	const callers = 4
+	errs := make(chan error, callers)
+	for range callers {
+		go func() { errs <- call() }()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	for range callers {
+		if err := <-errs; err != nil {
+			t.Fatalf("call failed: %v", err)
+		}
+	}
+	for range 2 {
+		if err := call(); err != nil {
+			t.Fatalf("repeat call failed: %v", err)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package hypermcp
+
+import (
+	"context"
+	"iter"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap"
+)
+
+// idleSessions closes client sessions that send no messages for a while.
+//
+// Every incoming message counts as activity. While a session has requests in
+// flight its timer is paused, so a slow tool call doesn't get its own session
+// closed underneath it. Sessions that connect without sending anything are
+// found by watch.
+type idleSessions struct {
+	timeout time.Duration
+	logger  *atomic.Pointer[zap.Logger]
+
+	mu       sync.Mutex
+	sessions map[*mcp.ServerSession]*idleSession
+}
+
+// idleSession is the idle state of one session. Guarded by idleSessions.mu.
+type idleSession struct {
+	timer    *time.Timer
+	inFlight int
+}
+
+func newIdleSessions(timeout time.Duration, logger *atomic.Pointer[zap.Logger]) *idleSessions {
+	return &idleSessions{
+		timeout:  timeout,
+		logger:   logger,
+		sessions: make(map[*mcp.ServerSession]*idleSession),
+	}
+}
+
+// middleware tracks activity on every incoming message.
+func (i *idleSessions) middleware() mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			ss, ok := req.GetSession().(*mcp.ServerSession)
+			if !ok || ss == nil {
+				return next(ctx, method, req)
+			}
+
+			i.begin(ss)
+			defer i.end(ss)
+			return next(ctx, method, req)
+		}
+	}
+}
+
+// begin records the start of a request, pausing the session's idle timer.
+func (i *idleSessions) begin(ss *mcp.ServerSession) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	state := i.track(ss)
+	state.inFlight++
+	state.timer.Stop()
+}
+
+// track returns the state of ss, starting its idle timer if it isn't tracked
+// yet. i.mu must be held.
+func (i *idleSessions) track(ss *mcp.ServerSession) *idleSession {
+	state, ok := i.sessions[ss]
+	if !ok {
+		state = &idleSession{timer: time.AfterFunc(i.timeout, func() { i.expire(ss) })}
+		i.sessions[ss] = state
+
+		// Forget sessions that end on their own
+		go func() {
+			_ = ss.Wait()
+			i.forget(ss)
+		}()
+	}
+	return state
+}
+
+// watch starts the idle timer of every session listed by sessions that has
+// not sent a message yet, checking four times per timeout until ctx is done.
+// The SDK offers no hook for a session connecting, so a session that never
+// sends anything is closed within a quarter of the timeout of its expiry.
+func (i *idleSessions) watch(ctx context.Context, sessions func() iter.Seq[*mcp.ServerSession]) {
+	ticker := time.NewTicker(i.timeout / 4)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			i.mu.Lock()
+			for ss := range sessions() {
+				i.track(ss)
+			}
+			i.mu.Unlock()
+		}
+	}
+}
+
+// end records the end of a request, restarting the idle timer once the
+// session has nothing in flight.
+func (i *idleSessions) end(ss *mcp.ServerSession) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	state, ok := i.sessions[ss]
+	if !ok {
+		return
+	}
+	state.inFlight--
+	if state.inFlight == 0 {
+		state.timer.Reset(i.timeout)
+	}
+}
+
+// expire closes a session whose idle timer fired.
+func (i *idleSessions) expire(ss *mcp.ServerSession) {
+	i.mu.Lock()
+	state, ok := i.sessions[ss]
+	if !ok || state.inFlight > 0 {
+		// Forgotten, or a request began as the timer fired
+		i.mu.Unlock()
+		return
+	}
+	delete(i.sessions, ss)
+	i.mu.Unlock()
+
+	i.logger.Load().Info("closing idle session",
+		zap.String("session_id", ss.ID()),
+		zap.Duration("idle_timeout", i.timeout),
+	)
+	if err := ss.Close(); err != nil {
+		i.logger.Load().Debug("failed to close idle session", zap.Error(err))
+	}
+}
+
+// forget stops tracking a session that has ended.
+func (i *idleSessions) forget(ss *mcp.ServerSession) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if state, ok := i.sessions[ss]; ok {
+		state.timer.Stop()
+		delete(i.sessions, ss)
+	}
+}
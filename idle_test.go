@@ -0,0 +1,163 @@
+package hypermcp
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap/zaptest"
+)
+
+const testIdleTimeout = 100 * time.Millisecond
+
+func newIdleTestServer(t *testing.T) *Server {
+	t.Helper()
+
+	srv, err := New(Config{
+		Name:      "test-server",
+		Version:   "1.0.0",
+		Transport: TransportConfig{IdleTimeout: testIdleTimeout},
+	}, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	return srv
+}
+
+// waitForSessionClosed fails the test unless the client session ends soon.
+func waitForSessionClosed(t *testing.T, session *mcp.ClientSession) {
+	t.Helper()
+
+	done := make(chan struct{})
+	go func() {
+		_ = session.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected the idle session to be closed")
+	}
+}
+
+func TestIdleTimeout_ClosesIdleSession(t *testing.T) {
+	srv := newIdleTestServer(t)
+	session := connectTestClient(t, srv)
+
+	if err := session.Ping(context.Background(), nil); err != nil {
+		t.Fatalf("ping failed: %v", err)
+	}
+
+	waitForSessionClosed(t, session)
+}
+
+func TestIdleTimeout_ClosesSilentSession(t *testing.T) {
+	srv := newIdleTestServer(t)
+	t.Cleanup(func() { _ = srv.Shutdown(context.Background()) })
+
+	// Connect a session that never sends a message, not even initialize
+	serverTransport, _ := mcp.NewInMemoryTransports()
+	serverSession, err := srv.MCP().Connect(context.Background(), serverTransport, nil)
+	if err != nil {
+		t.Fatalf("failed to connect server: %v", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		_ = serverSession.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected the silent session to be closed")
+	}
+}
+
+func TestIdleTimeout_ActivityKeepsSessionOpen(t *testing.T) {
+	srv := newIdleTestServer(t)
+	session := connectTestClient(t, srv)
+
+	for deadline := time.Now().Add(3 * testIdleTimeout); time.Now().Before(deadline); {
+		if err := session.Ping(context.Background(), nil); err != nil {
+			t.Fatalf("ping failed while the session was active: %v", err)
+		}
+		time.Sleep(testIdleTimeout / 4)
+	}
+}
+
+func TestIdleTimeout_PausedDuringRequests(t *testing.T) {
+	srv := newIdleTestServer(t)
+	AddTool(srv, &mcp.Tool{Name: "slow", Description: "Outlasts the idle timeout"},
+		func(ctx context.Context, req *mcp.CallToolRequest, input struct{}) (*mcp.CallToolResult, any, error) {
+			time.Sleep(3 * testIdleTimeout)
+			return &mcp.CallToolResult{}, nil, nil
+		})
+	session := connectTestClient(t, srv)
+
+	if _, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "slow"}); err != nil {
+		t.Fatalf("slow tool call failed: %v", err)
+	}
+}
+
+func TestIdleTimeout_CleansUpSubscriptions(t *testing.T) {
+	srv := newIdleTestServer(t)
+	srv.AddResource(&mcp.Resource{URI: testResourceURI, Name: "status"},
+		func(_ context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+			return &mcp.ReadResourceResult{Contents: []*mcp.ResourceContents{{URI: req.Params.URI, Text: "ok"}}}, nil
+		})
+	session, _ := connectSubscribingClient(t, srv)
+
+	if err := session.Subscribe(context.Background(), &mcp.SubscribeParams{URI: testResourceURI}); err != nil {
+		t.Fatalf("subscribe failed: %v", err)
+	}
+	if got := srv.Subscribers(testResourceURI); got != 1 {
+		t.Fatalf("expected 1 subscriber, got %d", got)
+	}
+
+	waitForSessionClosed(t, session)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for srv.Subscribers(testResourceURI) != 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the idle session's subscription to be dropped, got %d subscribers", srv.Subscribers(testResourceURI))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestIdleTimeout_SSE(t *testing.T) {
+	srv := newIdleTestServer(t)
+
+	handler, err := srv.HTTPHandler(TransportSSE)
+	if err != nil {
+		t.Fatalf("failed to build handler: %v", err)
+	}
+	httpServer := httptest.NewServer(handler)
+	t.Cleanup(httpServer.Close)
+
+	session := connectSSEClient(t, httpServer.URL+DefaultSSEPath)
+	if err := session.Ping(context.Background(), nil); err != nil {
+		t.Fatalf("ping failed: %v", err)
+	}
+	waitForActiveConnections(t, srv, 1)
+
+	// Closing the idle session ends the client's event stream
+	waitForActiveConnections(t, srv, 0)
+}
+
+func TestConfig_Validate_IdleTimeout(t *testing.T) {
+	err := Config{
+		Name:      "test-server",
+		Version:   "1.0.0",
+		Transport: TransportConfig{IdleTimeout: -time.Second},
+	}.Validate()
+
+	var cfgErr *ConfigError
+	if !errors.As(err, &cfgErr) || cfgErr.Field != "Transport.IdleTimeout" {
+		t.Errorf("expected ConfigError for Transport.IdleTimeout, got %v", err)
+	}
+}
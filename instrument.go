@@ -0,0 +1,38 @@
+package hypermcp
+
+import (
+	"context"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap"
+)
+
+// instrumentTool wraps a tool handler registered through AddTool with the
+// server's per-invocation instrumentation.
+func instrumentTool[In, Out any](s *Server, name string, handler mcp.ToolHandlerFor[In, Out]) mcp.ToolHandlerFor[In, Out] {
+	return func(ctx context.Context, req *mcp.CallToolRequest, input In) (*mcp.CallToolResult, Out, error) {
+		start := time.Now()
+		result, output, err := handler(ctx, req, input)
+		s.observeToolDuration(name, time.Since(start))
+		return result, output, err
+	}
+}
+
+// observeToolDuration records the invocation's latency, and warns about and
+// counts invocations exceeding Config.SlowToolThreshold.
+func (s *Server) observeToolDuration(name string, duration time.Duration) {
+	s.metrics.ObserveToolDuration(name, duration)
+
+	threshold := s.config.SlowToolThreshold
+	if threshold <= 0 || duration <= threshold {
+		return
+	}
+
+	s.metrics.IncrementSlowToolInvocations()
+	s.log().Warn("slow tool invocation",
+		zap.String("tool", name),
+		zap.Duration("duration", duration),
+		zap.Duration("threshold", threshold),
+	)
+}
@@ -0,0 +1,64 @@
+package hypermcp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestConfig_SlowToolThreshold(t *testing.T) {
+	tests := []struct {
+		name      string
+		threshold time.Duration
+		sleep     time.Duration
+		wantSlow  int
+	}{
+		{name: "slow tool warns", threshold: 10 * time.Millisecond, sleep: 30 * time.Millisecond, wantSlow: 1},
+		{name: "fast tool is quiet", threshold: time.Second, sleep: 0, wantSlow: 0},
+		{name: "disabled", threshold: 0, sleep: 30 * time.Millisecond, wantSlow: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, logs := observer.New(zap.WarnLevel)
+			srv, err := New(Config{
+				Name:              "test-server",
+				Version:           "1.0.0",
+				SlowToolThreshold: tt.threshold,
+			}, zap.New(core))
+			if err != nil {
+				t.Fatalf("failed to create server: %v", err)
+			}
+
+			AddTool(srv, &mcp.Tool{
+				Name:        "sleepy",
+				Description: "Sleeps before answering",
+			}, func(ctx context.Context, req *mcp.CallToolRequest, input struct{}) (*mcp.CallToolResult, any, error) {
+				time.Sleep(tt.sleep)
+				return &mcp.CallToolResult{}, nil, nil
+			})
+
+			session := connectTestClient(t, srv)
+			if _, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "sleepy"}); err != nil {
+				t.Fatalf("tool call failed: %v", err)
+			}
+
+			warnings := logs.FilterMessage("slow tool invocation")
+			if warnings.Len() != tt.wantSlow {
+				t.Fatalf("expected %d slow tool warnings, got %d", tt.wantSlow, warnings.Len())
+			}
+			if tt.wantSlow > 0 {
+				if tool := warnings.All()[0].ContextMap()["tool"]; tool != "sleepy" {
+					t.Errorf("expected tool field %q, got %v", "sleepy", tool)
+				}
+			}
+			if got := srv.GetMetrics().SlowToolInvocations; got != int64(tt.wantSlow) {
+				t.Errorf("expected %d slow invocations, got %d", tt.wantSlow, got)
+			}
+		})
+	}
+}
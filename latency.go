@@ -0,0 +1,215 @@
+package hypermcp
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// latencyBuckets are the inclusive upper bounds of the tool latency histogram.
+// Slower invocations fall into a final overflow bucket.
+var latencyBuckets = [...]time.Duration{
+	time.Millisecond,
+	2500 * time.Microsecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+	30 * time.Second,
+	time.Minute,
+}
+
+// latencyHistogram is a fixed-size bucketed histogram of durations.
+//
+// Its memory use does not grow with the number of observations; percentiles
+// are estimated by interpolating within the bucket they fall in.
+type latencyHistogram struct {
+	count   atomic.Int64
+	sum     atomic.Int64 // nanoseconds
+	max     atomic.Int64 // nanoseconds
+	buckets [len(latencyBuckets) + 1]atomic.Int64
+
+	// version is bumped once an observation is fully recorded. cached holds
+	// the last snapshot and the version it was taken at, so a histogram that
+	// hasn't changed since is not copied again.
+	version atomic.Int64
+	cached  atomic.Pointer[cachedLatency]
+}
+
+// cachedLatency is a snapshot of a latencyHistogram taken at version.
+type cachedLatency struct {
+	version int64
+	snap    LatencySnapshot
+}
+
+// observe records one duration.
+func (h *latencyHistogram) observe(d time.Duration) {
+	if d < 0 {
+		d = 0
+	}
+
+	bucket := len(latencyBuckets)
+	for i, upper := range latencyBuckets {
+		if d <= upper {
+			bucket = i
+			break
+		}
+	}
+	h.buckets[bucket].Add(1)
+	h.count.Add(1)
+	h.sum.Add(int64(d))
+
+	for {
+		current := h.max.Load()
+		if int64(d) <= current || h.max.CompareAndSwap(current, int64(d)) {
+			break
+		}
+	}
+	h.version.Add(1)
+}
+
+// LatencyBucket is one bucket of a latency histogram in LatencySnapshot.
+type LatencyBucket struct {
+	UpperBound time.Duration // Slowest duration counted in this bucket; 0 for the overflow bucket
+	Count      int64         // Observations that fell in this bucket
+}
+
+// LatencySnapshot is the latency distribution of one tool in MetricsSnapshot.
+//
+// Percentiles are estimates: they are interpolated within histogram buckets,
+// so their precision is bounded by the bucket widths.
+type LatencySnapshot struct {
+	Count int64         // Invocations observed
+	Sum   time.Duration // Total time spent in the tool
+	Max   time.Duration // Slowest invocation observed
+
+	P50 time.Duration
+	P95 time.Duration
+	P99 time.Duration
+
+	// Buckets holds the non-cumulative bucket counts. Snapshots taken while
+	// the tool isn't called share it, so it must not be modified.
+	Buckets []LatencyBucket
+}
+
+// Mean returns the average invocation duration, or zero if there were none.
+func (s LatencySnapshot) Mean() time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+	return s.Sum / time.Duration(s.Count)
+}
+
+// snapshot copies the histogram and estimates its percentiles, reusing the
+// previous snapshot if nothing was observed since.
+func (h *latencyHistogram) snapshot() LatencySnapshot {
+	version := h.version.Load()
+	cached := h.cached.Load()
+	if cached != nil && cached.version == version {
+		return cached.snap
+	}
+
+	snap := LatencySnapshot{
+		Sum:     time.Duration(h.sum.Load()),
+		Max:     time.Duration(h.max.Load()),
+		Buckets: make([]LatencyBucket, len(h.buckets)),
+	}
+	for i := range h.buckets {
+		if i < len(latencyBuckets) {
+			snap.Buckets[i].UpperBound = latencyBuckets[i]
+		}
+		snap.Buckets[i].Count = h.buckets[i].Load()
+		// Sum the copied buckets rather than loading count, so the
+		// percentiles are computed from a consistent total
+		snap.Count += snap.Buckets[i].Count
+	}
+
+	snap.P50 = snap.quantile(0.50)
+	snap.P95 = snap.quantile(0.95)
+	snap.P99 = snap.quantile(0.99)
+
+	// Cache the copy only if no observation completed while it was taken,
+	// and only in place of the snapshot it was compared against, so a racing
+	// snapshot that is newer is not replaced by an older one. An observation
+	// still in progress may be partly counted, as in any uncached snapshot;
+	// its version bump keeps the copy from being reused once it completes.
+	if h.version.Load() == version {
+		h.cached.CompareAndSwap(cached, &cachedLatency{version: version, snap: snap})
+	}
+	return snap
+}
+
+// quantile estimates the q-th quantile (0 < q <= 1) by linear interpolation
+// within the bucket containing it. The overflow bucket is bounded by Max.
+func (s LatencySnapshot) quantile(q float64) time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+
+	rank := q * float64(s.Count)
+	var cumulative int64
+	var lower time.Duration
+	for _, b := range s.Buckets {
+		upper := b.UpperBound
+		if upper == 0 || upper > s.Max {
+			upper = s.Max
+		}
+		if b.Count > 0 && float64(cumulative+b.Count) >= rank {
+			fraction := (rank - float64(cumulative)) / float64(b.Count)
+			return lower + time.Duration(fraction*float64(upper-lower))
+		}
+		cumulative += b.Count
+		lower = upper
+	}
+	return s.Max
+}
+
+// toolLatencies holds one latency histogram per tool name.
+type toolLatencies struct {
+	histograms sync.Map     // string -> *latencyHistogram
+	tools      atomic.Int64 // entries in histograms, to size snapshots
+}
+
+// observe records an invocation of the named tool.
+func (t *toolLatencies) observe(tool string, d time.Duration) {
+	h, ok := t.histograms.Load(tool)
+	if !ok {
+		var loaded bool
+		if h, loaded = t.histograms.LoadOrStore(tool, &latencyHistogram{}); !loaded {
+			t.tools.Add(1)
+		}
+	}
+	h.(*latencyHistogram).observe(d)
+}
+
+// snapshot returns the distribution of every tool observed so far, or nil if
+// none has been.
+func (t *toolLatencies) snapshot() map[string]LatencySnapshot {
+	var snaps map[string]LatencySnapshot
+	t.histograms.Range(func(key, value any) bool {
+		if snaps == nil {
+			snaps = make(map[string]LatencySnapshot, t.tools.Load())
+		}
+		snaps[key.(string)] = value.(*latencyHistogram).snapshot()
+		return true
+	})
+	return snaps
+}
+
+// sortedToolNames returns the keys of a ToolLatencies map in order.
+func sortedToolNames(latencies map[string]LatencySnapshot) []string {
+	names := make([]string, 0, len(latencies))
+	for name := range latencies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,238 @@
+package hypermcp
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// withinPercent reports whether got is within pct percent of want.
+func withinPercent(got, want time.Duration, pct float64) bool {
+	diff := float64(got - want)
+	if diff < 0 {
+		diff = -diff
+	}
+	return diff <= float64(want)*pct/100
+}
+
+func TestLatencyHistogram_Percentiles(t *testing.T) {
+	tests := []struct {
+		name                string
+		durations           func() []time.Duration
+		wantP50, wantP95    time.Duration
+		wantP99, wantMax    time.Duration
+		tolerancePercentage float64
+	}{
+		{
+			name: "uniform 1ms to 100ms",
+			durations: func() []time.Duration {
+				var ds []time.Duration
+				for i := 1; i <= 100; i++ {
+					ds = append(ds, time.Duration(i)*time.Millisecond)
+				}
+				return ds
+			},
+			wantP50:             50 * time.Millisecond,
+			wantP95:             95 * time.Millisecond,
+			wantP99:             99 * time.Millisecond,
+			wantMax:             100 * time.Millisecond,
+			tolerancePercentage: 10,
+		},
+		{
+			name: "fast majority with slow tail",
+			durations: func() []time.Duration {
+				var ds []time.Duration
+				for i := 0; i < 980; i++ {
+					ds = append(ds, 4*time.Millisecond)
+				}
+				for i := 0; i < 20; i++ {
+					ds = append(ds, 2*time.Second)
+				}
+				return ds
+			},
+			wantP50:             4 * time.Millisecond,
+			wantP95:             4 * time.Millisecond,
+			wantP99:             2 * time.Second,
+			wantMax:             2 * time.Second,
+			tolerancePercentage: 30,
+		},
+		{
+			name: "overflow bucket bounded by max",
+			durations: func() []time.Duration {
+				return []time.Duration{2 * time.Minute, 2 * time.Minute}
+			},
+			wantP50:             90 * time.Second,
+			wantP95:             2 * time.Minute,
+			wantP99:             2 * time.Minute,
+			wantMax:             2 * time.Minute,
+			tolerancePercentage: 10,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var h latencyHistogram
+			for _, d := range tt.durations() {
+				h.observe(d)
+			}
+			snap := h.snapshot()
+
+			for _, c := range []struct {
+				name      string
+				got, want time.Duration
+			}{
+				{"p50", snap.P50, tt.wantP50},
+				{"p95", snap.P95, tt.wantP95},
+				{"p99", snap.P99, tt.wantP99},
+				{"max", snap.Max, tt.wantMax},
+			} {
+				if !withinPercent(c.got, c.want, tt.tolerancePercentage) {
+					t.Errorf("%s = %v, want %v ±%v%%", c.name, c.got, c.want, tt.tolerancePercentage)
+				}
+			}
+			if snap.P50 > snap.P95 || snap.P95 > snap.P99 || snap.P99 > snap.Max {
+				t.Errorf("percentiles not monotonic: p50=%v p95=%v p99=%v max=%v", snap.P50, snap.P95, snap.P99, snap.Max)
+			}
+		})
+	}
+}
+
+func TestLatencyHistogram_BoundedMemory(t *testing.T) {
+	var h latencyHistogram
+	for i := 0; i < 100000; i++ {
+		h.observe(time.Duration(i) * time.Microsecond)
+	}
+	snap := h.snapshot()
+
+	if snap.Count != 100000 {
+		t.Errorf("expected count 100000, got %d", snap.Count)
+	}
+	if len(snap.Buckets) != len(latencyBuckets)+1 {
+		t.Errorf("expected %d buckets, got %d", len(latencyBuckets)+1, len(snap.Buckets))
+	}
+	if mean := snap.Mean(); !withinPercent(mean, 50*time.Millisecond, 1) {
+		t.Errorf("expected mean ~50ms, got %v", mean)
+	}
+}
+
+func TestMetrics_ObserveToolDuration(t *testing.T) {
+	m := newMetrics()
+
+	if got := m.Snapshot().ToolLatencies; got != nil {
+		t.Errorf("expected no latencies before any observation, got %v", got)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				m.ObserveToolDuration("fast", time.Millisecond)
+				m.ObserveToolDuration("slow", time.Second)
+			}
+		}()
+	}
+	wg.Wait()
+
+	latencies := m.Snapshot().ToolLatencies
+	if len(latencies) != 2 {
+		t.Fatalf("expected 2 tools, got %d", len(latencies))
+	}
+	for name, want := range map[string]time.Duration{"fast": time.Millisecond, "slow": time.Second} {
+		l := latencies[name]
+		if l.Count != 1000 {
+			t.Errorf("%s: expected 1000 observations, got %d", name, l.Count)
+		}
+		if l.P99 > want {
+			t.Errorf("%s: p99 %v exceeds the only observed value %v", name, l.P99, want)
+		}
+	}
+}
+
+func TestLatencyHistogram_CachedSnapshot(t *testing.T) {
+	var h latencyHistogram
+	h.observe(time.Millisecond)
+
+	first := h.snapshot()
+	if again := h.snapshot(); &again.Buckets[0] != &first.Buckets[0] {
+		t.Error("expected an unchanged histogram to reuse its snapshot")
+	}
+
+	h.observe(time.Second)
+	if snap := h.snapshot(); snap.Count != 2 || snap.Max != time.Second {
+		t.Errorf("expected the snapshot to include the new observation, got count %d max %v", snap.Count, snap.Max)
+	}
+	if first.Count != 1 {
+		t.Errorf("expected the earlier snapshot to be unchanged, got count %d", first.Count)
+	}
+}
+
+func TestMetrics_SnapshotConcurrent(t *testing.T) {
+	m := newMetrics()
+
+	const (
+		writers = 8
+		calls   = 500
+	)
+
+	var writersWG, readersWG sync.WaitGroup
+	done := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		readersWG.Add(1)
+		go func() {
+			defer readersWG.Done()
+			var last int64
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+
+				l := m.Snapshot().ToolLatencies["tool"]
+				var buckets int64
+				for _, b := range l.Buckets {
+					buckets += b.Count
+				}
+				if l.Count != buckets {
+					t.Errorf("count %d disagrees with bucket total %d", l.Count, buckets)
+					return
+				}
+				if l.Count < last {
+					t.Errorf("count went backwards from %d to %d", last, l.Count)
+					return
+				}
+				last = l.Count
+			}
+		}()
+	}
+	for i := 0; i < writers; i++ {
+		writersWG.Add(1)
+		go func() {
+			defer writersWG.Done()
+			for j := 0; j < calls; j++ {
+				m.ObserveToolDuration("tool", time.Duration(j)*time.Millisecond)
+			}
+		}()
+	}
+	writersWG.Wait()
+	close(done)
+	readersWG.Wait()
+
+	// Once writers are done, the snapshot must account for every observation
+	l := m.Snapshot().ToolLatencies["tool"]
+	if l.Count != writers*calls {
+		t.Errorf("expected %d observations, got %d", writers*calls, l.Count)
+	}
+	if l.Max != (calls-1)*time.Millisecond {
+		t.Errorf("expected max %v, got %v", (calls-1)*time.Millisecond, l.Max)
+	}
+	var sum time.Duration
+	for j := 0; j < calls; j++ {
+		sum += time.Duration(j) * time.Millisecond
+	}
+	if l.Sum != writers*sum {
+		t.Errorf("expected sum %v, got %v", writers*sum, l.Sum)
+	}
+}
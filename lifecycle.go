@@ -0,0 +1,78 @@
+package hypermcp
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// startShutdownTimeout bounds the Shutdown that Start runs once the transport
+// has returned.
+const startShutdownTimeout = 5 * time.Second
+
+// Start runs the server with the specified transport in the background and
+// returns immediately. When ctx is canceled or the transport fails, the
+// server shuts down on its own, running Shutdown with a five second timeout.
+// Use Wait to block until it has fully stopped:
+//
+//	if err := srv.Start(ctx, hypermcp.TransportStdio); err != nil {
+//	    logger.Fatal("failed to start", zap.Error(err))
+//	}
+//	// ... other work ...
+//	cancel()
+//	if err := srv.Wait(); err != nil {
+//	    logger.Error("server error", zap.Error(err))
+//	}
+//
+// The configuration is validated for the transport before Start returns, so
+// a misconfiguration is reported immediately; failures once running, such as
+// a listener that cannot bind, are reported by Wait. A server can be started
+// only once; later calls return ErrServerAlreadyStarted.
+func (s *Server) Start(ctx context.Context, transportType TransportType) error {
+	// Report what RunWithTransport would reject before going to the background
+	if _, err := transportRunner(s, transportType); err != nil {
+		return err
+	}
+
+	s.runMu.Lock()
+	defer s.runMu.Unlock()
+	if s.runDone != nil {
+		return ErrServerAlreadyStarted
+	}
+	done := make(chan struct{})
+	s.runDone = done
+
+	go func() {
+		runErr := RunWithTransport(ctx, s, transportType, s.log())
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), startShutdownTimeout)
+		defer cancel()
+		shutdownErr := s.Shutdown(shutdownCtx)
+
+		s.runMu.Lock()
+		s.runErr = errors.Join(runErr, shutdownErr)
+		s.runMu.Unlock()
+		close(done)
+	}()
+	return nil
+}
+
+// Wait blocks until a server started with Start has fully stopped: the
+// transport has returned and Shutdown, including the OnShutdown hooks, has
+// run. It returns the transport's error joined with Shutdown's, or nil after
+// a clean stop. Wait may be called any number of times, from any goroutine.
+//
+// Returns ErrServerNotRunning if Start has not been called.
+func (s *Server) Wait() error {
+	s.runMu.Lock()
+	done := s.runDone
+	s.runMu.Unlock()
+	if done == nil {
+		return ErrServerNotRunning
+	}
+
+	<-done
+	s.runMu.Lock()
+	defer s.runMu.Unlock()
+	return s.runErr
+}
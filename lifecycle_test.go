@@ -0,0 +1,154 @@
+package hypermcp
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestServer_StartWait(t *testing.T) {
+	path := tempSocketPath(t)
+	srv := newUnixTestServer(t, path)
+
+	var hookRan atomic.Bool
+	srv.OnShutdown("flush", func(ctx context.Context) error {
+		hookRan.Store(true)
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := srv.Start(ctx, TransportUnix); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	// Start returns before the listener is up; do some work once it is
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if info, err := os.Stat(path); err == nil && info.Mode()&fs.ModeSocket != 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("socket was not created")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	session := connectUnixClient(t, path)
+	res, err := session.CallTool(context.Background(), &mcp.CallToolParams{
+		Name:      "echo",
+		Arguments: map[string]any{"message": "working"},
+	})
+	if err != nil {
+		t.Fatalf("tool call failed: %v", err)
+	}
+	if text := res.Content[0].(*mcp.TextContent).Text; text != "working" {
+		t.Errorf("echo returned %q, want working", text)
+	}
+
+	waitErr := make(chan error, 1)
+	go func() { waitErr <- srv.Wait() }()
+	select {
+	case err := <-waitErr:
+		t.Fatalf("Wait() returned %v before the server was stopped", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	cancel()
+	select {
+	case err := <-waitErr:
+		if err != nil {
+			t.Fatalf("Wait() error = %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Wait() did not return after the server was stopped")
+	}
+
+	if !hookRan.Load() {
+		t.Error("expected the shutdown hook to have run when Wait returned")
+	}
+	if _, err := os.Lstat(path); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected socket file to be removed when Wait returned, got %v", err)
+	}
+	if err := srv.Wait(); err != nil {
+		t.Errorf("second Wait() error = %v", err)
+	}
+}
+
+func TestServer_Start_Errors(t *testing.T) {
+	t.Run("already started", func(t *testing.T) {
+		srv := newUnixTestServer(t, tempSocketPath(t))
+		ctx, cancel := context.WithCancel(context.Background())
+		if err := srv.Start(ctx, TransportUnix); err != nil {
+			t.Fatalf("Start() error = %v", err)
+		}
+		defer func() {
+			cancel()
+			_ = srv.Wait()
+		}()
+
+		if err := srv.Start(ctx, TransportUnix); !errors.Is(err, ErrServerAlreadyStarted) {
+			t.Errorf("second Start() error = %v, want ErrServerAlreadyStarted", err)
+		}
+	})
+
+	tests := []struct {
+		name      string
+		transport TransportType
+		want      error
+	}{
+		{name: "unknown transport", transport: "carrier-pigeon", want: ErrUnknownTransport},
+		{name: "unsupported transport", transport: TransportStreamableHTTP, want: ErrTransportNotSupported},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newShutdownTestServer(t)
+			err := srv.Start(context.Background(), tt.transport)
+			var transportErr *TransportError
+			if !errors.As(err, &transportErr) || !errors.Is(err, tt.want) {
+				t.Errorf("Start() error = %v, want TransportError wrapping %v", err, tt.want)
+			}
+			if err := srv.Wait(); !errors.Is(err, ErrServerNotRunning) {
+				t.Errorf("Wait() error = %v, want ErrServerNotRunning", err)
+			}
+		})
+	}
+
+	t.Run("misconfigured", func(t *testing.T) {
+		srv := newShutdownTestServer(t)
+		var cfgErr *ConfigError
+		if err := srv.Start(context.Background(), TransportUnix); !errors.As(err, &cfgErr) {
+			t.Errorf("Start() error = %v, want ConfigError for the missing socket path", err)
+		}
+	})
+}
+
+func TestServer_Wait_ReportsRunFailure(t *testing.T) {
+	path := tempSocketPath(t)
+	if err := os.WriteFile(path, []byte("data"), 0o600); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	srv := newUnixTestServer(t, path)
+
+	var hookRan atomic.Bool
+	srv.OnShutdown("flush", func(ctx context.Context) error {
+		hookRan.Store(true)
+		return nil
+	})
+
+	if err := srv.Start(context.Background(), TransportUnix); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	var transportErr *TransportError
+	if err := srv.Wait(); !errors.As(err, &transportErr) {
+		t.Errorf("Wait() error = %v, want the transport's TransportError", err)
+	}
+	if !hookRan.Load() {
+		t.Error("expected Shutdown to run after the transport failed")
+	}
+}
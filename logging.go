@@ -0,0 +1,86 @@
+package hypermcp
+
+import (
+	"io"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Logging and the stdio transport
+//
+// With TransportStdio, stdout IS the protocol channel: the client reads
+// newline-delimited JSON-RPC messages from the server's stdout. Any log line
+// written to stdout is interleaved with those messages and corrupts the
+// stream, typically surfacing on the client as a parse error or a dropped
+// connection. Loggers passed to New must therefore write to stderr (or a file)
+// when the stdio transport is used. zap.NewProduction and zap.NewDevelopment
+// log to stderr; zap.NewExample and configs listing "stdout" in OutputPaths
+// are NOT safe. Config.LogWriter guarantees where the logs go regardless of
+// how the logger was built.
+
+// newStderrLogger returns the logger New uses when it is given a nil logger.
+//
+// It logs JSON at Info level to stderr, which is safe for every transport.
+func newStderrLogger() *zap.Logger {
+	cfg := zap.NewProductionConfig()
+	cfg.OutputPaths = []string{"stderr"}
+	cfg.ErrorOutputPaths = []string{"stderr"}
+
+	logger, err := cfg.Build()
+	if err != nil {
+		return zap.NewNop()
+	}
+	return logger
+}
+
+// redirectLogger returns logger writing JSON to w instead of its own
+// destination, for Config.LogWriter. The logger keeps its name, options such
+// as caller annotation, and the levels it enables, and fields attached with
+// With after a redirect survive further redirects. Fields attached before
+// logger was first redirected are dropped: zap cannot separate them from the
+// core that holds them.
+func redirectLogger(logger *zap.Logger, w io.Writer) *zap.Logger {
+	sink := zapcore.Lock(zapcore.AddSync(w))
+	if logger == nil {
+		return zap.New(newRedirectCore(zapcore.InfoLevel, sink, nil), zap.ErrorOutput(sink))
+	}
+	return logger.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		if redirected, ok := core.(*redirectCore); ok {
+			return newRedirectCore(redirected.enabler, sink, redirected.fields)
+		}
+		return newRedirectCore(core, sink, nil)
+	}), zap.ErrorOutput(sink))
+}
+
+// redirectCore writes JSON to a sink at the levels another core enables.
+type redirectCore struct {
+	zapcore.Core
+
+	enabler zapcore.LevelEnabler
+	fields  []zapcore.Field // attached with With, to carry over to a new sink
+}
+
+func newRedirectCore(enabler zapcore.LevelEnabler, sink zapcore.WriteSyncer, fields []zapcore.Field) *redirectCore {
+	encoder := zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig())
+	return &redirectCore{
+		Core:    zapcore.NewCore(encoder, sink, enabler).With(fields),
+		enabler: enabler,
+		fields:  fields,
+	}
+}
+
+func (c *redirectCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redirectCore{
+		Core:    c.Core.With(fields),
+		enabler: c.enabler,
+		fields:  append(c.fields[:len(c.fields):len(c.fields)], fields...),
+	}
+}
+
+func (c *redirectCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
@@ -0,0 +1,302 @@
+package hypermcp
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rayprogramming/hypermcp/cache"
+	"github.com/rayprogramming/hypermcp/httpx"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestNew_NilLoggerKeepsStdoutClean(t *testing.T) {
+	stdoutReader, stdoutWriter, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("failed to create stdout pipe: %v", err)
+	}
+	stdinReader, stdinWriter, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("failed to create stdin pipe: %v", err)
+	}
+
+	origStdout, origStdin := os.Stdout, os.Stdin
+	os.Stdout, os.Stdin = stdoutWriter, stdinReader
+	defer func() {
+		os.Stdout, os.Stdin = origStdout, origStdin
+	}()
+
+	// Build the server and run stdio setup while stdout is captured
+	srv, err := New(Config{
+		Name:    "test-server",
+		Version: "1.0.0",
+	}, nil)
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_ = RunWithTransport(ctx, srv, TransportStdio, srv.Logger())
+	srv.LogRegistrationStats()
+
+	_ = stdinWriter.Close()
+	_ = stdoutWriter.Close()
+	os.Stdout, os.Stdin = origStdout, origStdin
+
+	leaked, err := io.ReadAll(stdoutReader)
+	if err != nil {
+		t.Fatalf("failed to read captured stdout: %v", err)
+	}
+	if len(leaked) != 0 {
+		t.Errorf("expected no bytes on stdout, got %q", leaked)
+	}
+}
+
+// lockedBuffer is a bytes.Buffer safe for concurrent writes.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestConfig_LogWriter(t *testing.T) {
+	stdoutReader, stdoutWriter, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("failed to create stdout pipe: %v", err)
+	}
+	stdinReader, stdinWriter, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("failed to create stdin pipe: %v", err)
+	}
+
+	origStdout, origStdin := os.Stdout, os.Stdin
+	os.Stdout, os.Stdin = stdoutWriter, stdinReader
+	defer func() {
+		os.Stdout, os.Stdin = origStdout, origStdin
+	}()
+
+	// zap.NewExample logs to stdout, which would corrupt the stdio stream
+	stdoutLogger := zap.NewExample()
+	var logs lockedBuffer
+	srv, err := New(Config{
+		Name:      "test-server",
+		Version:   "1.0.0",
+		LogWriter: &logs,
+	}, stdoutLogger)
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_ = RunWithTransport(ctx, srv, TransportStdio, stdoutLogger)
+	srv.LogRegistrationStats()
+	srv.Logger().Debug("debug enabled by the example logger")
+
+	_ = stdinWriter.Close()
+	_ = stdoutWriter.Close()
+	os.Stdout, os.Stdin = origStdout, origStdin
+
+	leaked, err := io.ReadAll(stdoutReader)
+	if err != nil {
+		t.Fatalf("failed to read captured stdout: %v", err)
+	}
+	if len(leaked) != 0 {
+		t.Errorf("expected no bytes on stdout, got %q", leaked)
+	}
+
+	for _, msg := range []string{"base server initialized", "using stdio transport (recommended)", "registered tools and resources", "debug enabled by the example logger"} {
+		if !strings.Contains(logs.String(), msg) {
+			t.Errorf("LogWriter output is missing %q:\n%s", msg, logs.String())
+		}
+	}
+}
+
+func TestServer_SetLogger_LogWriter(t *testing.T) {
+	var logs lockedBuffer
+	srv, err := New(Config{Name: "test-server", Version: "1.0.0", LogWriter: &logs, DisableStartupLog: true}, nil)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	core, observed := observer.New(zapcore.InfoLevel)
+	srv.SetLogger(zap.New(core))
+	srv.Logger().Info("after SetLogger")
+	srv.Logger().Debug("below the new logger's level")
+
+	if observed.Len() != 0 {
+		t.Errorf("replacement logger got %d entries, want them redirected", observed.Len())
+	}
+	if !strings.Contains(logs.String(), "after SetLogger") {
+		t.Errorf("LogWriter output is missing the log written after SetLogger:\n%s", logs.String())
+	}
+	if strings.Contains(logs.String(), "below the new logger's level") {
+		t.Error("LogWriter got a debug log the logger does not enable")
+	}
+}
+
+func TestRedirectLogger_KeepsNameOptionsAndFields(t *testing.T) {
+	var first, second lockedBuffer
+	logger := zap.NewExample(zap.AddCaller()).Named("app")
+
+	redirected := redirectLogger(logger, &first).With(zap.String("request", "r1"))
+	redirected.Info("to first")
+	// Redirecting again, as RunWithTransport does, keeps the fields
+	redirectLogger(redirected, &second).Info("to second")
+
+	for name, logs := range map[string]*lockedBuffer{"first": &first, "second": &second} {
+		for _, want := range []string{`"logger":"app"`, `"caller":`, `"request":"r1"`} {
+			if !strings.Contains(logs.String(), want) {
+				t.Errorf("%s output is missing %s:\n%s", name, want, logs.String())
+			}
+		}
+	}
+	if strings.Contains(first.String(), "to second") {
+		t.Error("log written after the second redirect reached the first writer")
+	}
+}
+
+func TestConfig_DisableStartupLog(t *testing.T) {
+	tests := []struct {
+		name              string
+		disableStartupLog bool
+		wantLogs          int
+	}{
+		{name: "startup log enabled", disableStartupLog: false, wantLogs: 1},
+		{name: "startup log disabled", disableStartupLog: true, wantLogs: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, logs := observer.New(zap.InfoLevel)
+
+			_, err := New(Config{
+				Name:              "test-server",
+				Version:           "1.0.0",
+				DisableStartupLog: tt.disableStartupLog,
+			}, zap.New(core))
+			if err != nil {
+				t.Fatalf("failed to create server: %v", err)
+			}
+
+			if got := logs.FilterMessage("base server initialized").Len(); got != tt.wantLogs {
+				t.Errorf("expected %d startup log entries, got %d", tt.wantLogs, got)
+			}
+		})
+	}
+}
+
+func TestServer_SetLogger(t *testing.T) {
+	httpConfig := httpx.DefaultConfig()
+	httpConfig.MaxRetries = 0
+
+	oldCore, oldLogs := observer.New(zapcore.DebugLevel)
+	srv, err := New(Config{
+		Name:              "test-server",
+		Version:           "1.0.0",
+		CacheEnabled:      true,
+		CacheConfig:       cache.DefaultConfig(),
+		HTTPConfig:        &httpConfig,
+		DisableStartupLog: true,
+	}, zap.New(oldCore))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer func() { _ = srv.Shutdown(context.Background()) }()
+
+	// A tool call that is in flight while the logger is swapped
+	started, release := make(chan struct{}), make(chan struct{})
+	AddTool(srv, &mcp.Tool{Name: "slow"}, func(ctx context.Context, _ *mcp.CallToolRequest, _ struct{}) (*mcp.CallToolResult, any, error) {
+		close(started)
+		<-release
+		LoggerFromContext(ctx).Info("tool finished")
+		return &mcp.CallToolResult{}, nil, nil
+	})
+	session := connectTestClient(t, srv)
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "slow", Arguments: map[string]any{}})
+		done <- err
+	}()
+	<-started
+
+	newCore, newLogs := observer.New(zapcore.DebugLevel)
+	srv.SetLogger(zap.New(newCore))
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("CallTool() error = %v", err)
+	}
+
+	srv.Cache().Clear()
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer backend.Close()
+	_ = srv.HTTPClient().Get(context.Background(), backend.URL, nil)
+
+	for _, msg := range []string{"tool finished", "cache cleared", "http request failed after retries"} {
+		if newLogs.FilterMessage(msg).Len() != 1 {
+			t.Errorf("new logger got %d %q logs, want 1", newLogs.FilterMessage(msg).Len(), msg)
+		}
+		if oldLogs.FilterMessage(msg).Len() != 0 {
+			t.Errorf("old logger got %q after SetLogger", msg)
+		}
+	}
+	if srv.Logger() != srv.log() {
+		t.Error("Logger() does not return the current logger")
+	}
+}
+
+func TestServer_SetLogger_Concurrent(t *testing.T) {
+	srv, err := New(Config{Name: "test-server", Version: "1.0.0", DisableStartupLog: true}, zap.NewNop())
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	AddTool(srv, &mcp.Tool{Name: "log"}, func(ctx context.Context, _ *mcp.CallToolRequest, _ struct{}) (*mcp.CallToolResult, any, error) {
+		LoggerFromContext(ctx).Debug("called")
+		return &mcp.CallToolResult{}, nil, nil
+	})
+	session := connectTestClient(t, srv)
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for range 50 {
+			srv.SetLogger(zap.NewNop())
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for range 50 {
+			if _, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "log", Arguments: map[string]any{}}); err != nil {
+				t.Errorf("CallTool() error = %v", err)
+				return
+			}
+		}
+	}()
+	wg.Wait()
+}
@@ -0,0 +1,113 @@
+package hypermcp
+
+import (
+	"encoding/json"
+	"runtime/debug"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Manifest describes a server's identity, features, and infrastructure settings.
+//
+// It is returned by Server.Describe and is intended for documentation
+// generation and client bootstrapping. It marshals to JSON directly.
+type Manifest struct {
+	Server            ServerInfo              `json:"server"`
+	Tools             []*mcp.Tool             `json:"tools"`
+	Resources         []*mcp.Resource         `json:"resources"`
+	ResourceTemplates []*mcp.ResourceTemplate `json:"resourceTemplates"`
+	Prompts           []*mcp.Prompt           `json:"prompts"`
+	Cache             CacheSummary            `json:"cache"`
+	HTTP              HTTPSummary             `json:"http"`
+}
+
+// CacheSummary summarizes the cache configuration in a Manifest.
+type CacheSummary struct {
+	Enabled    bool          `json:"enabled"`
+	MaxCost    int64         `json:"maxCost,omitempty"`
+	DefaultTTL time.Duration `json:"defaultTTL,omitempty"`
+}
+
+// HTTPSummary summarizes the shared HTTP client configuration in a Manifest.
+type HTTPSummary struct {
+	UserAgent       string        `json:"userAgent"`
+	RequestTimeout  time.Duration `json:"requestTimeout"`
+	MaxRetries      int           `json:"maxRetries"`
+	MaxResponseSize int64         `json:"maxResponseSize"`
+}
+
+// JSON returns the manifest as indented JSON.
+func (m Manifest) JSON() ([]byte, error) {
+	return json.MarshalIndent(m, "", "  ")
+}
+
+// Describe returns a manifest of the server: its name, version, and build
+// information, every tool, resource, resource template, and prompt registered
+// through the Server helpers (with descriptions and schemas), and summaries of
+// the cache and HTTP client configuration.
+//
+// Features registered directly on the underlying MCP server are not included.
+// Entries are sorted by name or URI.
+//
+// Example:
+//
+//	data, err := srv.Describe().JSON()
+func (s *Server) Describe() Manifest {
+	tools, resources, templates, prompts := s.registry.snapshot()
+
+	manifest := Manifest{
+		Server:            s.serverInfo(),
+		Tools:             tools,
+		Resources:         resources,
+		ResourceTemplates: templates,
+		Prompts:           prompts,
+		Cache: CacheSummary{
+			Enabled: s.cacheEnabled(),
+		},
+	}
+
+	if s.cacheEnabled() {
+		manifest.Cache.MaxCost = s.config.CacheConfig.MaxCost
+		if s.cache != nil {
+			manifest.Cache.MaxCost = s.cache.MaxCost()
+		}
+		manifest.Cache.DefaultTTL = s.config.CacheConfig.DefaultTTL
+	}
+
+	if s.httpClient != nil {
+		httpConfig := s.httpClient.Config()
+		manifest.HTTP = HTTPSummary{
+			UserAgent:       httpConfig.UserAgent,
+			RequestTimeout:  httpConfig.RequestTimeout,
+			MaxRetries:      httpConfig.MaxRetries,
+			MaxResponseSize: httpConfig.MaxResponseSize,
+		}
+	}
+
+	return manifest
+}
+
+// serverInfo returns the server's identity, filling in build information from
+// the binary's embedded VCS metadata when it was not configured.
+func (s *Server) serverInfo() ServerInfo {
+	info := ServerInfo{
+		Name:      s.config.Name,
+		Version:   s.config.Version,
+		Commit:    s.config.Commit,
+		BuildDate: s.config.BuildDate,
+	}
+
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range bi.Settings {
+			switch {
+			case setting.Key == "vcs.revision" && info.Commit == "":
+				info.Commit = setting.Value
+			case setting.Key == "vcs.time" && info.BuildDate == "":
+				info.BuildDate = setting.Value
+			}
+		}
+	}
+
+	return info
+}
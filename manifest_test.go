@@ -0,0 +1,128 @@
+package hypermcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestServer_Describe(t *testing.T) {
+	srv, err := New(Config{
+		Name:      "test-server",
+		Version:   "2.3.4",
+		Commit:    "abc123",
+		BuildDate: "2025-01-15",
+	}, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+
+	type weatherInput struct {
+		City string `json:"city" jsonschema:"City name"`
+	}
+	type weatherOutput struct {
+		Summary string `json:"summary"`
+	}
+	AddTool(srv, &mcp.Tool{
+		Name:        "get_weather",
+		Description: "Get the weather",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input weatherInput) (*mcp.CallToolResult, weatherOutput, error) {
+		return nil, weatherOutput{}, nil
+	})
+
+	srv.AddResource(&mcp.Resource{URI: "test://doc", Name: "Doc"},
+		func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+			return &mcp.ReadResourceResult{}, nil
+		})
+	srv.AddResourceTemplate(&mcp.ResourceTemplate{URITemplate: "test://items/{id}", Name: "Item"},
+		func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+			return &mcp.ReadResourceResult{}, nil
+		})
+	srv.AddPrompt(&mcp.Prompt{Name: "summarize", Description: "Summarize a document"},
+		func(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+			return &mcp.GetPromptResult{}, nil
+		})
+
+	manifest := srv.Describe()
+
+	want := ServerInfo{Name: "test-server", Version: "2.3.4", Commit: "abc123", BuildDate: "2025-01-15"}
+	if manifest.Server != want {
+		t.Errorf("expected server info %+v, got %+v", want, manifest.Server)
+	}
+
+	if len(manifest.Tools) != 1 || manifest.Tools[0].Name != "get_weather" {
+		t.Fatalf("expected get_weather tool, got %+v", manifest.Tools)
+	}
+	tool := manifest.Tools[0]
+	if tool.Description != "Get the weather" {
+		t.Errorf("expected tool description, got %q", tool.Description)
+	}
+	input, ok := tool.InputSchema.(*jsonschema.Schema)
+	if !ok || input.Properties["city"] == nil || input.Properties["city"].Description != "City name" {
+		t.Errorf("expected inferred input schema with city property, got %#v", tool.InputSchema)
+	}
+	if tool.OutputSchema == nil {
+		t.Error("expected inferred output schema")
+	}
+
+	if len(manifest.Resources) != 1 || manifest.Resources[0].URI != "test://doc" {
+		t.Errorf("expected test://doc resource, got %+v", manifest.Resources)
+	}
+	if len(manifest.ResourceTemplates) != 1 || manifest.ResourceTemplates[0].URITemplate != "test://items/{id}" {
+		t.Errorf("expected item template, got %+v", manifest.ResourceTemplates)
+	}
+	if len(manifest.Prompts) != 1 || manifest.Prompts[0].Name != "summarize" {
+		t.Errorf("expected summarize prompt, got %+v", manifest.Prompts)
+	}
+	if manifest.Cache.Enabled {
+		t.Error("expected cache to be reported as disabled")
+	}
+	if manifest.HTTP.UserAgent != "hypermcp" {
+		t.Errorf("expected default user agent, got %q", manifest.HTTP.UserAgent)
+	}
+
+	data, err := manifest.JSON()
+	if err != nil {
+		t.Fatalf("failed to marshal manifest: %v", err)
+	}
+	var decoded struct {
+		Server struct {
+			Version string `json:"version"`
+		} `json:"server"`
+		Tools []struct {
+			Name        string         `json:"name"`
+			InputSchema map[string]any `json:"inputSchema"`
+		} `json:"tools"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("failed to decode manifest JSON: %v", err)
+	}
+	if decoded.Server.Version != "2.3.4" {
+		t.Errorf("expected version 2.3.4 in JSON, got %q", decoded.Server.Version)
+	}
+	if len(decoded.Tools) != 1 || decoded.Tools[0].InputSchema["type"] != "object" {
+		t.Errorf("expected tool with object input schema in JSON, got %+v", decoded.Tools)
+	}
+}
+
+func TestServer_Describe_KeepsFirstRegistration(t *testing.T) {
+	srv, err := New(Config{Name: "test-server", Version: "1.0.0"}, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+
+	handler := func(ctx context.Context, req *mcp.CallToolRequest, input struct{}) (*mcp.CallToolResult, any, error) {
+		return &mcp.CallToolResult{}, nil, nil
+	}
+	AddTool(srv, &mcp.Tool{Name: "echo", Description: "old"}, handler)
+	AddTool(srv, &mcp.Tool{Name: "echo", Description: "new"}, handler)
+
+	tools := srv.Describe().Tools
+	if len(tools) != 1 || tools[0].Description != "old" {
+		t.Errorf("expected the first registration only, got %+v", tools)
+	}
+}
@@ -0,0 +1,59 @@
+package hypermcp
+
+import "reflect"
+
+// Merge returns a copy of c with every field that is set in override
+// replacing the corresponding field of c, so configuration sources can be
+// layered by precedence:
+//
+//	cfg := defaults.Merge(fromFile).Merge(fromEnv).Merge(fromFlags)
+//
+// A field counts as set when it is not its zero value. Nested structs
+// (CacheConfig, Transport and its Auth) are merged field by field rather
+// than replaced whole, and so is HTTPConfig when both configs have one;
+// otherwise a non-nil HTTPConfig in override is copied. Other pointers, such
+// as CacheRequired, replace the receiver's when non-nil, and slices, funcs,
+// and interfaces replace it when non-empty or non-nil.
+//
+// Because unset is detected by the zero value, override cannot reset a field
+// to zero: false, 0, and "" are indistinguishable from a field the source did
+// not mention. To turn something off in a later layer, set the field on the
+// merged result directly. CacheRequired is a pointer for this reason, so an
+// explicit false does override.
+//
+// Neither c nor override is modified. When HTTPConfig is merged, the result
+// points to a new httpx.Config.
+func (c Config) Merge(override Config) Config {
+	merged := c
+	mergeFields(reflect.ValueOf(&merged).Elem(), reflect.ValueOf(override))
+	return merged
+}
+
+// mergeFields overlays the set fields of src onto dst, which must be a
+// settable struct of the same type.
+func mergeFields(dst, src reflect.Value) {
+	for i := range src.NumField() {
+		d, s := dst.Field(i), src.Field(i)
+		if !d.CanSet() {
+			continue
+		}
+
+		switch {
+		case s.Kind() == reflect.Struct:
+			mergeFields(d, s)
+		case s.Kind() == reflect.Pointer && s.Type().Elem().Kind() == reflect.Struct:
+			if s.IsNil() {
+				continue
+			}
+			// Merge into a fresh copy so the receiver's pointee is left alone
+			p := reflect.New(s.Type().Elem())
+			if !d.IsNil() {
+				p.Elem().Set(d.Elem())
+			}
+			mergeFields(p.Elem(), s.Elem())
+			d.Set(p)
+		case !s.IsZero():
+			d.Set(s)
+		}
+	}
+}
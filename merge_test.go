@@ -0,0 +1,168 @@
+package hypermcp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rayprogramming/hypermcp/cache"
+	"github.com/rayprogramming/hypermcp/httpx"
+)
+
+func TestConfig_Merge_Scalars(t *testing.T) {
+	base := Config{
+		Name:               "base",
+		Version:            "1.0.0",
+		CacheEnabled:       true,
+		MaxConcurrentTools: 4,
+		SlowToolThreshold:  time.Second,
+	}
+
+	merged := base.Merge(Config{
+		Version:           "2.0.0",
+		SlowToolThreshold: 3 * time.Second,
+	})
+
+	if merged.Name != "base" {
+		t.Errorf("Name = %q, want unset override to keep %q", merged.Name, "base")
+	}
+	if merged.Version != "2.0.0" {
+		t.Errorf("Version = %q, want override %q", merged.Version, "2.0.0")
+	}
+	if !merged.CacheEnabled {
+		t.Error("CacheEnabled = false, want a false override to count as unset")
+	}
+	if merged.MaxConcurrentTools != 4 {
+		t.Errorf("MaxConcurrentTools = %d, want 4", merged.MaxConcurrentTools)
+	}
+	if merged.SlowToolThreshold != 3*time.Second {
+		t.Errorf("SlowToolThreshold = %v, want 3s", merged.SlowToolThreshold)
+	}
+}
+
+func TestConfig_Merge_Pointers(t *testing.T) {
+	yes, no := true, false
+
+	tests := []struct {
+		name     string
+		base     *bool
+		override *bool
+		want     *bool
+	}{
+		{name: "nil override keeps base", base: &yes, override: nil, want: &yes},
+		{name: "explicit false overrides", base: &yes, override: &no, want: &no},
+		{name: "override fills nil base", base: nil, override: &yes, want: &yes},
+		{name: "both nil", base: nil, override: nil, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged := Config{CacheRequired: tt.base}.Merge(Config{CacheRequired: tt.override})
+			if tt.want == nil {
+				if merged.CacheRequired != nil {
+					t.Errorf("CacheRequired = %v, want nil", *merged.CacheRequired)
+				}
+				return
+			}
+			if merged.CacheRequired == nil || *merged.CacheRequired != *tt.want {
+				t.Errorf("CacheRequired = %v, want %v", merged.CacheRequired, *tt.want)
+			}
+		})
+	}
+}
+
+func TestConfig_Merge_Nested(t *testing.T) {
+	baseHTTP := httpx.DefaultConfig()
+	base := Config{
+		HTTPConfig: &baseHTTP,
+		CacheConfig: cache.Config{
+			MaxCost:     1024,
+			NumCounters: 100,
+			DefaultTTL:  time.Minute,
+		},
+		Transport: TransportConfig{
+			Addr: ":8080",
+			Auth: AuthConfig{Tokens: []string{"base-token"}},
+		},
+	}
+
+	merged := base.Merge(Config{
+		HTTPConfig:  &httpx.Config{MaxRetries: 7},
+		CacheConfig: cache.Config{DefaultTTL: time.Hour},
+		Transport: TransportConfig{
+			SSEPath: "/events",
+			Auth:    AuthConfig{Tokens: []string{"env-token"}},
+		},
+	})
+
+	if merged.HTTPConfig.MaxRetries != 7 {
+		t.Errorf("HTTPConfig.MaxRetries = %d, want 7", merged.HTTPConfig.MaxRetries)
+	}
+	if merged.HTTPConfig.RequestTimeout != baseHTTP.RequestTimeout {
+		t.Errorf("HTTPConfig.RequestTimeout = %v, want base %v", merged.HTTPConfig.RequestTimeout, baseHTTP.RequestTimeout)
+	}
+	if baseHTTP.MaxRetries != httpx.DefaultConfig().MaxRetries {
+		t.Error("Merge modified the receiver's HTTPConfig")
+	}
+
+	if merged.CacheConfig.MaxCost != 1024 || merged.CacheConfig.NumCounters != 100 {
+		t.Errorf("CacheConfig = %+v, want base sizes kept", merged.CacheConfig)
+	}
+	if merged.CacheConfig.DefaultTTL != time.Hour {
+		t.Errorf("CacheConfig.DefaultTTL = %v, want 1h", merged.CacheConfig.DefaultTTL)
+	}
+
+	if merged.Transport.Addr != ":8080" || merged.Transport.SSEPath != "/events" {
+		t.Errorf("Transport = %+v, want base Addr and override SSEPath", merged.Transport)
+	}
+	if len(merged.Transport.Auth.Tokens) != 1 || merged.Transport.Auth.Tokens[0] != "env-token" {
+		t.Errorf("Transport.Auth.Tokens = %v, want override to replace the slice", merged.Transport.Auth.Tokens)
+	}
+}
+
+func TestConfig_Merge_HTTPConfigIntoNil(t *testing.T) {
+	override := &httpx.Config{MaxRetries: 2}
+
+	merged := Config{}.Merge(Config{HTTPConfig: override})
+
+	if merged.HTTPConfig == nil || merged.HTTPConfig.MaxRetries != 2 {
+		t.Fatalf("HTTPConfig = %+v, want override copied", merged.HTTPConfig)
+	}
+	if merged.HTTPConfig == override {
+		t.Error("HTTPConfig aliases the override's pointer, want a copy")
+	}
+}
+
+func TestConfig_Merge_Funcs(t *testing.T) {
+	type key struct{}
+	base := Config{BaseContext: context.Background}
+	override := Config{BaseContext: func() context.Context {
+		return context.WithValue(context.Background(), key{}, "override")
+	}}
+
+	merged := base.Merge(override)
+	if merged.BaseContext().Value(key{}) != "override" {
+		t.Error("BaseContext was not replaced by override")
+	}
+
+	merged = override.Merge(Config{})
+	if merged.BaseContext().Value(key{}) != "override" {
+		t.Error("nil BaseContext in override replaced the receiver's")
+	}
+}
+
+func TestConfig_Merge_Layers(t *testing.T) {
+	defaults := Config{Name: "server", Version: "0.0.0", MaxConcurrentTools: 2}
+	file := Config{Version: "1.0.0", MaxConcurrentTools: 8}
+	env := Config{MaxConcurrentTools: 16}
+	flags := Config{Version: "1.0.1"}
+
+	cfg := defaults.Merge(file).Merge(env).Merge(flags)
+
+	if cfg.Name != "server" || cfg.Version != "1.0.1" || cfg.MaxConcurrentTools != 16 {
+		t.Errorf("layered config = %q %q %d, want server 1.0.1 16", cfg.Name, cfg.Version, cfg.MaxConcurrentTools)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
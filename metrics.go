@@ -3,8 +3,16 @@ package hypermcp
 import (
 	"sync/atomic"
 	"time"
+
+	"github.com/rayprogramming/hypermcp/cache"
 )
 
+// Clock supplies the current time to the server's metrics and cache.
+//
+// It is the same interface the cache package uses, so a single fake clock can
+// drive both in tests.
+type Clock = cache.Clock
+
 // Metrics tracks server performance and usage statistics.
 //
 // All counters are thread-safe using atomic operations and can be safely
@@ -12,10 +20,16 @@ import (
 type Metrics struct {
 	// Server lifecycle
 	startTime time.Time
+	clock     Clock
 
 	// Tool and resource usage
-	toolInvocations atomic.Int64
-	resourceReads   atomic.Int64
+	toolInvocations      atomic.Int64
+	resourceReads        atomic.Int64
+	slowToolInvocations  atomic.Int64
+	oversizedToolOutputs atomic.Int64
+	toolLatencies        toolLatencies
+	templateUsage        templateUsage
+	clientUsage          clientUsage
 
 	// Cache statistics
 	cacheHits   atomic.Int64
@@ -23,6 +37,44 @@ type Metrics struct {
 
 	// Error tracking
 	errors atomic.Int64
+
+	// Tool call outcomes over the degraded mode window
+	toolOutcomes *errorWindow
+
+	// Most recent errors, or nil unless Config.RecentErrors is set
+	recentErrors *errorRing
+
+	// Application metrics registered through the Server
+	custom customMetrics
+
+	// Transport connections
+	activeConnections atomic.Int64
+	totalConnections  atomic.Int64
+
+	// Tool invocation queue
+	queueDepth    atomic.Int64
+	queueRejected atomic.Int64
+
+	// Stdio write buffer (see StdioConfig)
+	stdioBuffered    atomic.Int64
+	stdioBufferWaits atomic.Int64
+	stdioDropped     atomic.Int64
+
+	// Incoming JSON-RPC messages
+	singleMessages  atomic.Int64
+	batches         atomic.Int64
+	batchedMessages atomic.Int64
+	batchSizes      [len(batchSizeBuckets) + 1]atomic.Int64
+}
+
+// batchSizeBuckets are the inclusive upper bounds of the batch size histogram.
+// Larger batches fall into a final overflow bucket.
+var batchSizeBuckets = [...]int{2, 5, 10, 25, 50}
+
+// BatchSizeBucket is one bucket of the batch size histogram in MetricsSnapshot.
+type BatchSizeBucket struct {
+	UpperBound int   `json:"upperBound"` // Largest batch size counted in this bucket; 0 for the overflow bucket
+	Count      int64 `json:"count"`      // Batches whose size fell in this bucket
 }
 
 // MetricsSnapshot provides a point-in-time view of server metrics.
@@ -34,8 +86,25 @@ type MetricsSnapshot struct {
 	Uptime time.Duration
 
 	// Tool and resource usage
-	ToolInvocations int64
-	ResourceReads   int64
+	ToolInvocations      int64
+	ResourceReads        int64
+	SlowToolInvocations  int64 // Invocations exceeding Config.SlowToolThreshold
+	OversizedToolOutputs int64 // Tool results exceeding Config.MaxToolOutputBytes, rejected or truncated
+
+	// ToolLatencies holds the latency distribution of each tool registered
+	// with AddTool, keyed by tool name. Tools never invoked are absent.
+	ToolLatencies map[string]LatencySnapshot
+
+	// ResourceTemplates holds the usage of each resource template registered
+	// with AddResourceTemplate, keyed by URI template. Templates never read
+	// are absent.
+	ResourceTemplates map[string]TemplateSnapshot
+
+	// Clients holds the traffic of each client, keyed by the name it reports,
+	// when Config.ClientLimits is set. It is nil otherwise. Only the first
+	// 256 names seen are tracked on their own; later ones are counted
+	// together under "(other)".
+	Clients map[string]ClientSnapshot
 
 	// Cache statistics
 	CacheHits    int64
@@ -44,12 +113,99 @@ type MetricsSnapshot struct {
 
 	// Error tracking
 	Errors int64
+
+	// Tool calls and the fraction of them that failed over the sliding window
+	// set by Config.Degraded.Window (DefaultDegradedWindow if unset).
+	RecentToolCalls     int64
+	RecentToolErrorRate float64
+
+	// Degraded reports whether the server is in degraded mode. It is only
+	// populated by Server.GetMetrics.
+	Degraded bool
+
+	// Transport connections
+	ActiveConnections int64 // Clients currently connected
+	TotalConnections  int64 // Clients connected since start
+
+	// Tool invocation queue (see Config.MaxConcurrentTools)
+	QueueDepth    int64 // Tool calls currently waiting for a worker
+	QueueRejected int64 // Tool calls rejected because the queue was full
+
+	// Stdio write buffer (see Config.Stdio)
+	StdioBufferedBytes   int64 // Bytes of outgoing messages waiting to be written
+	StdioBufferWaits     int64 // Messages that waited for room in a full buffer
+	StdioDroppedMessages int64 // Notifications dropped because the buffer was full
+
+	// Incoming JSON-RPC messages, as dispatched by the transport
+	SingleMessages  int64             // Messages received on their own
+	Batches         int64             // JSON-RPC batches received
+	BatchedMessages int64             // Messages received inside batches
+	BatchSizes      []BatchSizeBucket // Histogram of batch sizes
+
+	// Custom holds the application metrics registered with
+	// Server.RegisterCounter and Server.RegisterGauge, keyed by name. It is
+	// nil when none are registered.
+	Custom map[string]CustomMetric
+
+	// Cache holds the cache's own Ristretto statistics. It is only populated
+	// by Server.GetMetrics, and only when caching is enabled.
+	Cache CacheSnapshot
+}
+
+// CacheSnapshot provides a point-in-time view of the cache's Ristretto metrics.
+//
+// Unlike the CacheHits/CacheMisses counters in MetricsSnapshot, which count what
+// tools report via IncrementCacheHits/IncrementCacheMisses, these values come
+// directly from the cache and cover every Get and Set.
+type CacheSnapshot struct {
+	Enabled        bool    `json:"enabled"`        // False when caching is disabled; all other fields are zero
+	Hits           uint64  `json:"hits"`           // Gets that found a value
+	Misses         uint64  `json:"misses"`         // Gets that found nothing
+	Ratio          float64 `json:"ratio"`          // Hits / (Hits + Misses)
+	KeysAdded      uint64  `json:"keysAdded"`      // New keys admitted to the cache
+	KeysUpdated    uint64  `json:"keysUpdated"`    // Existing keys overwritten
+	KeysEvicted    uint64  `json:"keysEvicted"`    // Keys evicted to stay within MaxCost
+	CostAdded      uint64  `json:"costAdded"`      // Total cost of admitted keys
+	CostEvicted    uint64  `json:"costEvicted"`    // Total cost of evicted keys
+	Rejected       int64   `json:"rejected"`       // Sets the cache did not store (oversized, dropped, or refused by admission)
+	TrackedKeys    int     `json:"trackedKeys"`    // Keys whose TTL the cache is tracking
+	PressureClears int64   `json:"pressureClears"` // Times the cache was cleared because memory use exceeded CacheConfig.MemoryLimit
+}
+
+// newCacheSnapshot copies the current values out of the cache and its Ristretto metrics.
+func newCacheSnapshot(c *cache.Cache) CacheSnapshot {
+	m := c.Metrics()
+	return CacheSnapshot{
+		Enabled:        true,
+		Hits:           m.Hits(),
+		Misses:         m.Misses(),
+		Ratio:          m.Ratio(),
+		KeysAdded:      m.KeysAdded(),
+		KeysUpdated:    m.KeysUpdated(),
+		KeysEvicted:    m.KeysEvicted(),
+		CostAdded:      m.CostAdded(),
+		CostEvicted:    m.CostEvicted(),
+		Rejected:       c.Rejected(),
+		TrackedKeys:    c.TrackedKeys(),
+		PressureClears: c.PressureClears(),
+	}
 }
 
 // newMetrics creates a new Metrics instance with the current time as start time.
 func newMetrics() *Metrics {
+	return newMetricsWithClock(nil)
+}
+
+// newMetricsWithClock creates a new Metrics instance that measures uptime with
+// clock, or with the system clock if clock is nil.
+func newMetricsWithClock(clock Clock) *Metrics {
+	if clock == nil {
+		clock = cache.SystemClock()
+	}
 	return &Metrics{
-		startTime: time.Now(),
+		startTime:    clock.Now(),
+		clock:        clock,
+		toolOutcomes: newErrorWindow(DefaultDegradedWindow, clock),
 	}
 }
 
@@ -58,6 +214,21 @@ func (m *Metrics) IncrementToolInvocations() {
 	m.toolInvocations.Add(1)
 }
 
+// IncrementSlowToolInvocations increments the slow tool invocation counter.
+//
+// It is called automatically for tools registered with AddTool when an
+// invocation exceeds Config.SlowToolThreshold.
+func (m *Metrics) IncrementSlowToolInvocations() {
+	m.slowToolInvocations.Add(1)
+}
+
+// ObserveToolDuration records how long one invocation of the named tool took.
+//
+// It is called automatically for tools registered with AddTool.
+func (m *Metrics) ObserveToolDuration(tool string, duration time.Duration) {
+	m.toolLatencies.observe(tool, duration)
+}
+
 // IncrementResourceReads increments the resource read counter.
 func (m *Metrics) IncrementResourceReads() {
 	m.resourceReads.Add(1)
@@ -78,6 +249,80 @@ func (m *Metrics) IncrementErrors() {
 	m.errors.Add(1)
 }
 
+// RecordError records an error for RecentErrors, tagged with the tool whose
+// call failed, or an empty tool for errors outside tool calls. Unlike
+// IncrementErrors it does not change the error counter. Failed tool calls and
+// resource loader failures are recorded by the server itself. A nil err is
+// ignored.
+func (m *Metrics) RecordError(tool string, err error) {
+	if err == nil {
+		return
+	}
+	m.recordError(tool, err.Error())
+}
+
+// recordError records message for RecentErrors.
+func (m *Metrics) recordError(tool, message string) {
+	if m.recentErrors == nil {
+		return
+	}
+	m.recentErrors.add(ErrorRecord{Time: m.clock.Now(), Tool: tool, Message: message})
+}
+
+// RecentErrors returns the errors kept for Config.RecentErrors, oldest first,
+// or nil if none are kept.
+func (m *Metrics) RecentErrors() []ErrorRecord {
+	return m.recentErrors.list()
+}
+
+// ObserveToolResult records whether a tool call failed, for the error rate
+// over the sliding window that drives degraded mode. The server records every
+// tool call itself.
+func (m *Metrics) ObserveToolResult(failed bool) {
+	m.toolOutcomes.observe(failed)
+}
+
+// IncrementConnections records a newly connected client.
+//
+// It is called by the transports when a client session starts; the stdio
+// transport counts its single client for as long as it runs.
+func (m *Metrics) IncrementConnections() {
+	m.activeConnections.Add(1)
+	m.totalConnections.Add(1)
+}
+
+// DecrementActiveConnections records a client disconnecting.
+func (m *Metrics) DecrementActiveConnections() {
+	m.activeConnections.Add(-1)
+}
+
+// RecordMessages records incoming JSON-RPC traffic as dispatched by a transport.
+//
+// A size of 1 counts a message received on its own; larger sizes count a batch
+// of that many messages. The built-in transports call this for every message
+// or batch they read; only stdio accepts batches.
+func (m *Metrics) RecordMessages(size int) {
+	if size <= 0 {
+		return
+	}
+	if size == 1 {
+		m.singleMessages.Add(1)
+		return
+	}
+
+	m.batches.Add(1)
+	m.batchedMessages.Add(int64(size))
+
+	bucket := len(batchSizeBuckets)
+	for i, upper := range batchSizeBuckets {
+		if size <= upper {
+			bucket = i
+			break
+		}
+	}
+	m.batchSizes[bucket].Add(1)
+}
+
 // Snapshot creates a point-in-time snapshot of current metrics.
 func (m *Metrics) Snapshot() MetricsSnapshot {
 	hits := m.cacheHits.Load()
@@ -89,15 +334,59 @@ func (m *Metrics) Snapshot() MetricsSnapshot {
 		hitRate = float64(hits) / float64(totalCacheAccess)
 	}
 
+	recentCalls, recentErrors := m.toolOutcomes.counts()
+	var recentRate float64
+	if recentCalls > 0 {
+		recentRate = float64(recentErrors) / float64(recentCalls)
+	}
+
 	return MetricsSnapshot{
-		Uptime:          time.Since(m.startTime),
+		Uptime:          m.clock.Now().Sub(m.startTime),
 		ToolInvocations: m.toolInvocations.Load(),
 		ResourceReads:   m.resourceReads.Load(),
 		CacheHits:       hits,
 		CacheMisses:     misses,
 		CacheHitRate:    hitRate,
 		Errors:          m.errors.Load(),
+
+		RecentToolCalls:     recentCalls,
+		RecentToolErrorRate: recentRate,
+
+		SlowToolInvocations:  m.slowToolInvocations.Load(),
+		OversizedToolOutputs: m.oversizedToolOutputs.Load(),
+		ToolLatencies:        m.toolLatencies.snapshot(),
+		ResourceTemplates:    m.templateUsage.snapshot(),
+		Clients:              m.clientUsage.snapshot(),
+
+		ActiveConnections: m.activeConnections.Load(),
+		TotalConnections:  m.totalConnections.Load(),
+
+		QueueDepth:    m.queueDepth.Load(),
+		QueueRejected: m.queueRejected.Load(),
+
+		StdioBufferedBytes:   m.stdioBuffered.Load(),
+		StdioBufferWaits:     m.stdioBufferWaits.Load(),
+		StdioDroppedMessages: m.stdioDropped.Load(),
+
+		SingleMessages:  m.singleMessages.Load(),
+		Batches:         m.batches.Load(),
+		BatchedMessages: m.batchedMessages.Load(),
+		BatchSizes:      m.batchSizeSnapshot(),
+
+		Custom: m.custom.snapshot(),
+	}
+}
+
+// batchSizeSnapshot copies the batch size histogram.
+func (m *Metrics) batchSizeSnapshot() []BatchSizeBucket {
+	buckets := make([]BatchSizeBucket, len(m.batchSizes))
+	for i := range m.batchSizes {
+		if i < len(batchSizeBuckets) {
+			buckets[i].UpperBound = batchSizeBuckets[i]
+		}
+		buckets[i].Count = m.batchSizes[i].Load()
 	}
+	return buckets
 }
 
 // GetMetrics returns a snapshot of current server metrics.
@@ -111,8 +400,19 @@ func (m *Metrics) Snapshot() MetricsSnapshot {
 //	fmt.Printf("Uptime: %v\n", metrics.Uptime)
 //	fmt.Printf("Tool invocations: %d\n", metrics.ToolInvocations)
 //	fmt.Printf("Cache hit rate: %.2f%%\n", metrics.CacheHitRate*100)
+//
+// When caching is enabled, the snapshot's Cache field also reports the
+// cache's Ristretto statistics (keys added, cost added/evicted, hit ratio).
 func (s *Server) GetMetrics() MetricsSnapshot {
-	return s.metrics.Snapshot()
+	snapshot := s.metrics.Snapshot()
+	snapshot.Degraded = s.Degraded()
+
+	// The minimal cache used when caching is disabled isn't worth reporting
+	if s.cacheEnabled() && s.cache != nil {
+		snapshot.Cache = newCacheSnapshot(s.cache)
+	}
+
+	return snapshot
 }
 
 // Metrics returns the raw Metrics instance for direct access.
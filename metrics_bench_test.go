@@ -0,0 +1,59 @@
+package hypermcp
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// newBenchMetrics returns metrics populated the way a busy server's are.
+func newBenchMetrics() *Metrics {
+	m := newMetrics()
+	for i := 0; i < 20; i++ {
+		tool := fmt.Sprintf("tool_%d", i)
+		for j := 0; j < 100; j++ {
+			m.ObserveToolDuration(tool, time.Duration(j)*time.Millisecond)
+		}
+	}
+	for i := 0; i < 100; i++ {
+		m.IncrementToolInvocations()
+		m.IncrementCacheHits()
+		m.toolOutcomes.observe(i%10 == 0)
+	}
+	return m
+}
+
+func BenchmarkMetrics_Snapshot(b *testing.B) {
+	m := newBenchMetrics()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = m.Snapshot()
+	}
+}
+
+// BenchmarkMetrics_SnapshotWhileCalled snapshots after every tool call, so
+// the called tool's histogram is copied afresh each time.
+func BenchmarkMetrics_SnapshotWhileCalled(b *testing.B) {
+	m := newBenchMetrics()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.ObserveToolDuration("tool_0", time.Millisecond)
+		_ = m.Snapshot()
+	}
+}
+
+func BenchmarkMetrics_SnapshotParallel(b *testing.B) {
+	m := newBenchMetrics()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_ = m.Snapshot()
+		}
+	})
+}
@@ -0,0 +1,54 @@
+package hypermcp
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// logMetrics logs a metrics snapshot every interval until ctx is canceled,
+// then closes done.
+func (s *Server) logMetrics(ctx context.Context, interval time.Duration, done chan<- struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.log().Info("periodic metrics", metricsFields(s.GetMetrics())...)
+		}
+	}
+}
+
+// metricsFields returns the headline values of snap as log fields.
+func metricsFields(snap MetricsSnapshot) []zap.Field {
+	fields := []zap.Field{
+		zap.Duration("uptime", snap.Uptime),
+		zap.Int64("tool_invocations", snap.ToolInvocations),
+		zap.Int64("resource_reads", snap.ResourceReads),
+		zap.Int64("slow_tool_invocations", snap.SlowToolInvocations),
+		zap.Int64("cache_hits", snap.CacheHits),
+		zap.Int64("cache_misses", snap.CacheMisses),
+		zap.Float64("cache_hit_rate", snap.CacheHitRate),
+		zap.Int64("errors", snap.Errors),
+		zap.Int64("recent_tool_calls", snap.RecentToolCalls),
+		zap.Float64("recent_tool_error_rate", snap.RecentToolErrorRate),
+		zap.Bool("degraded", snap.Degraded),
+		zap.Int64("active_connections", snap.ActiveConnections),
+		zap.Int64("queue_depth", snap.QueueDepth),
+		zap.Int64("queue_rejected", snap.QueueRejected),
+	}
+	if snap.Cache.Enabled {
+		fields = append(fields,
+			zap.Float64("cache_store_hit_ratio", snap.Cache.Ratio),
+			zap.Uint64("cache_store_keys_evicted", snap.Cache.KeysEvicted),
+			zap.Int64("cache_store_rejected", snap.Cache.Rejected),
+		)
+	}
+	return fields
+}
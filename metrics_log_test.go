@@ -0,0 +1,74 @@
+package hypermcp
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rayprogramming/hypermcp/cache"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestServer_MetricsLogInterval(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	srv, err := New(Config{
+		Name:               "test-server",
+		Version:            "1.0.0",
+		CacheEnabled:       true,
+		CacheConfig:        cache.DefaultConfig(),
+		MetricsLogInterval: 5 * time.Millisecond,
+	}, zap.New(core))
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	srv.Metrics().IncrementToolInvocations()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for logs.FilterMessage("periodic metrics").Len() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("expected a periodic metrics log")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	fields := logs.FilterMessage("periodic metrics").All()[0].ContextMap()
+	if fields["tool_invocations"] != int64(1) {
+		t.Errorf("logged tool_invocations = %v, want 1", fields["tool_invocations"])
+	}
+	if _, ok := fields["cache_store_hit_ratio"]; !ok {
+		t.Errorf("expected cache store fields while caching is enabled, got %v", fields)
+	}
+
+	if err := srv.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	logged := logs.FilterMessage("periodic metrics").Len()
+	time.Sleep(30 * time.Millisecond)
+	if got := logs.FilterMessage("periodic metrics").Len(); got != logged {
+		t.Errorf("expected no periodic metrics logs after shutdown, got %d more", got-logged)
+	}
+}
+
+func TestServer_MetricsLogInterval_Disabled(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	srv, err := New(Config{Name: "test-server", Version: "1.0.0"}, zap.New(core))
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	defer func() { _ = srv.Shutdown(context.Background()) }()
+
+	time.Sleep(20 * time.Millisecond)
+	if got := logs.FilterMessage("periodic metrics").Len(); got != 0 {
+		t.Errorf("expected no periodic metrics logs by default, got %d", got)
+	}
+}
+
+func TestConfig_Validate_MetricsLogInterval(t *testing.T) {
+	cfg := Config{Name: "test-server", Version: "1.0.0", MetricsLogInterval: -time.Second}
+	var cfgErr *ConfigError
+	if err := cfg.Validate(); !errors.As(err, &cfgErr) || cfgErr.Field != "MetricsLogInterval" {
+		t.Errorf("Validate() error = %v, want ConfigError for MetricsLogInterval", err)
+	}
+}
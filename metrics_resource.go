@@ -0,0 +1,181 @@
+package hypermcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// DefaultMetricsResourceURI is the URI AddMetricsResource registers the
+// metrics at when given an empty URI.
+const DefaultMetricsResourceURI = "hypermcp://metrics"
+
+// AddMetricsResource registers a resource at uri, or at
+// DefaultMetricsResourceURI if uri is empty, that returns the current
+// MetricsSnapshot as JSON, for clients that prefer reading metrics to calling
+// a tool. Each read takes a fresh snapshot with GetMetrics, so it reflects the
+// counters at the time of the read; see MetricsReport for the encoding.
+// Nothing is registered unless this is called.
+func (s *Server) AddMetricsResource(uri string) {
+	if uri == "" {
+		uri = DefaultMetricsResourceURI
+	}
+	s.AddResource(&mcp.Resource{
+		URI:         uri,
+		Name:        "metrics",
+		Description: "Current server metrics",
+		MIMEType:    "application/json",
+	}, func(_ context.Context, _ *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+		data, err := json.Marshal(newMetricsReport(s.GetMetrics()))
+		if err != nil {
+			return nil, fmt.Errorf("encode metrics: %w", err)
+		}
+		return &mcp.ReadResourceResult{
+			Contents: []*mcp.ResourceContents{{
+				URI:      uri,
+				MIMEType: "application/json",
+				Text:     string(data),
+			}},
+		}, nil
+	})
+}
+
+// MetricsReport is the JSON served by AddMetricsResource: a MetricsSnapshot
+// with camelCase keys, and durations encoded as in VersionReport, both as a
+// string such as "1.5s" and as a number of seconds. Every MetricsSnapshot
+// field has a counterpart of the same name.
+type MetricsReport struct {
+	Uptime        string  `json:"uptime"`
+	UptimeSeconds float64 `json:"uptimeSeconds"`
+
+	ToolInvocations      int64 `json:"toolInvocations"`
+	ResourceReads        int64 `json:"resourceReads"`
+	SlowToolInvocations  int64 `json:"slowToolInvocations"`
+	OversizedToolOutputs int64 `json:"oversizedToolOutputs"`
+
+	ToolLatencies     map[string]LatencyReport    `json:"toolLatencies,omitempty"`
+	ResourceTemplates map[string]TemplateSnapshot `json:"resourceTemplates,omitempty"`
+	Clients           map[string]ClientSnapshot   `json:"clients,omitempty"`
+
+	CacheHits    int64   `json:"cacheHits"`
+	CacheMisses  int64   `json:"cacheMisses"`
+	CacheHitRate float64 `json:"cacheHitRate"`
+
+	Errors              int64   `json:"errors"`
+	RecentToolCalls     int64   `json:"recentToolCalls"`
+	RecentToolErrorRate float64 `json:"recentToolErrorRate"`
+	Degraded            bool    `json:"degraded"`
+
+	ActiveConnections int64 `json:"activeConnections"`
+	TotalConnections  int64 `json:"totalConnections"`
+
+	QueueDepth    int64 `json:"queueDepth"`
+	QueueRejected int64 `json:"queueRejected"`
+
+	StdioBufferedBytes   int64 `json:"stdioBufferedBytes"`
+	StdioBufferWaits     int64 `json:"stdioBufferWaits"`
+	StdioDroppedMessages int64 `json:"stdioDroppedMessages"`
+
+	SingleMessages  int64             `json:"singleMessages"`
+	Batches         int64             `json:"batches"`
+	BatchedMessages int64             `json:"batchedMessages"`
+	BatchSizes      []BatchSizeBucket `json:"batchSizes"`
+
+	Custom map[string]CustomMetric `json:"custom,omitempty"`
+	Cache  CacheSnapshot           `json:"cache"`
+}
+
+// LatencyReport is a LatencySnapshot in MetricsReport.
+type LatencyReport struct {
+	Count int64 `json:"count"`
+
+	Sum        string  `json:"sum"`
+	SumSeconds float64 `json:"sumSeconds"`
+	Max        string  `json:"max"`
+	MaxSeconds float64 `json:"maxSeconds"`
+	P50        string  `json:"p50"`
+	P50Seconds float64 `json:"p50Seconds"`
+	P95        string  `json:"p95"`
+	P95Seconds float64 `json:"p95Seconds"`
+	P99        string  `json:"p99"`
+	P99Seconds float64 `json:"p99Seconds"`
+
+	Buckets []LatencyBucketReport `json:"buckets"`
+}
+
+// LatencyBucketReport is a LatencyBucket in LatencyReport. The overflow
+// bucket has an empty UpperBound and zero UpperBoundSeconds.
+type LatencyBucketReport struct {
+	UpperBound        string  `json:"upperBound"`
+	UpperBoundSeconds float64 `json:"upperBoundSeconds"`
+	Count             int64   `json:"count"`
+}
+
+// newMetricsReport converts snap to a MetricsReport.
+func newMetricsReport(snap MetricsSnapshot) MetricsReport {
+	report := MetricsReport{
+		Uptime:               snap.Uptime.String(),
+		UptimeSeconds:        snap.Uptime.Seconds(),
+		ToolInvocations:      snap.ToolInvocations,
+		ResourceReads:        snap.ResourceReads,
+		SlowToolInvocations:  snap.SlowToolInvocations,
+		OversizedToolOutputs: snap.OversizedToolOutputs,
+		ResourceTemplates:    snap.ResourceTemplates,
+		Clients:              snap.Clients,
+		CacheHits:            snap.CacheHits,
+		CacheMisses:          snap.CacheMisses,
+		CacheHitRate:         snap.CacheHitRate,
+		Errors:               snap.Errors,
+		RecentToolCalls:      snap.RecentToolCalls,
+		RecentToolErrorRate:  snap.RecentToolErrorRate,
+		Degraded:             snap.Degraded,
+		ActiveConnections:    snap.ActiveConnections,
+		TotalConnections:     snap.TotalConnections,
+		QueueDepth:           snap.QueueDepth,
+		QueueRejected:        snap.QueueRejected,
+		StdioBufferedBytes:   snap.StdioBufferedBytes,
+		StdioBufferWaits:     snap.StdioBufferWaits,
+		StdioDroppedMessages: snap.StdioDroppedMessages,
+		SingleMessages:       snap.SingleMessages,
+		Batches:              snap.Batches,
+		BatchedMessages:      snap.BatchedMessages,
+		BatchSizes:           snap.BatchSizes,
+		Custom:               snap.Custom,
+		Cache:                snap.Cache,
+	}
+	if len(snap.ToolLatencies) > 0 {
+		report.ToolLatencies = make(map[string]LatencyReport, len(snap.ToolLatencies))
+		for tool, latency := range snap.ToolLatencies {
+			report.ToolLatencies[tool] = newLatencyReport(latency)
+		}
+	}
+	return report
+}
+
+// newLatencyReport converts snap to a LatencyReport.
+func newLatencyReport(snap LatencySnapshot) LatencyReport {
+	report := LatencyReport{
+		Count:      snap.Count,
+		Sum:        snap.Sum.String(),
+		SumSeconds: snap.Sum.Seconds(),
+		Max:        snap.Max.String(),
+		MaxSeconds: snap.Max.Seconds(),
+		P50:        snap.P50.String(),
+		P50Seconds: snap.P50.Seconds(),
+		P95:        snap.P95.String(),
+		P95Seconds: snap.P95.Seconds(),
+		P99:        snap.P99.String(),
+		P99Seconds: snap.P99.Seconds(),
+		Buckets:    make([]LatencyBucketReport, len(snap.Buckets)),
+	}
+	for i, bucket := range snap.Buckets {
+		report.Buckets[i].Count = bucket.Count
+		if bucket.UpperBound > 0 {
+			report.Buckets[i].UpperBound = bucket.UpperBound.String()
+			report.Buckets[i].UpperBoundSeconds = bucket.UpperBound.Seconds()
+		}
+	}
+	return report
+}
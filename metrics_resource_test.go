@@ -0,0 +1,124 @@
+package hypermcp
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestServer_AddMetricsResource_CamelCase(t *testing.T) {
+	srv, err := New(Config{Name: "test-server", Version: "1.0.0"}, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	srv.AddMetricsResource("")
+	session := connectTestClient(t, srv)
+
+	res, err := session.ReadResource(context.Background(), &mcp.ReadResourceParams{URI: DefaultMetricsResourceURI})
+	if err != nil {
+		t.Fatalf("ReadResource() error = %v", err)
+	}
+	var raw map[string]any
+	if err := json.Unmarshal([]byte(res.Contents[0].Text), &raw); err != nil {
+		t.Fatalf("decode metrics: %v", err)
+	}
+	for _, key := range []string{"uptime", "uptimeSeconds", "toolInvocations", "cacheHitRate", "batchSizes", "cache"} {
+		if _, ok := raw[key]; !ok {
+			t.Errorf("metrics JSON is missing %q: %s", key, res.Contents[0].Text)
+		}
+	}
+	if _, ok := raw["Uptime"]; ok {
+		t.Errorf("metrics JSON uses Go field names: %s", res.Contents[0].Text)
+	}
+}
+
+// TestMetricsReport_CoversSnapshot guards against fields added to the
+// snapshots being left out of the metrics resource.
+func TestMetricsReport_CoversSnapshot(t *testing.T) {
+	tests := []struct {
+		snapshot, report reflect.Type
+	}{
+		{reflect.TypeFor[MetricsSnapshot](), reflect.TypeFor[MetricsReport]()},
+		{reflect.TypeFor[LatencySnapshot](), reflect.TypeFor[LatencyReport]()},
+		{reflect.TypeFor[LatencyBucket](), reflect.TypeFor[LatencyBucketReport]()},
+	}
+	for _, tt := range tests {
+		for i := range tt.snapshot.NumField() {
+			name := tt.snapshot.Field(i).Name
+			if _, ok := tt.report.FieldByName(name); !ok {
+				t.Errorf("%s.%s has no counterpart in %s", tt.snapshot.Name(), name, tt.report.Name())
+			}
+		}
+	}
+}
+
+func TestServer_AddMetricsResource(t *testing.T) {
+	tests := []struct {
+		name    string
+		uri     string
+		wantURI string
+	}{
+		{name: "default URI", wantURI: DefaultMetricsResourceURI},
+		{name: "custom URI", uri: "app://stats", wantURI: "app://stats"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := newFakeClock()
+			srv, err := New(Config{Name: "test-server", Version: "1.0.0", Clock: clock}, zaptest.NewLogger(t))
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			srv.AddMetricsResource(tt.uri)
+			session := connectTestClient(t, srv)
+
+			read := func() MetricsReport {
+				t.Helper()
+				res, err := session.ReadResource(context.Background(), &mcp.ReadResourceParams{URI: tt.wantURI})
+				if err != nil {
+					t.Fatalf("ReadResource() error = %v", err)
+				}
+				if len(res.Contents) != 1 || res.Contents[0].MIMEType != "application/json" {
+					t.Fatalf("expected one JSON content, got %+v", res.Contents)
+				}
+				var snap MetricsReport
+				if err := json.Unmarshal([]byte(res.Contents[0].Text), &snap); err != nil {
+					t.Fatalf("decode metrics: %v", err)
+				}
+				return snap
+			}
+
+			if snap := read(); snap.ToolInvocations != 0 || snap.Errors != 0 {
+				t.Errorf("expected zeroed counters, got %+v", snap)
+			}
+
+			srv.metrics.IncrementToolInvocations()
+			srv.metrics.IncrementToolInvocations()
+			srv.metrics.IncrementCacheHits()
+			srv.metrics.IncrementCacheMisses()
+			srv.metrics.IncrementErrors()
+			srv.metrics.ObserveToolDuration("echo", 250*time.Millisecond)
+			clock.Advance(time.Minute)
+
+			snap := read()
+			if snap.ToolInvocations != 2 || snap.Errors != 1 {
+				t.Errorf("expected 2 invocations and 1 error, got %d and %d", snap.ToolInvocations, snap.Errors)
+			}
+			if snap.CacheHits != 1 || snap.CacheMisses != 1 || snap.CacheHitRate != 0.5 {
+				t.Errorf("expected 1 hit, 1 miss, rate 0.5, got %d, %d, %v", snap.CacheHits, snap.CacheMisses, snap.CacheHitRate)
+			}
+			if snap.Uptime != "1m0s" || snap.UptimeSeconds != 60 {
+				t.Errorf("expected uptime 1m0s (60 seconds), got %q (%v)", snap.Uptime, snap.UptimeSeconds)
+			}
+			latency := snap.ToolLatencies["echo"]
+			if latency.Count != 1 || latency.Max != "250ms" || latency.MaxSeconds != 0.25 {
+				t.Errorf("expected one 250ms echo call, got %+v", latency)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package hypermcp
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// metricsSinkStep is the name Shutdown reports Config.MetricsSink errors
+// under.
+const metricsSinkStep = "metrics sink"
+
+// pushMetrics passes a metrics snapshot to sink every interval until ctx is
+// canceled, then closes done. Failed pushes are logged; the next one is
+// attempted on schedule.
+func (s *Server) pushMetrics(ctx context.Context, sink func(MetricsSnapshot) error, interval time.Duration, done chan<- struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.callMetricsSink(ctx, sink); err != nil {
+				s.log().Warn("metrics sink failed", zap.Error(err))
+			}
+		}
+	}
+}
+
+// flushMetricsSink passes the final metrics snapshot to Config.MetricsSink,
+// once, returning its error as a ShutdownError.
+func (s *Server) flushMetricsSink(ctx context.Context) error {
+	sink := s.config.MetricsSink
+	if sink == nil || !s.metricsSinkFlushed.CompareAndSwap(false, true) {
+		return nil
+	}
+	if err := s.callMetricsSink(ctx, sink); err != nil {
+		s.log().Error("metrics sink failed", zap.Error(err))
+		return NewShutdownError(metricsSinkStep, err)
+	}
+	return nil
+}
+
+// callMetricsSink passes the current metrics snapshot to sink, returning once
+// it does or ctx is done, whichever comes first. The sink runs in its own
+// goroutine so a stalled one can't hold up Shutdown past its deadline; a
+// panic in it is returned as an error.
+func (s *Server) callMetricsSink(ctx context.Context, sink func(MetricsSnapshot) error) error {
+	snapshot := s.GetMetrics()
+
+	result := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				result <- fmt.Errorf("panic: %v", r)
+			}
+		}()
+		result <- sink(snapshot)
+	}()
+
+	select {
+	case err := <-result:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
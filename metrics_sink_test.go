@@ -0,0 +1,159 @@
+package hypermcp
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zaptest"
+)
+
+// memorySink records the snapshots passed to Config.MetricsSink.
+type memorySink struct {
+	mu        sync.Mutex
+	snapshots []MetricsSnapshot
+	err       error
+}
+
+func (s *memorySink) push(snap MetricsSnapshot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.snapshots = append(s.snapshots, snap)
+	return s.err
+}
+
+func (s *memorySink) received() []MetricsSnapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]MetricsSnapshot(nil), s.snapshots...)
+}
+
+func TestServer_Shutdown_MetricsSink(t *testing.T) {
+	sink := &memorySink{}
+	srv, err := New(Config{Name: "test-server", Version: "1.0.0", MetricsSink: sink.push}, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	// A hook's work is included in the final snapshot
+	srv.OnShutdown("last call", func(context.Context) error {
+		srv.Metrics().IncrementToolInvocations()
+		return nil
+	})
+	srv.Metrics().IncrementToolInvocations()
+
+	if err := srv.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if err := srv.Shutdown(context.Background()); err != nil {
+		t.Fatalf("second Shutdown() error = %v", err)
+	}
+
+	snaps := sink.received()
+	if len(snaps) != 1 {
+		t.Fatalf("sink received %d snapshots, want 1", len(snaps))
+	}
+	if snaps[0].ToolInvocations != 2 {
+		t.Errorf("final ToolInvocations = %d, want 2", snaps[0].ToolInvocations)
+	}
+}
+
+func TestServer_Shutdown_MetricsSinkError(t *testing.T) {
+	errSink := errors.New("statsd unreachable")
+	sink := &memorySink{err: errSink}
+	srv, err := New(Config{Name: "test-server", Version: "1.0.0", MetricsSink: sink.push}, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	errHook := errors.New("flush failed")
+	srv.OnShutdown("flush", func(context.Context) error { return errHook })
+
+	err = srv.Shutdown(context.Background())
+	if !errors.Is(err, errSink) || !errors.Is(err, errHook) {
+		t.Fatalf("Shutdown() error = %v, want the sink and hook errors", err)
+	}
+	var shutdownErr *ShutdownError
+	for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+		if errors.As(e, &shutdownErr) && shutdownErr.Step == metricsSinkStep && errors.Is(e, errSink) {
+			return
+		}
+	}
+	t.Errorf("Shutdown() error = %v, want a ShutdownError for step %q", err, metricsSinkStep)
+}
+
+func TestServer_Shutdown_StalledMetricsSink(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	sink := func(MetricsSnapshot) error {
+		<-release
+		return nil
+	}
+	srv, err := New(Config{Name: "test-server", Version: "1.0.0", MetricsSink: sink}, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err = srv.Shutdown(ctx)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Shutdown() took %v with a stalled sink, want it bounded by the deadline", elapsed)
+	}
+	if !errors.Is(err, ErrShutdownTimeout) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Shutdown() error = %v, want ErrShutdownTimeout", err)
+	}
+}
+
+func TestServer_MetricsSinkInterval(t *testing.T) {
+	sink := &memorySink{}
+	srv, err := New(Config{
+		Name:                "test-server",
+		Version:             "1.0.0",
+		MetricsSink:         sink.push,
+		MetricsSinkInterval: 5 * time.Millisecond,
+	}, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(sink.received()) < 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("no periodic pushes to the metrics sink")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if err := srv.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	pushed := len(sink.received())
+	time.Sleep(20 * time.Millisecond)
+	if got := len(sink.received()); got != pushed {
+		t.Errorf("sink received %d more snapshots after Shutdown", got-pushed)
+	}
+}
+
+func TestConfig_Validate_MetricsSink(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+	}{
+		{name: "negative interval", cfg: Config{MetricsSink: (&memorySink{}).push, MetricsSinkInterval: -time.Second}},
+		{name: "interval without sink", cfg: Config{MetricsSinkInterval: time.Second}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := tt.cfg
+			cfg.Name, cfg.Version = "test-server", "1.0.0"
+			var cfgErr *ConfigError
+			if err := cfg.Validate(); !errors.As(err, &cfgErr) || cfgErr.Field != "MetricsSinkInterval" {
+				t.Errorf("Validate() error = %v, want ConfigError for MetricsSinkInterval", err)
+			}
+		})
+	}
+}
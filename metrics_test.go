@@ -1,9 +1,12 @@
 package hypermcp
 
 import (
+	"context"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/rayprogramming/hypermcp/cache"
 	"go.uber.org/zap/zaptest"
 )
 
@@ -101,18 +104,64 @@ func TestMetrics_CacheHitRate(t *testing.T) {
 	}
 }
 
+// fakeClock is a Clock that only moves when advanced.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
 func TestMetrics_Uptime(t *testing.T) {
-	m := newMetrics()
+	clock := newFakeClock()
+	m := newMetricsWithClock(clock)
 
-	// Wait a bit
-	time.Sleep(10 * time.Millisecond)
+	if uptime := m.Snapshot().Uptime; uptime != 0 {
+		t.Errorf("expected zero uptime before the clock moves, got %v", uptime)
+	}
 
-	snapshot := m.Snapshot()
-	if snapshot.Uptime < 10*time.Millisecond {
-		t.Errorf("expected uptime >= 10ms, got %v", snapshot.Uptime)
+	clock.Advance(90 * time.Minute)
+	if uptime := m.Snapshot().Uptime; uptime != 90*time.Minute {
+		t.Errorf("expected uptime 1h30m, got %v", uptime)
+	}
+}
+
+func TestConfig_Clock(t *testing.T) {
+	clock := newFakeClock()
+	srv, err := New(Config{
+		Name:         "test-server",
+		Version:      "1.0.0",
+		CacheEnabled: true,
+		CacheConfig:  cache.DefaultConfig(),
+		Clock:        clock,
+	}, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
 	}
-	if snapshot.Uptime > 1*time.Second {
-		t.Errorf("expected uptime < 1s, got %v", snapshot.Uptime)
+	defer func() { _ = srv.Shutdown(context.Background()) }()
+
+	srv.Cache().SetWait("key", "value", time.Hour)
+	clock.Advance(2 * time.Hour)
+
+	if _, found := srv.Cache().Get("key"); found {
+		t.Error("expected the server clock to expire the cache entry")
+	}
+	if uptime := srv.GetMetrics().Uptime; uptime != 2*time.Hour {
+		t.Errorf("expected uptime 2h, got %v", uptime)
 	}
 }
 
@@ -192,3 +241,118 @@ func TestMetrics_Concurrent(t *testing.T) {
 		t.Errorf("expected %d errors, got %d", expected, snapshot.Errors)
 	}
 }
+
+func TestMetrics_Connections(t *testing.T) {
+	m := newMetrics()
+
+	m.IncrementConnections()
+	m.IncrementConnections()
+	m.DecrementActiveConnections()
+
+	snapshot := m.Snapshot()
+	if snapshot.ActiveConnections != 1 {
+		t.Errorf("expected 1 active connection, got %d", snapshot.ActiveConnections)
+	}
+	if snapshot.TotalConnections != 2 {
+		t.Errorf("expected 2 total connections, got %d", snapshot.TotalConnections)
+	}
+}
+
+func TestServer_GetMetrics_CacheStats(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	srv, err := New(Config{
+		Name:         "test-server",
+		Version:      "1.0.0",
+		CacheEnabled: true,
+		CacheConfig:  cache.DefaultConfig(),
+	}, logger)
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	defer func() { _ = srv.Shutdown(context.Background()) }()
+
+	c := srv.Cache()
+	c.SetWait("a", 1, time.Minute)
+	c.SetWait("b", 2, time.Minute)
+	c.SetWait("c", 3, time.Minute)
+	c.Get("a")
+	c.Get("b")
+	c.Get("missing")
+
+	stats := srv.GetMetrics().Cache
+	if !stats.Enabled {
+		t.Fatal("expected cache stats to be enabled")
+	}
+	if stats.KeysAdded != 3 {
+		t.Errorf("expected 3 keys added, got %d", stats.KeysAdded)
+	}
+	if stats.CostAdded == 0 {
+		t.Error("expected non-zero cost added")
+	}
+	if stats.Hits != 2 || stats.Misses != 1 {
+		t.Errorf("expected 2 hits and 1 miss, got %d hits and %d misses", stats.Hits, stats.Misses)
+	}
+	if stats.Ratio < 0.66 || stats.Ratio > 0.67 {
+		t.Errorf("expected ratio ~0.667, got %f", stats.Ratio)
+	}
+	if stats.TrackedKeys != 3 {
+		t.Errorf("expected 3 tracked keys, got %d", stats.TrackedKeys)
+	}
+	if stats.Rejected != 0 {
+		t.Errorf("expected no rejections, got %d", stats.Rejected)
+	}
+}
+
+func TestServer_GetMetrics_CacheDisabled(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	srv, err := New(Config{
+		Name:         "test-server",
+		Version:      "1.0.0",
+		CacheEnabled: false,
+	}, logger)
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+
+	srv.Cache().SetWait("a", 1, time.Minute)
+
+	if stats := srv.GetMetrics().Cache; stats != (CacheSnapshot{}) {
+		t.Errorf("expected empty cache stats when caching is disabled, got %+v", stats)
+	}
+}
+
+func TestMetrics_RecordMessages(t *testing.T) {
+	m := newMetrics()
+
+	for _, size := range []int{0, 1, 1, 2, 3, 5, 6, 50, 51, 200} {
+		m.RecordMessages(size)
+	}
+
+	snapshot := m.Snapshot()
+	if snapshot.SingleMessages != 2 {
+		t.Errorf("expected 2 single messages, got %d", snapshot.SingleMessages)
+	}
+	if snapshot.Batches != 7 {
+		t.Errorf("expected 7 batches, got %d", snapshot.Batches)
+	}
+	if snapshot.BatchedMessages != 2+3+5+6+50+51+200 {
+		t.Errorf("expected %d batched messages, got %d", 2+3+5+6+50+51+200, snapshot.BatchedMessages)
+	}
+
+	want := []BatchSizeBucket{
+		{UpperBound: 2, Count: 1},
+		{UpperBound: 5, Count: 2},
+		{UpperBound: 10, Count: 1},
+		{UpperBound: 25, Count: 0},
+		{UpperBound: 50, Count: 1},
+		{UpperBound: 0, Count: 2},
+	}
+	if len(snapshot.BatchSizes) != len(want) {
+		t.Fatalf("expected %d buckets, got %d", len(want), len(snapshot.BatchSizes))
+	}
+	for i, bucket := range snapshot.BatchSizes {
+		if bucket != want[i] {
+			t.Errorf("bucket %d: expected %+v, got %+v", i, want[i], bucket)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package hypermcp
+
+import (
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+)
+
+// Module is a reusable bundle of tools, resources, and prompts.
+//
+// Modules let related features be packaged and shared as importable packages.
+// Register is called once by Server.Install and should add the module's
+// features using the server's registration helpers (AddTool, AddResource, etc.)
+// so that they are counted.
+type Module interface {
+	Register(srv *Server) error
+}
+
+// ModuleFunc adapts an ordinary function to the Module interface.
+type ModuleFunc func(srv *Server) error
+
+// Register calls f(srv).
+func (f ModuleFunc) Register(srv *Server) error {
+	return f(srv)
+}
+
+// Install registers each module with the server, in order.
+//
+// Every module is installed even if an earlier one fails. Failures are wrapped in a
+// ModuleError and returned together via errors.Join, so callers can inspect each with
+// errors.As. Features a module registered before failing remain registered.
+//
+// Example:
+//
+//	if err := srv.Install(weather.Module(), geo.Module()); err != nil {
+//	    log.Fatal(err)
+//	}
+func (s *Server) Install(modules ...Module) error {
+	var errs []error
+
+	for _, m := range modules {
+		if m == nil {
+			continue
+		}
+
+		name := moduleName(m)
+		tools, resources := s.toolCount.Load(), s.resourceCount.Load()
+
+		if err := m.Register(s); err != nil {
+			s.log().Error("module registration failed",
+				zap.String("module", name),
+				zap.Error(err),
+			)
+			errs = append(errs, NewModuleError(name, err))
+			continue
+		}
+
+		s.log().Info("module installed",
+			zap.String("module", name),
+			zap.Int64("tools", s.toolCount.Load()-tools),
+			zap.Int64("resources", s.resourceCount.Load()-resources),
+		)
+	}
+
+	return errors.Join(errs...)
+}
+
+// moduleName returns a name identifying m in logs and errors. Modules can
+// provide their own by implementing fmt.Stringer; otherwise the type name is used.
+func moduleName(m Module) string {
+	if s, ok := m.(fmt.Stringer); ok {
+		return s.String()
+	}
+	return fmt.Sprintf("%T", m)
+}
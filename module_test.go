@@ -0,0 +1,114 @@
+package hypermcp
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap/zaptest"
+)
+
+// greetingModule registers a tool and a resource.
+type greetingModule struct{}
+
+func (greetingModule) String() string { return "greeting" }
+
+func (greetingModule) Register(srv *Server) error {
+	AddTool(srv, &mcp.Tool{
+		Name:        "greet",
+		Description: "Greets someone",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input struct {
+		Name string `json:"name"`
+	}) (*mcp.CallToolResult, any, error) {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: "Hello, " + input.Name}},
+		}, nil, nil
+	})
+
+	srv.AddResource(&mcp.Resource{
+		URI:  "greeting://default",
+		Name: "Default Greeting",
+	}, func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+		return &mcp.ReadResourceResult{
+			Contents: []*mcp.ResourceContents{{URI: req.Params.URI, Text: "Hello"}},
+		}, nil
+	})
+
+	return nil
+}
+
+func TestServer_Install(t *testing.T) {
+	srv, err := New(Config{
+		Name:    "test-server",
+		Version: "1.0.0",
+	}, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+
+	if err := srv.Install(greetingModule{}); err != nil {
+		t.Fatalf("install failed: %v", err)
+	}
+
+	if srv.toolCount.Load() != 1 || srv.resourceCount.Load() != 1 {
+		t.Errorf("expected 1 tool and 1 resource, got %d and %d", srv.toolCount.Load(), srv.resourceCount.Load())
+	}
+
+	session := connectTestClient(t, srv)
+
+	tools, err := session.ListTools(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("failed to list tools: %v", err)
+	}
+	if len(tools.Tools) != 1 || tools.Tools[0].Name != "greet" {
+		t.Errorf("expected greet tool, got %+v", tools.Tools)
+	}
+
+	res, err := session.ReadResource(context.Background(), &mcp.ReadResourceParams{URI: "greeting://default"})
+	if err != nil {
+		t.Fatalf("failed to read resource: %v", err)
+	}
+	if len(res.Contents) != 1 || res.Contents[0].Text != "Hello" {
+		t.Errorf("unexpected resource contents: %+v", res.Contents)
+	}
+}
+
+func TestServer_Install_AggregatesErrors(t *testing.T) {
+	srv, err := New(Config{
+		Name:    "test-server",
+		Version: "1.0.0",
+	}, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+
+	errFirst := errors.New("first failure")
+	errSecond := errors.New("second failure")
+
+	err = srv.Install(
+		ModuleFunc(func(*Server) error { return errFirst }),
+		greetingModule{},
+		ModuleFunc(func(*Server) error { return errSecond }),
+	)
+	if err == nil {
+		t.Fatal("expected error but got nil")
+	}
+
+	if !errors.Is(err, errFirst) || !errors.Is(err, errSecond) {
+		t.Errorf("expected both module errors, got %v", err)
+	}
+
+	var modErr *ModuleError
+	if !errors.As(err, &modErr) {
+		t.Fatalf("expected ModuleError, got %T", err)
+	}
+	if modErr.Module != "hypermcp.ModuleFunc" {
+		t.Errorf("expected module name %q, got %q", "hypermcp.ModuleFunc", modErr.Module)
+	}
+
+	// Modules after a failing one are still installed
+	if srv.toolCount.Load() != 1 {
+		t.Errorf("expected greeting module to be installed, got %d tools", srv.toolCount.Load())
+	}
+}
@@ -0,0 +1,153 @@
+package hypermcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+
+	"go.uber.org/zap"
+)
+
+// Defaults for ObservabilityConfig fields left empty.
+const (
+	DefaultMetricsPath = "/metrics"
+	DefaultHealthPath  = "/healthz"
+)
+
+// TracingShutdown flushes and stops the tracing set up by
+// ObservabilityConfig.InitTracing.
+type TracingShutdown func(ctx context.Context) error
+
+// ObservabilityConfig configures RunObservable.
+type ObservabilityConfig struct {
+	// MetricsAddr is the TCP address the metrics listener binds to, e.g.
+	// ":9090". Required unless MetricsListener is set.
+	MetricsAddr string
+
+	// MetricsListener, if set, is used for the metrics endpoint instead of
+	// binding MetricsAddr, e.g. a listener on port 0 or one inherited from a
+	// socket-activating supervisor. RunObservable takes ownership and closes
+	// it when it returns.
+	MetricsListener net.Listener
+
+	// MetricsPath is the URL path serving PrometheusHandler. Defaults to
+	// DefaultMetricsPath.
+	MetricsPath string
+
+	// HealthPath is the URL path serving Server.Health as JSON. Defaults to
+	// DefaultHealthPath.
+	HealthPath string
+
+	// InitTracing, if set, is called before the server starts to set up
+	// tracing, for example by installing an OpenTelemetry tracer provider.
+	// The returned shutdown func, if not nil, is called once the server has
+	// stopped, so buffered spans are exported before RunObservable returns.
+	InitTracing func(ctx context.Context) (TracingShutdown, error)
+}
+
+func (c ObservabilityConfig) metricsPath() string {
+	if c.MetricsPath == "" {
+		return DefaultMetricsPath
+	}
+	return c.MetricsPath
+}
+
+// closeListener closes MetricsListener when RunObservable returns before
+// serving on it.
+func (c ObservabilityConfig) closeListener() {
+	if c.MetricsListener != nil {
+		_ = c.MetricsListener.Close()
+	}
+}
+
+func (c ObservabilityConfig) healthPath() string {
+	if c.HealthPath == "" {
+		return DefaultHealthPath
+	}
+	return c.HealthPath
+}
+
+// RunObservable runs srv on the given transport like RunWithTransport, along
+// with an HTTP listener on cfg.MetricsAddr (or cfg.MetricsListener) serving the Prometheus metrics and
+// the health report, and the tracing set up by cfg.InitTracing.
+//
+// Everything stops when ctx is canceled or the transport returns: the metrics
+// listener is shut down and tracing flushed before RunObservable returns. If
+// the metrics listener fails, the transport is stopped too. If ctx is already
+// done, nothing is started, as with RunWithTransport.
+func RunObservable(ctx context.Context, cfg ObservabilityConfig, srv *Server, transportType TransportType, logger *zap.Logger) error {
+	if err := ctx.Err(); err != nil {
+		cfg.closeListener()
+		return NewTransportError(transportType, fmt.Errorf("not started: %w", err))
+	}
+	if cfg.MetricsAddr == "" && cfg.MetricsListener == nil {
+		return NewConfigError("MetricsAddr", fmt.Errorf("cannot be empty without MetricsListener"))
+	}
+	if srv.config.LogWriter != nil {
+		logger = redirectLogger(logger, srv.config.LogWriter)
+	}
+
+	if cfg.InitTracing != nil {
+		shutdown, err := cfg.InitTracing(ctx)
+		if err != nil {
+			cfg.closeListener()
+			return fmt.Errorf("initialize tracing: %w", err)
+		}
+		if shutdown != nil {
+			defer func() {
+				shutdownCtx, cancel := context.WithTimeout(context.Background(), httpShutdownTimeout)
+				defer cancel()
+				if err := shutdown(shutdownCtx); err != nil {
+					logger.Warn("tracing did not shut down cleanly", zap.Error(err))
+				}
+			}()
+		}
+	}
+
+	ln := cfg.MetricsListener
+	if ln == nil {
+		var lc net.ListenConfig
+		var err error
+		ln, err = lc.Listen(ctx, "tcp", cfg.MetricsAddr)
+		if err != nil {
+			return fmt.Errorf("listen on %s: %w", cfg.MetricsAddr, err)
+		}
+	}
+
+	logger.Info("metrics endpoint ready",
+		zap.String("addr", ln.Addr().String()),
+		zap.String("metrics_path", cfg.metricsPath()),
+		zap.String("health_path", cfg.healthPath()))
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	metricsErr := make(chan error, 1)
+	go func() {
+		metricsErr <- serveHTTP(ctx, ln, srv.observabilityHandler(cfg), logger)
+		cancel()
+	}()
+
+	runErr := RunWithTransport(ctx, srv, transportType, logger)
+	cancel()
+	if err := <-metricsErr; err != nil {
+		return errors.Join(runErr, fmt.Errorf("metrics endpoint: %w", err))
+	}
+	return runErr
+}
+
+// observabilityHandler serves the metrics and health endpoints of cfg.
+func (s *Server) observabilityHandler(cfg ObservabilityConfig) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle(cfg.metricsPath(), s.PrometheusHandler())
+	mux.HandleFunc(cfg.healthPath(), func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(s.Health()); err != nil {
+			s.log().Warn("failed to write health report", zap.Error(err))
+		}
+	})
+	return mux
+}
@@ -0,0 +1,164 @@
+package hypermcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zaptest"
+)
+
+// listenTCP returns a loopback listener on a port chosen by the kernel.
+func listenTCP(t *testing.T) net.Listener {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	return ln
+}
+
+func TestRunObservable(t *testing.T) {
+	path := tempSocketPath(t)
+	srv := newUnixTestServer(t, path)
+	srv.Metrics().IncrementToolInvocations()
+
+	tracingStarted := make(chan struct{})
+	tracingStopped := make(chan struct{})
+	cfg := ObservabilityConfig{
+		MetricsListener: listenTCP(t),
+		InitTracing: func(context.Context) (TracingShutdown, error) {
+			close(tracingStarted)
+			return func(context.Context) error {
+				close(tracingStopped)
+				return nil
+			}, nil
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- RunObservable(ctx, cfg, srv, TransportUnix, zaptest.NewLogger(t))
+	}()
+
+	<-tracingStarted
+	base := "http://" + cfg.MetricsListener.Addr().String()
+
+	var resp *http.Response
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		var err error
+		resp, err = http.Get(base + DefaultMetricsPath)
+		if err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("metrics endpoint not reachable: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !strings.Contains(string(body), "hypermcp_tool_invocations_total 1") {
+		t.Fatalf("metrics = %d %q, want tool invocations counted", resp.StatusCode, body)
+	}
+
+	resp, err := http.Get(base + DefaultHealthPath)
+	if err != nil {
+		t.Fatalf("health endpoint not reachable: %v", err)
+	}
+	var health Health
+	err = json.NewDecoder(resp.Body).Decode(&health)
+	resp.Body.Close()
+	if err != nil || health.Status != HealthOK {
+		t.Fatalf("health = %+v (%v), want ok", health, err)
+	}
+
+	// The transport is served alongside the metrics
+	session := connectUnixClient(t, path)
+	session.Close()
+
+	cancel()
+	select {
+	case err := <-errCh:
+		if err != nil {
+			t.Fatalf("RunObservable() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("RunObservable did not return after cancel")
+	}
+
+	select {
+	case <-tracingStopped:
+	default:
+		t.Error("tracing was not shut down")
+	}
+	if _, err := http.Get(base + DefaultMetricsPath); err == nil {
+		t.Error("metrics endpoint still reachable after shutdown")
+	}
+}
+
+func TestRunObservable_Errors(t *testing.T) {
+	srv := newUnixTestServer(t, tempSocketPath(t))
+
+	var cfgErr *ConfigError
+	err := RunObservable(context.Background(), ObservabilityConfig{}, srv, TransportUnix, zaptest.NewLogger(t))
+	if !errors.As(err, &cfgErr) || cfgErr.Field != "MetricsAddr" {
+		t.Errorf("RunObservable() without MetricsAddr error = %v, want MetricsAddr config error", err)
+	}
+
+	errTracing := errors.New("collector unreachable")
+	ln := listenTCP(t)
+	err = RunObservable(context.Background(), ObservabilityConfig{
+		MetricsListener: ln,
+		InitTracing:     func(context.Context) (TracingShutdown, error) { return nil, errTracing },
+	}, srv, TransportUnix, zaptest.NewLogger(t))
+	if !errors.Is(err, errTracing) {
+		t.Errorf("RunObservable() error = %v, want tracing error", err)
+	}
+	if _, err := http.Get("http://" + ln.Addr().String() + DefaultMetricsPath); err == nil {
+		t.Error("metrics listener not closed after tracing failed")
+	}
+
+	// A failed transport stops the metrics listener too
+	ln = listenTCP(t)
+	err = RunObservable(context.Background(), ObservabilityConfig{MetricsListener: ln}, srv, TransportStreamableHTTP, zaptest.NewLogger(t))
+	if !errors.Is(err, ErrTransportNotSupported) {
+		t.Errorf("RunObservable() error = %v, want ErrTransportNotSupported", err)
+	}
+	if _, err := http.Get("http://" + ln.Addr().String() + DefaultMetricsPath); err == nil {
+		t.Error("metrics endpoint still reachable after the transport failed")
+	}
+}
+
+func TestRunObservable_CanceledContext(t *testing.T) {
+	srv := newUnixTestServer(t, tempSocketPath(t))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	initialized := false
+	cfg := ObservabilityConfig{
+		MetricsListener: listenTCP(t),
+		InitTracing: func(context.Context) (TracingShutdown, error) {
+			initialized = true
+			return nil, nil
+		},
+	}
+	err := RunObservable(ctx, cfg, srv, TransportUnix, zaptest.NewLogger(t))
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("RunObservable() error = %v, want context.Canceled", err)
+	}
+	if initialized {
+		t.Error("tracing was initialized for a canceled context")
+	}
+}
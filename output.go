@@ -0,0 +1,52 @@
+package hypermcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap"
+)
+
+// validateToolOutput wraps a tool handler to check the structured content it
+// sets itself against schema, for Config.ValidateToolOutput. That is only the
+// case when the handler returns a nil output of interface type: the SDK sends
+// the result's StructuredContent as is then, while it marshals and validates
+// any other output itself. Failed calls are passed through unchecked.
+func validateToolOutput[In, Out any](s *Server, name string, schema *jsonschema.Resolved, handler mcp.ToolHandlerFor[In, Out]) mcp.ToolHandlerFor[In, Out] {
+	return func(ctx context.Context, req *mcp.CallToolRequest, input In) (*mcp.CallToolResult, Out, error) {
+		result, output, err := handler(ctx, req, input)
+		if err != nil || result == nil || result.IsError {
+			return result, output, err
+		}
+		if any(output) != nil || result.StructuredContent == nil {
+			return result, output, nil
+		}
+
+		if verr := checkOutput(schema, result.StructuredContent); verr != nil {
+			s.log().Warn("tool output does not match its output schema",
+				zap.String("tool", name),
+				zap.Error(verr),
+			)
+			var zero Out
+			return nil, zero, fmt.Errorf("%w: tool %q: %w", ErrInvalidOutput, name, verr)
+		}
+		return result, output, nil
+	}
+}
+
+// checkOutput validates value against schema in its JSON form, the way
+// clients will see it.
+func checkOutput(schema *jsonschema.Resolved, value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("marshal output: %w", err)
+	}
+	var instance map[string]any
+	if err := json.Unmarshal(data, &instance); err != nil {
+		return fmt.Errorf("output is not a JSON object")
+	}
+	return schema.Validate(&instance)
+}
@@ -0,0 +1,126 @@
+package hypermcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap"
+)
+
+// truncationMarker is appended to text content shortened to fit
+// Config.MaxToolOutputBytes.
+const truncationMarker = "\n[output truncated]"
+
+// limitToolOutput wraps a tool handler to enforce Config.MaxToolOutputBytes,
+// rejecting oversized results or, with Config.TruncateToolOutput, shortening
+// their text content.
+func limitToolOutput[In, Out any](s *Server, name string, handler mcp.ToolHandlerFor[In, Out]) mcp.ToolHandlerFor[In, Out] {
+	limit := s.config.MaxToolOutputBytes
+	truncate := s.config.TruncateToolOutput
+
+	return func(ctx context.Context, req *mcp.CallToolRequest, input In) (*mcp.CallToolResult, Out, error) {
+		result, output, err := handler(ctx, req, input)
+		if err != nil {
+			return result, output, err
+		}
+
+		size := toolOutputSize(result, output)
+		if size <= limit {
+			return result, output, nil
+		}
+		s.metrics.oversizedToolOutputs.Add(1)
+
+		if truncate {
+			if truncated, ok := truncateToolResult(result, output, limit); ok {
+				s.log().Warn("tool output truncated",
+					zap.String("tool", name),
+					zap.Int64("size", size),
+					zap.Int64("limit", limit),
+				)
+				return truncated, output, nil
+			}
+		}
+
+		s.log().Warn("tool output rejected: exceeds MaxToolOutputBytes",
+			zap.String("tool", name),
+			zap.Int64("size", size),
+			zap.Int64("limit", limit),
+		)
+		var zero Out
+		return nil, zero, fmt.Errorf("%w: tool %q returned %d bytes, limit %d", ErrToolOutputTooLarge, name, size, limit)
+	}
+}
+
+// toolOutputSize estimates the bytes of the result sent for a call: the
+// result's JSON, plus the structured output, which the SDK also sends as text
+// content when the result has none.
+func toolOutputSize(result *mcp.CallToolResult, output any) int64 {
+	var size int64
+	if result != nil {
+		data, _ := json.Marshal(result)
+		size += int64(len(data))
+	}
+	if output != nil && !isNilPointer(output) {
+		data, _ := json.Marshal(output)
+		size += int64(len(data))
+		if result == nil || result.Content == nil {
+			size += int64(len(data))
+		}
+	}
+	return size
+}
+
+// truncateToolResult returns a copy of result whose text content is shortened
+// until the call fits in limit bytes, reporting false if shortening the text
+// can't make it fit.
+func truncateToolResult(result *mcp.CallToolResult, output any, limit int64) (*mcp.CallToolResult, bool) {
+	if result == nil {
+		return nil, false
+	}
+	truncated := copyResult(result)
+	truncated.Content = append([]mcp.Content(nil), result.Content...)
+
+	// JSON escaping makes text longer on the wire than in memory, so cut and
+	// measure until it fits
+	for {
+		over := toolOutputSize(truncated, output) - limit
+		if over <= 0 {
+			return truncated, true
+		}
+
+		// Shorten the longest text, the one most likely to be the bulk
+		var longest *mcp.TextContent
+		index := -1
+		for i, content := range truncated.Content {
+			if text, ok := content.(*mcp.TextContent); ok && (longest == nil || len(text.Text) > len(longest.Text)) {
+				longest, index = text, i
+			}
+		}
+		if longest == nil {
+			return nil, false
+		}
+		body := strings.TrimSuffix(longest.Text, truncationMarker)
+		if body == "" {
+			return nil, false
+		}
+		keep := max(len(body)-int(over)-len(truncationMarker), 0)
+		for keep > 0 && !utf8.RuneStart(body[keep]) {
+			keep--
+		}
+		text := *longest
+		text.Text = body[:keep] + truncationMarker
+		truncated.Content[index] = &text
+	}
+}
+
+// isNilPointer reports whether v is a nil pointer, for which the SDK sends the
+// zero value of the element type.
+func isNilPointer(v any) bool {
+	rv := reflect.ValueOf(v)
+	return rv.Kind() == reflect.Pointer && rv.IsNil()
+}
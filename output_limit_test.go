@@ -0,0 +1,134 @@
+package hypermcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestConfig_MaxToolOutputBytes(t *testing.T) {
+	const limit = 200
+	textResult := func(text string) mcp.ToolHandlerFor[struct{}, any] {
+		return func(context.Context, *mcp.CallToolRequest, struct{}) (*mcp.CallToolResult, any, error) {
+			return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: text}}}, nil, nil
+		}
+	}
+	structured := func(context.Context, *mcp.CallToolRequest, struct{}) (*mcp.CallToolResult, any, error) {
+		return nil, map[string]any{"data": strings.Repeat("x", 500)}, nil
+	}
+
+	tests := []struct {
+		name          string
+		truncate      bool
+		handler       mcp.ToolHandlerFor[struct{}, any]
+		wantErr       bool
+		wantTruncated bool
+		wantOversized int64
+	}{
+		{
+			name:    "small result",
+			handler: textResult("hello"),
+		},
+		{
+			name:          "oversized result rejected",
+			handler:       textResult(strings.Repeat("a", 500)),
+			wantErr:       true,
+			wantOversized: 1,
+		},
+		{
+			name:          "oversized result truncated",
+			truncate:      true,
+			handler:       textResult(strings.Repeat("é", 500)),
+			wantTruncated: true,
+			wantOversized: 1,
+		},
+		{
+			name:          "oversized structured output cannot be truncated",
+			truncate:      true,
+			handler:       structured,
+			wantErr:       true,
+			wantOversized: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, logs := observer.New(zapcore.WarnLevel)
+			srv, err := New(Config{
+				Name:               "test-server",
+				Version:            "1.0.0",
+				MaxToolOutputBytes: limit,
+				TruncateToolOutput: tt.truncate,
+			}, zap.New(core))
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			if err := AddToolErr(srv, &mcp.Tool{Name: "big"}, tt.handler); err != nil {
+				t.Fatalf("AddToolErr() error = %v", err)
+			}
+			session := connectTestClient(t, srv)
+
+			result, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "big", Arguments: map[string]any{}})
+			if err != nil {
+				t.Fatalf("CallTool() error = %v", err)
+			}
+			if result.IsError != tt.wantErr {
+				t.Fatalf("IsError = %v, want %v (content %v)", result.IsError, tt.wantErr, result.Content)
+			}
+			if got := srv.Metrics().Snapshot().OversizedToolOutputs; got != tt.wantOversized {
+				t.Errorf("OversizedToolOutputs = %d, want %d", got, tt.wantOversized)
+			}
+
+			text := result.Content[0].(*mcp.TextContent).Text
+			switch {
+			case tt.wantErr:
+				if !strings.Contains(text, ErrToolOutputTooLarge.Error()) {
+					t.Errorf("error result %q does not mention the size limit", text)
+				}
+				if logs.FilterMessage("tool output rejected: exceeds MaxToolOutputBytes").Len() != 1 {
+					t.Error("rejected output was not logged")
+				}
+			case tt.wantTruncated:
+				if !strings.HasSuffix(text, truncationMarker) {
+					t.Errorf("truncated text %q does not end with the marker", text)
+				}
+				data, _ := json.Marshal(result)
+				if len(data) > limit {
+					t.Errorf("truncated result is %d bytes, limit %d", len(data), limit)
+				}
+				if logs.FilterMessage("tool output truncated").Len() != 1 {
+					t.Error("truncated output was not logged")
+				}
+			}
+		})
+	}
+}
+
+func TestConfig_Validate_MaxToolOutputBytes(t *testing.T) {
+	tests := []struct {
+		name      string
+		cfg       Config
+		wantField string
+	}{
+		{name: "negative limit", cfg: Config{MaxToolOutputBytes: -1}, wantField: "MaxToolOutputBytes"},
+		{name: "truncate without limit", cfg: Config{TruncateToolOutput: true}, wantField: "TruncateToolOutput"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := tt.cfg
+			cfg.Name, cfg.Version = "test-server", "1.0.0"
+			var cfgErr *ConfigError
+			if err := cfg.Validate(); !errors.As(err, &cfgErr) || cfgErr.Field != tt.wantField {
+				t.Errorf("Validate() error = %v, want ConfigError for %s", err, tt.wantField)
+			}
+		})
+	}
+}
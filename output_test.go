@@ -0,0 +1,149 @@
+package hypermcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestConfig_ValidateToolOutput(t *testing.T) {
+	type Report struct {
+		Status string `json:"status"`
+	}
+	statusSchema := map[string]any{
+		"type":     "object",
+		"required": []any{"status"},
+		"properties": map[string]any{
+			"status": map[string]any{"type": "string", "enum": []any{"ok", "failed"}},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		tool    *mcp.Tool
+		handler mcp.ToolHandlerFor[struct{}, any]
+		wantErr bool
+	}{
+		{
+			name: "matching output",
+			tool: &mcp.Tool{Name: "report", OutputSchema: statusSchema},
+			handler: func(context.Context, *mcp.CallToolRequest, struct{}) (*mcp.CallToolResult, any, error) {
+				return nil, Report{Status: "ok"}, nil
+			},
+		},
+		{
+			name: "matching structured content",
+			tool: &mcp.Tool{Name: "report", OutputSchema: statusSchema},
+			handler: func(context.Context, *mcp.CallToolRequest, struct{}) (*mcp.CallToolResult, any, error) {
+				return &mcp.CallToolResult{
+					Content:           []mcp.Content{&mcp.TextContent{Text: "done"}},
+					StructuredContent: Report{Status: "ok"},
+				}, nil, nil
+			},
+		},
+		{
+			name: "structured content outside the declared enum",
+			tool: &mcp.Tool{Name: "report", OutputSchema: statusSchema},
+			handler: func(context.Context, *mcp.CallToolRequest, struct{}) (*mcp.CallToolResult, any, error) {
+				return &mcp.CallToolResult{
+					Content:           []mcp.Content{&mcp.TextContent{Text: "done"}},
+					StructuredContent: Report{Status: "maybe"},
+				}, nil, nil
+			},
+			wantErr: true,
+		},
+		{
+			name: "structured content missing a required property",
+			tool: &mcp.Tool{Name: "report", OutputSchema: statusSchema},
+			handler: func(context.Context, *mcp.CallToolRequest, struct{}) (*mcp.CallToolResult, any, error) {
+				return &mcp.CallToolResult{
+					Content:           []mcp.Content{&mcp.TextContent{Text: "done"}},
+					StructuredContent: map[string]any{"other": 1},
+				}, nil, nil
+			},
+			wantErr: true,
+		},
+		{
+			name: "structured content of the wrong type",
+			tool: &mcp.Tool{Name: "report", OutputSchema: statusSchema},
+			handler: func(context.Context, *mcp.CallToolRequest, struct{}) (*mcp.CallToolResult, any, error) {
+				return &mcp.CallToolResult{
+					Content:           []mcp.Content{&mcp.TextContent{Text: "done"}},
+					StructuredContent: map[string]any{"status": 42},
+				}, nil, nil
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, logs := observer.New(zapcore.WarnLevel)
+			srv, err := New(Config{Name: "test-server", Version: "1.0.0", ValidateToolOutput: true}, zap.New(core))
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			if err := AddToolErr(srv, tt.tool, tt.handler); err != nil {
+				t.Fatalf("AddToolErr() error = %v", err)
+			}
+			session := connectTestClient(t, srv)
+
+			result, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "report", Arguments: map[string]any{}})
+			if err != nil {
+				t.Fatalf("CallTool() error = %v", err)
+			}
+			if result.IsError != tt.wantErr {
+				t.Errorf("IsError = %v, want %v (content %v)", result.IsError, tt.wantErr, result.Content)
+			}
+			if !tt.wantErr {
+				return
+			}
+			if text := result.Content[0].(*mcp.TextContent).Text; !strings.Contains(text, ErrInvalidOutput.Error()) {
+				t.Errorf("error result %q does not mention the invalid output", text)
+			}
+			if logs.FilterMessage("tool output does not match its output schema").Len() != 1 {
+				t.Error("invalid output was not logged")
+			}
+		})
+	}
+}
+
+func TestConfig_ValidateToolOutput_TypedOutputLeftToSDK(t *testing.T) {
+	type Report struct {
+		Status string `json:"status"`
+	}
+	tool := &mcp.Tool{Name: "report", OutputSchema: map[string]any{
+		"type":     "object",
+		"required": []any{"status"},
+		"properties": map[string]any{
+			"status": map[string]any{"type": "string", "enum": []any{"ok", "failed"}},
+		},
+	}}
+
+	core, logs := observer.New(zapcore.WarnLevel)
+	srv, err := New(Config{Name: "test-server", Version: "1.0.0", ValidateToolOutput: true}, zap.New(core))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	err = AddToolErr(srv, tool, func(context.Context, *mcp.CallToolRequest, struct{}) (*mcp.CallToolResult, Report, error) {
+		return nil, Report{Status: "maybe"}, nil
+	})
+	if err != nil {
+		t.Fatalf("AddToolErr() error = %v", err)
+	}
+	session := connectTestClient(t, srv)
+
+	// The SDK rejects the output itself, so it isn't validated twice
+	_, err = session.CallTool(context.Background(), &mcp.CallToolParams{Name: "report", Arguments: map[string]any{}})
+	if err == nil || !strings.Contains(err.Error(), "validating tool output") {
+		t.Errorf("CallTool() error = %v, want the SDK's output validation error", err)
+	}
+	if logs.Len() != 0 {
+		t.Errorf("expected nothing logged, got %v", logs.All())
+	}
+}
@@ -0,0 +1,227 @@
+package hypermcp
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// prometheusContentType is the Prometheus text exposition format version
+// written by PrometheusHandler.
+const prometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// PrometheusHandler returns an http.Handler serving the server metrics in the
+// Prometheus text exposition format.
+//
+// Mount it on the route your Prometheus scrapes, typically "/metrics":
+//
+//	mux.Handle("/metrics", srv.PrometheusHandler())
+//
+// Every metric name is prefixed with "hypermcp_". Tool latencies are exported
+// both as a histogram (hypermcp_tool_duration_seconds) and as precomputed
+// p50/p95/p99 estimates (hypermcp_tool_duration_quantile_seconds).
+//
+// The cache's own Ristretto statistics are exported alongside, under the
+// "hypermcp_cache_store_" prefix, so they don't collide with the
+// tool-reported hypermcp_cache_hits_total and hypermcp_cache_misses_total.
+// When caching is disabled only hypermcp_cache_enabled is written, as 0.
+//
+// Application metrics registered with RegisterCounter and RegisterGauge are
+// exported as hypermcp_custom_<name>.
+func (s *Server) PrometheusHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", prometheusContentType)
+		if err := writePrometheus(w, s.GetMetrics()); err != nil {
+			s.log().Debug("failed to write prometheus metrics", zap.Error(err))
+		}
+	})
+}
+
+// promWriter writes metric families, remembering the first write error.
+type promWriter struct {
+	w   *bufio.Writer
+	err error
+}
+
+func (p *promWriter) printf(format string, args ...any) {
+	if p.err != nil {
+		return
+	}
+	_, p.err = fmt.Fprintf(p.w, format, args...)
+}
+
+// metric writes a single unlabeled sample with its HELP and TYPE lines.
+func (p *promWriter) metric(name, typ, help string, value float64) {
+	p.printf("# HELP %s %s\n# TYPE %s %s\n%s %s\n", name, help, name, typ, name, formatFloat(value))
+}
+
+// writePrometheus renders snap in the Prometheus text exposition format.
+func writePrometheus(w io.Writer, snap MetricsSnapshot) error {
+	p := &promWriter{w: bufio.NewWriter(w)}
+
+	p.metric("hypermcp_uptime_seconds", "gauge", "Time since the server started.", snap.Uptime.Seconds())
+	p.metric("hypermcp_tool_invocations_total", "counter", "Tool invocations.", float64(snap.ToolInvocations))
+	p.metric("hypermcp_slow_tool_invocations_total", "counter", "Tool invocations exceeding the slow tool threshold.", float64(snap.SlowToolInvocations))
+	p.metric("hypermcp_tool_output_oversized_total", "counter", "Tool results exceeding the maximum output size, rejected or truncated.", float64(snap.OversizedToolOutputs))
+	p.metric("hypermcp_resource_reads_total", "counter", "Resource reads.", float64(snap.ResourceReads))
+	p.metric("hypermcp_cache_hits_total", "counter", "Cache hits reported by tools.", float64(snap.CacheHits))
+	p.metric("hypermcp_cache_misses_total", "counter", "Cache misses reported by tools.", float64(snap.CacheMisses))
+	p.metric("hypermcp_errors_total", "counter", "Errors.", float64(snap.Errors))
+	p.metric("hypermcp_tool_error_rate", "gauge", "Fraction of recent tool calls that failed.", snap.RecentToolErrorRate)
+	p.metric("hypermcp_degraded", "gauge", "Whether the server is in degraded mode (1) or not (0).", boolGauge(snap.Degraded))
+	p.metric("hypermcp_active_connections", "gauge", "Clients currently connected.", float64(snap.ActiveConnections))
+	p.metric("hypermcp_connections_total", "counter", "Clients connected since start.", float64(snap.TotalConnections))
+	p.metric("hypermcp_queue_depth", "gauge", "Tool calls waiting for a worker.", float64(snap.QueueDepth))
+	p.metric("hypermcp_queue_rejected_total", "counter", "Tool calls rejected because the queue was full.", float64(snap.QueueRejected))
+	p.metric("hypermcp_stdio_write_buffer_bytes", "gauge", "Bytes of outgoing stdio messages waiting to be written.", float64(snap.StdioBufferedBytes))
+	p.metric("hypermcp_stdio_write_buffer_waits_total", "counter", "Outgoing stdio messages that waited for room in a full write buffer.", float64(snap.StdioBufferWaits))
+	p.metric("hypermcp_stdio_dropped_messages_total", "counter", "Stdio notifications dropped because the write buffer was full.", float64(snap.StdioDroppedMessages))
+
+	writeToolLatencies(p, snap.ToolLatencies)
+	writeTemplateReads(p, snap.ResourceTemplates)
+	writeClientRequests(p, snap.Clients)
+	writeCacheMetrics(p, snap.Cache)
+	writeCustomMetrics(p, snap.Custom)
+
+	if p.err != nil {
+		return p.err
+	}
+	return p.w.Flush()
+}
+
+// writeToolLatencies renders the per-tool latency histograms and percentiles.
+func writeToolLatencies(p *promWriter, latencies map[string]LatencySnapshot) {
+	if len(latencies) == 0 {
+		return
+	}
+	names := sortedToolNames(latencies)
+
+	const histogram = "hypermcp_tool_duration_seconds"
+	p.printf("# HELP %s Tool invocation latency.\n# TYPE %s histogram\n", histogram, histogram)
+	for _, name := range names {
+		l := latencies[name]
+		tool := escapeLabelValue(name)
+
+		var cumulative int64
+		for _, b := range l.Buckets {
+			cumulative += b.Count
+			le := "+Inf"
+			if b.UpperBound > 0 {
+				le = formatFloat(b.UpperBound.Seconds())
+			}
+			p.printf("%s_bucket{tool=\"%s\",le=\"%s\"} %d\n", histogram, tool, le, cumulative)
+		}
+		p.printf("%s_sum{tool=\"%s\"} %s\n", histogram, tool, formatFloat(l.Sum.Seconds()))
+		p.printf("%s_count{tool=\"%s\"} %d\n", histogram, tool, l.Count)
+	}
+
+	const quantiles = "hypermcp_tool_duration_quantile_seconds"
+	p.printf("# HELP %s Estimated tool invocation latency percentiles.\n# TYPE %s gauge\n", quantiles, quantiles)
+	for _, name := range names {
+		l := latencies[name]
+		tool := escapeLabelValue(name)
+		for _, q := range []struct {
+			label string
+			value time.Duration
+		}{{"0.5", l.P50}, {"0.95", l.P95}, {"0.99", l.P99}} {
+			p.printf("%s{tool=\"%s\",quantile=\"%s\"} %s\n", quantiles, tool, q.label, formatFloat(q.value.Seconds()))
+		}
+	}
+}
+
+// writeTemplateReads renders the per-template read counters.
+func writeTemplateReads(p *promWriter, templates map[string]TemplateSnapshot) {
+	if len(templates) == 0 {
+		return
+	}
+	names := make([]string, 0, len(templates))
+	for name := range templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	const reads = "hypermcp_resource_template_reads_total"
+	p.printf("# HELP %s Reads of resources matching each resource template.\n# TYPE %s counter\n", reads, reads)
+	for _, name := range names {
+		p.printf("%s{template=\"%s\"} %d\n", reads, escapeLabelValue(name), templates[name].Reads)
+	}
+}
+
+// writeClientRequests renders the per-client request counters.
+func writeClientRequests(p *promWriter, clients map[string]ClientSnapshot) {
+	if len(clients) == 0 {
+		return
+	}
+	names := make([]string, 0, len(clients))
+	for name := range clients {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, counter := range []struct {
+		name, help string
+		value      func(ClientSnapshot) int64
+	}{
+		{"hypermcp_client_requests_total", "Requests received from each client.", func(c ClientSnapshot) int64 { return c.Requests }},
+		{"hypermcp_client_rate_limited_total", "Requests rejected because the client exceeded its rate limit.", func(c ClientSnapshot) int64 { return c.RateLimited }},
+		{"hypermcp_client_oversized_requests_total", "Requests rejected because they exceeded the client's size limit.", func(c ClientSnapshot) int64 { return c.OversizedRequests }},
+	} {
+		p.printf("# HELP %s %s\n# TYPE %s counter\n", counter.name, counter.help, counter.name)
+		for _, name := range names {
+			p.printf("%s{client=\"%s\"} %d\n", counter.name, escapeLabelValue(name), counter.value(clients[name]))
+		}
+	}
+}
+
+// writeCacheMetrics renders the cache's Ristretto statistics.
+func writeCacheMetrics(p *promWriter, c CacheSnapshot) {
+	p.metric("hypermcp_cache_enabled", "gauge", "Whether caching is enabled (1) or not (0).", boolGauge(c.Enabled))
+	if !c.Enabled {
+		return
+	}
+	p.metric("hypermcp_cache_store_hits_total", "counter", "Cache gets that found a value.", float64(c.Hits))
+	p.metric("hypermcp_cache_store_misses_total", "counter", "Cache gets that found nothing.", float64(c.Misses))
+	p.metric("hypermcp_cache_store_hit_ratio", "gauge", "Fraction of cache gets that found a value.", c.Ratio)
+	p.metric("hypermcp_cache_store_keys_added_total", "counter", "New keys admitted to the cache.", float64(c.KeysAdded))
+	p.metric("hypermcp_cache_store_keys_updated_total", "counter", "Existing cache keys overwritten.", float64(c.KeysUpdated))
+	p.metric("hypermcp_cache_store_keys_evicted_total", "counter", "Keys evicted to stay within the cache's MaxCost.", float64(c.KeysEvicted))
+	p.metric("hypermcp_cache_store_cost_added_total", "counter", "Total cost of keys admitted to the cache.", float64(c.CostAdded))
+	p.metric("hypermcp_cache_store_cost_evicted_total", "counter", "Total cost of keys evicted from the cache.", float64(c.CostEvicted))
+	p.metric("hypermcp_cache_store_rejected_total", "counter", "Cache sets that were not stored.", float64(c.Rejected))
+	p.metric("hypermcp_cache_store_tracked_keys", "gauge", "Keys whose TTL the cache is tracking.", float64(c.TrackedKeys))
+	p.metric("hypermcp_cache_store_pressure_clears_total", "counter", "Times the cache was cleared because memory use exceeded its limit.", float64(c.PressureClears))
+}
+
+// writeCustomMetrics renders the application metrics.
+func writeCustomMetrics(p *promWriter, custom map[string]CustomMetric) {
+	for _, name := range sortedCustomNames(custom) {
+		m := custom[name]
+		p.metric("hypermcp_custom_"+name, string(m.Type), "Application metric "+name+".", m.Value)
+	}
+}
+
+// boolGauge converts a boolean to a gauge value.
+func boolGauge(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// labelEscaper escapes label values per the text exposition format.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabelValue(v string) string {
+	return labelEscaper.Replace(v)
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
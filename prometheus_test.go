@@ -0,0 +1,146 @@
+package hypermcp
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rayprogramming/hypermcp/cache"
+	"go.uber.org/zap/zaptest"
+)
+
+func scrapePrometheus(t *testing.T, srv *Server) string {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	srv.PrometheusHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("unexpected content type %q", ct)
+	}
+	body, err := io.ReadAll(rec.Body)
+	if err != nil {
+		t.Fatalf("failed to read body: %v", err)
+	}
+	return string(body)
+}
+
+func TestServer_PrometheusHandler(t *testing.T) {
+	srv, err := New(Config{Name: "test-server", Version: "1.0.0"}, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	srv.Metrics().IncrementToolInvocations()
+	srv.Metrics().IncrementErrors()
+	srv.Metrics().ObserveToolDuration(`say "hi"`, 20*time.Millisecond)
+
+	body := scrapePrometheus(t, srv)
+
+	for _, want := range []string{
+		"# TYPE hypermcp_tool_invocations_total counter\nhypermcp_tool_invocations_total 1\n",
+		"hypermcp_errors_total 1\n",
+		"# TYPE hypermcp_tool_duration_seconds histogram\n",
+		`hypermcp_tool_duration_seconds_bucket{tool="say \"hi\"",le="0.01"} 0` + "\n",
+		`hypermcp_tool_duration_seconds_bucket{tool="say \"hi\"",le="0.025"} 1` + "\n",
+		`hypermcp_tool_duration_seconds_bucket{tool="say \"hi\"",le="+Inf"} 1` + "\n",
+		`hypermcp_tool_duration_seconds_sum{tool="say \"hi\""} 0.02` + "\n",
+		`hypermcp_tool_duration_seconds_count{tool="say \"hi\""} 1` + "\n",
+		`hypermcp_tool_duration_quantile_seconds{tool="say \"hi\"",quantile="0.99"} 0.0199` + "\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected exposition to contain %q\n%s", want, body)
+		}
+	}
+}
+
+func TestServer_PrometheusHandler_ToolCalls(t *testing.T) {
+	srv, err := New(Config{Name: "test-server", Version: "1.0.0"}, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	AddTool(srv, &mcp.Tool{Name: "echo", Description: "Echoes"},
+		func(ctx context.Context, req *mcp.CallToolRequest, input struct{}) (*mcp.CallToolResult, any, error) {
+			return &mcp.CallToolResult{}, nil, nil
+		})
+
+	session := connectTestClient(t, srv)
+	for i := 0; i < 3; i++ {
+		if _, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "echo"}); err != nil {
+			t.Fatalf("tool call failed: %v", err)
+		}
+	}
+
+	if got := srv.GetMetrics().ToolLatencies["echo"].Count; got != 3 {
+		t.Errorf("expected 3 observations in snapshot, got %d", got)
+	}
+	if body := scrapePrometheus(t, srv); !strings.Contains(body, `hypermcp_tool_duration_seconds_count{tool="echo"} 3`) {
+		t.Errorf("expected echo latency count in exposition\n%s", body)
+	}
+}
+
+func TestServer_PrometheusHandler_Cache(t *testing.T) {
+	srv, err := New(Config{
+		Name:         "test-server",
+		Version:      "1.0.0",
+		CacheEnabled: true,
+		CacheConfig:  cache.DefaultConfig(),
+	}, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	defer func() { _ = srv.Shutdown(context.Background()) }()
+
+	c := srv.Cache()
+	c.SetWait("a", 1, time.Minute)
+	c.SetWait("b", 2, time.Minute)
+	c.SetWait("a", 3, time.Minute)
+	c.Get("a")
+	c.Get("missing")
+
+	body := scrapePrometheus(t, srv)
+
+	for _, want := range []string{
+		"hypermcp_cache_enabled 1\n",
+		"# TYPE hypermcp_cache_store_hits_total counter\nhypermcp_cache_store_hits_total 1\n",
+		"hypermcp_cache_store_misses_total 1\n",
+		"# TYPE hypermcp_cache_store_hit_ratio gauge\nhypermcp_cache_store_hit_ratio 0.5\n",
+		"hypermcp_cache_store_keys_added_total 2\n",
+		"hypermcp_cache_store_keys_updated_total 1\n",
+		"hypermcp_cache_store_keys_evicted_total 0\n",
+		"hypermcp_cache_store_cost_evicted_total 0\n",
+		"hypermcp_cache_store_tracked_keys 2\n",
+		"hypermcp_cache_store_pressure_clears_total 0\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected exposition to contain %q\n%s", want, body)
+		}
+	}
+	if strings.Contains(body, "hypermcp_cache_store_cost_added_total 0\n") {
+		t.Errorf("expected non-zero cost added\n%s", body)
+	}
+}
+
+func TestServer_PrometheusHandler_CacheDisabled(t *testing.T) {
+	srv, err := New(Config{Name: "test-server", Version: "1.0.0"}, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	srv.Cache().SetWait("a", 1, time.Minute)
+
+	body := scrapePrometheus(t, srv)
+
+	if !strings.Contains(body, "hypermcp_cache_enabled 0\n") {
+		t.Errorf("expected cache reported as disabled\n%s", body)
+	}
+	if strings.Contains(body, "hypermcp_cache_store_") {
+		t.Errorf("expected no cache store metrics when caching is disabled\n%s", body)
+	}
+}
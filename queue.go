@@ -0,0 +1,75 @@
+package hypermcp
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// toolQueue limits concurrent tool execution, letting a bounded number of
+// further calls wait for a free worker.
+type toolQueue struct {
+	workers   chan struct{}
+	metrics   *Metrics
+	queueSize int64
+}
+
+// newToolQueue creates a queue running at most workers calls at once, with
+// room for queueSize waiting calls.
+func newToolQueue(workers, queueSize int, metrics *Metrics) *toolQueue {
+	return &toolQueue{
+		workers:   make(chan struct{}, workers),
+		metrics:   metrics,
+		queueSize: int64(queueSize),
+	}
+}
+
+// acquire claims a worker, waiting in the queue if none is free.
+//
+// It returns ErrServerBusy if the queue is full, or the context's error if ctx
+// is done before a worker frees up.
+func (q *toolQueue) acquire(ctx context.Context) error {
+	// Fast path: a worker is free
+	select {
+	case q.workers <- struct{}{}:
+		return nil
+	default:
+	}
+
+	if q.metrics.queueDepth.Add(1) > q.queueSize {
+		q.metrics.queueDepth.Add(-1)
+		q.metrics.queueRejected.Add(1)
+		return ErrServerBusy
+	}
+	defer q.metrics.queueDepth.Add(-1)
+
+	select {
+	case q.workers <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// release returns a worker claimed by acquire.
+func (q *toolQueue) release() {
+	<-q.workers
+}
+
+// middleware returns receiving middleware that runs tools/call requests through the queue.
+func (q *toolQueue) middleware() mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			if method != "tools/call" {
+				return next(ctx, method, req)
+			}
+
+			if err := q.acquire(ctx); err != nil {
+				return nil, err
+			}
+			defer q.release()
+
+			return next(ctx, method, req)
+		}
+	}
+}
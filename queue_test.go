@@ -0,0 +1,134 @@
+package hypermcp
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap/zaptest"
+)
+
+// waitForQueueDepth polls until the queue depth reaches want.
+func waitForQueueDepth(t *testing.T, m *Metrics, want int64) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for m.queueDepth.Load() != want {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected queue depth %d, got %d", want, m.queueDepth.Load())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestToolQueue_Acquire(t *testing.T) {
+	metrics := newMetrics()
+	q := newToolQueue(1, 1, metrics)
+
+	// Occupy the only worker
+	if err := q.acquire(context.Background()); err != nil {
+		t.Fatalf("first acquire failed: %v", err)
+	}
+
+	// Second caller waits in the queue
+	queued := make(chan error, 1)
+	go func() {
+		queued <- q.acquire(context.Background())
+	}()
+	waitForQueueDepth(t, metrics, 1)
+
+	// Queue is full: third caller is rejected immediately
+	if err := q.acquire(context.Background()); !errors.Is(err, ErrServerBusy) {
+		t.Errorf("expected ErrServerBusy, got %v", err)
+	}
+	if got := metrics.Snapshot().QueueRejected; got != 1 {
+		t.Errorf("expected 1 rejected call, got %d", got)
+	}
+
+	// Freeing the worker drains the queue
+	q.release()
+	if err := <-queued; err != nil {
+		t.Errorf("queued acquire failed: %v", err)
+	}
+	if got := metrics.Snapshot().QueueDepth; got != 0 {
+		t.Errorf("expected empty queue, got depth %d", got)
+	}
+	q.release()
+}
+
+func TestToolQueue_AcquireCanceled(t *testing.T) {
+	metrics := newMetrics()
+	q := newToolQueue(1, 1, metrics)
+
+	if err := q.acquire(context.Background()); err != nil {
+		t.Fatalf("first acquire failed: %v", err)
+	}
+	defer q.release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := q.acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context.DeadlineExceeded, got %v", err)
+	}
+	if got := metrics.Snapshot().QueueDepth; got != 0 {
+		t.Errorf("expected canceled caller to leave the queue, got depth %d", got)
+	}
+}
+
+func TestConfig_MaxConcurrentTools(t *testing.T) {
+	srv, err := New(Config{
+		Name:               "test-server",
+		Version:            "1.0.0",
+		MaxConcurrentTools: 1,
+		QueueSize:          1,
+	}, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+
+	started := make(chan struct{}, 3)
+	unblock := make(chan struct{})
+	AddTool(srv, &mcp.Tool{
+		Name:        "slow",
+		Description: "Blocks until released",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input struct{}) (*mcp.CallToolResult, any, error) {
+		started <- struct{}{}
+		<-unblock
+		return &mcp.CallToolResult{}, nil, nil
+	})
+
+	session := connectTestClient(t, srv)
+	call := func() error {
+		_, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "slow"})
+		return err
+	}
+
+	results := make(chan error, 2)
+	go func() { results <- call() }()
+	<-started
+	go func() { results <- call() }()
+	waitForQueueDepth(t, srv.metrics, 1)
+
+	if err := call(); err == nil || !strings.Contains(err.Error(), ErrServerBusy.Error()) {
+		t.Errorf("expected server busy error, got %v", err)
+	}
+
+	close(unblock)
+	for i := 0; i < 2; i++ {
+		if err := <-results; err != nil {
+			t.Errorf("queued call failed: %v", err)
+		}
+	}
+
+	snapshot := srv.GetMetrics()
+	if snapshot.QueueDepth != 0 {
+		t.Errorf("expected empty queue, got depth %d", snapshot.QueueDepth)
+	}
+	if snapshot.QueueRejected != 1 {
+		t.Errorf("expected 1 rejected call, got %d", snapshot.QueueRejected)
+	}
+}
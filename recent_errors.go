@@ -0,0 +1,88 @@
+package hypermcp
+
+import (
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ErrorRecord describes one error kept by Config.RecentErrors.
+type ErrorRecord struct {
+	Time    time.Time // When the error was recorded
+	Tool    string    // Tool whose call failed; empty for errors outside tool calls
+	Message string    // The error message
+}
+
+// errorRing keeps the most recent errors in a fixed-size ring buffer.
+type errorRing struct {
+	mu      sync.Mutex
+	records []ErrorRecord
+	next    int  // Index the next record is written to
+	full    bool // Whether records has wrapped around
+}
+
+// newErrorRing returns a ring keeping the last size errors, or nil if size is
+// not positive. A nil ring records nothing.
+func newErrorRing(size int) *errorRing {
+	if size <= 0 {
+		return nil
+	}
+	return &errorRing{records: make([]ErrorRecord, size)}
+}
+
+// add records rec, overwriting the oldest record once the ring is full.
+func (r *errorRing) add(rec ErrorRecord) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.records[r.next] = rec
+	r.next++
+	if r.next == len(r.records) {
+		r.next = 0
+		r.full = true
+	}
+}
+
+// list returns the recorded errors, oldest first.
+func (r *errorRing) list() []ErrorRecord {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.full {
+		return append([]ErrorRecord(nil), r.records[:r.next]...)
+	}
+	out := make([]ErrorRecord, 0, len(r.records))
+	out = append(out, r.records[r.next:]...)
+	return append(out, r.records[:r.next]...)
+}
+
+// toolErrorMessage returns the message of a failed tool call: err's message,
+// or the first text content of a result with IsError set.
+func toolErrorMessage(result mcp.Result, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	if r, ok := result.(*mcp.CallToolResult); ok && r != nil {
+		for _, content := range r.Content {
+			if text, ok := content.(*mcp.TextContent); ok {
+				return text.Text
+			}
+		}
+	}
+	return "tool returned an error result"
+}
+
+// RecentErrors returns the most recent errors recorded by the server, oldest
+// first: failed tool calls and resource loader failures. At most
+// Config.RecentErrors are kept; it returns nil when Config.RecentErrors is
+// zero.
+func (s *Server) RecentErrors() []ErrorRecord {
+	return s.metrics.RecentErrors()
+}
@@ -0,0 +1,150 @@
+package hypermcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestMetrics_RecentErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		size     int
+		recorded int
+		want     []string
+	}{
+		{name: "disabled", size: 0, recorded: 3, want: nil},
+		{name: "partially filled", size: 3, recorded: 2, want: []string{"error 1", "error 2"}},
+		{name: "exactly full", size: 3, recorded: 3, want: []string{"error 1", "error 2", "error 3"}},
+		{name: "wrapped", size: 3, recorded: 7, want: []string{"error 5", "error 6", "error 7"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := newFakeClock()
+			m := newMetricsWithClock(clock)
+			m.recentErrors = newErrorRing(tt.size)
+
+			for i := 1; i <= tt.recorded; i++ {
+				clock.Advance(time.Second)
+				m.RecordError("tool", fmt.Errorf("error %d", i))
+			}
+
+			got := m.RecentErrors()
+			if len(got) != len(tt.want) {
+				t.Fatalf("RecentErrors() returned %d records, want %d: %+v", len(got), len(tt.want), got)
+			}
+			for i, rec := range got {
+				if rec.Message != tt.want[i] {
+					t.Errorf("record %d message = %q, want %q", i, rec.Message, tt.want[i])
+				}
+				if rec.Tool != "tool" {
+					t.Errorf("record %d tool = %q, want tool", i, rec.Tool)
+				}
+				if i > 0 && !rec.Time.After(got[i-1].Time) {
+					t.Errorf("record %d time %v is not after record %d time %v", i, rec.Time, i-1, got[i-1].Time)
+				}
+			}
+			if m.Snapshot().Errors != 0 {
+				t.Error("expected RecordError not to change the error counter")
+			}
+		})
+	}
+}
+
+func TestMetrics_RecordError_Nil(t *testing.T) {
+	m := newMetrics()
+	m.recentErrors = newErrorRing(3)
+
+	m.RecordError("tool", nil)
+
+	if got := m.RecentErrors(); len(got) != 0 {
+		t.Errorf("RecentErrors() = %+v, want a nil error ignored", got)
+	}
+}
+
+func TestMetrics_RecentErrors_Concurrent(t *testing.T) {
+	const size, goroutines, perGoroutine = 10, 8, 50
+
+	m := newMetrics()
+	m.recentErrors = newErrorRing(size)
+
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perGoroutine; i++ {
+				m.RecordError("tool", errors.New("boom"))
+				_ = m.RecentErrors()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := len(m.RecentErrors()); got != size {
+		t.Errorf("RecentErrors() returned %d records, want %d", got, size)
+	}
+}
+
+func TestServer_RecentErrors(t *testing.T) {
+	srv, err := New(Config{Name: "test-server", Version: "1.0.0", RecentErrors: 2}, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	AddTool(srv, &mcp.Tool{Name: "fail", Description: "Always fails"},
+		func(ctx context.Context, req *mcp.CallToolRequest, input struct {
+			Reason string `json:"reason"`
+		}) (*mcp.CallToolResult, any, error) {
+			return nil, nil, errors.New(input.Reason)
+		})
+	AddTool(srv, &mcp.Tool{Name: "reject", Description: "Returns an error result"},
+		func(ctx context.Context, req *mcp.CallToolRequest, input struct{}) (*mcp.CallToolResult, any, error) {
+			return ErrorResult("input rejected"), nil, nil
+		})
+	AddTool(srv, &mcp.Tool{Name: "ok", Description: "Succeeds"},
+		func(ctx context.Context, req *mcp.CallToolRequest, input struct{}) (*mcp.CallToolResult, any, error) {
+			return TextResult("ok"), nil, nil
+		})
+
+	session := connectTestClient(t, srv)
+	calls := []*mcp.CallToolParams{
+		{Name: "fail", Arguments: map[string]any{"reason": "first"}},
+		{Name: "fail", Arguments: map[string]any{"reason": "second"}},
+		{Name: "ok", Arguments: map[string]any{}},
+		{Name: "reject", Arguments: map[string]any{}},
+	}
+	for _, params := range calls {
+		if _, err := session.CallTool(context.Background(), params); err != nil {
+			t.Fatalf("tool call %s failed: %v", params.Name, err)
+		}
+	}
+
+	got := srv.RecentErrors()
+	want := []ErrorRecord{{Tool: "fail", Message: "second"}, {Tool: "reject", Message: "input rejected"}}
+	if len(got) != len(want) {
+		t.Fatalf("RecentErrors() = %+v, want %d records", got, len(want))
+	}
+	for i := range want {
+		if got[i].Tool != want[i].Tool || got[i].Message != want[i].Message {
+			t.Errorf("record %d = %+v, want tool %q and message %q", i, got[i], want[i].Tool, want[i].Message)
+		}
+		if got[i].Time.IsZero() {
+			t.Errorf("record %d has no time", i)
+		}
+	}
+}
+
+func TestConfig_Validate_RecentErrors(t *testing.T) {
+	cfg := Config{Name: "test-server", Version: "1.0.0", RecentErrors: -1}
+	var cfgErr *ConfigError
+	if err := cfg.Validate(); !errors.As(err, &cfgErr) || cfgErr.Field != "RecentErrors" {
+		t.Errorf("Validate() error = %v, want ConfigError for RecentErrors", err)
+	}
+}
@@ -0,0 +1,147 @@
+package hypermcp
+
+import (
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// registry records the features registered through the Server helpers, keyed
+// the same way the MCP server keys them. Each key can be registered once, so
+// a second registration is detected instead of silently shadowing the first.
+type registry struct {
+	mu        sync.Mutex
+	tools     map[string]*mcp.Tool
+	resources map[string]*mcp.Resource
+	templates map[string]*mcp.ResourceTemplate
+	prompts   map[string]*mcp.Prompt
+}
+
+func newRegistry() *registry {
+	return &registry{
+		tools:     make(map[string]*mcp.Tool),
+		resources: make(map[string]*mcp.Resource),
+		templates: make(map[string]*mcp.ResourceTemplate),
+		prompts:   make(map[string]*mcp.Prompt),
+	}
+}
+
+// addTool calls register to add tool to the MCP server, then records it,
+// reporting false without calling register if a tool of the same name is
+// already registered. If register panics, nothing is recorded. addResource
+// and addTemplate do the same for resource URIs and URI templates.
+func (r *registry) addTool(tool *mcp.Tool, register func()) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.tools[tool.Name]; ok {
+		return false
+	}
+	register()
+	r.tools[tool.Name] = tool
+	return true
+}
+
+// removeTool forgets the tool named name, reporting whether it was registered.
+func (r *registry) removeTool(name string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.tools[name]; !ok {
+		return false
+	}
+	delete(r.tools, name)
+	return true
+}
+
+// tool returns the tool registered as name.
+func (r *registry) tool(name string) (*mcp.Tool, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	tool, ok := r.tools[name]
+	return tool, ok
+}
+
+func (r *registry) addResource(resource *mcp.Resource, register func()) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.resources[resource.URI]; ok {
+		return false
+	}
+	register()
+	r.resources[resource.URI] = resource
+	return true
+}
+
+func (r *registry) addTemplate(template *mcp.ResourceTemplate, register func()) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.templates[template.URITemplate]; ok {
+		return false
+	}
+	register()
+	r.templates[template.URITemplate] = template
+	return true
+}
+
+func (r *registry) addPrompt(prompt *mcp.Prompt) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.prompts[prompt.Name] = prompt
+}
+
+// snapshot returns the registered features, each sorted by key.
+func (r *registry) snapshot() (tools []*mcp.Tool, resources []*mcp.Resource, templates []*mcp.ResourceTemplate, prompts []*mcp.Prompt) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return sortedValues(r.tools), sortedValues(r.resources), sortedValues(r.templates), sortedValues(r.prompts)
+}
+
+// sortedValues returns the values of m ordered by key.
+func sortedValues[V any](m map[string]V) []V {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	values := make([]V, 0, len(keys))
+	for _, k := range keys {
+		values = append(values, m[k])
+	}
+	return values
+}
+
+// describeTool returns a copy of tool with its schemas filled in the way
+// mcp.AddTool infers them, so the registry reflects what clients see.
+func describeTool[In, Out any](tool *mcp.Tool) *mcp.Tool {
+	tt := *tool
+
+	if tt.InputSchema == nil {
+		if reflect.TypeFor[In]() == reflect.TypeFor[any]() {
+			tt.InputSchema = &jsonschema.Schema{Type: "object"}
+		} else {
+			tt.InputSchema = inferSchema[In]()
+		}
+	}
+	if tt.OutputSchema == nil && reflect.TypeFor[Out]() != reflect.TypeFor[any]() {
+		tt.OutputSchema = inferSchema[Out]()
+	}
+
+	return &tt
+}
+
+// inferSchema infers the schema for T, dereferencing pointer types, or returns
+// nil if T has no schema.
+func inferSchema[T any]() any {
+	rt := reflect.TypeFor[T]()
+	if rt.Kind() == reflect.Pointer {
+		rt = rt.Elem()
+	}
+	schema, err := jsonschema.ForType(rt, &jsonschema.ForOptions{})
+	if err != nil {
+		return nil
+	}
+	return schema
+}
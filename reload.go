@@ -0,0 +1,105 @@
+package hypermcp
+
+import (
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+)
+
+// RemoveTool unregisters the tool named name, reporting whether it was
+// registered. Connected clients are sent a tools/list_changed notification,
+// and calls to the tool fail from then on. Calls already running finish.
+func (s *Server) RemoveTool(name string) bool {
+	if !s.registry.removeTool(name) {
+		return false
+	}
+	s.mcp.RemoveTools(name)
+	s.toolCount.Add(-1)
+	return true
+}
+
+// removeTools unregisters the named tools with a single call to the SDK, so
+// clients are sent one tools/list_changed notification for all of them.
+func (s *Server) removeTools(names []string) {
+	var removed []string
+	for _, name := range names {
+		if s.registry.removeTool(name) {
+			removed = append(removed, name)
+		}
+	}
+	if len(removed) == 0 {
+		return
+	}
+	s.mcp.RemoveTools(removed...)
+	s.toolCount.Add(-int64(len(removed)))
+}
+
+// ReloadTools replaces the registered tools with the ones returned by
+// provider, for deployments whose tool set changes at runtime, such as tools
+// loaded from a plugin directory.
+//
+// provider returns the complete tool set. Registered tools missing from it
+// are removed, and tools not yet registered are added; tools already
+// registered under the same name are left as they are, so remove a tool
+// first to change its definition or handler. Clients are sent a single
+// tools/list_changed notification for the whole reload.
+//
+// If provider fails, or returns two tools of the same name, nothing changes
+// and the error is returned. A tool that fails to register, for example
+// because of a malformed schema, is skipped and its error returned once the
+// rest of the reload has been applied. Reloads run one at a time.
+func (s *Server) ReloadTools(provider func() ([]ToolRegistration, error)) error {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	regs, err := provider()
+	if err != nil {
+		return fmt.Errorf("reload tools: %w", err)
+	}
+
+	wanted := make(map[string]bool, len(regs))
+	for _, reg := range regs {
+		if reg.register == nil {
+			return fmt.Errorf("reload tools: empty tool registration")
+		}
+		if wanted[reg.tool.Name] {
+			return fmt.Errorf("reload tools: %w: tool %q", ErrDuplicateRegistration, reg.tool.Name)
+		}
+		wanted[reg.tool.Name] = true
+	}
+
+	tools, _, _, _ := s.registry.snapshot()
+	registered := make(map[string]bool, len(tools))
+	var removed []string
+	for _, tool := range tools {
+		registered[tool.Name] = true
+		if !wanted[tool.Name] {
+			removed = append(removed, tool.Name)
+		}
+	}
+	s.removeTools(removed)
+
+	var added []string
+	var errs []error
+	for _, reg := range regs {
+		if registered[reg.tool.Name] {
+			continue
+		}
+		if err := reg.register(s); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		added = append(added, reg.tool.Name)
+	}
+
+	s.log().Info("tools reloaded",
+		zap.Strings("added", added),
+		zap.Strings("removed", removed),
+		zap.Int64("tools", s.toolCount.Load()),
+	)
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("reload tools: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package hypermcp
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap/zaptest"
+)
+
+func echoRegistration(name string) ToolRegistration {
+	return NewToolRegistration(&mcp.Tool{Name: name}, func(context.Context, *mcp.CallToolRequest, struct{}) (*mcp.CallToolResult, any, error) {
+		return TextResult(name), nil, nil
+	})
+}
+
+func listToolNames(t *testing.T, session *mcp.ClientSession) []string {
+	t.Helper()
+
+	result, err := session.ListTools(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("ListTools() error = %v", err)
+	}
+	var names []string
+	for _, tool := range result.Tools {
+		names = append(names, tool.Name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func TestServer_ReloadTools(t *testing.T) {
+	srv, err := New(Config{Name: "test-server", Version: "1.0.0"}, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	srv.AddTools(echoRegistration("kept"), echoRegistration("gone"))
+
+	changed := make(chan struct{}, 10)
+	session := connectTestClientWithOptions(t, srv, &mcp.ClientOptions{
+		ToolListChangedHandler: func(context.Context, *mcp.ToolListChangedRequest) {
+			changed <- struct{}{}
+		},
+	})
+
+	err = srv.ReloadTools(func() ([]ToolRegistration, error) {
+		return []ToolRegistration{echoRegistration("kept"), echoRegistration("new")}, nil
+	})
+	if err != nil {
+		t.Fatalf("ReloadTools() error = %v", err)
+	}
+
+	select {
+	case <-changed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no tools/list_changed notification after reload")
+	}
+	if got, want := listToolNames(t, session), []string{"kept", "new"}; !slices.Equal(got, want) {
+		t.Errorf("tools after reload = %v, want %v", got, want)
+	}
+	if srv.toolCount.Load() != 2 {
+		t.Errorf("toolCount = %d, want 2", srv.toolCount.Load())
+	}
+
+	result, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "new", Arguments: map[string]any{}})
+	if err != nil {
+		t.Fatalf("CallTool(new) error = %v", err)
+	}
+	if text := result.Content[0].(*mcp.TextContent).Text; text != "new" {
+		t.Errorf("new tool returned %q", text)
+	}
+	if _, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "gone", Arguments: map[string]any{}}); err == nil {
+		t.Error("CallTool(gone) succeeded after the tool was removed")
+	}
+}
+
+func TestServer_ReloadToolsErrors(t *testing.T) {
+	srv, err := New(Config{Name: "test-server", Version: "1.0.0"}, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	srv.AddTools(echoRegistration("kept"))
+	session := connectTestClient(t, srv)
+
+	errProvider := errors.New("plugin directory unreadable")
+	err = srv.ReloadTools(func() ([]ToolRegistration, error) { return nil, errProvider })
+	if !errors.Is(err, errProvider) {
+		t.Errorf("ReloadTools() error = %v, want provider error", err)
+	}
+
+	err = srv.ReloadTools(func() ([]ToolRegistration, error) {
+		return []ToolRegistration{echoRegistration("twice"), echoRegistration("twice")}, nil
+	})
+	if !errors.Is(err, ErrDuplicateRegistration) {
+		t.Errorf("ReloadTools() error = %v, want ErrDuplicateRegistration", err)
+	}
+
+	// Failed reloads leave the tools alone
+	if got, want := listToolNames(t, session), []string{"kept"}; !slices.Equal(got, want) {
+		t.Errorf("tools after failed reloads = %v, want %v", got, want)
+	}
+}
+
+func TestServer_RemoveTool(t *testing.T) {
+	srv, err := New(Config{Name: "test-server", Version: "1.0.0"}, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	srv.AddTools(echoRegistration("echo"))
+
+	if !srv.RemoveTool("echo") {
+		t.Error("RemoveTool(echo) = false, want true")
+	}
+	if srv.RemoveTool("echo") {
+		t.Error("second RemoveTool(echo) = true, want false")
+	}
+
+	// The name can be registered again
+	if err := AddToolErr(srv, &mcp.Tool{Name: "echo"}, func(context.Context, *mcp.CallToolRequest, struct{}) (*mcp.CallToolResult, any, error) {
+		return TextResult("again"), nil, nil
+	}); err != nil {
+		t.Errorf("AddToolErr() after RemoveTool error = %v", err)
+	}
+}
@@ -0,0 +1,125 @@
+package hypermcp
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap"
+)
+
+// ResourceLoader produces the content of a resource and its MIME type.
+type ResourceLoader func(ctx context.Context) (data []byte, mimeType string, err error)
+
+// lazyResourceContent is the cached output of a ResourceLoader.
+type lazyResourceContent struct {
+	mimeType string
+	data     []byte
+}
+
+// AddLazyResource registers a resource whose content is produced by loader and
+// cached by URI for ttl.
+//
+// The loader runs on the first read and again only after the cached content
+// expires, so expensive content (a remote document, a generated report) is
+// computed at most once per TTL window. Cached content is charged its size
+// against Config.ResourceCacheMaxBytes; when it runs out, the largest cached
+// resources are evicted first, and content larger than the whole budget is
+// served without being cached. Reads, cache hits, and cache misses are
+// recorded in the server metrics; loader errors are counted and returned to the
+// client without being cached.
+//
+// The content is returned as text when its MIME type is textual (text/*, JSON,
+// XML, YAML, JavaScript) and as a blob otherwise. If the loader returns an empty
+// MIME type, resource.MIMEType is used.
+//
+// Example:
+//
+//	srv.AddLazyResource(&mcp.Resource{
+//	    URI:  "myapp://report",
+//	    Name: "Daily Report",
+//	}, 10*time.Minute, func(ctx context.Context) ([]byte, string, error) {
+//	    data, err := buildReport(ctx)
+//	    return data, "text/markdown", err
+//	})
+func (s *Server) AddLazyResource(resource *mcp.Resource, ttl time.Duration, loader ResourceLoader) {
+	uri := resource.URI
+	cacheKey := "resource:" + uri
+
+	handler := func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+		s.metrics.IncrementResourceReads()
+
+		if content, ok := s.resources.get(cacheKey); ok {
+			s.metrics.IncrementCacheHits()
+			return newResourceResult(uri, content), nil
+		}
+		s.metrics.IncrementCacheMisses()
+
+		data, mimeType, err := loader(ctx)
+		if err != nil {
+			s.metrics.IncrementErrors()
+			s.log().Warn("lazy resource loader failed",
+				zap.String("uri", uri),
+				zap.Error(err),
+			)
+			err = fmt.Errorf("load resource %s: %w", uri, err)
+			s.metrics.RecordError("", err)
+			return nil, err
+		}
+		if mimeType == "" {
+			mimeType = resource.MIMEType
+		}
+
+		content := lazyResourceContent{mimeType: mimeType, data: data}
+		s.resources.set(cacheKey, content, ttl)
+
+		return newResourceResult(uri, content), nil
+	}
+
+	s.AddResource(resource, handler)
+}
+
+// newResourceResult wraps content as a text or blob resource result.
+func newResourceResult(uri string, content lazyResourceContent) *mcp.ReadResourceResult {
+	contents := &mcp.ResourceContents{
+		URI:      uri,
+		MIMEType: content.mimeType,
+	}
+	if isTextMIMEType(content.mimeType) {
+		contents.Text = string(content.data)
+	} else {
+		contents.Blob = content.data
+	}
+
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{contents},
+	}
+}
+
+// isTextMIMEType reports whether content of the given MIME type should be sent as text.
+func isTextMIMEType(mimeType string) bool {
+	mediaType, _, err := mime.ParseMediaType(mimeType)
+	if err != nil {
+		return false
+	}
+
+	if strings.HasPrefix(mediaType, "text/") ||
+		strings.HasSuffix(mediaType, "+json") ||
+		strings.HasSuffix(mediaType, "+xml") {
+		return true
+	}
+
+	switch mediaType {
+	case "application/json",
+		"application/xml",
+		"application/yaml",
+		"application/x-yaml",
+		"application/javascript":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,121 @@
+package hypermcp
+
+import (
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// resourceCache keeps the content cached by AddLazyResource within a byte
+// budget. Each entry is charged its size against the cache's MaxCost, and
+// when a new entry doesn't fit, the largest cached resources are evicted
+// first: one large report frees more room than many small documents, which
+// are usually cheaper to keep and read more often.
+type resourceCache struct {
+	srv    *Server
+	budget int64
+
+	mu    sync.Mutex
+	used  int64
+	sizes map[string]int64 // Cache key to the size of its cached content
+}
+
+// newResourceCache returns a resource cache for srv holding at most budget
+// bytes of content.
+func newResourceCache(srv *Server, budget int64) *resourceCache {
+	return &resourceCache{
+		srv:    srv,
+		budget: budget,
+		sizes:  make(map[string]int64),
+	}
+}
+
+// get returns the cached content for key, forgetting the key if the cache
+// has dropped it (expired or evicted by Ristretto).
+func (rc *resourceCache) get(key string) (lazyResourceContent, bool) {
+	if cached, ok := rc.srv.cache.Get(key); ok {
+		if content, ok := cached.(lazyResourceContent); ok {
+			return content, true
+		}
+	}
+	rc.forget(key)
+	return lazyResourceContent{}, false
+}
+
+// set caches content under key for ttl, charged its size, after evicting the
+// largest cached resources needed to make room. Content larger than the whole
+// budget is not cached.
+func (rc *resourceCache) set(key string, content lazyResourceContent, ttl time.Duration) {
+	size := int64(len(content.data))
+	evicted, ok := rc.reserve(key, size)
+	for _, k := range evicted {
+		rc.srv.cache.Delete(k)
+	}
+	if len(evicted) > 0 {
+		rc.srv.log().Debug("evicted cached resources to make room",
+			zap.String("key", key),
+			zap.Int64("size", size),
+			zap.Strings("evicted", evicted),
+		)
+	}
+	if !ok {
+		rc.srv.log().Debug("resource content exceeds the cache budget, not caching",
+			zap.String("key", key),
+			zap.Int64("size", size),
+			zap.Int64("budget", rc.budget),
+		)
+		return
+	}
+
+	// Wait for the write so concurrent and immediate re-reads hit the cache
+	stored := rc.srv.cache.SetWithCost(key, content, size, ttl)
+	rc.srv.cache.Flush()
+	if !stored {
+		rc.forget(key)
+	}
+}
+
+// reserve accounts size bytes for key, returning the keys evicted, largest
+// first, to fit it within the budget. It returns false if size alone exceeds
+// the budget.
+func (rc *resourceCache) reserve(key string, size int64) ([]string, bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	rc.remove(key)
+	if size > rc.budget {
+		return nil, false
+	}
+
+	var evicted []string
+	for rc.used+size > rc.budget {
+		largest, largestSize := "", int64(-1)
+		for k, s := range rc.sizes {
+			if s > largestSize {
+				largest, largestSize = k, s
+			}
+		}
+		rc.remove(largest)
+		evicted = append(evicted, largest)
+	}
+
+	rc.sizes[key] = size
+	rc.used += size
+	return evicted, true
+}
+
+// forget stops accounting for key.
+func (rc *resourceCache) forget(key string) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.remove(key)
+}
+
+// remove stops accounting for key. rc.mu must be held.
+func (rc *resourceCache) remove(key string) {
+	if size, ok := rc.sizes[key]; ok {
+		rc.used -= size
+		delete(rc.sizes, key)
+	}
+}
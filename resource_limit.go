@@ -0,0 +1,40 @@
+package hypermcp
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// resourceReadLimiter limits how many resources/read requests run at once.
+// Reads beyond the limit wait for a free slot.
+type resourceReadLimiter struct {
+	slots chan struct{}
+}
+
+// newResourceReadLimiter creates a limiter running at most n reads at once.
+func newResourceReadLimiter(n int) *resourceReadLimiter {
+	return &resourceReadLimiter{slots: make(chan struct{}, n)}
+}
+
+// middleware returns receiving middleware that holds resources/read requests
+// until a slot is free, or fails them with the context's error if ctx is done
+// first.
+func (l *resourceReadLimiter) middleware() mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			if method != "resources/read" {
+				return next(ctx, method, req)
+			}
+
+			select {
+			case l.slots <- struct{}{}:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+			defer func() { <-l.slots }()
+
+			return next(ctx, method, req)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package hypermcp
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestConfig_MaxConcurrentResourceReads(t *testing.T) {
+	srv, err := New(Config{
+		Name:                       "test-server",
+		Version:                    "1.0.0",
+		MaxConcurrentResourceReads: 1,
+	}, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+
+	var active, peak atomic.Int32
+	srv.AddResource(&mcp.Resource{
+		URI:  "test://slow",
+		Name: "slow",
+	}, func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+		n := active.Add(1)
+		defer active.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		return &mcp.ReadResourceResult{
+			Contents: []*mcp.ResourceContents{{URI: req.Params.URI, Text: "ok"}},
+		}, nil
+	})
+
+	session := connectTestClient(t, srv)
+
+	const reads = 3
+	var wg sync.WaitGroup
+	errs := make(chan error, reads)
+	for i := 0; i < reads; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := session.ReadResource(context.Background(), &mcp.ReadResourceParams{URI: "test://slow"})
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("read failed: %v", err)
+		}
+	}
+	if got := peak.Load(); got != 1 {
+		t.Errorf("expected reads to run one at a time, got %d concurrent", got)
+	}
+}
+
+func TestResourceReadLimiter_Canceled(t *testing.T) {
+	limiter := newResourceReadLimiter(1)
+	limiter.slots <- struct{}{}
+
+	handler := limiter.middleware()(func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		t.Error("handler ran without a free slot")
+		return nil, nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := handler(ctx, "resources/read", nil); err != context.Canceled {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
@@ -0,0 +1,124 @@
+package hypermcp
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap"
+)
+
+// DefaultMaxResourceSize is the size limit ReadResourceContent and
+// AddStreamingResource apply when given a maxSize of zero or less.
+const DefaultMaxResourceSize = 10 * 1024 * 1024 // 10MB
+
+// ResourceOpener opens the content of a resource for reading, along with its
+// MIME type. The returned reader is closed after each read.
+type ResourceOpener func(ctx context.Context) (r io.ReadCloser, mimeType string, err error)
+
+// ReadResourceContent reads a resource's content from r into a result for uri,
+// failing with ErrResourceTooLarge instead of reading more than maxSize bytes.
+//
+// MCP has no partial resource reads, so a resource's content is always
+// returned whole; this bounds the memory a read can take rather than streaming
+// it to the client. Content is read incrementally and never more than
+// maxSize+1 bytes are consumed from r, so an oversized or endless source is
+// rejected early. Files (and any reader with a Stat method) are rejected from
+// their size without being read at all. A maxSize of zero or less means
+// DefaultMaxResourceSize.
+//
+// The content is returned as text when mimeType is textual and as a blob
+// otherwise, as AddLazyResource does. Use it from resource and resource
+// template handlers serving files or other large sources:
+//
+//	f, err := os.Open(path)
+//	if err != nil {
+//	    return nil, err
+//	}
+//	defer f.Close()
+//	return hypermcp.ReadResourceContent(req.Params.URI, f, "text/plain", 1<<20)
+func ReadResourceContent(uri string, r io.Reader, mimeType string, maxSize int64) (*mcp.ReadResourceResult, error) {
+	if maxSize <= 0 {
+		maxSize = DefaultMaxResourceSize
+	}
+
+	if st, ok := r.(interface{ Stat() (fs.FileInfo, error) }); ok {
+		if info, err := st.Stat(); err == nil && info.Mode().IsRegular() && info.Size() > maxSize {
+			return nil, resourceTooLarge(uri, maxSize)
+		}
+	}
+
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(io.LimitReader(r, maxSize+1)); err != nil {
+		return nil, fmt.Errorf("read resource %s: %w", uri, err)
+	}
+	if int64(buf.Len()) > maxSize {
+		return nil, resourceTooLarge(uri, maxSize)
+	}
+
+	return newResourceResult(uri, lazyResourceContent{mimeType: mimeType, data: buf.Bytes()}), nil
+}
+
+func resourceTooLarge(uri string, maxSize int64) error {
+	return fmt.Errorf("%w: %s exceeds the %d byte limit", ErrResourceTooLarge, uri, maxSize)
+}
+
+// AddStreamingResource registers a resource whose content is read from the
+// reader returned by open on every read, with at most maxSize bytes read
+// into memory. Larger content fails the read with ErrResourceTooLarge. See
+// ReadResourceContent for how the limit is enforced.
+//
+// Reads are recorded in the server metrics, and open and read failures are
+// counted as errors. If open returns an empty MIME type, resource.MIMEType is
+// used.
+//
+// Example:
+//
+//	srv.AddStreamingResource(&mcp.Resource{
+//	    URI:  "file:///var/log/app.log",
+//	    Name: "Application Log",
+//	}, 5<<20, func(ctx context.Context) (io.ReadCloser, string, error) {
+//	    f, err := os.Open("/var/log/app.log")
+//	    return f, "text/plain", err
+//	})
+func (s *Server) AddStreamingResource(resource *mcp.Resource, maxSize int64, open ResourceOpener) {
+	uri := resource.URI
+
+	handler := func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+		s.metrics.IncrementResourceReads()
+
+		r, mimeType, err := open(ctx)
+		if err != nil {
+			s.metrics.IncrementErrors()
+			s.log().Warn("streaming resource open failed",
+				zap.String("uri", uri),
+				zap.Error(err),
+			)
+			err = fmt.Errorf("open resource %s: %w", uri, err)
+			s.metrics.RecordError("", err)
+			return nil, err
+		}
+		defer func() { _ = r.Close() }()
+
+		if mimeType == "" {
+			mimeType = resource.MIMEType
+		}
+
+		result, err := ReadResourceContent(uri, r, mimeType, maxSize)
+		if err != nil {
+			s.metrics.IncrementErrors()
+			s.metrics.RecordError("", err)
+			s.log().Warn("streaming resource read failed",
+				zap.String("uri", uri),
+				zap.Error(err),
+			)
+			return nil, err
+		}
+		return result, nil
+	}
+
+	s.AddResource(resource, handler)
+}
@@ -0,0 +1,118 @@
+package hypermcp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap/zaptest"
+)
+
+// endlessReader produces an unbounded stream of bytes, counting how many were read.
+type endlessReader struct {
+	read int64
+}
+
+func (r *endlessReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 'x'
+	}
+	r.read += int64(len(p))
+	return len(p), nil
+}
+
+// writeLargeFile writes size bytes of varied text to a temporary file and returns its path and content.
+func writeLargeFile(t *testing.T, size int) (string, []byte) {
+	t.Helper()
+	line := []byte("0123456789abcdefghijklmnopqrstuvwxyz\n")
+	content := bytes.Repeat(line, size/len(line)+1)[:size]
+	path := filepath.Join(t.TempDir(), "large.txt")
+	if err := os.WriteFile(path, content, 0o600); err != nil {
+		t.Fatalf("write large file: %v", err)
+	}
+	return path, content
+}
+
+func TestServer_AddStreamingResource(t *testing.T) {
+	path, content := writeLargeFile(t, 3<<20)
+
+	srv, err := New(Config{Name: "test-server", Version: "1.0.0"}, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	open := func(context.Context) (io.ReadCloser, string, error) {
+		f, err := os.Open(path)
+		return f, "text/plain", err
+	}
+	srv.AddStreamingResource(&mcp.Resource{URI: "test://large", Name: "large"}, 4<<20, open)
+	srv.AddStreamingResource(&mcp.Resource{URI: "test://limited", Name: "limited"}, 1<<20, open)
+
+	session := connectTestClient(t, srv)
+	ctx := context.Background()
+
+	result, err := session.ReadResource(ctx, &mcp.ReadResourceParams{URI: "test://large"})
+	if err != nil {
+		t.Fatalf("ReadResource() error = %v", err)
+	}
+	if len(result.Contents) != 1 || result.Contents[0].Text != string(content) {
+		t.Errorf("content mismatch: got %d contents", len(result.Contents))
+	}
+
+	_, err = session.ReadResource(ctx, &mcp.ReadResourceParams{URI: "test://limited"})
+	if err == nil || !strings.Contains(err.Error(), "resource too large") {
+		t.Errorf("ReadResource() over the limit error = %v, want resource too large", err)
+	}
+
+	snap := srv.GetMetrics()
+	if snap.ResourceReads != 2 || snap.Errors != 1 {
+		t.Errorf("ResourceReads, Errors = %d, %d, want 2, 1", snap.ResourceReads, snap.Errors)
+	}
+}
+
+func TestReadResourceContent_BoundsReads(t *testing.T) {
+	const maxSize = 64 << 10
+	r := &endlessReader{}
+
+	_, err := ReadResourceContent("test://endless", r, "text/plain", maxSize)
+	if !errors.Is(err, ErrResourceTooLarge) {
+		t.Fatalf("error = %v, want ErrResourceTooLarge", err)
+	}
+	if r.read > maxSize+1 {
+		t.Errorf("read %d bytes from the source, want at most %d", r.read, maxSize+1)
+	}
+}
+
+func TestReadResourceContent_RejectsLargeFileWithoutReading(t *testing.T) {
+	path, _ := writeLargeFile(t, 1<<20)
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer f.Close()
+
+	_, err = ReadResourceContent("test://file", f, "text/plain", 1<<10)
+	if !errors.Is(err, ErrResourceTooLarge) {
+		t.Fatalf("error = %v, want ErrResourceTooLarge", err)
+	}
+	if offset, _ := f.Seek(0, io.SeekCurrent); offset != 0 {
+		t.Errorf("file was read to offset %d before being rejected", offset)
+	}
+}
+
+func TestReadResourceContent_Blob(t *testing.T) {
+	data := []byte{0x00, 0xff, 0x10}
+
+	result, err := ReadResourceContent("test://blob", bytes.NewReader(data), "application/octet-stream", 0)
+	if err != nil {
+		t.Fatalf("ReadResourceContent() error = %v", err)
+	}
+	if !bytes.Equal(result.Contents[0].Blob, data) || result.Contents[0].Text != "" {
+		t.Errorf("contents = %+v, want blob %v", result.Contents[0], data)
+	}
+}
@@ -0,0 +1,232 @@
+package hypermcp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rayprogramming/hypermcp/cache"
+	"go.uber.org/zap/zaptest"
+)
+
+func newLazyResourceTestServer(t *testing.T) *Server {
+	t.Helper()
+
+	srv, err := New(Config{
+		Name:         "test-server",
+		Version:      "1.0.0",
+		CacheEnabled: true,
+		CacheConfig:  cache.DefaultConfig(),
+	}, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	t.Cleanup(func() { _ = srv.Shutdown(context.Background()) })
+
+	return srv
+}
+
+func TestServer_AddLazyResource_CachesPerTTL(t *testing.T) {
+	srv := newLazyResourceTestServer(t)
+
+	var loads atomic.Int32
+	srv.AddLazyResource(&mcp.Resource{
+		URI:  "test://report",
+		Name: "Report",
+	}, 50*time.Millisecond, func(ctx context.Context) ([]byte, string, error) {
+		loads.Add(1)
+		return []byte("# Report"), "text/markdown", nil
+	})
+
+	if srv.resourceCount.Load() != 1 {
+		t.Errorf("expected resource count 1, got %d", srv.resourceCount.Load())
+	}
+
+	session := connectTestClient(t, srv)
+	read := func() *mcp.ResourceContents {
+		t.Helper()
+		res, err := session.ReadResource(context.Background(), &mcp.ReadResourceParams{URI: "test://report"})
+		if err != nil {
+			t.Fatalf("failed to read resource: %v", err)
+		}
+		if len(res.Contents) != 1 {
+			t.Fatalf("expected 1 content block, got %d", len(res.Contents))
+		}
+		return res.Contents[0]
+	}
+
+	for i := 0; i < 3; i++ {
+		contents := read()
+		if contents.Text != "# Report" || contents.MIMEType != "text/markdown" {
+			t.Errorf("unexpected contents: %+v", contents)
+		}
+	}
+	if got := loads.Load(); got != 1 {
+		t.Errorf("expected loader to run once within the TTL, ran %d times", got)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	read()
+	if got := loads.Load(); got != 2 {
+		t.Errorf("expected loader to run again after the TTL, ran %d times", got)
+	}
+
+	metrics := srv.GetMetrics()
+	if metrics.ResourceReads != 4 || metrics.CacheHits != 2 || metrics.CacheMisses != 2 {
+		t.Errorf("unexpected metrics: reads=%d hits=%d misses=%d", metrics.ResourceReads, metrics.CacheHits, metrics.CacheMisses)
+	}
+}
+
+func TestServer_AddLazyResource_Blob(t *testing.T) {
+	srv := newLazyResourceTestServer(t)
+
+	png := []byte{0x89, 'P', 'N', 'G'}
+	srv.AddLazyResource(&mcp.Resource{
+		URI:      "test://logo",
+		Name:     "Logo",
+		MIMEType: "image/png",
+	}, time.Minute, func(ctx context.Context) ([]byte, string, error) {
+		return png, "", nil
+	})
+
+	session := connectTestClient(t, srv)
+	res, err := session.ReadResource(context.Background(), &mcp.ReadResourceParams{URI: "test://logo"})
+	if err != nil {
+		t.Fatalf("failed to read resource: %v", err)
+	}
+
+	contents := res.Contents[0]
+	if !bytes.Equal(contents.Blob, png) || contents.Text != "" {
+		t.Errorf("expected blob content, got %+v", contents)
+	}
+	if contents.MIMEType != "image/png" {
+		t.Errorf("expected resource MIME type fallback, got %q", contents.MIMEType)
+	}
+}
+
+func TestServer_AddLazyResource_LoaderErrorNotCached(t *testing.T) {
+	srv := newLazyResourceTestServer(t)
+
+	var loads atomic.Int32
+	srv.AddLazyResource(&mcp.Resource{
+		URI:  "test://flaky",
+		Name: "Flaky",
+	}, time.Minute, func(ctx context.Context) ([]byte, string, error) {
+		if loads.Add(1) == 1 {
+			return nil, "", errors.New("upstream unavailable")
+		}
+		return []byte(`{"ok":true}`), "application/json", nil
+	})
+
+	session := connectTestClient(t, srv)
+	if _, err := session.ReadResource(context.Background(), &mcp.ReadResourceParams{URI: "test://flaky"}); err == nil {
+		t.Fatal("expected error from failing loader")
+	}
+
+	res, err := session.ReadResource(context.Background(), &mcp.ReadResourceParams{URI: "test://flaky"})
+	if err != nil {
+		t.Fatalf("expected retry to succeed, got %v", err)
+	}
+	if res.Contents[0].Text != `{"ok":true}` {
+		t.Errorf("expected JSON as text, got %+v", res.Contents[0])
+	}
+	if got := srv.GetMetrics().Errors; got != 1 {
+		t.Errorf("expected 1 error, got %d", got)
+	}
+}
+
+func TestIsTextMIMEType(t *testing.T) {
+	tests := []struct {
+		mimeType string
+		want     bool
+	}{
+		{"text/plain; charset=utf-8", true},
+		{"application/json", true},
+		{"application/vnd.api+json", true},
+		{"image/svg+xml", true},
+		{"application/octet-stream", false},
+		{"image/png", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := isTextMIMEType(tt.mimeType); got != tt.want {
+			t.Errorf("isTextMIMEType(%q) = %v, want %v", tt.mimeType, got, tt.want)
+		}
+	}
+}
+
+func TestServer_AddLazyResource_EvictsLargestFirst(t *testing.T) {
+	srv, err := New(Config{
+		Name:                  "test-server",
+		Version:               "1.0.0",
+		CacheEnabled:          true,
+		CacheConfig:           cache.DefaultConfig(),
+		ResourceCacheMaxBytes: 1000,
+	}, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	t.Cleanup(func() { _ = srv.Shutdown(context.Background()) })
+
+	sizes := map[string]int{
+		"test://small":  100,
+		"test://large":  500,
+		"test://medium": 300,
+		"test://late":   250,
+		"test://huge":   1500,
+	}
+	loads := make(map[string]*atomic.Int32)
+	for uri, size := range sizes {
+		count := new(atomic.Int32)
+		loads[uri] = count
+		srv.AddLazyResource(&mcp.Resource{URI: uri, Name: uri}, time.Hour,
+			func(ctx context.Context) ([]byte, string, error) {
+				count.Add(1)
+				return bytes.Repeat([]byte("x"), size), "text/plain", nil
+			})
+	}
+
+	session := connectTestClient(t, srv)
+	read := func(uri string) {
+		t.Helper()
+		if _, err := session.ReadResource(context.Background(), &mcp.ReadResourceParams{URI: uri}); err != nil {
+			t.Fatalf("failed to read %s: %v", uri, err)
+		}
+	}
+
+	// 900 of the 1000 byte budget is used; the next 250 bytes only fit once
+	// the 500 byte resource is evicted
+	for _, uri := range []string{"test://small", "test://large", "test://medium", "test://late"} {
+		read(uri)
+	}
+	for _, uri := range []string{"test://small", "test://medium", "test://late", "test://large"} {
+		read(uri)
+	}
+
+	want := map[string]int32{
+		"test://small":  1,
+		"test://medium": 1,
+		"test://late":   1,
+		"test://large":  2, // evicted first, so loaded again
+	}
+	for uri, n := range want {
+		if got := loads[uri].Load(); got != n {
+			t.Errorf("%s loaded %d times, want %d", uri, got, n)
+		}
+	}
+
+	// Content larger than the whole budget is served but never cached
+	read("test://huge")
+	read("test://huge")
+	if got := loads["test://huge"].Load(); got != 2 {
+		t.Errorf("oversized resource loaded %d times, want 2", got)
+	}
+	if _, ok := srv.Cache().Get("resource:test://huge"); ok {
+		t.Error("expected the oversized resource not to be cached")
+	}
+}
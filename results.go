@@ -0,0 +1,159 @@
+package hypermcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// TextResult returns a tool result holding a single text content block.
+//
+// It replaces the usual
+//
+//	&mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: s}}}
+func TextResult(s string) *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: s}},
+	}
+}
+
+// JSONResult returns a tool result holding v encoded as indented JSON in a
+// single text content block.
+//
+// Returns an error if v cannot be marshaled.
+func JSONResult(v any) (*mcp.CallToolResult, error) {
+	content, err := jsonContent(v)
+	if err != nil {
+		return nil, err
+	}
+	return &mcp.CallToolResult{Content: []mcp.Content{content}}, nil
+}
+
+// jsonContent returns v encoded as indented JSON in a text content block.
+func jsonContent(v any) (*mcp.TextContent, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshal tool result: %w", err)
+	}
+	return &mcp.TextContent{Text: string(data)}, nil
+}
+
+// ErrorResult returns a tool result reporting msg as a tool error.
+//
+// The result has IsError set, so the model sees the failure and can react to
+// it, rather than the call failing at the protocol level as it does when the
+// handler returns an error.
+func ErrorResult(msg string) *mcp.CallToolResult {
+	result := TextResult(msg)
+	result.IsError = true
+	return result
+}
+
+// ImageResult returns a tool result holding a single image content block.
+//
+// data is the raw image; it is base64-encoded on the wire. mimeType is the
+// image's type, such as "image/png".
+func ImageResult(data []byte, mimeType string) *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.ImageContent{Data: data, MIMEType: mimeType}},
+	}
+}
+
+// ResultBuilder assembles a tool result from several content blocks of mixed
+// types, in the order they are added:
+//
+//	return hypermcp.NewResultBuilder().
+//	    AddText("Revenue grew 12% this quarter.").
+//	    AddImage(chartPNG, "image/png").
+//	    AddResourceLink("myapp://reports/q3", "Q3 report", "").
+//	    Build()
+//
+// The Add methods return the builder so calls can be chained. A builder is
+// not safe for concurrent use.
+type ResultBuilder struct {
+	content []mcp.Content
+	err     error
+	isError bool
+}
+
+// NewResultBuilder returns an empty ResultBuilder.
+func NewResultBuilder() *ResultBuilder {
+	return &ResultBuilder{}
+}
+
+// AddText adds a text content block.
+func (b *ResultBuilder) AddText(s string) *ResultBuilder {
+	b.content = append(b.content, &mcp.TextContent{Text: s})
+	return b
+}
+
+// AddImage adds an image content block. data is the raw image; it is
+// base64-encoded on the wire. If mimeType is empty, it is detected from data.
+func (b *ResultBuilder) AddImage(data []byte, mimeType string) *ResultBuilder {
+	if mimeType == "" {
+		mimeType = http.DetectContentType(data)
+	}
+	b.content = append(b.content, &mcp.ImageContent{Data: data, MIMEType: mimeType})
+	return b
+}
+
+// AddJSON adds v encoded as indented JSON in a text content block, as
+// JSONResult does. If v cannot be marshaled, Build returns the error.
+func (b *ResultBuilder) AddJSON(v any) *ResultBuilder {
+	content, err := jsonContent(v)
+	if err != nil {
+		if b.err == nil {
+			b.err = err
+		}
+		return b
+	}
+	b.content = append(b.content, content)
+	return b
+}
+
+// AddResourceLink adds a link to a resource the client can read separately,
+// rather than embedding its content. If name is empty, the last element of
+// the URI's path is used; if mimeType is empty, it is guessed from the path's
+// extension and otherwise left out.
+func (b *ResultBuilder) AddResourceLink(uri, name, mimeType string) *ResultBuilder {
+	var uriPath string
+	if u, err := url.Parse(uri); err == nil {
+		uriPath = u.Path
+		if uriPath == "" {
+			uriPath = u.Opaque
+		}
+	}
+	if name == "" {
+		name = path.Base(uriPath)
+		if name == "." || name == "/" {
+			name = uri
+		}
+	}
+	if mimeType == "" {
+		mimeType = mime.TypeByExtension(path.Ext(uriPath))
+	}
+	b.content = append(b.content, &mcp.ResourceLink{URI: uri, Name: name, MIMEType: mimeType})
+	return b
+}
+
+// SetError marks the result as a tool error, as ErrorResult does, so the
+// model sees the failure along with the content added.
+func (b *ResultBuilder) SetError() *ResultBuilder {
+	b.isError = true
+	return b
+}
+
+// Build returns the assembled result, or the first error from AddJSON.
+func (b *ResultBuilder) Build() (*mcp.CallToolResult, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	content := make([]mcp.Content, len(b.content))
+	copy(content, b.content)
+	return &mcp.CallToolResult{Content: content, IsError: b.isError}, nil
+}
@@ -0,0 +1,180 @@
+package hypermcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestTextResult(t *testing.T) {
+	result := TextResult("hello")
+
+	if result.IsError {
+		t.Error("expected IsError to be false")
+	}
+	if len(result.Content) != 1 {
+		t.Fatalf("expected 1 content block, got %d", len(result.Content))
+	}
+	text, ok := result.Content[0].(*mcp.TextContent)
+	if !ok || text.Text != "hello" {
+		t.Errorf("expected text content \"hello\", got %#v", result.Content[0])
+	}
+}
+
+func TestJSONResult(t *testing.T) {
+	result, err := JSONResult(map[string]any{"city": "Paris", "temp": 21})
+	if err != nil {
+		t.Fatalf("JSONResult returned error: %v", err)
+	}
+	if result.IsError || len(result.Content) != 1 {
+		t.Fatalf("expected a single non-error content block, got %+v", result)
+	}
+	text, ok := result.Content[0].(*mcp.TextContent)
+	if !ok {
+		t.Fatalf("expected text content, got %T", result.Content[0])
+	}
+
+	var decoded struct {
+		City string `json:"city"`
+		Temp int    `json:"temp"`
+	}
+	if err := json.Unmarshal([]byte(text.Text), &decoded); err != nil {
+		t.Fatalf("content is not valid JSON: %v\n%s", err, text.Text)
+	}
+	if decoded.City != "Paris" || decoded.Temp != 21 {
+		t.Errorf("unexpected decoded content %+v", decoded)
+	}
+
+	if _, err := JSONResult(make(chan int)); err == nil || !strings.Contains(err.Error(), "marshal tool result") {
+		t.Errorf("expected marshal error for an unencodable value, got %v", err)
+	}
+}
+
+func TestErrorResult(t *testing.T) {
+	result := ErrorResult("city not found")
+
+	if !result.IsError {
+		t.Error("expected IsError to be true")
+	}
+	if len(result.Content) != 1 {
+		t.Fatalf("expected 1 content block, got %d", len(result.Content))
+	}
+	if text, ok := result.Content[0].(*mcp.TextContent); !ok || text.Text != "city not found" {
+		t.Errorf("expected text content \"city not found\", got %#v", result.Content[0])
+	}
+}
+
+func TestImageResult(t *testing.T) {
+	png := []byte{0x89, 'P', 'N', 'G'}
+	result := ImageResult(png, "image/png")
+
+	if result.IsError || len(result.Content) != 1 {
+		t.Fatalf("expected a single non-error content block, got %+v", result)
+	}
+	image, ok := result.Content[0].(*mcp.ImageContent)
+	if !ok {
+		t.Fatalf("expected image content, got %T", result.Content[0])
+	}
+	if !bytes.Equal(image.Data, png) || image.MIMEType != "image/png" {
+		t.Errorf("unexpected image content %+v", image)
+	}
+
+	data, err := json.Marshal(image)
+	if err != nil {
+		t.Fatalf("failed to marshal image content: %v", err)
+	}
+	if !strings.Contains(string(data), `"data":"iVBORw=="`) {
+		t.Errorf("expected base64 data on the wire, got %s", data)
+	}
+}
+
+func TestResultBuilder(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\x0dIHDR")
+
+	result, err := NewResultBuilder().
+		AddText("summary").
+		AddImage(png, "").
+		AddJSON(map[string]int{"count": 3}).
+		AddResourceLink("file:///reports/q3.json", "", "").
+		Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if result.IsError {
+		t.Error("expected IsError to be false")
+	}
+	if len(result.Content) != 4 {
+		t.Fatalf("expected 4 content blocks, got %d", len(result.Content))
+	}
+
+	text, ok := result.Content[0].(*mcp.TextContent)
+	if !ok || text.Text != "summary" {
+		t.Errorf("content[0] = %#v, want text %q", result.Content[0], "summary")
+	}
+
+	image, ok := result.Content[1].(*mcp.ImageContent)
+	if !ok {
+		t.Fatalf("content[1] = %T, want *mcp.ImageContent", result.Content[1])
+	}
+	if !bytes.Equal(image.Data, png) {
+		t.Errorf("image data = %q, want %q", image.Data, png)
+	}
+	if image.MIMEType != "image/png" {
+		t.Errorf("image MIMEType = %q, want detected %q", image.MIMEType, "image/png")
+	}
+
+	jsonText, ok := result.Content[2].(*mcp.TextContent)
+	if !ok {
+		t.Fatalf("content[2] = %T, want *mcp.TextContent", result.Content[2])
+	}
+	var decoded map[string]int
+	if err := json.Unmarshal([]byte(jsonText.Text), &decoded); err != nil || decoded["count"] != 3 {
+		t.Errorf("content[2] text = %q, want JSON with count 3", jsonText.Text)
+	}
+
+	link, ok := result.Content[3].(*mcp.ResourceLink)
+	if !ok {
+		t.Fatalf("content[3] = %T, want *mcp.ResourceLink", result.Content[3])
+	}
+	if link.URI != "file:///reports/q3.json" || link.Name != "q3.json" || link.MIMEType != "application/json" {
+		t.Errorf("resource link = %+v, want name and MIME type derived from the URI", link)
+	}
+}
+
+func TestResultBuilder_ImageEncodedAsBase64(t *testing.T) {
+	result, err := NewResultBuilder().AddImage([]byte{0x01, 0x02, 0x03}, "image/gif").Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		t.Fatalf("marshal result: %v", err)
+	}
+	if !strings.Contains(string(data), `"data":"AQID"`) || !strings.Contains(string(data), `"mimeType":"image/gif"`) {
+		t.Errorf("marshaled result = %s, want base64 data and the given MIME type", data)
+	}
+}
+
+func TestResultBuilder_JSONError(t *testing.T) {
+	_, err := NewResultBuilder().
+		AddText("before").
+		AddJSON(make(chan int)).
+		Build()
+	if err == nil || !strings.Contains(err.Error(), "marshal tool result") {
+		t.Errorf("Build() error = %v, want marshal error", err)
+	}
+}
+
+func TestResultBuilder_SetError(t *testing.T) {
+	result, err := NewResultBuilder().AddText("failed").SetError().Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if !result.IsError {
+		t.Error("expected IsError to be true")
+	}
+}
@@ -0,0 +1,126 @@
+package hypermcp
+
+import (
+	"context"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap"
+)
+
+// Defaults for RetryPolicy fields left zero.
+const (
+	DefaultToolRetryAttempts        = 3
+	DefaultToolRetryInitialInterval = 100 * time.Millisecond
+	DefaultToolRetryMaxInterval     = 2 * time.Second
+)
+
+// RetryPolicy configures how AddToolWithRetry retries a failing tool handler.
+type RetryPolicy struct {
+	// MaxAttempts is how many times the handler runs at most, including the
+	// first attempt. Defaults to DefaultToolRetryAttempts.
+	MaxAttempts int
+
+	// InitialInterval is the wait before the first retry; later waits grow
+	// exponentially, with jitter, up to MaxInterval. They default to
+	// DefaultToolRetryInitialInterval and DefaultToolRetryMaxInterval.
+	InitialInterval time.Duration
+	MaxInterval     time.Duration
+
+	// Retryable reports whether an error returned by the handler is
+	// transient and worth retrying. Nil retries every error.
+	Retryable func(err error) bool
+}
+
+func (p RetryPolicy) maxAttempts() int {
+	if p.MaxAttempts <= 0 {
+		return DefaultToolRetryAttempts
+	}
+	return p.MaxAttempts
+}
+
+// backOff returns the wait schedule between attempts, stopping after
+// maxAttempts or when ctx is done.
+func (p RetryPolicy) backOff(ctx context.Context) backoff.BackOff {
+	expBackoff := backoff.NewExponentialBackOff()
+	expBackoff.InitialInterval = DefaultToolRetryInitialInterval
+	if p.InitialInterval > 0 {
+		expBackoff.InitialInterval = p.InitialInterval
+	}
+	expBackoff.MaxInterval = DefaultToolRetryMaxInterval
+	if p.MaxInterval > 0 {
+		expBackoff.MaxInterval = p.MaxInterval
+	}
+	expBackoff.MaxElapsedTime = 0 // bounded by attempts and ctx instead
+
+	retries := uint64(p.maxAttempts() - 1) // #nosec G115 -- maxAttempts is positive
+	return backoff.WithContext(backoff.WithMaxRetries(expBackoff, retries), ctx)
+}
+
+// AddToolWithRetry registers a tool like AddTool, retrying its handler with
+// exponential backoff when it returns an error that policy.Retryable accepts.
+// It is meant for handlers calling flaky internal dependencies, on top of the
+// retries httpx already does for HTTP requests.
+//
+// Retrying runs the handler again, so it is only done for tools declaring
+// themselves idempotent with Annotations.IdempotentHint. Any other tool is
+// registered without retries, and a warning is logged.
+//
+// Retries stop once policy.MaxAttempts have run, when an error is not
+// retryable, or when the call's context is done; the last error is returned,
+// or the context's error if it ended during a wait. Results with IsError set
+// are tool-level failures reported to the model, and are not retried.
+//
+// Example:
+//
+//	hypermcp.AddToolWithRetry(srv, &mcp.Tool{
+//	    Name:        "lookup",
+//	    Annotations: &mcp.ToolAnnotations{IdempotentHint: true},
+//	}, lookupHandler, hypermcp.RetryPolicy{
+//	    MaxAttempts: 4,
+//	    Retryable:   func(err error) bool { return errors.Is(err, errUnavailable) },
+//	})
+func AddToolWithRetry[In, Out any](s *Server, tool *mcp.Tool, handler mcp.ToolHandlerFor[In, Out], policy RetryPolicy) {
+	if tool.Annotations == nil || !tool.Annotations.IdempotentHint {
+		s.log().Warn("tool is not declared idempotent, registering without retries",
+			zap.String("tool", tool.Name),
+		)
+		AddTool(s, tool, handler)
+		return
+	}
+
+	toolName := tool.Name
+	wrapped := func(ctx context.Context, req *mcp.CallToolRequest, input In) (*mcp.CallToolResult, Out, error) {
+		var (
+			result  *mcp.CallToolResult
+			output  Out
+			attempt int
+		)
+		operation := func() error {
+			attempt++
+			var err error
+			result, output, err = handler(ctx, req, input)
+			if err != nil && policy.Retryable != nil && !policy.Retryable(err) {
+				return backoff.Permanent(err)
+			}
+			return err
+		}
+		notify := func(err error, wait time.Duration) {
+			s.log().Warn("retrying tool handler",
+				zap.String("tool", toolName),
+				zap.Int("attempt", attempt),
+				zap.Duration("wait", wait),
+				zap.Error(err),
+			)
+		}
+
+		if err := backoff.RetryNotify(operation, policy.backOff(ctx), notify); err != nil {
+			var zero Out
+			return result, zero, err
+		}
+		return result, output, nil
+	}
+
+	AddTool(s, tool, wrapped)
+}
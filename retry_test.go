@@ -0,0 +1,160 @@
+package hypermcp
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+var errFlaky = errors.New("dependency unavailable")
+
+// flakyHandler fails with errFlaky until it has been called failures times,
+// counting its calls in attempts.
+func flakyHandler(attempts *atomic.Int32, failures int32) mcp.ToolHandlerFor[struct{}, any] {
+	return func(ctx context.Context, req *mcp.CallToolRequest, input struct{}) (*mcp.CallToolResult, any, error) {
+		if attempts.Add(1) <= failures {
+			return nil, nil, errFlaky
+		}
+		return TextResult("done"), nil, nil
+	}
+}
+
+func idempotentTool(name string) *mcp.Tool {
+	return &mcp.Tool{
+		Name:        name,
+		Description: "Calls a flaky dependency",
+		Annotations: &mcp.ToolAnnotations{IdempotentHint: true},
+	}
+}
+
+func TestAddToolWithRetry(t *testing.T) {
+	tests := []struct {
+		name         string
+		failures     int32
+		policy       RetryPolicy
+		wantAttempts int32
+		wantError    bool
+	}{
+		{
+			name:         "fails twice then succeeds",
+			failures:     2,
+			policy:       RetryPolicy{InitialInterval: time.Millisecond},
+			wantAttempts: 3,
+		},
+		{
+			name:         "attempts exhausted",
+			failures:     5,
+			policy:       RetryPolicy{MaxAttempts: 2, InitialInterval: time.Millisecond},
+			wantAttempts: 2,
+			wantError:    true,
+		},
+		{
+			name:     "error not retryable",
+			failures: 2,
+			policy: RetryPolicy{
+				InitialInterval: time.Millisecond,
+				Retryable:       func(err error) bool { return !errors.Is(err, errFlaky) },
+			},
+			wantAttempts: 1,
+			wantError:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newShutdownTestServer(t)
+			var attempts atomic.Int32
+			AddToolWithRetry(srv, idempotentTool("lookup"), flakyHandler(&attempts, tt.failures), tt.policy)
+
+			session := connectTestClient(t, srv)
+			res, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "lookup"})
+			if err != nil {
+				t.Fatalf("tool call failed: %v", err)
+			}
+
+			if got := attempts.Load(); got != tt.wantAttempts {
+				t.Errorf("handler ran %d times, want %d", got, tt.wantAttempts)
+			}
+			if res.IsError != tt.wantError {
+				t.Fatalf("IsError = %v, want %v (%+v)", res.IsError, tt.wantError, res.Content)
+			}
+			if !tt.wantError {
+				if text := res.Content[0].(*mcp.TextContent).Text; text != "done" {
+					t.Errorf("result text = %q, want done", text)
+				}
+			}
+		})
+	}
+}
+
+func TestAddToolWithRetry_NotIdempotent(t *testing.T) {
+	core, logs := observer.New(zapcore.WarnLevel)
+	srv, err := New(Config{Name: "test-server", Version: "1.0.0"}, zap.New(core))
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+
+	var attempts atomic.Int32
+	tool := &mcp.Tool{Name: "transfer", Description: "Moves money"}
+	AddToolWithRetry(srv, tool, flakyHandler(&attempts, 1), RetryPolicy{InitialInterval: time.Millisecond})
+
+	if logs.FilterMessage("tool is not declared idempotent, registering without retries").Len() != 1 {
+		t.Error("expected a warning that the tool is registered without retries")
+	}
+
+	session := connectTestClient(t, srv)
+	res, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "transfer"})
+	if err != nil {
+		t.Fatalf("tool call failed: %v", err)
+	}
+	if !res.IsError || attempts.Load() != 1 {
+		t.Errorf("expected a single failed attempt, got IsError=%v after %d attempts", res.IsError, attempts.Load())
+	}
+}
+
+func TestAddToolWithRetry_StopsWhenCanceled(t *testing.T) {
+	srv, err := New(Config{Name: "test-server", Version: "1.0.0"}, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+
+	var attempts atomic.Int32
+	finished := make(chan struct{})
+	AddToolWithRetry(srv, idempotentTool("lookup"), flakyHandler(&attempts, 10),
+		RetryPolicy{MaxAttempts: 10, InitialInterval: time.Hour, MaxInterval: time.Hour})
+
+	// Observe when the retrying handler returns
+	srv.MCP().AddReceivingMiddleware(func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			res, err := next(ctx, method, req)
+			if method == "tools/call" {
+				close(finished)
+			}
+			return res, err
+		}
+	})
+
+	session := connectTestClient(t, srv)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "lookup"}); err == nil {
+		t.Fatal("expected the canceled call to fail")
+	}
+
+	select {
+	case <-finished:
+	case <-time.After(5 * time.Second):
+		t.Fatal("handler kept waiting to retry after the call was canceled")
+	}
+	if got := attempts.Load(); got != 1 {
+		t.Errorf("handler ran %d times, want 1", got)
+	}
+}
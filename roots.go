@@ -0,0 +1,131 @@
+package hypermcp
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// Root is a directory the client allows the server to work in, as listed by
+// Server.Roots.
+type Root struct {
+	// URI identifies the root, usually as a file:// URI.
+	URI string
+
+	// Name is a human-readable name for the root, if the client gave one.
+	Name string
+
+	// Path is the local filesystem path of a file:// URI, cleaned and
+	// absolute, or empty for roots with other schemes.
+	Path string
+}
+
+// Roots lists the roots the client whose request is being handled has
+// approved, so a server working with local files, such as a file server, can
+// stay within the client's chosen directories instead of a hardcoded one.
+//
+// It must be called with the context of a handler, and returns ErrNoSession
+// otherwise. Clients that did not declare the roots capability fail with
+// ErrRootsNotSupported, so callers can fall back to their own configuration:
+//
+//	roots, err := srv.Roots(ctx)
+//	if errors.Is(err, hypermcp.ErrRootsNotSupported) {
+//	    roots = []hypermcp.Root{{Path: baseDir}}
+//	} else if err != nil {
+//	    return nil, err
+//	}
+//	path, err := hypermcp.PathInRoots(requested, roots)
+//
+// The roots are requested from the client on every call, so changes the
+// client makes are seen immediately.
+func (s *Server) Roots(ctx context.Context) ([]Root, error) {
+	session := sessionFromContext(ctx)
+	if session == nil {
+		return nil, ErrNoSession
+	}
+	if init := session.InitializeParams(); init == nil || init.Capabilities == nil || init.Capabilities.RootsV2 == nil {
+		return nil, ErrRootsNotSupported
+	}
+
+	res, err := session.ListRoots(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("list roots: %w", err)
+	}
+
+	roots := make([]Root, 0, len(res.Roots))
+	for _, r := range res.Roots {
+		if r == nil {
+			continue
+		}
+		root := Root{URI: r.URI, Name: r.Name}
+		if path, ok := fileURIPath(r.URI); ok {
+			root.Path = path
+		} else {
+			s.log().Debug("client root is not a local directory", zap.String("uri", r.URI))
+		}
+		roots = append(roots, root)
+	}
+	return roots, nil
+}
+
+// fileURIPath returns the cleaned local path of a file:// URI.
+func fileURIPath(uri string) (string, bool) {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" || (u.Host != "" && u.Host != "localhost") {
+		return "", false
+	}
+	path := filepath.FromSlash(u.Path)
+	if !filepath.IsAbs(path) {
+		return "", false
+	}
+	return filepath.Clean(path), true
+}
+
+// PathInRoots returns the cleaned absolute form of path if it is within one
+// of roots, and an error wrapping ErrPathOutsideRoots otherwise. A relative
+// path is resolved against the first root with a local path. Symbolic links
+// in existing paths are resolved first, so a link inside a root pointing
+// outside it is rejected.
+func PathInRoots(path string, roots []Root) (string, error) {
+	var dirs []string
+	for _, root := range roots {
+		if root.Path != "" {
+			dirs = append(dirs, resolveSymlinks(root.Path))
+		}
+	}
+	if len(dirs) == 0 {
+		return "", fmt.Errorf("%w: %s: no local roots", ErrPathOutsideRoots, path)
+	}
+
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dirs[0], path)
+	}
+	path = resolveSymlinks(filepath.Clean(path))
+
+	for _, dir := range dirs {
+		rel, err := filepath.Rel(dir, path)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s", ErrPathOutsideRoots, path)
+}
+
+// resolveSymlinks returns path with symbolic links evaluated. Of a path that
+// doesn't exist, the longest existing prefix is evaluated.
+func resolveSymlinks(path string) string {
+	rest := ""
+	for dir := path; ; dir = filepath.Dir(dir) {
+		if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+			return filepath.Join(resolved, rest)
+		}
+		if parent := filepath.Dir(dir); parent == dir {
+			return path
+		}
+		rest = filepath.Join(filepath.Base(dir), rest)
+	}
+}
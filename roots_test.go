@@ -0,0 +1,178 @@
+package hypermcp
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap/zaptest"
+)
+
+// fileURI returns the file:// URI of a local path.
+func fileURI(path string) string {
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+}
+
+// newRootsTestServer returns a server with a "check" tool reporting whether
+// its path argument is within the client's roots.
+func newRootsTestServer(t *testing.T) *Server {
+	t.Helper()
+
+	srv, err := New(Config{Name: "test-server", Version: "1.0.0"}, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	AddTool(srv, &mcp.Tool{Name: "check"}, func(ctx context.Context, req *mcp.CallToolRequest, input struct {
+		Path string `json:"path"`
+	}) (*mcp.CallToolResult, any, error) {
+		roots, err := srv.Roots(ctx)
+		if err != nil {
+			return ErrorResult(err.Error()), nil, nil
+		}
+		path, err := PathInRoots(input.Path, roots)
+		if err != nil {
+			return ErrorResult(err.Error()), nil, nil
+		}
+		return TextResult(path), nil, nil
+	})
+	return srv
+}
+
+func callCheck(t *testing.T, session *mcp.ClientSession, path string) (string, bool) {
+	t.Helper()
+
+	res, err := session.CallTool(context.Background(), &mcp.CallToolParams{
+		Name:      "check",
+		Arguments: map[string]any{"path": path},
+	})
+	if err != nil {
+		t.Fatalf("tool call failed: %v", err)
+	}
+	return res.Content[0].(*mcp.TextContent).Text, !res.IsError
+}
+
+func TestServer_Roots(t *testing.T) {
+	project := resolveSymlinks(t.TempDir())
+	docs := resolveSymlinks(t.TempDir())
+	outside := resolveSymlinks(t.TempDir())
+
+	srv := newRootsTestServer(t)
+	var roots []Root
+	AddTool(srv, &mcp.Tool{Name: "roots"}, func(ctx context.Context, req *mcp.CallToolRequest, input struct{}) (*mcp.CallToolResult, any, error) {
+		var err error
+		roots, err = srv.Roots(ctx)
+		return TextResult("ok"), nil, err
+	})
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+	client.AddRoots(
+		&mcp.Root{URI: fileURI(project), Name: "project"},
+		&mcp.Root{URI: fileURI(docs)},
+		&mcp.Root{URI: "https://example.com/repo"},
+	)
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := srv.MCP().Connect(context.Background(), serverTransport, nil)
+	if err != nil {
+		t.Fatalf("failed to connect server: %v", err)
+	}
+	t.Cleanup(func() { _ = serverSession.Close() })
+	session, err := client.Connect(context.Background(), clientTransport, nil)
+	if err != nil {
+		t.Fatalf("failed to connect client: %v", err)
+	}
+	t.Cleanup(func() { _ = session.Close() })
+
+	if _, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "roots"}); err != nil {
+		t.Fatalf("roots call failed: %v", err)
+	}
+	want := []Root{
+		{URI: fileURI(project), Name: "project", Path: project},
+		{URI: fileURI(docs), Path: docs},
+		{URI: "https://example.com/repo"},
+	}
+	if len(roots) != len(want) {
+		t.Fatalf("Roots() = %+v, want %+v", roots, want)
+	}
+	for i := range want {
+		if roots[i] != want[i] {
+			t.Errorf("Roots()[%d] = %+v, want %+v", i, roots[i], want[i])
+		}
+	}
+
+	tests := []struct {
+		name   string
+		path   string
+		want   string
+		wantOK bool
+	}{
+		{name: "file in first root", path: filepath.Join(project, "main.go"), want: filepath.Join(project, "main.go"), wantOK: true},
+		{name: "file in second root", path: filepath.Join(docs, "a", "b.md"), want: filepath.Join(docs, "a", "b.md"), wantOK: true},
+		{name: "relative path", path: "main.go", want: filepath.Join(project, "main.go"), wantOK: true},
+		{name: "outside every root", path: filepath.Join(outside, "secret"), wantOK: false},
+		{name: "traversal out of a root", path: filepath.Join(project, "..", filepath.Base(outside)), wantOK: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := callCheck(t, session, tt.path)
+			if ok != tt.wantOK {
+				t.Fatalf("check(%q) = %q, ok %v, want ok %v", tt.path, got, ok, tt.wantOK)
+			}
+			if ok && got != tt.want {
+				t.Errorf("check(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServer_Roots_NotSupported(t *testing.T) {
+	srv := newRootsTestServer(t)
+	session := connectTestClientWithOptions(t, srv, &mcp.ClientOptions{
+		Capabilities: &mcp.ClientCapabilities{},
+	})
+
+	got, ok := callCheck(t, session, "/tmp")
+	if ok || got != ErrRootsNotSupported.Error() {
+		t.Errorf("check() = %q, ok %v, want ErrRootsNotSupported", got, ok)
+	}
+}
+
+func TestServer_Roots_NoSession(t *testing.T) {
+	srv := newRootsTestServer(t)
+	if _, err := srv.Roots(context.Background()); !errors.Is(err, ErrNoSession) {
+		t.Errorf("Roots() error = %v, want ErrNoSession", err)
+	}
+}
+
+func TestPathInRoots_Symlinks(t *testing.T) {
+	root := resolveSymlinks(t.TempDir())
+	outside := resolveSymlinks(t.TempDir())
+	if err := os.Symlink(outside, filepath.Join(root, "escape")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+	roots := []Root{{Path: root}}
+
+	if _, err := PathInRoots(filepath.Join(root, "escape", "file"), roots); !errors.Is(err, ErrPathOutsideRoots) {
+		t.Errorf("expected a link out of the root to be rejected, got %v", err)
+	}
+
+	// A root reached through a link accepts paths spelled either way
+	link := filepath.Join(outside, "root-link")
+	if err := os.Symlink(root, link); err != nil {
+		t.Fatalf("failed to create link: %v", err)
+	}
+	got, err := PathInRoots(filepath.Join(root, "new.txt"), []Root{{Path: link}})
+	if err != nil || got != filepath.Join(root, "new.txt") {
+		t.Errorf("PathInRoots() = %q, %v, want %q", got, err, filepath.Join(root, "new.txt"))
+	}
+}
+
+func TestPathInRoots_NoLocalRoots(t *testing.T) {
+	_, err := PathInRoots("/tmp/x", []Root{{URI: "https://example.com"}})
+	if !errors.Is(err, ErrPathOutsideRoots) {
+		t.Errorf("expected ErrPathOutsideRoots, got %v", err)
+	}
+}
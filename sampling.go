@@ -0,0 +1,117 @@
+package hypermcp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// DefaultSamplingMaxTokens is the token limit RequestSampling asks for when
+// SamplingRequest.MaxTokens is zero.
+const DefaultSamplingMaxTokens = 1024
+
+// SamplingRequest asks the client's LLM for a completion. See RequestSampling.
+type SamplingRequest struct {
+	// Prompt, if set, is sent as a user message after Messages. Most requests
+	// need nothing else.
+	Prompt string
+
+	// Messages is the conversation to complete, for requests needing more
+	// than a single prompt.
+	Messages []*mcp.SamplingMessage
+
+	// SystemPrompt is the system prompt the server would like used. The
+	// client may modify or omit it.
+	SystemPrompt string
+
+	// MaxTokens caps the tokens sampled. Defaults to DefaultSamplingMaxTokens.
+	MaxTokens int64
+
+	// Temperature, StopSequences, and ModelPreferences are passed to the
+	// client, which may ignore them.
+	Temperature      float64
+	StopSequences    []string
+	ModelPreferences *mcp.ModelPreferences
+}
+
+// SamplingResult is the completion returned by the client's LLM.
+type SamplingResult struct {
+	// Content is the generated message.
+	Content mcp.Content
+
+	// Text is the text of Content, or empty if the model returned another
+	// type of content, such as an image.
+	Text string
+
+	// Model is the name of the model that generated the message.
+	Model string
+
+	// StopReason is why sampling stopped, such as "endTurn" or "maxTokens",
+	// if the client reported it.
+	StopReason string
+}
+
+// RequestSampling asks the LLM of the client whose request is being handled
+// to complete a message, sending a sampling/createMessage request through the
+// client's session. It lets a tool use the model for a step of its own work,
+// such as summarizing data it fetched:
+//
+//	result, err := hypermcp.RequestSampling(ctx, hypermcp.SamplingRequest{
+//	    Prompt:    "Summarize this log in one sentence:\n" + logText,
+//	    MaxTokens: 200,
+//	})
+//	if err != nil {
+//	    return nil, nil, err
+//	}
+//	return hypermcp.TextResult(result.Text), nil, nil
+//
+// It must be called with the context of a tool, resource, or prompt handler,
+// and returns ErrNoSession otherwise. Clients may decline to sample; a client
+// that did not declare the sampling capability fails with
+// ErrSamplingNotSupported without a request being sent. The client usually
+// asks its user to approve the request, so pass a context with a generous
+// deadline.
+func RequestSampling(ctx context.Context, req SamplingRequest) (SamplingResult, error) {
+	session := sessionFromContext(ctx)
+	if session == nil {
+		return SamplingResult{}, ErrNoSession
+	}
+	if init := session.InitializeParams(); init == nil || init.Capabilities == nil || init.Capabilities.Sampling == nil {
+		return SamplingResult{}, ErrSamplingNotSupported
+	}
+
+	messages := append([]*mcp.SamplingMessage(nil), req.Messages...)
+	if req.Prompt != "" {
+		messages = append(messages, &mcp.SamplingMessage{
+			Role:    "user",
+			Content: &mcp.TextContent{Text: req.Prompt},
+		})
+	}
+	maxTokens := req.MaxTokens
+	if maxTokens <= 0 {
+		maxTokens = DefaultSamplingMaxTokens
+	}
+
+	res, err := session.CreateMessage(ctx, &mcp.CreateMessageParams{
+		Messages:         messages,
+		SystemPrompt:     req.SystemPrompt,
+		MaxTokens:        maxTokens,
+		Temperature:      req.Temperature,
+		StopSequences:    req.StopSequences,
+		ModelPreferences: req.ModelPreferences,
+	})
+	if err != nil {
+		return SamplingResult{}, fmt.Errorf("sampling request: %w", err)
+	}
+
+	result := SamplingResult{
+		Content:    res.Content,
+		Model:      res.Model,
+		StopReason: res.StopReason,
+	}
+	if text, ok := res.Content.(*mcp.TextContent); ok {
+		result.Text = text.Text
+	}
+	return result, nil
+}
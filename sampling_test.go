@@ -0,0 +1,123 @@
+package hypermcp
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap/zaptest"
+)
+
+// newSamplingTestServer returns a server with a "summarize" tool that asks
+// the client's LLM to summarize its input, reporting the completion or the
+// error as text.
+func newSamplingTestServer(t *testing.T) *Server {
+	t.Helper()
+
+	srv, err := New(Config{Name: "test-server", Version: "1.0.0"}, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	AddTool(srv, &mcp.Tool{Name: "summarize"}, func(ctx context.Context, req *mcp.CallToolRequest, input struct {
+		Text string `json:"text"`
+	}) (*mcp.CallToolResult, any, error) {
+		result, err := RequestSampling(ctx, SamplingRequest{
+			SystemPrompt: "Be brief.",
+			Prompt:       "Summarize: " + input.Text,
+		})
+		if errors.Is(err, ErrSamplingNotSupported) {
+			return ErrorResult("sampling not supported"), nil, nil
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		return TextResult(result.Model + ": " + result.Text + " (" + result.StopReason + ")"), nil, nil
+	})
+	return srv
+}
+
+func TestRequestSampling(t *testing.T) {
+	srv := newSamplingTestServer(t)
+
+	var got *mcp.CreateMessageParams
+	session := connectTestClientWithOptions(t, srv, &mcp.ClientOptions{
+		CreateMessageHandler: func(ctx context.Context, req *mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+			got = req.Params
+			prompt := req.Params.Messages[0].Content.(*mcp.TextContent).Text
+			return &mcp.CreateMessageResult{
+				Content:    &mcp.TextContent{Text: strings.ToUpper(prompt)},
+				Model:      "mock-model",
+				Role:       "assistant",
+				StopReason: "endTurn",
+			}, nil
+		},
+	})
+
+	res, err := session.CallTool(context.Background(), &mcp.CallToolParams{
+		Name:      "summarize",
+		Arguments: map[string]any{"text": "a long log"},
+	})
+	if err != nil {
+		t.Fatalf("tool call failed: %v", err)
+	}
+	if res.IsError {
+		t.Fatalf("tool returned error: %v", res.Content[0].(*mcp.TextContent).Text)
+	}
+	if text := res.Content[0].(*mcp.TextContent).Text; text != "mock-model: SUMMARIZE: A LONG LOG (endTurn)" {
+		t.Errorf("tool returned %q", text)
+	}
+
+	if got == nil {
+		t.Fatal("client did not receive a sampling request")
+	}
+	if got.SystemPrompt != "Be brief." {
+		t.Errorf("SystemPrompt = %q, want %q", got.SystemPrompt, "Be brief.")
+	}
+	if got.MaxTokens != DefaultSamplingMaxTokens {
+		t.Errorf("MaxTokens = %d, want default %d", got.MaxTokens, DefaultSamplingMaxTokens)
+	}
+	if len(got.Messages) != 1 || got.Messages[0].Role != "user" {
+		t.Errorf("Messages = %+v, want one user message", got.Messages)
+	}
+}
+
+func TestRequestSampling_ClientError(t *testing.T) {
+	srv := newSamplingTestServer(t)
+	session := connectTestClientWithOptions(t, srv, &mcp.ClientOptions{
+		CreateMessageHandler: func(ctx context.Context, req *mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+			return nil, errors.New("user declined")
+		},
+	})
+
+	res, err := session.CallTool(context.Background(), &mcp.CallToolParams{
+		Name:      "summarize",
+		Arguments: map[string]any{"text": "x"},
+	})
+	if err == nil && !res.IsError {
+		t.Fatal("expected the declined sampling request to fail the tool call")
+	}
+}
+
+func TestRequestSampling_NotSupported(t *testing.T) {
+	srv := newSamplingTestServer(t)
+	session := connectTestClient(t, srv)
+
+	res, err := session.CallTool(context.Background(), &mcp.CallToolParams{
+		Name:      "summarize",
+		Arguments: map[string]any{"text": "x"},
+	})
+	if err != nil {
+		t.Fatalf("tool call failed: %v", err)
+	}
+	if !res.IsError || res.Content[0].(*mcp.TextContent).Text != "sampling not supported" {
+		t.Errorf("expected ErrSamplingNotSupported, got %+v", res.Content)
+	}
+}
+
+func TestRequestSampling_NoSession(t *testing.T) {
+	if _, err := RequestSampling(context.Background(), SamplingRequest{Prompt: "hi"}); !errors.Is(err, ErrNoSession) {
+		t.Errorf("RequestSampling() error = %v, want ErrNoSession", err)
+	}
+}
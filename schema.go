@@ -0,0 +1,257 @@
+package hypermcp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// SchemaFor generates a JSON schema for T as a map, suitable for mcp.Tool.InputSchema
+// or OutputSchema.
+//
+// It uses the same inference as AddTool does when a tool's InputSchema is nil, so
+// most tools don't need to call it: define the input struct and leave InputSchema
+// unset. SchemaFor is useful when the schema is needed explicitly, for example to
+// tweak it before registration or to publish it elsewhere.
+//
+// Schema inference follows the MCP SDK's rules:
+//   - Property names come from the `json` struct tag.
+//   - Fields tagged `json:",omitempty"` (or omitzero) are optional; all others are required.
+//   - A `jsonschema:"..."` tag sets the property description. The description must
+//     not start with "WORD=", which is reserved for future use.
+//
+// AddTool additionally honors a `default:"..."` tag on the optional top-level
+// fields of a tool's input; see AddToolErr. SchemaFor ignores it.
+//
+// Example:
+//
+//	type Input struct {
+//	    City  string `json:"city" jsonschema:"City name"`
+//	    Units string `json:"units,omitempty" jsonschema:"metric or imperial"`
+//	}
+//	schema := hypermcp.SchemaFor[Input]()
+//
+// SchemaFor panics if T cannot be represented as a JSON schema (for example, a
+// struct containing a channel or func field), mirroring AddTool.
+func SchemaFor[T any]() map[string]any {
+	schema, err := jsonschema.For[T](nil)
+	if err != nil {
+		panic(fmt.Sprintf("SchemaFor: %v", err))
+	}
+
+	data, err := json.Marshal(schema)
+	if err != nil {
+		panic(fmt.Sprintf("SchemaFor: marshal schema: %v", err))
+	}
+
+	var m map[string]any
+	if err := json.Unmarshal(data, &m); err != nil {
+		panic(fmt.Sprintf("SchemaFor: unmarshal schema: %v", err))
+	}
+	return m
+}
+
+// checkToolComplete returns an error wrapping ErrIncompleteTool if tool has
+// no description, or neither an explicit input schema nor a typed input In to
+// infer one from.
+func checkToolComplete[In any](tool *mcp.Tool) error {
+	if strings.TrimSpace(tool.Description) == "" {
+		return fmt.Errorf("%w: tool %q: missing description", ErrIncompleteTool, tool.Name)
+	}
+	if tool.InputSchema == nil && reflect.TypeFor[In]() == reflect.TypeFor[any]() {
+		return fmt.Errorf("%w: tool %q: missing input schema", ErrIncompleteTool, tool.Name)
+	}
+	return nil
+}
+
+// inputSchemaWithDefaults infers the input schema for In like describeTool,
+// with each `default:"..."` struct tag set as its property's default. It
+// returns nil if In has no default tags.
+//
+// The tag holds the default as JSON, except for string fields, where it is
+// the string itself. Only optional top-level fields, including those of
+// embedded structs, may carry a default: the SDK applies defaults to omitted
+// arguments of the top-level object only, and never to required ones.
+func inputSchemaWithDefaults[In any]() (*jsonschema.Schema, error) {
+	rt := reflect.TypeFor[In]()
+	if rt.Kind() == reflect.Pointer {
+		rt = rt.Elem()
+	}
+	if rt.Kind() != reflect.Struct || !hasDefaultTags(rt) {
+		return nil, nil
+	}
+
+	schema, err := jsonschema.ForType(rt, &jsonschema.ForOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if err := applyDefaultTags(rt, schema); err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+// hasDefaultTags reports whether struct type rt, or a struct embedded in it,
+// has a field with a default tag.
+func hasDefaultTags(rt reflect.Type) bool {
+	for _, field := range reflect.VisibleFields(rt) {
+		if _, ok := field.Tag.Lookup("default"); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// applyDefaultTags sets the defaults given by the default tags of struct type
+// rt on the corresponding properties of schema.
+func applyDefaultTags(rt reflect.Type, schema *jsonschema.Schema) error {
+	for _, field := range reflect.VisibleFields(rt) {
+		tag, ok := field.Tag.Lookup("default")
+		if !ok {
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" {
+			name = field.Name
+		}
+		prop := schema.Properties[name]
+		if prop == nil || !field.IsExported() {
+			return fmt.Errorf("field %s: default tag on a field that is not an input property", field.Name)
+		}
+		if slices.Contains(schema.Required, name) {
+			return fmt.Errorf("field %s: default tag on required property %q; mark it omitempty", field.Name, name)
+		}
+
+		value := json.RawMessage(tag)
+		if ft := field.Type; ft.Kind() == reflect.String ||
+			(ft.Kind() == reflect.Pointer && ft.Elem().Kind() == reflect.String) {
+			data, err := json.Marshal(tag)
+			if err != nil {
+				return err
+			}
+			value = data
+		} else if !json.Valid(value) {
+			return fmt.Errorf("field %s: default %q is not valid JSON", field.Name, tag)
+		}
+		prop.Default = value
+	}
+	return nil
+}
+
+// validateToolSchemas checks tool's input and output schemas the way clients
+// will use them: each must be a JSON schema of type "object" that resolves,
+// with valid patterns, references, defaults, and type names. The input schema
+// is required; AddTool leaves it nil only when it cannot be inferred.
+func validateToolSchemas(tool *mcp.Tool) error {
+	if tool.InputSchema == nil {
+		return fmt.Errorf("%w: tool %q: input schema cannot be inferred", ErrInvalidSchema, tool.Name)
+	}
+	if err := validateSchema(tool.InputSchema); err != nil {
+		return fmt.Errorf("%w: tool %q: input schema: %w", ErrInvalidSchema, tool.Name, err)
+	}
+	if tool.OutputSchema != nil {
+		if err := validateSchema(tool.OutputSchema); err != nil {
+			return fmt.Errorf("%w: tool %q: output schema: %w", ErrInvalidSchema, tool.Name, err)
+		}
+	}
+	return nil
+}
+
+// validateSchema validates a tool schema given in any of the forms mcp.Tool
+// accepts: a *jsonschema.Schema, a map, or raw JSON.
+func validateSchema(v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	var m map[string]any
+	if err := json.Unmarshal(data, &m); err != nil {
+		return errors.New("schema is not a JSON object")
+	}
+	if m["type"] != "object" {
+		return fmt.Errorf(`type must be "object", got %v`, m["type"])
+	}
+	if err := checkTypeNames(m); err != nil {
+		return err
+	}
+
+	var schema jsonschema.Schema
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return err
+	}
+	_, err = schema.Resolve(&jsonschema.ResolveOptions{ValidateDefaults: true})
+	return err
+}
+
+// resolveSchema resolves a tool schema given in any of the forms mcp.Tool
+// accepts, for validating instances against it.
+func resolveSchema(v any) (*jsonschema.Resolved, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var schema jsonschema.Schema
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, err
+	}
+	return schema.Resolve(&jsonschema.ResolveOptions{ValidateDefaults: true})
+}
+
+// schemaTypes are the type names JSON schema defines.
+var schemaTypes = []string{"null", "boolean", "object", "array", "number", "string", "integer"}
+
+// checkTypeNames reports a "type" keyword naming an unknown type anywhere in
+// the schema v, which jsonschema's Resolve accepts. Keywords holding instance
+// data rather than subschemas are not searched.
+func checkTypeNames(v any) error {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			switch key {
+			case "type":
+				if err := checkTypeName(value); err != nil {
+					return err
+				}
+			case "const", "default", "enum", "examples":
+			default:
+				if err := checkTypeNames(value); err != nil {
+					return err
+				}
+			}
+		}
+	case []any:
+		for _, value := range v {
+			if err := checkTypeNames(value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkTypeName validates the value of a "type" keyword, a type name or a
+// list of them. A "type" that is an object is a property named "type".
+func checkTypeName(v any) error {
+	switch v := v.(type) {
+	case string:
+		if !slices.Contains(schemaTypes, v) {
+			return fmt.Errorf("unknown type %q", v)
+		}
+	case []any:
+		for _, name := range v {
+			if err := checkTypeName(name); err != nil {
+				return err
+			}
+		}
+	case map[string]any:
+		return checkTypeNames(v)
+	}
+	return nil
+}
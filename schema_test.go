@@ -0,0 +1,305 @@
+package hypermcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap/zaptest"
+)
+
+type schemaTestInput struct {
+	City  string `json:"city" jsonschema:"City name"`
+	Units string `json:"units,omitempty" jsonschema:"metric or imperial"`
+	Days  int    `json:"days,omitempty"`
+}
+
+func TestSchemaFor(t *testing.T) {
+	got := SchemaFor[schemaTestInput]()
+
+	want := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"city": map[string]any{
+				"type":        "string",
+				"description": "City name",
+			},
+			"units": map[string]any{
+				"type":        "string",
+				"description": "metric or imperial",
+			},
+			"days": map[string]any{
+				"type": "integer",
+			},
+		},
+		"required":             []any{"city"},
+		"additionalProperties": false,
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected schema:\ngot:  %#v\nwant: %#v", got, want)
+	}
+}
+
+func TestSchemaFor_InvalidType(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected SchemaFor to panic for an unsupported type")
+		}
+	}()
+
+	SchemaFor[struct {
+		Callback func() `json:"callback"`
+	}]()
+}
+
+func TestAddTool_InfersInputSchema(t *testing.T) {
+	srv, err := New(Config{
+		Name:    "test-server",
+		Version: "1.0.0",
+	}, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+
+	AddTool(srv, &mcp.Tool{
+		Name:        "forecast",
+		Description: "Gets a forecast",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input schemaTestInput) (*mcp.CallToolResult, any, error) {
+		return &mcp.CallToolResult{}, nil, nil
+	})
+
+	session := connectTestClient(t, srv)
+	res, err := session.ListTools(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("failed to list tools: %v", err)
+	}
+	if len(res.Tools) != 1 {
+		t.Fatalf("expected 1 tool, got %d", len(res.Tools))
+	}
+
+	// The schema advertised to clients must match SchemaFor
+	var advertised map[string]any
+	if err := remarshalForTest(res.Tools[0].InputSchema, &advertised); err != nil {
+		t.Fatalf("failed to decode advertised schema: %v", err)
+	}
+	if want := SchemaFor[schemaTestInput](); !reflect.DeepEqual(advertised, want) {
+		t.Errorf("advertised schema differs from SchemaFor:\ngot:  %#v\nwant: %#v", advertised, want)
+	}
+}
+
+func TestAddToolErr_InvalidSchema(t *testing.T) {
+	handler := func(ctx context.Context, req *mcp.CallToolRequest, input map[string]any) (*mcp.CallToolResult, any, error) {
+		return &mcp.CallToolResult{}, nil, nil
+	}
+
+	tests := []struct {
+		name    string
+		tool    *mcp.Tool
+		wantErr string // substring of the error; empty means valid
+	}{
+		{
+			name: "inferred schema",
+			tool: &mcp.Tool{Name: "inferred"},
+		},
+		{
+			name: "valid map schema",
+			tool: &mcp.Tool{Name: "map", InputSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"type": map[string]any{"type": "string", "enum": []any{map[string]any{"type": "anything"}}},
+				},
+			}},
+		},
+		{
+			name: "valid raw schema",
+			tool: &mcp.Tool{Name: "raw", InputSchema: json.RawMessage(`{"type":"object","properties":{"n":{"type":["integer","null"]}}}`)},
+		},
+		{
+			name:    "misspelled property type",
+			tool:    &mcp.Tool{Name: "typo", InputSchema: json.RawMessage(`{"type":"object","properties":{"city":{"type":"strng"}}}`)},
+			wantErr: `input schema: unknown type "strng"`,
+		},
+		{
+			name:    "not an object schema",
+			tool:    &mcp.Tool{Name: "string", InputSchema: map[string]any{"type": "string"}},
+			wantErr: `type must be "object"`,
+		},
+		{
+			name:    "invalid pattern",
+			tool:    &mcp.Tool{Name: "pattern", InputSchema: json.RawMessage(`{"type":"object","properties":{"id":{"type":"string","pattern":"("}}}`)},
+			wantErr: "error parsing regexp",
+		},
+		{
+			name:    "dangling reference",
+			tool:    &mcp.Tool{Name: "ref", InputSchema: json.RawMessage(`{"type":"object","properties":{"id":{"$ref":"#/$defs/id"}}}`)},
+			wantErr: "no key",
+		},
+		{
+			name:    "malformed keyword",
+			tool:    &mcp.Tool{Name: "required", InputSchema: json.RawMessage(`{"type":"object","required":"city"}`)},
+			wantErr: "input schema",
+		},
+		{
+			name: "invalid output schema",
+			tool: &mcp.Tool{
+				Name:         "output",
+				InputSchema:  &jsonschema.Schema{Type: "object"},
+				OutputSchema: &jsonschema.Schema{Type: "object", Properties: map[string]*jsonschema.Schema{"n": {Type: "integer", Default: json.RawMessage(`"x"`)}}},
+			},
+			wantErr: "output schema",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, err := New(Config{Name: "test-server", Version: "1.0.0"}, zaptest.NewLogger(t))
+			if err != nil {
+				t.Fatalf("failed to create server: %v", err)
+			}
+
+			err = AddToolErr(srv, tt.tool, handler)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("AddToolErr() error = %v, want nil", err)
+				}
+				if srv.toolCount.Load() != 1 {
+					t.Errorf("toolCount = %d, want 1", srv.toolCount.Load())
+				}
+				return
+			}
+
+			if !errors.Is(err, ErrInvalidSchema) {
+				t.Fatalf("AddToolErr() error = %v, want ErrInvalidSchema", err)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) || !strings.Contains(err.Error(), tt.tool.Name) {
+				t.Errorf("AddToolErr() error = %q, want it to name the tool and contain %q", err, tt.wantErr)
+			}
+			if srv.toolCount.Load() != 0 || len(srv.Describe().Tools) != 0 {
+				t.Error("expected the tool not to be registered")
+			}
+		})
+	}
+}
+
+type defaultsTestInput struct {
+	City  string  `json:"city"`
+	Units string  `json:"units,omitempty" default:"metric"`
+	Days  int     `json:"days,omitempty" default:"3"`
+	Alert *bool   `json:"alert,omitempty" default:"true"`
+	Scale float64 `json:"scale,omitempty"`
+}
+
+func TestAddTool_DefaultTags(t *testing.T) {
+	srv, err := New(Config{Name: "test-server", Version: "1.0.0"}, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+
+	inputs := make(chan defaultsTestInput, 1)
+	AddTool(srv, &mcp.Tool{
+		Name:        "forecast",
+		Description: "Gets a forecast",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input defaultsTestInput) (*mcp.CallToolResult, any, error) {
+		inputs <- input
+		return &mcp.CallToolResult{}, nil, nil
+	})
+
+	session := connectTestClient(t, srv)
+	call := func(args map[string]any) defaultsTestInput {
+		t.Helper()
+		if _, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "forecast", Arguments: args}); err != nil {
+			t.Fatalf("CallTool() error = %v", err)
+		}
+		return <-inputs
+	}
+
+	got := call(map[string]any{"city": "Oslo"})
+	if got.Units != "metric" || got.Days != 3 || got.Alert == nil || !*got.Alert || got.Scale != 0 {
+		t.Errorf("omitted fields = %+v, want tag defaults", got)
+	}
+
+	got = call(map[string]any{"city": "Oslo", "units": "imperial", "days": 7, "alert": false})
+	if got.Units != "imperial" || got.Days != 7 || got.Alert == nil || *got.Alert {
+		t.Errorf("given fields = %+v, want the arguments", got)
+	}
+
+	// The defaults are published in the advertised schema
+	res, err := session.ListTools(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("failed to list tools: %v", err)
+	}
+	var schema struct {
+		Properties map[string]struct {
+			Default any `json:"default"`
+		} `json:"properties"`
+	}
+	if err := remarshalForTest(res.Tools[0].InputSchema, &schema); err != nil {
+		t.Fatalf("failed to decode advertised schema: %v", err)
+	}
+	if d := schema.Properties["units"].Default; d != "metric" {
+		t.Errorf("advertised units default = %v, want metric", d)
+	}
+}
+
+func TestAddToolErr_InvalidDefaultTags(t *testing.T) {
+	tests := []struct {
+		name     string
+		register func(srv *Server) error
+		wantErr  string
+	}{
+		{
+			name: "required field",
+			register: func(srv *Server) error {
+				type input struct {
+					City string `json:"city" default:"Oslo"`
+				}
+				return AddToolErr(srv, &mcp.Tool{Name: "required"}, noopTool[input])
+			},
+			wantErr: "required property",
+		},
+		{
+			name: "invalid JSON",
+			register: func(srv *Server) error {
+				type input struct {
+					Days int `json:"days,omitempty" default:"three"`
+				}
+				return AddToolErr(srv, &mcp.Tool{Name: "json"}, noopTool[input])
+			},
+			wantErr: "not valid JSON",
+		},
+		{
+			name: "mismatched type",
+			register: func(srv *Server) error {
+				type input struct {
+					Days int `json:"days,omitempty" default:"true"`
+				}
+				return AddToolErr(srv, &mcp.Tool{Name: "type"}, noopTool[input])
+			},
+			wantErr: "input schema",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, err := New(Config{Name: "test-server", Version: "1.0.0"}, zaptest.NewLogger(t))
+			if err != nil {
+				t.Fatalf("failed to create server: %v", err)
+			}
+
+			err = tt.register(srv)
+			if !errors.Is(err, ErrInvalidSchema) || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("AddToolErr() error = %v, want ErrInvalidSchema containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func noopTool[In any](ctx context.Context, req *mcp.CallToolRequest, input In) (*mcp.CallToolResult, any, error) {
+	return &mcp.CallToolResult{}, nil, nil
+}
@@ -0,0 +1,137 @@
+package hypermcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"runtime/debug"
+	"sync/atomic"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// sdkModulePath is the module path of the MCP SDK hypermcp wraps.
+const sdkModulePath = "github.com/modelcontextprotocol/go-sdk"
+
+// sdkProbeTimeout bounds how long the SDK compatibility probe may take.
+const sdkProbeTimeout = 5 * time.Second
+
+// sdkProbeInput and sdkProbeOutput exercise the SDK's schema inference and
+// structured output handling.
+type sdkProbeInput struct {
+	Value string `json:"value"`
+}
+
+type sdkProbeOutput struct {
+	Echo string `json:"echo"`
+}
+
+// checkSDK verifies that the MCP SDK in the build behaves the way hypermcp
+// relies on, by running a tool call through a throwaway server over an
+// in-memory connection.
+//
+// It checks that AddTool infers input schemas from Go types, that receiving
+// middleware sees tool calls, and that structured tool output reaches the
+// client. Failures wrap ErrIncompatibleSDK.
+func checkSDK() error {
+	ctx, cancel := context.WithTimeout(context.Background(), sdkProbeTimeout)
+	defer cancel()
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "hypermcp-sdk-probe", Version: "0.0.0"}, nil)
+
+	var sawCall atomic.Bool
+	server.AddReceivingMiddleware(func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			if method == "tools/call" {
+				sawCall.Store(true)
+			}
+			return next(ctx, method, req)
+		}
+	})
+
+	tool := &mcp.Tool{Name: "probe", Description: "SDK compatibility probe"}
+	mcp.AddTool(server, tool, func(_ context.Context, _ *mcp.CallToolRequest, in sdkProbeInput) (*mcp.CallToolResult, sdkProbeOutput, error) {
+		return nil, sdkProbeOutput{Echo: in.Value}, nil
+	})
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		return sdkError("connect server: %v", err)
+	}
+	defer func() { _ = serverSession.Close() }()
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "hypermcp-sdk-probe-client", Version: "0.0.0"}, nil)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		return sdkError("connect client: %v", err)
+	}
+	defer func() { _ = session.Close() }()
+
+	tools, err := session.ListTools(ctx, nil)
+	if err != nil {
+		return sdkError("list tools: %v", err)
+	}
+	if len(tools.Tools) != 1 || !schemaHasProperty(tools.Tools[0].InputSchema, "value") {
+		return sdkError("AddTool did not infer the input schema")
+	}
+
+	result, err := session.CallTool(ctx, &mcp.CallToolParams{
+		Name:      "probe",
+		Arguments: map[string]any{"value": "ping"},
+	})
+	if err != nil {
+		return sdkError("call tool: %v", err)
+	}
+	if !sawCall.Load() {
+		return sdkError("receiving middleware did not see the tool call")
+	}
+
+	var out sdkProbeOutput
+	if err := remarshal(result.StructuredContent, &out); err != nil || out.Echo != "ping" {
+		return sdkError("structured tool output was not returned")
+	}
+
+	return nil
+}
+
+// schemaHasProperty reports whether a JSON schema, as received by a client,
+// declares the named property.
+func schemaHasProperty(schema any, name string) bool {
+	var s struct {
+		Properties map[string]any `json:"properties"`
+	}
+	if err := remarshal(schema, &s); err != nil {
+		return false
+	}
+	_, ok := s.Properties[name]
+	return ok
+}
+
+// remarshal round-trips v through JSON into out.
+func remarshal(v, out any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+// sdkError wraps a probe failure in ErrIncompatibleSDK, naming the SDK version
+// in the build when it is known.
+func sdkError(format string, args ...any) error {
+	version := "unknown version"
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, dep := range info.Deps {
+			if dep.Path == sdkModulePath {
+				version = dep.Version
+				if dep.Replace != nil {
+					version += " => " + dep.Replace.Path + " " + dep.Replace.Version
+				}
+				break
+			}
+		}
+	}
+	return fmt.Errorf("%w (%s %s): %s", ErrIncompatibleSDK, sdkModulePath, version, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,74 @@
+package hypermcp
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap/zaptest"
+)
+
+func TestCheckSDK_PinnedVersion(t *testing.T) {
+	if err := checkSDK(); err != nil {
+		t.Fatalf("expected the pinned SDK to pass the probe, got %v", err)
+	}
+}
+
+func TestNew_CheckSDK(t *testing.T) {
+	srv, err := New(Config{
+		Name:     "test-server",
+		Version:  "1.0.0",
+		CheckSDK: true,
+	}, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("expected New to succeed with the pinned SDK, got %v", err)
+	}
+	if srv == nil {
+		t.Fatal("expected a server")
+	}
+}
+
+func TestSDKError(t *testing.T) {
+	err := sdkError("list tools: %v", errors.New("boom"))
+
+	if !errors.Is(err, ErrIncompatibleSDK) {
+		t.Errorf("expected ErrIncompatibleSDK, got %v", err)
+	}
+	for _, want := range []string{sdkModulePath, "list tools: boom"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected error to mention %q, got %q", want, err.Error())
+		}
+	}
+}
+
+func TestSchemaHasProperty(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema any
+		want   bool
+	}{
+		{
+			name:   "declared property",
+			schema: map[string]any{"type": "object", "properties": map[string]any{"value": map[string]any{"type": "string"}}},
+			want:   true,
+		},
+		{
+			name:   "missing property",
+			schema: map[string]any{"type": "object", "properties": map[string]any{}},
+			want:   false,
+		},
+		{
+			name:   "nil schema",
+			schema: nil,
+			want:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := schemaHasProperty(tt.schema, "value"); got != tt.want {
+				t.Errorf("schemaHasProperty() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -22,7 +22,13 @@ package hypermcp
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/rayprogramming/hypermcp/cache"
@@ -39,13 +45,47 @@ type Server struct {
 	mcp        *mcp.Server
 	httpClient *httpx.Client
 	cache      *cache.Cache
-	logger     *zap.Logger
+	logger     *atomic.Pointer[zap.Logger] // shared with subscriptions and idle sessions
 	metrics    *Metrics
+	registry   *registry
 	config     Config
 
-	// Stats for logging
-	toolCount     int
-	resourceCount int
+	// cacheFallback records that the cache could not be created and the
+	// server fell back to running without caching (see Config.CacheRequired)
+	cacheFallback bool
+
+	subscriptions *subscriptions
+	degraded      *degradedMode
+	disabled      *disabledTools
+	resources     *resourceCache
+
+	// stop cancels the context background goroutines run under
+	stop context.CancelFunc
+
+	// metricsLogDone is closed when periodic metrics logging stops; nil
+	// unless Config.MetricsLogInterval is set
+	metricsLogDone chan struct{}
+
+	// metricsSinkDone is closed when periodic pushes to Config.MetricsSink
+	// stop; nil unless Config.MetricsSinkInterval is set
+	metricsSinkDone    chan struct{}
+	metricsSinkFlushed atomic.Bool
+
+	shutdownMu    sync.Mutex
+	shutdownHooks []shutdownHook
+
+	// reloadMu serializes ReloadTools
+	reloadMu sync.Mutex
+
+	// Set by Start; done is closed once the server has stopped, with the
+	// result in runErr
+	runMu   sync.Mutex
+	runDone chan struct{}
+	runErr  error
+
+	// Stats for logging; tools can be added and removed at runtime
+	toolCount     atomic.Int64
+	resourceCount atomic.Int64
 }
 
 // Config holds server configuration.
@@ -53,17 +93,173 @@ type Server struct {
 // Name and Version are required fields and will be validated.
 // CacheEnabled determines whether to initialize a full cache instance.
 // HTTPConfig allows customization of HTTP client behavior (optional, uses defaults if not set).
+// Transport configures network transports such as TransportSSE; network settings
+// are rejected for stdio.
+// BaseContext optionally seeds server-scoped values (a DB handle, settings) into
+// every handler invocation's context.
 type Config struct {
-	HTTPConfig   *httpx.Config // Optional: uses defaults if nil
+	HTTPConfig *httpx.Config // Optional: uses defaults if nil
+
+	// BaseContext, if set, returns the context whose values are visible to every
+	// tool, resource, and prompt handler, letting servers seed context.WithValue
+	// data once. Only its values are used: cancellation and deadlines still come
+	// from the run context and the individual request.
+	BaseContext func() context.Context
+
+	// CacheRequired controls what happens when CacheEnabled is set but the cache
+	// cannot be created from CacheConfig. If true (the default when nil), New fails.
+	// If false, New logs a warning and falls back to the minimal cache used when
+	// caching is disabled, so the server starts without caching.
+	CacheRequired *bool
+
+	// MaxConcurrentTools limits how many tool calls execute at once. Zero means
+	// unlimited. QueueSize bounds how many further calls may wait for a free
+	// worker; calls arriving when the queue is full fail with ErrServerBusy.
+	// QueueSize requires MaxConcurrentTools to be set.
+	MaxConcurrentTools int
+	QueueSize          int
+
+	// MaxConcurrentResourceReads limits how many resource reads execute at
+	// once, for resources and templates alike. Further reads wait for one to
+	// finish, or fail with the context's error if the request is canceled
+	// first. Zero means unlimited.
+	MaxConcurrentResourceReads int
+
+	// SlowToolThreshold, if positive, logs a warning for every tool invocation
+	// registered through AddTool that takes longer than this, and counts it in
+	// MetricsSnapshot.SlowToolInvocations. Zero disables the check.
+	SlowToolThreshold time.Duration
+
+	// TrackTemplateParams, if positive, records which parameter values of each
+	// resource template are read most, keeping at most this many distinct
+	// values per template. Reads per template are always counted. See
+	// MetricsSnapshot.ResourceTemplates.
+	TrackTemplateParams int
+
+	// MetricsLogInterval, if positive, logs a MetricsSnapshot at info level
+	// this often, from New until Shutdown. Zero disables periodic logging.
+	MetricsLogInterval time.Duration
+
+	// MetricsSink, if set, receives the final MetricsSnapshot during
+	// Shutdown, after the OnShutdown hooks have run, so the last counters are
+	// recorded durably in a monitoring system (statsd, a file, an HTTP
+	// endpoint) rather than only logged. Its error is returned by Shutdown as
+	// a ShutdownError. It is called once, however often Shutdown is.
+	MetricsSink func(MetricsSnapshot) error
+
+	// MetricsSinkInterval, if positive, also passes a snapshot to
+	// MetricsSink this often, from New until Shutdown. Failures are logged
+	// as warnings. Requires MetricsSink.
+	MetricsSinkInterval time.Duration
+
+	// RecentErrors, if positive, keeps the last this many errors, from failed
+	// tool calls and resource loaders, for Server.RecentErrors. Zero keeps
+	// none.
+	RecentErrors int
+
+	// Degraded configures degraded mode: while the tool error rate is too
+	// high, non-essential tools are rejected with ErrDegraded. Disabled
+	// unless Degraded.ErrorRate is set. See DegradedConfig.
+	Degraded DegradedConfig
+
+	// ClientLimits rate limits and caps the size of the requests of each
+	// client, identified by the name it reports. Disabled unless a limit is
+	// set. See ClientLimitsConfig.
+	ClientLimits ClientLimitsConfig
+
+	// Health tunes the checks reported by Server.Health. See HealthConfig.
+	Health HealthConfig
+
+	// Stdio tunes the stdio transport, such as bounding the messages queued
+	// for a slow client. See StdioConfig.
+	Stdio StdioConfig
+
+	// Clock, if set, supplies the time for metrics uptime and, unless
+	// CacheConfig.Clock is set, for cache TTLs. Defaults to the system clock;
+	// tests can inject a fake clock to advance time without sleeping.
+	Clock Clock
+
+	// ResourceCacheMaxBytes bounds the total size of the content
+	// AddLazyResource keeps cached. When caching another resource would
+	// exceed it, the largest cached resources are evicted first. Zero means
+	// CacheConfig.MaxCost. Requires CacheEnabled.
+	ResourceCacheMaxBytes int64
+
+	// CacheOptions tune the cache's Ristretto store beyond CacheConfig, such
+	// as a custom cost function. Requires CacheEnabled.
+	CacheOptions []cache.Option
+
+	Transport    TransportConfig
 	CacheConfig  cache.Config
 	Name         string
 	Version      string
 	CacheEnabled bool
+
+	// Commit and BuildDate optionally identify the build, typically set via
+	// ldflags. They are reported by Describe, which falls back to the VCS
+	// information embedded by the Go toolchain when they are empty.
+	Commit    string
+	BuildDate string
+
+	// CheckSDK makes New verify that the MCP SDK in the build supports the
+	// features hypermcp relies on, by running a tool call through a throwaway
+	// in-memory server. New then fails with ErrIncompatibleSDK instead of the
+	// server misbehaving at its first tool call. The probe takes a few
+	// milliseconds, so it is off by default.
+	CheckSDK bool
+
+	// StrictRegistration makes AddToolErr reject tools without a description
+	// or an input schema with ErrIncompleteTool, and AddTool skip them with a
+	// warning. A tool has an input schema if it sets InputSchema or its
+	// handler takes a typed input rather than any.
+	StrictRegistration bool
+
+	// ValidateToolOutput makes tools registered with AddTool check the
+	// CallToolResult.StructuredContent their handlers set directly against
+	// the tool's output schema. The SDK already validates output returned as
+	// the handler's typed Out value, but sends StructuredContent as is when
+	// that value is nil. Content that doesn't match is logged as a warning,
+	// and the call fails with a tool error wrapping ErrInvalidOutput instead
+	// of sending the client malformed data.
+	ValidateToolOutput bool
+
+	// MaxToolOutputBytes, if positive, limits the size of the results of
+	// tools registered with AddTool, measured as the JSON of the result and
+	// its structured output. An oversized result fails the call with a tool
+	// error wrapping ErrToolOutputTooLarge, unless TruncateToolOutput is set.
+	// Either way it is logged as a warning and counted in
+	// MetricsSnapshot.OversizedToolOutputs.
+	MaxToolOutputBytes int64
+
+	// TruncateToolOutput makes oversized results shorten their text content
+	// to fit MaxToolOutputBytes, ending it with a "[output truncated]" marker,
+	// instead of failing. Results that can't fit by shortening their text,
+	// such as those with large structured output, still fail. Requires
+	// MaxToolOutputBytes.
+	TruncateToolOutput bool
+
+	// LogWriter, if set, receives every log line hypermcp writes, as JSON at
+	// the levels the logger given to New (or SetLogger) enables, whatever
+	// destination that logger was built with. With the stdio transport, where
+	// stdout carries the protocol, it keeps a misconfigured logger from
+	// corrupting the stream; pass os.Stderr or a file. RunWithTransport
+	// redirects its logger the same way. The logger keeps its name and
+	// options, but fields attached to it with With before it was given to
+	// hypermcp are dropped. When nil, the logger is used as given, and a nil
+	// logger writes to stderr.
+	LogWriter io.Writer
+
+	// DisableStartupLog silences the "base server initialized" log line New
+	// emits at Info level.
+	DisableStartupLog bool
 }
 
 // Validate checks if the configuration is valid.
 //
-// Returns an error if Name or Version is empty.
+// Returns a ConfigError if Name or Version is empty, if a limit is negative, or if
+// fields are combined in a way that makes one of them meaningless, such as
+// CacheConfig being set while CacheEnabled is false. Such combinations usually
+// indicate a mistake, so they are rejected rather than silently ignored.
 func (c Config) Validate() error {
 	if c.Name == "" {
 		return NewConfigError("Name", fmt.Errorf("cannot be empty"))
@@ -71,22 +267,172 @@ func (c Config) Validate() error {
 	if c.Version == "" {
 		return NewConfigError("Version", fmt.Errorf("cannot be empty"))
 	}
+	if c.MaxConcurrentTools < 0 {
+		return NewConfigError("MaxConcurrentTools", fmt.Errorf("cannot be negative"))
+	}
+	if c.QueueSize < 0 {
+		return NewConfigError("QueueSize", fmt.Errorf("cannot be negative"))
+	}
+	if c.MaxConcurrentResourceReads < 0 {
+		return NewConfigError("MaxConcurrentResourceReads", fmt.Errorf("cannot be negative"))
+	}
+	if c.SlowToolThreshold < 0 {
+		return NewConfigError("SlowToolThreshold", fmt.Errorf("cannot be negative"))
+	}
+	if c.TrackTemplateParams < 0 {
+		return NewConfigError("TrackTemplateParams", fmt.Errorf("cannot be negative"))
+	}
+	if c.ResourceCacheMaxBytes < 0 {
+		return NewConfigError("ResourceCacheMaxBytes", fmt.Errorf("cannot be negative"))
+	}
+	if c.MetricsLogInterval < 0 {
+		return NewConfigError("MetricsLogInterval", fmt.Errorf("cannot be negative"))
+	}
+	if c.MetricsSinkInterval < 0 {
+		return NewConfigError("MetricsSinkInterval", fmt.Errorf("cannot be negative"))
+	}
+	if c.RecentErrors < 0 {
+		return NewConfigError("RecentErrors", fmt.Errorf("cannot be negative"))
+	}
+	if c.Transport.IdleTimeout < 0 {
+		return NewConfigError("Transport.IdleTimeout", fmt.Errorf("cannot be negative"))
+	}
+	if c.Degraded.ErrorRate < 0 || c.Degraded.ErrorRate > 1 {
+		return NewConfigError("Degraded.ErrorRate", fmt.Errorf("must be between 0 and 1"))
+	}
+	if c.Degraded.Window < 0 {
+		return NewConfigError("Degraded.Window", fmt.Errorf("cannot be negative"))
+	}
+	if c.Degraded.MinCalls < 0 {
+		return NewConfigError("Degraded.MinCalls", fmt.Errorf("cannot be negative"))
+	}
+	if c.Health.CacheEvictionRatio < 0 || c.Health.CacheEvictionRatio > 1 {
+		return NewConfigError("Health.CacheEvictionRatio", fmt.Errorf("must be between 0 and 1"))
+	}
+	if c.Health.CacheMinSets < 0 {
+		return NewConfigError("Health.CacheMinSets", fmt.Errorf("cannot be negative"))
+	}
+	if c.MaxToolOutputBytes < 0 {
+		return NewConfigError("MaxToolOutputBytes", fmt.Errorf("cannot be negative"))
+	}
+	if c.Stdio.WriteBufferSize < 0 {
+		return NewConfigError("Stdio.WriteBufferSize", fmt.Errorf("cannot be negative"))
+	}
+	if err := c.ClientLimits.validate(); err != nil {
+		return err
+	}
+	return c.validateCombinations()
+}
+
+// validateCombinations rejects fields that have no effect given the rest of the configuration.
+func (c Config) validateCombinations() error {
+	if !c.CacheEnabled {
+		if !reflect.ValueOf(c.CacheConfig).IsZero() {
+			return NewConfigError("CacheConfig", fmt.Errorf("is set but CacheEnabled is false"))
+		}
+		if len(c.CacheOptions) > 0 {
+			return NewConfigError("CacheOptions", fmt.Errorf("is set but CacheEnabled is false"))
+		}
+		if c.CacheRequired != nil {
+			return NewConfigError("CacheRequired", fmt.Errorf("is set but CacheEnabled is false"))
+		}
+		if c.ResourceCacheMaxBytes != 0 {
+			return NewConfigError("ResourceCacheMaxBytes", fmt.Errorf("is set but CacheEnabled is false"))
+		}
+	}
+	if c.QueueSize > 0 && c.MaxConcurrentTools == 0 {
+		return NewConfigError("QueueSize", fmt.Errorf("requires MaxConcurrentTools to be set"))
+	}
+	if !c.Degraded.enabled() && len(c.Degraded.NonEssentialTools) > 0 {
+		return NewConfigError("Degraded.NonEssentialTools", fmt.Errorf("is set but Degraded.ErrorRate is zero"))
+	}
+	if c.MetricsSinkInterval > 0 && c.MetricsSink == nil {
+		return NewConfigError("MetricsSinkInterval", fmt.Errorf("is set but MetricsSink is nil"))
+	}
+	if c.TruncateToolOutput && c.MaxToolOutputBytes == 0 {
+		return NewConfigError("TruncateToolOutput", fmt.Errorf("is set but MaxToolOutputBytes is zero"))
+	}
+	if c.Stdio.DropNotificationsWhenFull && c.Stdio.WriteBufferSize == 0 {
+		return NewConfigError("Stdio.DropNotificationsWhenFull", fmt.Errorf("is set but Stdio.WriteBufferSize is zero"))
+	}
 	return nil
 }
 
+// ValidateForTransport checks the configuration as Validate does, and additionally
+// that it suits the given transport. Network settings in Transport are rejected for
+// the stdio transport, since they would be silently ignored. The unix transport
+// requires Transport.SocketPath and does not support Transport.Auth.
+//
+// New runs Validate, so RunWithTransport only repeats the transport checks
+// before starting the transport.
+func (c Config) ValidateForTransport(transportType TransportType) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	return c.validateTransport(transportType)
+}
+
+// validateTransport runs the checks ValidateForTransport adds to Validate. The
+// transports call it on the config of a running server, which New validated
+// as given by the caller.
+func (c Config) validateTransport(transportType TransportType) error {
+	if transportType == TransportStdio && !reflect.ValueOf(c.Transport).IsZero() {
+		return NewConfigError("Transport", fmt.Errorf("network settings are not used by the %s transport", transportType))
+	}
+	if transportType == TransportUnix {
+		if c.Transport.SocketPath == "" {
+			return NewConfigError("Transport.SocketPath", fmt.Errorf("cannot be empty for the %s transport", transportType))
+		}
+		if c.Transport.Auth.enabled() {
+			return NewConfigError("Transport.Auth", fmt.Errorf("is not supported by the %s transport; access is limited by the socket's file permissions", transportType))
+		}
+	}
+	return nil
+}
+
+// cacheEnabled reports whether the server runs with the cache configured by
+// Config.CacheConfig: caching is enabled and the cache was created, rather
+// than replaced by the minimal fallback cache after a failure.
+func (s *Server) cacheEnabled() bool {
+	return s.config.CacheEnabled && !s.cacheFallback
+}
+
+// cacheRequired reports whether a cache creation failure should fail New.
+func (c Config) cacheRequired() bool {
+	return c.CacheRequired == nil || *c.CacheRequired
+}
+
 // New creates a new MCP server with common infrastructure.
 //
 // It initializes the HTTP client with retry logic, creates a cache instance
 // (if enabled), and sets up the underlying MCP server. The configuration is
 // validated before creating the server.
 //
-// Returns an error if the configuration is invalid or if cache creation fails.
+// If logger is nil, a production logger writing to stderr is used. When serving
+// over the stdio transport, stdout carries the JSON-RPC stream, so a logger that
+// writes to stdout will corrupt the protocol; see logging.go for details.
+//
+// Returns an error if the configuration is invalid or if cache creation fails
+// (unless Config.CacheRequired is false).
 func New(cfg Config, logger *zap.Logger) (*Server, error) {
 	// Validate configuration
 	if err := cfg.Validate(); err != nil {
 		return nil, fmt.Errorf("%w: %v", ErrInvalidConfig, err)
 	}
 
+	if cfg.CheckSDK {
+		if err := checkSDK(); err != nil {
+			return nil, err
+		}
+	}
+
+	// Never fall back to stdout: it is the protocol channel for stdio servers
+	if cfg.LogWriter != nil {
+		logger = redirectLogger(logger, cfg.LogWriter)
+	} else if logger == nil {
+		logger = newStderrLogger()
+	}
+
 	// Create shared HTTP client with optional custom config
 	var httpClient *httpx.Client
 	var err error
@@ -102,19 +448,38 @@ func New(cfg Config, logger *zap.Logger) (*Server, error) {
 		}
 	}
 
-	// Create cache
+	// Background goroutines (such as the cache's TTL cleanup) live until
+	// Shutdown cancels this context
+	lifecycle, stop := context.WithCancel(context.Background())
+
+	// Create cache. A failure tolerated by CacheRequired leaves cfg as given
+	// and is recorded in cacheFallback instead
 	var cacheInstance *cache.Cache
+	cacheFallback := false
 	if cfg.CacheEnabled {
-		cacheInstance, err = cache.New(cfg.CacheConfig, logger)
+		cacheConfig := cfg.CacheConfig
+		if cacheConfig.Clock == nil {
+			cacheConfig.Clock = cfg.Clock
+		}
+		cacheInstance, err = cache.NewWithContext(lifecycle, cacheConfig, logger, cfg.CacheOptions...)
 		if err != nil {
-			return nil, fmt.Errorf("create cache: %w", err)
+			if cfg.cacheRequired() {
+				stop()
+				return nil, fmt.Errorf("create cache: %w", err)
+			}
+			logger.Warn("cache creation failed, continuing without caching",
+				zap.Error(err),
+			)
+			cacheFallback = true
 		}
-	} else {
+	}
+	if !cfg.CacheEnabled || cacheFallback {
 		// Create a minimal no-op cache
-		cacheInstance, _ = cache.New(cache.Config{
+		cacheInstance, _ = cache.NewWithContext(lifecycle, cache.Config{
 			MaxCost:     1024,
 			NumCounters: 100,
 			BufferItems: 1,
+			Clock:       cfg.Clock,
 		}, logger)
 	}
 
@@ -123,23 +488,81 @@ func New(cfg Config, logger *zap.Logger) (*Server, error) {
 		Name:    cfg.Name,
 		Version: cfg.Version,
 	}
-	mcpServer := mcp.NewServer(impl, nil)
+	metrics := newMetricsWithClock(cfg.Clock)
+	metrics.toolOutcomes = newErrorWindow(cfg.Degraded.window(), metrics.clock)
+	metrics.recentErrors = newErrorRing(cfg.RecentErrors)
+	logRef := new(atomic.Pointer[zap.Logger])
+	logRef.Store(logger)
+	degraded := newDegradedMode(cfg.Degraded, metrics, logRef)
+	registry := newRegistry()
+	subs := newSubscriptions(logRef)
+	mcpServer := mcp.NewServer(impl, &mcp.ServerOptions{
+		SubscribeHandler:   subs.subscribe,
+		UnsubscribeHandler: subs.unsubscribe,
+	})
+	mcpServer.AddReceivingMiddleware(requestMetaMiddleware())
+	if cfg.MaxConcurrentTools > 0 {
+		mcpServer.AddReceivingMiddleware(newToolQueue(cfg.MaxConcurrentTools, cfg.QueueSize, metrics).middleware())
+	}
+	if cfg.MaxConcurrentResourceReads > 0 {
+		mcpServer.AddReceivingMiddleware(newResourceReadLimiter(cfg.MaxConcurrentResourceReads).middleware())
+	}
+	// Added after the queue so rejected calls never wait for a worker
+	mcpServer.AddReceivingMiddleware(degraded.middleware())
+	// Added after degraded mode so calls to disabled tools don't count as failures
+	disabled := &disabledTools{}
+	mcpServer.AddReceivingMiddleware(disabled.middleware())
+	if cfg.ClientLimits.enabled() {
+		mcpServer.AddReceivingMiddleware(newClientLimiter(cfg.ClientLimits, metrics, registry).middleware())
+	}
+	if cfg.BaseContext != nil {
+		mcpServer.AddReceivingMiddleware(baseContextMiddleware(cfg.BaseContext))
+	}
+	if cfg.Transport.IdleTimeout > 0 {
+		idle := newIdleSessions(cfg.Transport.IdleTimeout, logRef)
+		mcpServer.AddReceivingMiddleware(idle.middleware())
+		go idle.watch(lifecycle, mcpServer.Sessions)
+	}
 
 	// Create server instance
 	s := &Server{
-		mcp:        mcpServer,
-		httpClient: httpClient,
-		cache:      cacheInstance,
-		logger:     logger,
-		metrics:    newMetrics(),
-		config:     cfg,
+		mcp:           mcpServer,
+		httpClient:    httpClient,
+		cache:         cacheInstance,
+		logger:        logRef,
+		metrics:       metrics,
+		registry:      registry,
+		subscriptions: subs,
+		degraded:      degraded,
+		disabled:      disabled,
+		config:        cfg,
+		cacheFallback: cacheFallback,
+		stop:          stop,
+	}
+	mcpServer.AddReceivingMiddleware(serverContextMiddleware(s))
+
+	resourceBudget := cfg.ResourceCacheMaxBytes
+	if resourceBudget == 0 {
+		resourceBudget = cacheInstance.MaxCost()
 	}
+	s.resources = newResourceCache(s, resourceBudget)
 
-	logger.Info("base server initialized",
-		zap.String("name", cfg.Name),
-		zap.String("version", cfg.Version),
-		zap.Bool("cache_enabled", cfg.CacheEnabled),
-	)
+	if cfg.MetricsLogInterval > 0 {
+		s.metricsLogDone = make(chan struct{})
+		go s.logMetrics(lifecycle, cfg.MetricsLogInterval, s.metricsLogDone)
+	}
+	if cfg.MetricsSinkInterval > 0 {
+		s.metricsSinkDone = make(chan struct{})
+		go s.pushMetrics(lifecycle, cfg.MetricsSink, cfg.MetricsSinkInterval, s.metricsSinkDone)
+	}
+
+	if !cfg.DisableStartupLog {
+		logger.Info("base server initialized",
+			zap.String("name", cfg.Name),
+			zap.String("version", cfg.Version),
+			zap.Bool("cache_enabled", s.cacheEnabled()),
+		)
+	}
 
 	return s, nil
 }
@@ -160,9 +583,37 @@ func (s *Server) Cache() *cache.Cache {
 
 // Logger returns the logger instance.
 //
-// This is the same logger passed to New() during server creation.
+// This is the logger passed to New() during server creation, or the one
+// most recently set with SetLogger.
 func (s *Server) Logger() *zap.Logger {
-	return s.logger
+	return s.log()
+}
+
+// SetLogger replaces the logger used by the server, its cache, and its HTTP
+// client, for example to change the level or sink without a restart.
+//
+// The swap is atomic and safe while handlers are running: each log call uses
+// either the old or the new logger, and calls after SetLogger returns use the
+// new one. Handlers that obtained the logger earlier, through Logger or
+// LoggerFromContext, keep the logger they got. A nil logger is replaced by
+// the default stderr logger, as in New, and Config.LogWriter applies as it
+// does there.
+func (s *Server) SetLogger(logger *zap.Logger) {
+	if s.config.LogWriter != nil {
+		logger = redirectLogger(logger, s.config.LogWriter)
+	} else if logger == nil {
+		logger = newStderrLogger()
+	}
+	s.logger.Store(logger)
+	s.httpClient.SetLogger(logger)
+	if s.cache != nil {
+		s.cache.SetLogger(logger)
+	}
+}
+
+// log returns the current logger.
+func (s *Server) log() *zap.Logger {
+	return s.logger.Load()
 }
 
 // MCP returns the underlying MCP server for direct access if needed.
@@ -177,7 +628,7 @@ func (s *Server) MCP() *mcp.Server {
 //
 // This is called automatically by AddTool, so you typically don't need to call it manually.
 func (s *Server) IncrementToolCount() {
-	s.toolCount++
+	s.toolCount.Add(1)
 }
 
 // IncrementResourceCount increments the resource counter.
@@ -185,7 +636,7 @@ func (s *Server) IncrementToolCount() {
 // This is called automatically by AddResource and AddResourceTemplate,
 // so you typically don't need to call it manually.
 func (s *Server) IncrementResourceCount() {
-	s.resourceCount++
+	s.resourceCount.Add(1)
 }
 
 // LogRegistrationStats logs the number of registered tools and resources.
@@ -194,12 +645,12 @@ func (s *Server) IncrementResourceCount() {
 // Also includes cache configuration information if caching is enabled.
 func (s *Server) LogRegistrationStats() {
 	fields := []zap.Field{
-		zap.Int("tools", s.toolCount),
-		zap.Int("resources", s.resourceCount),
+		zap.Int64("tools", s.toolCount.Load()),
+		zap.Int64("resources", s.resourceCount.Load()),
 	}
 
 	// Add cache info if enabled
-	if s.config.CacheEnabled && s.cache != nil {
+	if s.cacheEnabled() && s.cache != nil {
 		metrics := s.cache.Metrics()
 		fields = append(fields,
 			zap.Bool("cache_enabled", true),
@@ -211,7 +662,7 @@ func (s *Server) LogRegistrationStats() {
 		fields = append(fields, zap.Bool("cache_enabled", false))
 	}
 
-	s.logger.Info("registered tools and resources", fields...)
+	s.log().Info("registered tools and resources", fields...)
 }
 
 // Run starts the server with the given transport.
@@ -219,7 +670,7 @@ func (s *Server) LogRegistrationStats() {
 // This method blocks until the context is canceled or an error occurs.
 // Most users should use RunWithTransport instead of calling this directly.
 func (s *Server) Run(ctx context.Context, transport mcp.Transport) error {
-	s.logger.Info("starting mcp server")
+	s.log().Info("starting mcp server")
 	return s.mcp.Run(ctx, transport)
 }
 
@@ -227,12 +678,14 @@ func (s *Server) Run(ctx context.Context, transport mcp.Transport) error {
 //
 // This is a generic function that provides type-safe tool registration. The input and output
 // types are inferred from the handler function signature. If the tool's input or output schema
-// is nil, it will be automatically generated from the type parameters.
+// is nil, it will be automatically generated from the type parameters: property names come
+// from `json` tags, fields without omitempty are required, and a `jsonschema:"..."` tag
+// supplies the description. Use SchemaFor to obtain the same schema explicitly.
 //
 // Example:
 //
 //	type Input struct {
-//	    Message string `json:"message"`
+//	    Message string `json:"message" jsonschema:"Message to echo"`
 //	}
 //	type Output struct {
 //	    Result string `json:"result"`
@@ -240,9 +693,132 @@ func (s *Server) Run(ctx context.Context, transport mcp.Transport) error {
 //	hypermcp.AddTool(srv, &mcp.Tool{Name: "echo"}, func(ctx context.Context, req *mcp.CallToolRequest, input Input) (*mcp.CallToolResult, Output, error) {
 //	    return nil, Output{Result: input.Message}, nil
 //	})
+//
+// A tool whose name is already registered is skipped with a warning, keeping
+// the first registration, as is a tool with a malformed schema. Use AddToolErr
+// to handle these as errors.
 func AddTool[In, Out any](s *Server, tool *mcp.Tool, handler mcp.ToolHandlerFor[In, Out]) {
-	mcp.AddTool(s.mcp, tool, handler)
+	if err := AddToolErr(s, tool, handler); err != nil {
+		s.log().Warn("skipping tool registration", zap.Error(err))
+	}
+}
+
+// AddToolErr registers a tool like AddTool, but returns an error instead of
+// logging when the tool cannot be registered:
+//   - ErrDuplicateRegistration if a tool of the same name is already
+//     registered; the existing tool is left in place.
+//   - ErrInvalidSchema if the tool's input or output schema is malformed, for
+//     example an unknown type name or an invalid pattern, so the mistake
+//     surfaces at startup rather than when a client calls the tool.
+//   - ErrIncompleteTool if Config.StrictRegistration is set and the tool has
+//     no description or input schema.
+//   - ErrInvalidAnnotations if the tool's annotations contradict each other,
+//     such as a read-only tool with DestructiveHint set to true.
+//
+// The tool's annotations are sent to clients as given.
+//
+// When the input schema is inferred from In, optional top-level fields may
+// declare a default with a `default:"..."` struct tag, which is published in
+// the schema and filled in when a call omits the argument:
+//
+//	type Input struct {
+//	    City  string `json:"city"`
+//	    Units string `json:"units,omitempty" default:"metric"`
+//	    Days  int    `json:"days,omitempty" default:"3"`
+//	}
+//
+// The tag holds the default as JSON, except for string fields, where it is the
+// string itself. A default tag on a required field, or one that doesn't match
+// the field's type, fails registration with ErrInvalidSchema.
+func AddToolErr[In, Out any](s *Server, tool *mcp.Tool, handler mcp.ToolHandlerFor[In, Out]) error {
+	if err := validateAnnotations(tool); err != nil {
+		return err
+	}
+	if tool.InputSchema == nil {
+		schema, err := inputSchemaWithDefaults[In]()
+		if err != nil {
+			return fmt.Errorf("%w: tool %q: input schema: %w", ErrInvalidSchema, tool.Name, err)
+		}
+		if schema != nil {
+			withDefaults := *tool
+			withDefaults.InputSchema = schema
+			tool = &withDefaults
+		}
+	}
+	if s.config.StrictRegistration {
+		if err := checkToolComplete[In](tool); err != nil {
+			return err
+		}
+	}
+	described := describeTool[In, Out](tool)
+	if err := validateToolSchemas(described); err != nil {
+		return err
+	}
+	wrapped := instrumentTool(s, tool.Name, handler)
+	if s.config.ValidateToolOutput && described.OutputSchema != nil {
+		resolved, err := resolveSchema(described.OutputSchema)
+		if err != nil {
+			return fmt.Errorf("%w: tool %q: output schema: %w", ErrInvalidSchema, tool.Name, err)
+		}
+		wrapped = validateToolOutput(s, tool.Name, resolved, wrapped)
+	}
+	if s.config.MaxToolOutputBytes > 0 {
+		wrapped = limitToolOutput(s, tool.Name, wrapped)
+	}
+	if !s.registry.addTool(described, func() { mcp.AddTool(s.mcp, tool, wrapped) }) {
+		return fmt.Errorf("%w: tool %q", ErrDuplicateRegistration, tool.Name)
+	}
 	s.IncrementToolCount()
+	return nil
+}
+
+// ToolRegistration bundles a tool with its handler for bulk registration via AddTools.
+//
+// Because tool handlers are generic, a ToolRegistration is created with NewToolRegistration,
+// which captures the handler's input and output types. This allows tools with different
+// types to be collected in a single slice, e.g. one built from configuration or a plugin list.
+type ToolRegistration struct {
+	tool     *mcp.Tool
+	register func(s *Server) error
+}
+
+// NewToolRegistration creates a ToolRegistration for tool and handler.
+//
+// Registering it has the same effect as calling AddTool(srv, tool, handler).
+func NewToolRegistration[In, Out any](tool *mcp.Tool, handler mcp.ToolHandlerFor[In, Out]) ToolRegistration {
+	return ToolRegistration{
+		tool: tool,
+		register: func(s *Server) error {
+			return AddToolErr(s, tool, handler)
+		},
+	}
+}
+
+// Tool returns the tool described by the registration.
+func (r ToolRegistration) Tool() *mcp.Tool {
+	return r.tool
+}
+
+// AddTools registers each tool in regs and increments the tool counter accordingly.
+//
+// Example:
+//
+//	srv.AddTools(
+//	    hypermcp.NewToolRegistration(&mcp.Tool{Name: "echo"}, echoHandler),
+//	    hypermcp.NewToolRegistration(&mcp.Tool{Name: "add"}, addHandler),
+//	)
+//
+// Zero-value registrations are skipped with a warning.
+func (s *Server) AddTools(regs ...ToolRegistration) {
+	for _, reg := range regs {
+		if reg.register == nil {
+			s.log().Warn("skipping empty tool registration")
+			continue
+		}
+		if err := reg.register(s); err != nil {
+			s.log().Warn("skipping tool registration", zap.Error(err))
+		}
+	}
 }
 
 // AddResource registers a resource with the MCP server and automatically increments the resource counter.
@@ -257,9 +833,25 @@ func AddTool[In, Out any](s *Server, tool *mcp.Tool, handler mcp.ToolHandlerFor[
 //	}, func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
 //	    return &mcp.ReadResourceResult{...}, nil
 //	})
+//
+// A resource whose URI is already registered is skipped with a warning,
+// keeping the first registration. Use AddResourceErr to handle duplicates as
+// an error.
 func (s *Server) AddResource(resource *mcp.Resource, handler mcp.ResourceHandler) {
-	s.mcp.AddResource(resource, handler)
+	if err := s.AddResourceErr(resource, handler); err != nil {
+		s.log().Warn("skipping resource registration", zap.Error(err))
+	}
+}
+
+// AddResourceErr registers a resource like AddResource, but returns an error
+// wrapping ErrDuplicateRegistration instead of logging when a resource with
+// the same URI is already registered. The existing resource is left in place.
+func (s *Server) AddResourceErr(resource *mcp.Resource, handler mcp.ResourceHandler) error {
+	if !s.registry.addResource(resource, func() { s.mcp.AddResource(resource, handler) }) {
+		return fmt.Errorf("%w: resource %q", ErrDuplicateRegistration, resource.URI)
+	}
 	s.IncrementResourceCount()
+	return nil
 }
 
 // AddResourceTemplate registers a resource template with the MCP server and automatically
@@ -276,17 +868,59 @@ func (s *Server) AddResource(resource *mcp.Resource, handler mcp.ResourceHandler
 //	    userId := req.Params.URI // Extract from actual request
 //	    return &mcp.ReadResourceResult{...}, nil
 //	})
+//
+// A template whose URI template is already registered is skipped with a
+// warning, keeping the first registration. Use AddResourceTemplateErr to
+// handle duplicates as an error.
 func (s *Server) AddResourceTemplate(template *mcp.ResourceTemplate, handler mcp.ResourceHandler) {
-	s.mcp.AddResourceTemplate(template, handler)
+	if err := s.AddResourceTemplateErr(template, handler); err != nil {
+		s.log().Warn("skipping resource template registration", zap.Error(err))
+	}
+}
+
+// AddResourceTemplateErr registers a resource template like
+// AddResourceTemplate, but returns an error wrapping ErrDuplicateRegistration
+// instead of logging when the same URI template is already registered. The
+// existing template is left in place.
+func (s *Server) AddResourceTemplateErr(template *mcp.ResourceTemplate, handler mcp.ResourceHandler) error {
+	if !s.registry.addTemplate(template, func() { s.mcp.AddResourceTemplate(template, s.instrumentTemplate(template, handler)) }) {
+		return fmt.Errorf("%w: resource template %q", ErrDuplicateRegistration, template.URITemplate)
+	}
 	s.IncrementResourceCount()
+	return nil
+}
+
+// AddPrompt registers a prompt with the MCP server.
+//
+// Prompts registered this way are included in the manifest returned by Describe.
+//
+// Example:
+//
+//	srv.AddPrompt(&mcp.Prompt{
+//	    Name: "summarize",
+//	    Description: "Summarize a document",
+//	}, func(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+//	    return &mcp.GetPromptResult{...}, nil
+//	})
+func (s *Server) AddPrompt(prompt *mcp.Prompt, handler mcp.PromptHandler) {
+	s.mcp.AddPrompt(prompt, handler)
+	s.registry.addPrompt(prompt)
 }
 
 // Shutdown performs cleanup and gracefully shuts down the server.
 //
 // This method performs the following cleanup operations in order:
 // 1. Logs final registration statistics (tools and resources)
-// 2. Closes the cache instance (stops background goroutines)
-// 3. Checks for context cancellation or timeout
+// 2. Runs the hooks registered with OnShutdown
+// 3. Stops background goroutines, such as metrics logging
+// 4. Passes the final metrics to Config.MetricsSink, if set
+// 5. Closes the cache instance
+// 6. Checks for context cancellation or timeout
+//
+// Every step runs even if an earlier one fails. The errors of all steps are
+// returned together via errors.Join: failed hooks and a failed metrics sink
+// as ShutdownError values, and a canceled or expired ctx as
+// ErrShutdownTimeout wrapping ctx.Err().
 //
 // It's safe to call Shutdown multiple times, though subsequent calls
 // will have no effect (except checking context status).
@@ -298,27 +932,42 @@ func (s *Server) AddResourceTemplate(template *mcp.ResourceTemplate, handler mcp
 //	if err := srv.Shutdown(ctx); err != nil {
 //	    log.Printf("shutdown error: %v", err)
 //	}
-//
-// Returns an error if the context was canceled or timed out during cleanup.
 func (s *Server) Shutdown(ctx context.Context) error {
-	s.logger.Info("shutting down server")
+	s.log().Info("shutting down server")
 
 	// Log final statistics
 	s.LogRegistrationStats()
 
-	// Close cache
+	errs := s.runShutdownHooks(ctx)
+
+	// Stop background goroutines, then close cache
+	if s.stop != nil {
+		s.stop()
+	}
+	if s.metricsLogDone != nil {
+		<-s.metricsLogDone
+	}
+	if s.metricsSinkDone != nil {
+		select {
+		case <-s.metricsSinkDone:
+		case <-ctx.Done():
+		}
+	}
+	if err := s.flushMetricsSink(ctx); err != nil {
+		errs = append(errs, err)
+	}
 	if s.cache != nil {
-		s.logger.Debug("closing cache")
+		s.log().Debug("closing cache")
 		s.cache.Close()
 	}
 
-	s.logger.Info("server shutdown complete")
+	s.log().Info("server shutdown complete")
 
 	// Check if context was canceled during cleanup
 	if ctx.Err() != nil {
-		s.logger.Warn("shutdown canceled or timed out", zap.Error(ctx.Err()))
-		return ctx.Err()
+		s.log().Warn("shutdown canceled or timed out", zap.Error(ctx.Err()))
+		errs = append(errs, fmt.Errorf("%w: %w", ErrShutdownTimeout, ctx.Err()))
 	}
 
-	return nil
+	return errors.Join(errs...)
 }
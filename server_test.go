@@ -2,12 +2,19 @@ package hypermcp
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/rayprogramming/hypermcp/cache"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 	"go.uber.org/zap/zaptest"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 func TestConfig_Validate(t *testing.T) {
@@ -49,6 +56,42 @@ func TestConfig_Validate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "negative MaxConcurrentTools",
+			config: Config{
+				Name:               "test-server",
+				Version:            "1.0.0",
+				MaxConcurrentTools: -1,
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative QueueSize",
+			config: Config{
+				Name:      "test-server",
+				Version:   "1.0.0",
+				QueueSize: -1,
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative MaxConcurrentResourceReads",
+			config: Config{
+				Name:                       "test-server",
+				Version:                    "1.0.0",
+				MaxConcurrentResourceReads: -1,
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative SlowToolThreshold",
+			config: Config{
+				Name:              "test-server",
+				Version:           "1.0.0",
+				SlowToolThreshold: -time.Second,
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -72,6 +115,153 @@ func TestConfig_Validate(t *testing.T) {
 	}
 }
 
+func TestConfig_Validate_Combinations(t *testing.T) {
+	required := true
+
+	tests := []struct {
+		config    Config
+		name      string
+		wantField string
+	}{
+		{
+			name: "cache config without cache enabled",
+			config: Config{
+				CacheConfig: cache.DefaultConfig(),
+			},
+			wantField: "CacheConfig",
+		},
+		{
+			name: "cache required without cache enabled",
+			config: Config{
+				CacheRequired: &required,
+			},
+			wantField: "CacheRequired",
+		},
+		{
+			name: "cache options without cache enabled",
+			config: Config{
+				CacheOptions: []cache.Option{cache.WithIgnoreInternalCost()},
+			},
+			wantField: "CacheOptions",
+		},
+		{
+			name: "resource cache budget without cache enabled",
+			config: Config{
+				ResourceCacheMaxBytes: 1024,
+			},
+			wantField: "ResourceCacheMaxBytes",
+		},
+		{
+			name: "queue size without concurrency limit",
+			config: Config{
+				QueueSize: 10,
+			},
+			wantField: "QueueSize",
+		},
+		{
+			name: "cache config with cache enabled",
+			config: Config{
+				CacheEnabled:  true,
+				CacheConfig:   cache.DefaultConfig(),
+				CacheRequired: &required,
+			},
+		},
+		{
+			name: "queue size with concurrency limit",
+			config: Config{
+				MaxConcurrentTools: 4,
+				QueueSize:          10,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.config.Name = "test-server"
+			tt.config.Version = "1.0.0"
+
+			err := tt.config.Validate()
+			if tt.wantField == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			var configErr *ConfigError
+			if !errors.As(err, &configErr) {
+				t.Fatalf("expected ConfigError, got %v", err)
+			}
+			if configErr.Field != tt.wantField {
+				t.Errorf("expected field %q, got %q (%v)", tt.wantField, configErr.Field, err)
+			}
+		})
+	}
+}
+
+func TestConfig_ValidateForTransport(t *testing.T) {
+	cfg := Config{
+		Name:      "test-server",
+		Version:   "1.0.0",
+		Transport: TransportConfig{Addr: ":8080"},
+	}
+
+	var configErr *ConfigError
+	if err := cfg.ValidateForTransport(TransportStdio); !errors.As(err, &configErr) || configErr.Field != "Transport" {
+		t.Errorf("expected Transport ConfigError for stdio, got %v", err)
+	}
+	if err := cfg.ValidateForTransport(TransportSSE); err != nil {
+		t.Errorf("unexpected error for sse: %v", err)
+	}
+
+	// RunWithTransport refuses to start stdio with network settings
+	srv, err := New(cfg, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	err = RunWithTransport(context.Background(), srv, TransportStdio, zaptest.NewLogger(t))
+	if !errors.As(err, &configErr) {
+		t.Errorf("expected ConfigError from RunWithTransport, got %v", err)
+	}
+}
+
+func TestRunWithTransport_AfterCacheFallback(t *testing.T) {
+	optional := false
+	cfg := Config{
+		Name:          "test-server",
+		Version:       "1.0.0",
+		CacheEnabled:  true,
+		CacheConfig:   cache.Config{MaxCost: 0, NumCounters: 100, BufferItems: 10},
+		CacheRequired: &optional,
+	}
+	srv, err := New(cfg, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+
+	if err := srv.config.ValidateForTransport(TransportStdio); err != nil {
+		t.Errorf("ValidateForTransport() after cache fallback error = %v", err)
+	}
+
+	// Serve stdio from a pipe that is closed at once, so the session ends
+	stdinReader, stdinWriter, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("failed to create stdin pipe: %v", err)
+	}
+	_ = stdinWriter.Close()
+	origStdin := os.Stdin
+	os.Stdin = stdinReader
+	defer func() { os.Stdin = origStdin }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	err = RunWithTransport(ctx, srv, TransportStdio, zaptest.NewLogger(t))
+	var configErr *ConfigError
+	if errors.As(err, &configErr) {
+		t.Errorf("RunWithTransport() after cache fallback rejected the config: %v", err)
+	}
+}
+
 func TestNew_ValidationError(t *testing.T) {
 	logger := zaptest.NewLogger(t)
 
@@ -167,6 +357,64 @@ func TestNew_CacheDisabled(t *testing.T) {
 	}
 }
 
+func TestNew_CacheRequired(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	invalidCache := cache.Config{MaxCost: 0, NumCounters: 100, BufferItems: 10}
+	optional, required := false, true
+
+	tests := []struct {
+		cacheRequired *bool
+		name          string
+		wantErr       bool
+	}{
+		{name: "default is required", cacheRequired: nil, wantErr: true},
+		{name: "explicitly required", cacheRequired: &required, wantErr: true},
+		{name: "optional falls back", cacheRequired: &optional, wantErr: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, err := New(Config{
+				Name:          "test-server",
+				Version:       "1.0.0",
+				CacheEnabled:  true,
+				CacheConfig:   invalidCache,
+				CacheRequired: tt.cacheRequired,
+			}, logger)
+
+			if tt.wantErr {
+				if !errors.Is(err, cache.ErrInvalidMaxCost) {
+					t.Errorf("expected error to wrap %v, got %v", cache.ErrInvalidMaxCost, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to create server: %v", err)
+			}
+
+			// The fallback cache must be usable
+			if srv.Cache() == nil {
+				t.Fatal("Cache is nil in degraded mode")
+			}
+			srv.Cache().Set("key", "value", time.Minute)
+			srv.Cache().Get("key")
+
+			if srv.GetMetrics().Cache.Enabled {
+				t.Error("expected cache metrics to report caching disabled")
+			}
+			if srv.Describe().Cache.Enabled {
+				t.Error("expected the manifest to report caching disabled")
+			}
+
+			// The fallback is tracked separately; the config stays as given
+			if !srv.config.CacheEnabled || srv.config.CacheConfig.NumCounters != invalidCache.NumCounters {
+				t.Errorf("expected the config to be left as given, got CacheEnabled=%v CacheConfig=%+v",
+					srv.config.CacheEnabled, srv.config.CacheConfig)
+			}
+		})
+	}
+}
+
 func TestServer_AddTool(t *testing.T) {
 	logger := zaptest.NewLogger(t)
 	cfg := Config{
@@ -180,7 +428,7 @@ func TestServer_AddTool(t *testing.T) {
 		t.Fatalf("failed to create server: %v", err)
 	}
 
-	initialCount := srv.toolCount
+	initialCount := srv.toolCount.Load()
 
 	// Add a test tool
 	type TestInput struct {
@@ -197,8 +445,82 @@ func TestServer_AddTool(t *testing.T) {
 		return nil, TestOutput{Result: "ok"}, nil
 	})
 
-	if srv.toolCount != initialCount+1 {
-		t.Errorf("expected tool count to be %d, got %d", initialCount+1, srv.toolCount)
+	if srv.toolCount.Load() != initialCount+1 {
+		t.Errorf("expected tool count to be %d, got %d", initialCount+1, srv.toolCount.Load())
+	}
+}
+
+func TestServer_AddTools(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	srv, err := New(Config{
+		Name:    "test-server",
+		Version: "1.0.0",
+	}, logger)
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+
+	type echoInput struct {
+		Message string `json:"message"`
+	}
+	type sumInput struct {
+		A int `json:"a"`
+		B int `json:"b"`
+	}
+	type sumOutput struct {
+		Sum int `json:"sum"`
+	}
+
+	regs := []ToolRegistration{
+		NewToolRegistration(&mcp.Tool{Name: "echo", Description: "Echoes the message"},
+			func(ctx context.Context, req *mcp.CallToolRequest, input echoInput) (*mcp.CallToolResult, any, error) {
+				return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: input.Message}}}, nil, nil
+			}),
+		NewToolRegistration(&mcp.Tool{Name: "sum", Description: "Adds two numbers"},
+			func(ctx context.Context, req *mcp.CallToolRequest, input sumInput) (*mcp.CallToolResult, sumOutput, error) {
+				return nil, sumOutput{Sum: input.A + input.B}, nil
+			}),
+		{}, // zero value is skipped
+	}
+
+	initialCount := srv.toolCount.Load()
+	srv.AddTools(regs...)
+
+	if srv.toolCount.Load() != initialCount+2 {
+		t.Errorf("expected tool count to be %d, got %d", initialCount+2, srv.toolCount.Load())
+	}
+	if name := regs[1].Tool().Name; name != "sum" {
+		t.Errorf("expected registration tool name %q, got %q", "sum", name)
+	}
+
+	session := connectTestClient(t, srv)
+	list, err := session.ListTools(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("failed to list tools: %v", err)
+	}
+	names := make(map[string]bool)
+	for _, tool := range list.Tools {
+		names[tool.Name] = true
+	}
+	for _, want := range []string{"echo", "sum"} {
+		if !names[want] {
+			t.Errorf("expected tool %q to be listed", want)
+		}
+	}
+
+	res, err := session.CallTool(context.Background(), &mcp.CallToolParams{
+		Name:      "sum",
+		Arguments: map[string]any{"a": 2, "b": 3},
+	})
+	if err != nil {
+		t.Fatalf("tool call failed: %v", err)
+	}
+	var out sumOutput
+	if err := remarshalForTest(res.StructuredContent, &out); err != nil {
+		t.Fatalf("failed to decode structured content: %v", err)
+	}
+	if out.Sum != 5 {
+		t.Errorf("expected sum 5, got %d", out.Sum)
 	}
 }
 
@@ -215,7 +537,7 @@ func TestServer_AddResource(t *testing.T) {
 		t.Fatalf("failed to create server: %v", err)
 	}
 
-	initialCount := srv.resourceCount
+	initialCount := srv.resourceCount.Load()
 
 	srv.AddResource(&mcp.Resource{
 		URI:         "test://resource",
@@ -234,8 +556,8 @@ func TestServer_AddResource(t *testing.T) {
 		}, nil
 	})
 
-	if srv.resourceCount != initialCount+1 {
-		t.Errorf("expected resource count to be %d, got %d", initialCount+1, srv.resourceCount)
+	if srv.resourceCount.Load() != initialCount+1 {
+		t.Errorf("expected resource count to be %d, got %d", initialCount+1, srv.resourceCount.Load())
 	}
 }
 
@@ -252,7 +574,7 @@ func TestServer_AddResourceTemplate(t *testing.T) {
 		t.Fatalf("failed to create server: %v", err)
 	}
 
-	initialCount := srv.resourceCount
+	initialCount := srv.resourceCount.Load()
 
 	srv.AddResourceTemplate(&mcp.ResourceTemplate{
 		URITemplate: "test://resource/{id}",
@@ -271,8 +593,8 @@ func TestServer_AddResourceTemplate(t *testing.T) {
 		}, nil
 	})
 
-	if srv.resourceCount != initialCount+1 {
-		t.Errorf("expected resource count to be %d, got %d", initialCount+1, srv.resourceCount)
+	if srv.resourceCount.Load() != initialCount+1 {
+		t.Errorf("expected resource count to be %d, got %d", initialCount+1, srv.resourceCount.Load())
 	}
 }
 
@@ -375,16 +697,279 @@ func TestServer_IncrementCounters(t *testing.T) {
 		t.Fatalf("failed to create server: %v", err)
 	}
 
-	initialToolCount := srv.toolCount
-	initialResourceCount := srv.resourceCount
+	initialToolCount := srv.toolCount.Load()
+	initialResourceCount := srv.resourceCount.Load()
 
 	srv.IncrementToolCount()
-	if srv.toolCount != initialToolCount+1 {
-		t.Errorf("expected tool count %d, got %d", initialToolCount+1, srv.toolCount)
+	if srv.toolCount.Load() != initialToolCount+1 {
+		t.Errorf("expected tool count %d, got %d", initialToolCount+1, srv.toolCount.Load())
 	}
 
 	srv.IncrementResourceCount()
-	if srv.resourceCount != initialResourceCount+1 {
-		t.Errorf("expected resource count %d, got %d", initialResourceCount+1, srv.resourceCount)
+	if srv.resourceCount.Load() != initialResourceCount+1 {
+		t.Errorf("expected resource count %d, got %d", initialResourceCount+1, srv.resourceCount.Load())
+	}
+}
+
+// connectTestClient connects an in-memory MCP client to srv and returns its session.
+func connectTestClient(t *testing.T, srv *Server) *mcp.ClientSession {
+	t.Helper()
+	return connectTestClientWithOptions(t, srv, nil)
+}
+
+// connectTestClientWithOptions is connectTestClient for a client created with
+// opts, such as one handling sampling requests.
+func connectTestClientWithOptions(t *testing.T, srv *Server, opts *mcp.ClientOptions) *mcp.ClientSession {
+	t.Helper()
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+
+	serverSession, err := srv.MCP().Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("failed to connect server: %v", err)
+	}
+	t.Cleanup(func() { _ = serverSession.Close() })
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, opts)
+	clientSession, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("failed to connect client: %v", err)
+	}
+	t.Cleanup(func() { _ = clientSession.Close() })
+
+	return clientSession
+}
+
+// remarshalForTest round-trips v through JSON into out.
+func remarshalForTest(v, out any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+func TestServer_StopEndsCacheCleanup(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	srv, err := New(Config{
+		Name:         "test-server",
+		Version:      "1.0.0",
+		CacheEnabled: true,
+		CacheConfig:  cache.DefaultConfig(),
+	}, zap.New(core))
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+
+	// Cancel the server's lifecycle context without closing the cache
+	srv.stop()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for logs.FilterMessage("cache cleanup stopped").Len() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("cache cleanup did not stop when the server context was canceled")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if err := srv.Shutdown(context.Background()); err != nil {
+		t.Errorf("shutdown failed: %v", err)
+	}
+}
+
+func TestAddToolErr_Duplicate(t *testing.T) {
+	srv, err := New(Config{Name: "test-server", Version: "1.0.0"}, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	first := func(context.Context, *mcp.CallToolRequest, struct{}) (*mcp.CallToolResult, any, error) {
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "first"}}}, nil, nil
+	}
+	second := func(context.Context, *mcp.CallToolRequest, struct{}) (*mcp.CallToolResult, any, error) {
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "second"}}}, nil, nil
+	}
+
+	if err := AddToolErr(srv, &mcp.Tool{Name: "echo"}, first); err != nil {
+		t.Fatalf("first AddToolErr() error = %v", err)
+	}
+	err = AddToolErr(srv, &mcp.Tool{Name: "echo"}, second)
+	if !errors.Is(err, ErrDuplicateRegistration) {
+		t.Fatalf("duplicate AddToolErr() error = %v, want ErrDuplicateRegistration", err)
+	}
+	if srv.toolCount.Load() != 1 {
+		t.Errorf("toolCount = %d, want 1", srv.toolCount.Load())
+	}
+
+	// The first handler still serves the tool
+	session := connectTestClient(t, srv)
+	result, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "echo", Arguments: map[string]any{}})
+	if err != nil {
+		t.Fatalf("CallTool() error = %v", err)
+	}
+	if text := result.Content[0].(*mcp.TextContent).Text; text != "first" {
+		t.Errorf("tool returned %q, want the first handler's %q", text, "first")
+	}
+}
+
+func TestAddResourceErr_Duplicate(t *testing.T) {
+	srv, err := New(Config{Name: "test-server", Version: "1.0.0"}, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	handler := func(context.Context, *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+		return &mcp.ReadResourceResult{}, nil
+	}
+
+	if err := srv.AddResourceErr(&mcp.Resource{URI: "test://data", Name: "data"}, handler); err != nil {
+		t.Fatalf("first AddResourceErr() error = %v", err)
+	}
+	if err := srv.AddResourceErr(&mcp.Resource{URI: "test://data", Name: "other"}, handler); !errors.Is(err, ErrDuplicateRegistration) {
+		t.Errorf("duplicate AddResourceErr() error = %v, want ErrDuplicateRegistration", err)
+	}
+
+	if err := srv.AddResourceTemplateErr(&mcp.ResourceTemplate{URITemplate: "test://items/{id}", Name: "items"}, handler); err != nil {
+		t.Fatalf("first AddResourceTemplateErr() error = %v", err)
+	}
+	if err := srv.AddResourceTemplateErr(&mcp.ResourceTemplate{URITemplate: "test://items/{id}", Name: "other"}, handler); !errors.Is(err, ErrDuplicateRegistration) {
+		t.Errorf("duplicate AddResourceTemplateErr() error = %v, want ErrDuplicateRegistration", err)
+	}
+
+	if srv.resourceCount.Load() != 2 {
+		t.Errorf("resourceCount = %d, want 2", srv.resourceCount.Load())
+	}
+}
+
+func TestAddResourceErr_SDKPanicRecordsNothing(t *testing.T) {
+	srv, err := New(Config{Name: "test-server", Version: "1.0.0"}, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	handler := func(context.Context, *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+		return &mcp.ReadResourceResult{}, nil
+	}
+
+	// The SDK panics on a URI it cannot parse
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("expected AddResourceErr to panic on an invalid URI")
+			}
+		}()
+		_ = srv.AddResourceErr(&mcp.Resource{URI: "%zz", Name: "bad"}, handler)
+	}()
+
+	if resources := srv.Describe().Resources; len(resources) != 0 {
+		t.Errorf("Describe() lists %d resources, want the failed registration left out", len(resources))
+	}
+	if srv.resourceCount.Load() != 0 {
+		t.Errorf("resourceCount = %d, want 0", srv.resourceCount.Load())
+	}
+	// The server is still usable afterwards
+	if err := srv.AddResourceErr(&mcp.Resource{URI: "test://data", Name: "data"}, handler); err != nil {
+		t.Errorf("AddResourceErr() after the panic error = %v", err)
+	}
+}
+
+func TestAddTool_DuplicateWarns(t *testing.T) {
+	core, logs := observer.New(zapcore.WarnLevel)
+	srv, err := New(Config{Name: "test-server", Version: "1.0.0"}, zap.New(core))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	handler := func(context.Context, *mcp.CallToolRequest, struct{}) (*mcp.CallToolResult, any, error) {
+		return &mcp.CallToolResult{}, nil, nil
+	}
+
+	AddTool(srv, &mcp.Tool{Name: "echo"}, handler)
+	AddTool(srv, &mcp.Tool{Name: "echo"}, handler)
+	srv.AddResource(&mcp.Resource{URI: "test://data", Name: "data"}, nil)
+	srv.AddResource(&mcp.Resource{URI: "test://data", Name: "data"}, nil)
+
+	for _, msg := range []string{"skipping tool registration", "skipping resource registration"} {
+		entries := logs.FilterMessage(msg).All()
+		if len(entries) != 1 {
+			t.Errorf("got %d %q warnings, want 1", len(entries), msg)
+			continue
+		}
+		if err, _ := entries[0].ContextMap()["error"].(string); !strings.Contains(err, "duplicate registration") {
+			t.Errorf("%q warning error = %q, want duplicate registration", msg, err)
+		}
+	}
+}
+
+func TestAddToolErr_StrictRegistration(t *testing.T) {
+	typed := func(context.Context, *mcp.CallToolRequest, struct {
+		Message string `json:"message"`
+	}) (*mcp.CallToolResult, any, error) {
+		return &mcp.CallToolResult{}, nil, nil
+	}
+	untyped := func(context.Context, *mcp.CallToolRequest, any) (*mcp.CallToolResult, any, error) {
+		return &mcp.CallToolResult{}, nil, nil
+	}
+
+	tests := []struct {
+		name    string
+		strict  bool
+		add     func(*Server) error
+		wantErr bool
+	}{
+		{
+			name:   "complete typed tool",
+			strict: true,
+			add: func(s *Server) error {
+				return AddToolErr(s, &mcp.Tool{Name: "echo", Description: "Echoes"}, typed)
+			},
+		},
+		{
+			name:   "explicit schema",
+			strict: true,
+			add: func(s *Server) error {
+				return AddToolErr(s, &mcp.Tool{Name: "echo", Description: "Echoes", InputSchema: SchemaFor[struct{}]()}, untyped)
+			},
+		},
+		{
+			name:   "schema-less tool",
+			strict: true,
+			add: func(s *Server) error {
+				return AddToolErr(s, &mcp.Tool{Name: "echo", Description: "Echoes"}, untyped)
+			},
+			wantErr: true,
+		},
+		{
+			name:   "missing description",
+			strict: true,
+			add: func(s *Server) error {
+				return AddToolErr(s, &mcp.Tool{Name: "echo", Description: "  "}, typed)
+			},
+			wantErr: true,
+		},
+		{
+			name: "schema-less tool without strict mode",
+			add: func(s *Server) error {
+				return AddToolErr(s, &mcp.Tool{Name: "echo"}, untyped)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, err := New(Config{Name: "test-server", Version: "1.0.0", StrictRegistration: tt.strict}, zaptest.NewLogger(t))
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+
+			err = tt.add(srv)
+			if tt.wantErr != errors.Is(err, ErrIncompleteTool) {
+				t.Fatalf("AddToolErr() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && srv.toolCount.Load() != 0 {
+				t.Errorf("toolCount = %d, want the tool rejected", srv.toolCount.Load())
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("AddToolErr() error = %v", err)
+			}
+		})
 	}
 }
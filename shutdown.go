@@ -0,0 +1,63 @@
+package hypermcp
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+)
+
+// shutdownHook is a cleanup function registered with OnShutdown.
+type shutdownHook struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+// OnShutdown registers fn to run when the server shuts down, for cleanup such
+// as flushing state or closing connections a server opened itself.
+//
+// Hooks run before the cache is closed, so they can still use it, and in the
+// reverse order of registration, like deferred calls. Each receives the
+// context passed to Shutdown and should return promptly once it is done. A
+// hook that fails or panics doesn't stop the others; its error is reported
+// by Shutdown wrapped in a ShutdownError naming the hook.
+func (s *Server) OnShutdown(name string, fn func(ctx context.Context) error) {
+	if fn == nil {
+		return
+	}
+	s.shutdownMu.Lock()
+	defer s.shutdownMu.Unlock()
+	s.shutdownHooks = append(s.shutdownHooks, shutdownHook{name: name, fn: fn})
+}
+
+// runShutdownHooks runs and forgets the registered hooks, returning their errors.
+func (s *Server) runShutdownHooks(ctx context.Context) []error {
+	s.shutdownMu.Lock()
+	hooks := s.shutdownHooks
+	s.shutdownHooks = nil
+	s.shutdownMu.Unlock()
+
+	var errs []error
+	for i := len(hooks) - 1; i >= 0; i-- {
+		hook := hooks[i]
+		if err := runShutdownHook(ctx, hook); err != nil {
+			s.log().Error("shutdown hook failed",
+				zap.String("hook", hook.name),
+				zap.Error(err),
+			)
+			errs = append(errs, NewShutdownError(hook.name, err))
+		}
+	}
+	return errs
+}
+
+// runShutdownHook runs hook, turning a panic into an error so the remaining
+// shutdown steps still run.
+func runShutdownHook(ctx context.Context, hook shutdownHook) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return hook.fn(ctx)
+}
@@ -0,0 +1,93 @@
+package hypermcp
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zaptest"
+)
+
+func newShutdownTestServer(t *testing.T) *Server {
+	t.Helper()
+	srv, err := New(Config{Name: "test-server", Version: "1.0.0"}, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return srv
+}
+
+func TestServer_Shutdown_JoinsHookAndTimeoutErrors(t *testing.T) {
+	srv := newShutdownTestServer(t)
+
+	errFlush := errors.New("flush failed")
+	srv.OnShutdown("flush", func(ctx context.Context) error {
+		<-ctx.Done()
+		return errFlush
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err := srv.Shutdown(ctx)
+	if err == nil {
+		t.Fatal("Shutdown() error = nil")
+	}
+	if !errors.Is(err, errFlush) {
+		t.Errorf("error = %v, want it to contain the hook error", err)
+	}
+	if !errors.Is(err, ErrShutdownTimeout) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("error = %v, want it to contain ErrShutdownTimeout and context.DeadlineExceeded", err)
+	}
+
+	var shutdownErr *ShutdownError
+	if !errors.As(err, &shutdownErr) || shutdownErr.Step != "flush" {
+		t.Errorf("errors.As(ShutdownError) = %+v, want step %q", shutdownErr, "flush")
+	}
+}
+
+func TestServer_Shutdown_RunsEveryHook(t *testing.T) {
+	srv := newShutdownTestServer(t)
+
+	var order []string
+	srv.OnShutdown("first", func(context.Context) error {
+		order = append(order, "first")
+		return errors.New("first failed")
+	})
+	srv.OnShutdown("second", func(context.Context) error {
+		order = append(order, "second")
+		panic("boom")
+	})
+	srv.OnShutdown("third", func(context.Context) error {
+		order = append(order, "third")
+		return nil
+	})
+
+	err := srv.Shutdown(context.Background())
+
+	if got := strings.Join(order, ","); got != "third,second,first" {
+		t.Errorf("hook order = %s, want third,second,first", got)
+	}
+	if err == nil {
+		t.Fatal("Shutdown() error = nil")
+	}
+	for _, want := range []string{"shutdown first: first failed", "shutdown second: panic: boom"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error = %q, want it to contain %q", err, want)
+		}
+	}
+	if errors.Is(err, ErrShutdownTimeout) {
+		t.Errorf("error = %v, want no timeout error for an uncanceled context", err)
+	}
+
+	// Hooks run only once
+	order = nil
+	if err := srv.Shutdown(context.Background()); err != nil {
+		t.Errorf("second Shutdown() error = %v, want nil", err)
+	}
+	if len(order) != 0 {
+		t.Errorf("second Shutdown() ran hooks %v again", order)
+	}
+}
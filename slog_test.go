@@ -0,0 +1,73 @@
+package hypermcp
+
+import (
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestNewWithSlog(t *testing.T) {
+	var buf lockedBuffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo}))
+
+	srv, err := NewWithSlog(Config{Name: "test-server", Version: "1.0.0"}, logger)
+	if err != nil {
+		t.Fatalf("NewWithSlog() error = %v", err)
+	}
+	srv.Logger().Debug("filtered by the slog handler")
+	srv.Logger().With(zap.String("component", "test")).Warn("with fields", zap.Int("count", 3), zap.Duration("took", time.Second))
+
+	var lines []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("log line %q is not slog JSON: %v", line, err)
+		}
+		lines = append(lines, entry)
+	}
+
+	find := func(msg string) map[string]any {
+		for _, entry := range lines {
+			if entry["msg"] == msg {
+				return entry
+			}
+		}
+		return nil
+	}
+	if entry := find("base server initialized"); entry == nil || entry["level"] != "INFO" || entry["name"] != "test-server" {
+		t.Errorf("startup log not routed through slog: %v", entry)
+	}
+	if find("filtered by the slog handler") != nil {
+		t.Error("debug entry was logged despite the handler's Info level")
+	}
+	entry := find("with fields")
+	if entry == nil {
+		t.Fatalf("warning not routed through slog, got %v", lines)
+	}
+	if entry["level"] != "WARN" || entry["component"] != "test" || entry["count"] != float64(3) || entry["took"] != float64(time.Second) {
+		t.Errorf("warning = %v, want level, With fields, and entry fields carried over", entry)
+	}
+}
+
+func TestSlogLevel(t *testing.T) {
+	tests := []struct {
+		level zapcore.Level
+		want  slog.Level
+	}{
+		{zapcore.DebugLevel, slog.LevelDebug},
+		{zapcore.InfoLevel, slog.LevelInfo},
+		{zapcore.WarnLevel, slog.LevelWarn},
+		{zapcore.ErrorLevel, slog.LevelError},
+		{zapcore.FatalLevel, slog.LevelError},
+	}
+	for _, tt := range tests {
+		if got := slogLevel(tt.level); got != tt.want {
+			t.Errorf("slogLevel(%v) = %v, want %v", tt.level, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package hypermcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// StdioDrainTimeout bounds how long closing a stdio session waits for the
+// messages queued in the write buffer to be written (see
+// StdioConfig.WriteBufferSize).
+const StdioDrainTimeout = 5 * time.Second
+
+// StdioConfig tunes the stdio transport.
+type StdioConfig struct {
+	// WriteBufferSize, if positive, queues up to this many bytes of outgoing
+	// messages for a background writer, so handlers don't wait on a client
+	// reading stdout slowly, and the memory held for such a client stays
+	// bounded. When the queue is full, senders wait for space, or drop the
+	// message if DropNotificationsWhenFull allows it. A message larger than
+	// the whole buffer is queued alone once the queue is empty. Zero writes
+	// each message directly, so every sender waits for its own write. When
+	// the session ends, the messages already queued are still written, for
+	// up to StdioDrainTimeout; if the client hasn't read them by then, the
+	// rest are dropped and counted in MetricsSnapshot.StdioDroppedMessages,
+	// so a stalled client cannot keep the session from closing.
+	WriteBufferSize int
+
+	// DropNotificationsWhenFull drops notifications, such as progress and
+	// log messages, that don't fit in a full write buffer instead of waiting
+	// for space. Responses and requests always wait, since dropping one would
+	// leave the other side waiting forever. Drops are counted in
+	// MetricsSnapshot.StdioDroppedMessages. Requires WriteBufferSize.
+	DropNotificationsWhenFull bool
+}
+
+// boundedWriter queues writes for a background goroutine writing them to w,
+// holding at most size bytes (see StdioConfig.WriteBufferSize). A failed
+// write to w fails every later Write with the same error.
+type boundedWriter struct {
+	w                 io.Writer
+	size              int
+	dropNotifications bool
+	drainTimeout      time.Duration
+	metrics           *Metrics
+
+	mu     sync.Mutex
+	cond   *sync.Cond // signaled when the queue changes or the writer closes
+	queue  [][]byte
+	queued int   // bytes in queue, including the message being written
+	err    error // first error from w
+	closed bool
+	done   chan struct{} // closed when the background writer exits
+}
+
+func newBoundedWriter(w io.Writer, size int, dropNotifications bool, metrics *Metrics) *boundedWriter {
+	b := &boundedWriter{
+		w:                 w,
+		size:              size,
+		dropNotifications: dropNotifications,
+		drainTimeout:      StdioDrainTimeout,
+		metrics:           metrics,
+		done:              make(chan struct{}),
+	}
+	b.cond = sync.NewCond(&b.mu)
+	go b.run()
+	return b
+}
+
+// Write queues a copy of p, waiting while the queue has no room for it.
+func (b *boundedWriter) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	waited := false
+	for b.err == nil && !b.closed && b.queued > 0 && b.queued+len(p) > b.size {
+		if b.dropNotifications && isNotification(p) {
+			b.metrics.stdioDropped.Add(1)
+			return len(p), nil
+		}
+		if !waited {
+			waited = true
+			b.metrics.stdioBufferWaits.Add(1)
+		}
+		b.cond.Wait()
+	}
+	if b.err != nil {
+		return 0, b.err
+	}
+	if b.closed {
+		return 0, io.ErrClosedPipe
+	}
+
+	b.queue = append(b.queue, bytes.Clone(p))
+	b.queued += len(p)
+	b.metrics.stdioBuffered.Add(int64(len(p)))
+	b.cond.Broadcast()
+	return len(p), nil
+}
+
+// run writes queued messages to w until the writer is closed and drained, or
+// a write fails.
+func (b *boundedWriter) run() {
+	defer close(b.done)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for {
+		for len(b.queue) == 0 && !b.closed {
+			b.cond.Wait()
+		}
+		if len(b.queue) == 0 {
+			return
+		}
+		msg := b.queue[0]
+		b.queue = b.queue[1:]
+
+		// The message's bytes stay counted until written
+		b.mu.Unlock()
+		_, err := b.w.Write(msg)
+		b.mu.Lock()
+
+		b.queued -= len(msg)
+		b.metrics.stdioBuffered.Add(-int64(len(msg)))
+		if err != nil {
+			b.err = err
+			b.metrics.stdioBuffered.Add(-int64(b.queued))
+			b.queue, b.queued = nil, 0
+			b.cond.Broadcast()
+			return
+		}
+		b.cond.Broadcast()
+	}
+}
+
+// Close stops accepting writes and waits up to drainTimeout for the queued
+// messages to be written. Messages still queued after that are dropped and
+// reported in the returned error; a write to w already in progress is left to
+// finish in the background. Close does not close w.
+func (b *boundedWriter) Close() error {
+	b.mu.Lock()
+	b.closed = true
+	b.cond.Broadcast()
+	b.mu.Unlock()
+
+	timer := time.NewTimer(b.drainTimeout)
+	defer timer.Stop()
+	select {
+	case <-b.done:
+		return nil
+	case <-timer.C:
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	dropped := len(b.queue)
+	if dropped == 0 {
+		// Only the message being written is left; run exits once it's done
+		return nil
+	}
+	var n int
+	for _, msg := range b.queue {
+		n += len(msg)
+	}
+	b.queue = nil
+	b.queued -= n
+	b.metrics.stdioBuffered.Add(-int64(n))
+	b.metrics.stdioDropped.Add(int64(dropped))
+	b.cond.Broadcast()
+	return fmt.Errorf("stdio reader stalled: dropped %d queued messages after %v", dropped, b.drainTimeout)
+}
+
+// isNotification reports whether line holds a single JSON-RPC notification: a
+// message with a method but no id.
+func isNotification(line []byte) bool {
+	var msg struct {
+		ID     json.RawMessage `json:"id"`
+		Method string          `json:"method"`
+	}
+	if err := json.Unmarshal(line, &msg); err != nil {
+		return false
+	}
+	return msg.Method != "" && msg.ID == nil
+}
@@ -0,0 +1,277 @@
+package hypermcp
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// gatedWriter is a slow reader's end of a pipe: each Write blocks until a
+// value is sent on release, then appends to buf.
+type gatedWriter struct {
+	release chan struct{}
+	mu      sync.Mutex
+	buf     bytes.Buffer
+}
+
+func (g *gatedWriter) Write(p []byte) (int, error) {
+	<-g.release
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.buf.Write(p)
+}
+
+func (g *gatedWriter) String() string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.buf.String()
+}
+
+// response returns a JSON-RPC response line of exactly 32 bytes.
+func response(id int) []byte {
+	return []byte(fmt.Sprintf(`{"jsonrpc":"2.0","id":%02d,"r":1}`+"\n", id))
+}
+
+// notification returns a JSON-RPC notification line.
+func notification() []byte {
+	return []byte(`{"jsonrpc":"2.0","method":"n"}` + "\n")
+}
+
+// writeAsync writes p to w in a goroutine, returning a channel closed when
+// the write returns.
+func writeAsync(t *testing.T, w *boundedWriter, p []byte) <-chan struct{} {
+	t.Helper()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if _, err := w.Write(p); err != nil {
+			t.Errorf("Write() error = %v", err)
+		}
+	}()
+	return done
+}
+
+func TestBoundedWriter_RespectsBound(t *testing.T) {
+	const size = 100
+	metrics := newMetrics()
+	slow := &gatedWriter{release: make(chan struct{})}
+	w := newBoundedWriter(slow, size, false, metrics)
+
+	// Three 32-byte messages fit while the reader is stalled
+	for i := 1; i <= 3; i++ {
+		if _, err := w.Write(response(i)); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+	if got := metrics.stdioBuffered.Load(); got != 96 {
+		t.Errorf("buffered bytes = %d, want 96", got)
+	}
+
+	// A fourth would exceed the bound, so it waits
+	fourth := writeAsync(t, w, response(4))
+	select {
+	case <-fourth:
+		t.Fatal("expected the write to wait for room in the buffer")
+	case <-time.After(50 * time.Millisecond):
+	}
+	if got := metrics.stdioBuffered.Load(); got > size {
+		t.Errorf("buffered bytes = %d, want at most %d", got, size)
+	}
+	if got := metrics.stdioBufferWaits.Load(); got != 1 {
+		t.Errorf("buffer waits = %d, want 1", got)
+	}
+
+	// The reader catching up makes room
+	slow.release <- struct{}{}
+	select {
+	case <-fourth:
+	case <-time.After(5 * time.Second):
+		t.Fatal("write did not proceed after the reader caught up")
+	}
+
+	close(slow.release)
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	want := string(response(1)) + string(response(2)) + string(response(3)) + string(response(4))
+	if got := slow.String(); got != want {
+		t.Errorf("written = %q, want all messages in order", got)
+	}
+	if got := metrics.stdioBuffered.Load(); got != 0 {
+		t.Errorf("buffered bytes after Close = %d, want 0", got)
+	}
+}
+
+func TestBoundedWriter_DropNotificationsWhenFull(t *testing.T) {
+	metrics := newMetrics()
+	slow := &gatedWriter{release: make(chan struct{})}
+	w := newBoundedWriter(slow, 64, true, metrics)
+
+	for i := 1; i <= 2; i++ {
+		if _, err := w.Write(response(i)); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+
+	// A notification is dropped at once; a response still waits
+	if _, err := w.Write(notification()); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if got := metrics.stdioDropped.Load(); got != 1 {
+		t.Errorf("dropped = %d, want 1", got)
+	}
+	third := writeAsync(t, w, response(3))
+	select {
+	case <-third:
+		t.Fatal("expected the response to wait rather than be dropped")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(slow.release)
+	<-third
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	want := string(response(1)) + string(response(2)) + string(response(3))
+	if got := slow.String(); got != want {
+		t.Errorf("written = %q, want the responses without the notification", got)
+	}
+}
+
+func TestBoundedWriter_OversizedMessage(t *testing.T) {
+	slow := &gatedWriter{release: make(chan struct{})}
+	close(slow.release)
+	w := newBoundedWriter(slow, 8, false, newMetrics())
+
+	if _, err := w.Write(response(1)); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if got := slow.String(); got != string(response(1)) {
+		t.Errorf("written = %q, want the oversized message", got)
+	}
+}
+
+func TestBoundedWriter_CloseWithStalledReader(t *testing.T) {
+	metrics := newMetrics()
+	stalled := &gatedWriter{release: make(chan struct{})}
+	// Let the blocked background write finish once the test is done
+	t.Cleanup(func() { close(stalled.release) })
+
+	w := newBoundedWriter(stalled, 1024, false, metrics)
+	w.drainTimeout = 20 * time.Millisecond
+	for i := 1; i <= 3; i++ {
+		if _, err := w.Write(response(i)); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+	// Wait for the background writer to block on the first message
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		w.mu.Lock()
+		pending := len(w.queue)
+		w.mu.Unlock()
+		if pending == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("queue holds %d messages, want 2 behind the one being written", pending)
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	closed := make(chan error, 1)
+	go func() { closed <- w.Close() }()
+	select {
+	case err := <-closed:
+		if err == nil {
+			t.Error("Close() error = nil, want the dropped messages reported")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close() did not return with a reader that never reads")
+	}
+
+	// The first message is stuck in the write; the other two are dropped
+	if got := metrics.stdioDropped.Load(); got != 2 {
+		t.Errorf("dropped messages = %d, want 2", got)
+	}
+	if got := metrics.stdioBuffered.Load(); got != int64(len(response(1))) {
+		t.Errorf("buffered bytes = %d, want only the message being written", got)
+	}
+	if _, err := w.Write(response(4)); err == nil {
+		t.Error("Write() after Close succeeded")
+	}
+}
+
+type errWriter struct{ err error }
+
+func (e errWriter) Write([]byte) (int, error) { return 0, e.err }
+
+func TestBoundedWriter_WriteErrorIsSticky(t *testing.T) {
+	writeErr := errors.New("broken pipe")
+	metrics := newMetrics()
+	w := newBoundedWriter(errWriter{writeErr}, 1024, false, metrics)
+	defer func() { _ = w.Close() }()
+
+	if _, err := w.Write(response(1)); err != nil {
+		t.Fatalf("first Write() error = %v, want it queued", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		_, err := w.Write(response(2))
+		if errors.Is(err, writeErr) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Write() error = %v, want the background write error", err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if got := metrics.stdioBuffered.Load(); got != 0 {
+		t.Errorf("buffered bytes = %d, want 0 after the queue is discarded", got)
+	}
+}
+
+func TestIsNotification(t *testing.T) {
+	tests := []struct {
+		line string
+		want bool
+	}{
+		{`{"jsonrpc":"2.0","method":"notifications/progress","params":{}}`, true},
+		{`{"jsonrpc":"2.0","id":1,"method":"roots/list"}`, false},
+		{`{"jsonrpc":"2.0","id":1,"result":{}}`, false},
+		{`[{"jsonrpc":"2.0","method":"n"}]`, false},
+		{`not json`, false},
+	}
+	for _, tt := range tests {
+		if got := isNotification([]byte(tt.line)); got != tt.want {
+			t.Errorf("isNotification(%s) = %v, want %v", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestConfig_Validate_Stdio(t *testing.T) {
+	tests := []struct {
+		name      string
+		stdio     StdioConfig
+		wantField string
+	}{
+		{name: "negative buffer", stdio: StdioConfig{WriteBufferSize: -1}, wantField: "Stdio.WriteBufferSize"},
+		{name: "drop without buffer", stdio: StdioConfig{DropNotificationsWhenFull: true}, wantField: "Stdio.DropNotificationsWhenFull"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{Name: "test-server", Version: "1.0.0", Stdio: tt.stdio}
+			var cfgErr *ConfigError
+			if err := cfg.Validate(); !errors.As(err, &cfgErr) || cfgErr.Field != tt.wantField {
+				t.Errorf("Validate() error = %v, want ConfigError for %s", err, tt.wantField)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package hypermcp
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap"
+)
+
+// PartialContentMetaKey is the _meta key under which progress notifications
+// sent by streaming tools carry the partial content itself.
+const PartialContentMetaKey = "hypermcp/partial"
+
+// StreamingToolHandler handles a call to a tool that produces its output
+// incrementally. The handler passes each piece of content to send as soon as
+// it is ready; send returns an error once the call's context is done or the
+// client can no longer be reached.
+//
+// The returned result may be nil. Any content it carries is appended after
+// the partials.
+type StreamingToolHandler[In any] func(ctx context.Context, req *mcp.CallToolRequest, input In, send func(mcp.Content) error) (*mcp.CallToolResult, error)
+
+// AddStreamingTool registers a tool whose handler emits its output in parts.
+//
+// When the client asked for progress notifications by supplying a progress
+// token, each part is forwarded to it as a notification as soon as it is
+// sent: the notification's message holds the part's text, its _meta holds the
+// part under PartialContentMetaKey, and its progress counts the parts sent so
+// far. Either way, all parts are assembled in order into the content of the
+// final result, so clients that don't follow progress still receive the
+// complete output.
+func AddStreamingTool[In any](s *Server, tool *mcp.Tool, handler StreamingToolHandler[In]) {
+	toolName := tool.Name
+
+	wrapped := func(ctx context.Context, req *mcp.CallToolRequest, input In) (*mcp.CallToolResult, any, error) {
+		var token any
+		if req.Params != nil {
+			token = req.Params.GetProgressToken()
+		}
+
+		var (
+			mu    sync.Mutex
+			parts []mcp.Content
+		)
+		send := func(part mcp.Content) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			parts = append(parts, part)
+
+			if token == nil || req.Session == nil {
+				return nil
+			}
+			err := req.Session.NotifyProgress(ctx, &mcp.ProgressNotificationParams{
+				Meta:          mcp.Meta{PartialContentMetaKey: part},
+				ProgressToken: token,
+				Message:       partialMessage(part),
+				Progress:      float64(len(parts)),
+			})
+			if err != nil {
+				s.log().Debug("failed to stream partial tool output",
+					zap.String("tool", toolName),
+					zap.Error(err),
+				)
+			}
+			return err
+		}
+
+		result, err := handler(ctx, req, input, send)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		mu.Lock()
+		content := append([]mcp.Content(nil), parts...)
+		mu.Unlock()
+
+		if result == nil {
+			result = &mcp.CallToolResult{}
+		}
+		result.Content = append(content, result.Content...)
+		return result, nil, nil
+	}
+
+	AddTool(s, tool, wrapped)
+}
+
+// partialMessage returns the progress message for a part: its text for text
+// content, otherwise its JSON encoding.
+func partialMessage(part mcp.Content) string {
+	if text, ok := part.(*mcp.TextContent); ok {
+		return text.Text
+	}
+	data, err := json.Marshal(part)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
@@ -0,0 +1,137 @@
+package hypermcp
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap"
+)
+
+type streamInput struct {
+	Parts int `json:"parts"`
+}
+
+func newStreamingTestServer(t *testing.T) *Server {
+	t.Helper()
+
+	srv, err := New(Config{Name: "test", Version: "1.0.0"}, zap.NewNop())
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	AddStreamingTool(srv, &mcp.Tool{Name: "stream", Description: "Streams parts"},
+		func(ctx context.Context, req *mcp.CallToolRequest, input streamInput, send func(mcp.Content) error) (*mcp.CallToolResult, error) {
+			for i := 1; i <= input.Parts; i++ {
+				if err := send(&mcp.TextContent{Text: fmt.Sprintf("part %d", i)}); err != nil {
+					return nil, err
+				}
+			}
+			return TextResult("done"), nil
+		})
+	return srv
+}
+
+func resultTexts(t *testing.T, result *mcp.CallToolResult) []string {
+	t.Helper()
+
+	texts := make([]string, 0, len(result.Content))
+	for _, c := range result.Content {
+		text, ok := c.(*mcp.TextContent)
+		if !ok {
+			t.Fatalf("content = %T, want *mcp.TextContent", c)
+		}
+		texts = append(texts, text.Text)
+	}
+	return texts
+}
+
+func TestAddStreamingTool_StreamsPartsInOrder(t *testing.T) {
+	srv := newStreamingTestServer(t)
+
+	var (
+		mu       sync.Mutex
+		messages []string
+		progress []float64
+		all      = make(chan struct{})
+	)
+	session := connectTestClientWithOptions(t, srv, &mcp.ClientOptions{
+		ProgressNotificationHandler: func(_ context.Context, req *mcp.ProgressNotificationClientRequest) {
+			mu.Lock()
+			defer mu.Unlock()
+			if req.Params.Meta[PartialContentMetaKey] == nil {
+				t.Errorf("notification %q carries no partial content", req.Params.Message)
+			}
+			messages = append(messages, req.Params.Message)
+			progress = append(progress, req.Params.Progress)
+			if len(messages) == 3 {
+				close(all)
+			}
+		},
+	})
+
+	// SetProgressToken doesn't allocate Meta, so set the token directly
+	params := &mcp.CallToolParams{
+		Meta:      mcp.Meta{"progressToken": "tok"},
+		Name:      "stream",
+		Arguments: map[string]any{"parts": 3},
+	}
+	result, err := session.CallTool(context.Background(), params)
+	if err != nil {
+		t.Fatalf("CallTool() error = %v", err)
+	}
+
+	select {
+	case <-all:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for progress notifications")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := []string{"part 1", "part 2", "part 3"}
+	for i := range want {
+		if messages[i] != want[i] {
+			t.Errorf("notification %d = %q, want %q", i, messages[i], want[i])
+		}
+		if progress[i] != float64(i+1) {
+			t.Errorf("notification %d progress = %v, want %d", i, progress[i], i+1)
+		}
+	}
+
+	got := resultTexts(t, result)
+	wantContent := append(want, "done")
+	if fmt.Sprint(got) != fmt.Sprint(wantContent) {
+		t.Errorf("result content = %q, want %q", got, wantContent)
+	}
+}
+
+func TestAddStreamingTool_AssemblesWithoutProgressToken(t *testing.T) {
+	srv := newStreamingTestServer(t)
+
+	notified := make(chan struct{}, 3)
+	session := connectTestClientWithOptions(t, srv, &mcp.ClientOptions{
+		ProgressNotificationHandler: func(context.Context, *mcp.ProgressNotificationClientRequest) {
+			notified <- struct{}{}
+		},
+	})
+
+	result, err := session.CallTool(context.Background(), &mcp.CallToolParams{
+		Name:      "stream",
+		Arguments: map[string]any{"parts": 3},
+	})
+	if err != nil {
+		t.Fatalf("CallTool() error = %v", err)
+	}
+
+	got := resultTexts(t, result)
+	want := []string{"part 1", "part 2", "part 3", "done"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("result content = %q, want %q", got, want)
+	}
+	if len(notified) != 0 {
+		t.Errorf("received %d progress notifications without a progress token, want 0", len(notified))
+	}
+}
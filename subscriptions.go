@@ -0,0 +1,127 @@
+package hypermcp
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap"
+)
+
+// subscriptions tracks which client sessions subscribed to which resource URIs.
+//
+// The MCP SDK keeps its own subscriber sets to route resource update
+// notifications but does not expose them; this mirror exists so the server can
+// report subscriber counts.
+type subscriptions struct {
+	mu      sync.Mutex
+	byURI   map[string]map[*mcp.ServerSession]struct{}
+	watched map[*mcp.ServerSession]struct{} // sessions whose end forgets their subscriptions
+	logger  *atomic.Pointer[zap.Logger]
+}
+
+func newSubscriptions(logger *atomic.Pointer[zap.Logger]) *subscriptions {
+	return &subscriptions{
+		byURI:   make(map[string]map[*mcp.ServerSession]struct{}),
+		watched: make(map[*mcp.ServerSession]struct{}),
+		logger:  logger,
+	}
+}
+
+// subscribe is installed as the SDK's SubscribeHandler.
+func (s *subscriptions) subscribe(_ context.Context, req *mcp.SubscribeRequest) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sessions := s.byURI[req.Params.URI]
+	if sessions == nil {
+		sessions = make(map[*mcp.ServerSession]struct{})
+		s.byURI[req.Params.URI] = sessions
+	}
+	sessions[req.Session] = struct{}{}
+
+	if _, ok := s.watched[req.Session]; !ok && req.Session != nil {
+		s.watched[req.Session] = struct{}{}
+
+		// Forget the subscriptions of sessions that end
+		go func(ss *mcp.ServerSession) {
+			_ = ss.Wait()
+			s.forget(ss)
+		}(req.Session)
+	}
+
+	s.logger.Load().Debug("resource subscribed", zap.String("uri", req.Params.URI))
+	return nil
+}
+
+// unsubscribe is installed as the SDK's UnsubscribeHandler.
+func (s *subscriptions) unsubscribe(_ context.Context, req *mcp.UnsubscribeRequest) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if sessions, ok := s.byURI[req.Params.URI]; ok {
+		delete(sessions, req.Session)
+		if len(sessions) == 0 {
+			delete(s.byURI, req.Params.URI)
+		}
+	}
+
+	s.logger.Load().Debug("resource unsubscribed", zap.String("uri", req.Params.URI))
+	return nil
+}
+
+// forget drops every subscription held by ss.
+func (s *subscriptions) forget(ss *mcp.ServerSession) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.watched, ss)
+	for uri, sessions := range s.byURI {
+		delete(sessions, ss)
+		if len(sessions) == 0 {
+			delete(s.byURI, uri)
+		}
+	}
+}
+
+// count returns the number of subscribers to uri among the live sessions,
+// forgetting subscriptions held by sessions that have since disconnected.
+func (s *subscriptions) count(uri string, live map[*mcp.ServerSession]struct{}) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sessions := s.byURI[uri]
+	for ss := range sessions {
+		if _, ok := live[ss]; !ok {
+			delete(sessions, ss)
+		}
+	}
+	if len(sessions) == 0 {
+		delete(s.byURI, uri)
+	}
+	return len(sessions)
+}
+
+// Subscribers returns the number of connected clients subscribed to updates of
+// the resource at uri.
+func (s *Server) Subscribers(uri string) int {
+	return s.subscriptions.count(uri, s.liveSessions())
+}
+
+// liveSessions returns the sessions currently connected.
+func (s *Server) liveSessions() map[*mcp.ServerSession]struct{} {
+	live := make(map[*mcp.ServerSession]struct{})
+	for ss := range s.mcp.Sessions() {
+		live[ss] = struct{}{}
+	}
+	return live
+}
+
+// NotifyResourceUpdated tells clients subscribed to uri that the resource has
+// changed. Clients that have not subscribed to uri are not notified.
+// Subscriptions of disconnected sessions are forgotten along the way.
+func (s *Server) NotifyResourceUpdated(ctx context.Context, uri string) error {
+	s.subscriptions.count(uri, s.liveSessions())
+	return s.mcp.ResourceUpdated(ctx, &mcp.ResourceUpdatedNotificationParams{URI: uri})
+}
@@ -0,0 +1,181 @@
+package hypermcp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap/zaptest"
+)
+
+const testResourceURI = "test://status"
+
+// connectSubscribingClient connects a client that forwards resource update
+// notifications to the returned channel.
+func connectSubscribingClient(t *testing.T, srv *Server) (*mcp.ClientSession, <-chan string) {
+	t.Helper()
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+
+	serverSession, err := srv.MCP().Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("failed to connect server: %v", err)
+	}
+	t.Cleanup(func() { _ = serverSession.Close() })
+
+	updates := make(chan string, 10)
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, &mcp.ClientOptions{
+		ResourceUpdatedHandler: func(_ context.Context, req *mcp.ResourceUpdatedNotificationRequest) {
+			updates <- req.Params.URI
+		},
+	})
+	clientSession, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("failed to connect client: %v", err)
+	}
+	t.Cleanup(func() { _ = clientSession.Close() })
+
+	return clientSession, updates
+}
+
+func newSubscriptionTestServer(t *testing.T) *Server {
+	t.Helper()
+
+	srv, err := New(Config{Name: "test-server", Version: "1.0.0"}, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	srv.AddResource(&mcp.Resource{URI: testResourceURI, Name: "status"},
+		func(_ context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+			return &mcp.ReadResourceResult{Contents: []*mcp.ResourceContents{{URI: req.Params.URI, Text: "ok"}}}, nil
+		})
+	return srv
+}
+
+func expectUpdate(t *testing.T, updates <-chan string, uri string) {
+	t.Helper()
+	select {
+	case got := <-updates:
+		if got != uri {
+			t.Errorf("expected update for %q, got %q", uri, got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timed out waiting for update of %q", uri)
+	}
+}
+
+func expectNoUpdate(t *testing.T, updates <-chan string) {
+	t.Helper()
+	select {
+	case got := <-updates:
+		t.Errorf("unexpected update for %q", got)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestServer_NotifyResourceUpdated_OnlySubscribers(t *testing.T) {
+	srv := newSubscriptionTestServer(t)
+	ctx := context.Background()
+
+	subscriber, subscriberUpdates := connectSubscribingClient(t, srv)
+	_, bystanderUpdates := connectSubscribingClient(t, srv)
+
+	if err := subscriber.Subscribe(ctx, &mcp.SubscribeParams{URI: testResourceURI}); err != nil {
+		t.Fatalf("subscribe failed: %v", err)
+	}
+	if got := srv.Subscribers(testResourceURI); got != 1 {
+		t.Errorf("expected 1 subscriber, got %d", got)
+	}
+
+	if err := srv.NotifyResourceUpdated(ctx, testResourceURI); err != nil {
+		t.Fatalf("notify failed: %v", err)
+	}
+	expectUpdate(t, subscriberUpdates, testResourceURI)
+	expectNoUpdate(t, bystanderUpdates)
+
+	// Updates to other resources are not delivered
+	if err := srv.NotifyResourceUpdated(ctx, "test://other"); err != nil {
+		t.Fatalf("notify failed: %v", err)
+	}
+	expectNoUpdate(t, subscriberUpdates)
+}
+
+func TestServer_Unsubscribe(t *testing.T) {
+	srv := newSubscriptionTestServer(t)
+	ctx := context.Background()
+
+	session, updates := connectSubscribingClient(t, srv)
+	if err := session.Subscribe(ctx, &mcp.SubscribeParams{URI: testResourceURI}); err != nil {
+		t.Fatalf("subscribe failed: %v", err)
+	}
+	if err := session.Unsubscribe(ctx, &mcp.UnsubscribeParams{URI: testResourceURI}); err != nil {
+		t.Fatalf("unsubscribe failed: %v", err)
+	}
+
+	if got := srv.Subscribers(testResourceURI); got != 0 {
+		t.Errorf("expected 0 subscribers after unsubscribe, got %d", got)
+	}
+	if err := srv.NotifyResourceUpdated(ctx, testResourceURI); err != nil {
+		t.Fatalf("notify failed: %v", err)
+	}
+	expectNoUpdate(t, updates)
+}
+
+func TestServer_Subscribers_ForgetsDisconnectedSessions(t *testing.T) {
+	srv := newSubscriptionTestServer(t)
+	ctx := context.Background()
+
+	first, _ := connectSubscribingClient(t, srv)
+	second, _ := connectSubscribingClient(t, srv)
+	for _, session := range []*mcp.ClientSession{first, second} {
+		if err := session.Subscribe(ctx, &mcp.SubscribeParams{URI: testResourceURI}); err != nil {
+			t.Fatalf("subscribe failed: %v", err)
+		}
+	}
+	if got := srv.Subscribers(testResourceURI); got != 2 {
+		t.Fatalf("expected 2 subscribers, got %d", got)
+	}
+
+	if err := first.Close(); err != nil {
+		t.Fatalf("close failed: %v", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for srv.Subscribers(testResourceURI) != 1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected 1 subscriber after disconnect, got %d", srv.Subscribers(testResourceURI))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestServer_Subscriptions_ForgottenWhenSessionCloses(t *testing.T) {
+	srv := newSubscriptionTestServer(t)
+	ctx := context.Background()
+
+	session, _ := connectSubscribingClient(t, srv)
+	for _, uri := range []string{testResourceURI, "test://other"} {
+		if err := session.Subscribe(ctx, &mcp.SubscribeParams{URI: uri}); err != nil {
+			t.Fatalf("subscribe failed: %v", err)
+		}
+	}
+	if err := session.Close(); err != nil {
+		t.Fatalf("close failed: %v", err)
+	}
+
+	// The subscriptions are dropped without anyone asking for counts
+	tracked := func() int {
+		srv.subscriptions.mu.Lock()
+		defer srv.subscriptions.mu.Unlock()
+		return len(srv.subscriptions.byURI) + len(srv.subscriptions.watched)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for tracked() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("still tracking %d entries after the session closed", tracked())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
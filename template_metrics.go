@@ -0,0 +1,152 @@
+package hypermcp
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/yosida95/uritemplate/v3"
+	"go.uber.org/zap"
+)
+
+// ParamCount is how often one combination of template parameter values was read.
+type ParamCount struct {
+	Params string `json:"params"` // Parameter values, URL-encoded and sorted by name, e.g. "filename=a.txt"
+	Count  int64  `json:"count"`
+}
+
+// TemplateSnapshot is the usage of one resource template in MetricsSnapshot.
+type TemplateSnapshot struct {
+	Reads int64 `json:"reads"` // Reads of any URI matching the template
+
+	// TopParams lists the most read parameter values, most read first. It is
+	// only populated when Config.TrackTemplateParams is set. Counts are
+	// approximate once more distinct values have been read than are tracked.
+	TopParams []ParamCount `json:"topParams,omitempty"`
+}
+
+// templateStats counts the reads of one resource template.
+type templateStats struct {
+	reads atomic.Int64
+
+	mu     sync.Mutex
+	params map[string]int64 // bounded to the configured number of values
+}
+
+// observe counts a read with the given parameter values. At most limit
+// distinct values are tracked, using the Space-Saving algorithm: once full, a
+// new value replaces the least read one and inherits its count, so frequently
+// read values stay tracked while rare ones churn.
+func (t *templateStats) observe(params string, limit int) {
+	t.reads.Add(1)
+	if limit <= 0 || params == "" {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.params == nil {
+		t.params = make(map[string]int64, limit)
+	}
+	if _, ok := t.params[params]; ok || len(t.params) < limit {
+		t.params[params]++
+		return
+	}
+
+	var minKey string
+	minCount := int64(-1)
+	for k, c := range t.params {
+		if minCount < 0 || c < minCount || (c == minCount && k < minKey) {
+			minKey, minCount = k, c
+		}
+	}
+	delete(t.params, minKey)
+	t.params[params] = minCount + 1
+}
+
+func (t *templateStats) snapshot() TemplateSnapshot {
+	snap := TemplateSnapshot{Reads: t.reads.Load()}
+
+	t.mu.Lock()
+	for params, count := range t.params {
+		snap.TopParams = append(snap.TopParams, ParamCount{Params: params, Count: count})
+	}
+	t.mu.Unlock()
+
+	sort.Slice(snap.TopParams, func(i, j int) bool {
+		a, b := snap.TopParams[i], snap.TopParams[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Params < b.Params
+	})
+	return snap
+}
+
+// templateUsage holds the read statistics of every resource template.
+type templateUsage struct {
+	templates sync.Map // URI template -> *templateStats
+}
+
+func (u *templateUsage) observe(template, params string, limit int) {
+	stats, ok := u.templates.Load(template)
+	if !ok {
+		stats, _ = u.templates.LoadOrStore(template, &templateStats{})
+	}
+	stats.(*templateStats).observe(params, limit)
+}
+
+// snapshot returns the usage of every template read so far, or nil if none has been.
+func (u *templateUsage) snapshot() map[string]TemplateSnapshot {
+	var snaps map[string]TemplateSnapshot
+	u.templates.Range(func(key, value any) bool {
+		if snaps == nil {
+			snaps = make(map[string]TemplateSnapshot)
+		}
+		snaps[key.(string)] = value.(*templateStats).snapshot()
+		return true
+	})
+	return snaps
+}
+
+// instrumentTemplate wraps a resource template handler to record its reads in
+// the server metrics, along with the parameter values when
+// Config.TrackTemplateParams is set.
+func (s *Server) instrumentTemplate(template *mcp.ResourceTemplate, handler mcp.ResourceHandler) mcp.ResourceHandler {
+	limit := s.config.TrackTemplateParams
+
+	var matcher *uritemplate.Template
+	if limit > 0 {
+		var err error
+		if matcher, err = uritemplate.New(template.URITemplate); err != nil {
+			// The SDK rejects invalid templates on registration; count reads anyway
+			s.log().Warn("cannot track parameters of resource template",
+				zap.String("template", template.URITemplate),
+				zap.Error(err),
+			)
+		}
+	}
+
+	return func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+		var params string
+		if matcher != nil && req.Params != nil {
+			params = templateParams(matcher, req.Params.URI)
+		}
+		s.metrics.templateUsage.observe(template.URITemplate, params, limit)
+		return handler(ctx, req)
+	}
+}
+
+// templateParams extracts the variables of uri matched against t, encoded as
+// a sorted query string.
+func templateParams(t *uritemplate.Template, uri string) string {
+	values := url.Values{}
+	for name, v := range t.Match(uri) {
+		values[name] = v.V
+	}
+	return values.Encode()
+}
@@ -0,0 +1,120 @@
+package hypermcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap/zaptest"
+)
+
+const testTemplate = "file:///examples/{filename}"
+
+func newTemplateTestServer(t *testing.T, trackParams int) *Server {
+	t.Helper()
+
+	srv, err := New(Config{
+		Name:                "test-server",
+		Version:             "1.0.0",
+		TrackTemplateParams: trackParams,
+	}, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	srv.AddResourceTemplate(&mcp.ResourceTemplate{URITemplate: testTemplate, Name: "examples"},
+		func(_ context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+			return &mcp.ReadResourceResult{Contents: []*mcp.ResourceContents{{URI: req.Params.URI, Text: "content"}}}, nil
+		})
+	return srv
+}
+
+func readExamples(t *testing.T, session *mcp.ClientSession, reads map[string]int) {
+	t.Helper()
+	for name, n := range reads {
+		for i := 0; i < n; i++ {
+			if _, err := session.ReadResource(context.Background(), &mcp.ReadResourceParams{URI: "file:///examples/" + name}); err != nil {
+				t.Fatalf("read of %s failed: %v", name, err)
+			}
+		}
+	}
+}
+
+func TestResourceTemplateMetrics(t *testing.T) {
+	tests := []struct {
+		name        string
+		trackParams int
+		wantTop     []ParamCount
+	}{
+		{
+			name:        "reads only",
+			trackParams: 0,
+		},
+		{
+			name:        "top parameter values",
+			trackParams: 10,
+			wantTop: []ParamCount{
+				{Params: "filename=hot.txt", Count: 5},
+				{Params: "filename=warm.txt", Count: 2},
+				{Params: "filename=cold.txt", Count: 1},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newTemplateTestServer(t, tt.trackParams)
+			session := connectTestClient(t, srv)
+
+			readExamples(t, session, map[string]int{"hot.txt": 5, "warm.txt": 2, "cold.txt": 1})
+
+			usage, ok := srv.GetMetrics().ResourceTemplates[testTemplate]
+			if !ok {
+				t.Fatalf("expected usage for %s", testTemplate)
+			}
+			if usage.Reads != 8 {
+				t.Errorf("expected 8 reads, got %d", usage.Reads)
+			}
+			if fmt.Sprint(usage.TopParams) != fmt.Sprint(tt.wantTop) {
+				t.Errorf("expected top params %v, got %v", tt.wantTop, usage.TopParams)
+			}
+		})
+	}
+}
+
+func TestTemplateStats_BoundedCardinality(t *testing.T) {
+	var stats templateStats
+	const limit = 10
+
+	// A hot value survives a long tail of values read once
+	for i := 0; i < 30; i++ {
+		stats.observe("filename=hot.txt", limit)
+	}
+	for i := 0; i < 100; i++ {
+		stats.observe(fmt.Sprintf("filename=rare-%d.txt", i), limit)
+	}
+
+	snap := stats.snapshot()
+	if snap.Reads != 130 {
+		t.Errorf("expected 130 reads, got %d", snap.Reads)
+	}
+	if len(snap.TopParams) != limit {
+		t.Fatalf("expected %d tracked values, got %d", limit, len(snap.TopParams))
+	}
+	if top := snap.TopParams[0]; top.Params != "filename=hot.txt" || top.Count != 30 {
+		t.Errorf("expected hot.txt first with 30 reads, got %+v", top)
+	}
+}
+
+func TestServer_PrometheusHandler_TemplateReads(t *testing.T) {
+	srv := newTemplateTestServer(t, 0)
+	session := connectTestClient(t, srv)
+	readExamples(t, session, map[string]int{"a.txt": 2})
+
+	body := scrapePrometheus(t, srv)
+	want := `hypermcp_resource_template_reads_total{template="file:///examples/{filename}"} 2`
+	if !strings.Contains(body, want) {
+		t.Errorf("expected exposition to contain %q\n%s", want, body)
+	}
+}
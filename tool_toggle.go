@@ -0,0 +1,67 @@
+package hypermcp
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap"
+)
+
+// disabledTools holds the names of the tools disabled with SetToolEnabled.
+type disabledTools struct {
+	tools sync.Map // tool name -> struct{}
+}
+
+func (d *disabledTools) disabled(name string) bool {
+	_, ok := d.tools.Load(name)
+	return ok
+}
+
+// middleware rejects calls to disabled tools and leaves them out of tool
+// lists.
+func (d *disabledTools) middleware() mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			switch method {
+			case "tools/call":
+				if call, ok := req.(*mcp.CallToolRequest); ok && call.Params != nil && d.disabled(call.Params.Name) {
+					return nil, fmt.Errorf("%w: tool %q is disabled", ErrToolDisabled, call.Params.Name)
+				}
+			case "tools/list":
+				result, err := next(ctx, method, req)
+				if list, ok := result.(*mcp.ListToolsResult); ok && list != nil {
+					filtered := *list
+					filtered.Tools = slices.DeleteFunc(slices.Clone(list.Tools), func(tool *mcp.Tool) bool {
+						return d.disabled(tool.Name)
+					})
+					return &filtered, err
+				}
+				return result, err
+			}
+			return next(ctx, method, req)
+		}
+	}
+}
+
+// SetToolEnabled enables or disables the tool named name without
+// unregistering it, for feature-flagging a tool off and quickly back on.
+// Calls to a disabled tool fail with ErrToolDisabled, and it is left out of
+// tools/list results, while its registration and metrics are kept. Clients
+// are not notified of the change; they see it on their next tools/list.
+//
+// The state is kept by name, so it also applies to a tool registered under
+// that name later. Tools are enabled by default.
+func (s *Server) SetToolEnabled(name string, enabled bool) {
+	if enabled {
+		s.disabled.tools.Delete(name)
+	} else {
+		s.disabled.tools.Store(name, struct{}{})
+	}
+	s.log().Info("tool availability changed",
+		zap.String("tool", name),
+		zap.Bool("enabled", enabled),
+	)
+}
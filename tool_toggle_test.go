@@ -0,0 +1,47 @@
+package hypermcp
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestServer_SetToolEnabled(t *testing.T) {
+	srv, err := New(Config{Name: "test-server", Version: "1.0.0"}, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	srv.AddTools(echoRegistration("flagged"), echoRegistration("other"))
+	session := connectTestClient(t, srv)
+	ctx := context.Background()
+
+	srv.SetToolEnabled("flagged", false)
+
+	_, err = session.CallTool(ctx, &mcp.CallToolParams{Name: "flagged", Arguments: map[string]any{}})
+	if err == nil || !strings.Contains(err.Error(), ErrToolDisabled.Error()) {
+		t.Errorf("CallTool() on a disabled tool error = %v, want %q", err, ErrToolDisabled)
+	}
+	if names := listToolNames(t, session); !slices.Equal(names, []string{"other"}) {
+		t.Errorf("tools while disabled = %v, want [other]", names)
+	}
+	if result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "other", Arguments: map[string]any{}}); err != nil || result.IsError {
+		t.Errorf("CallTool() on an enabled tool = %v, %v", result, err)
+	}
+
+	srv.SetToolEnabled("flagged", true)
+
+	result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "flagged", Arguments: map[string]any{}})
+	if err != nil || result.IsError {
+		t.Fatalf("CallTool() after re-enabling = %v, %v", result, err)
+	}
+	if text := result.Content[0].(*mcp.TextContent).Text; text != "flagged" {
+		t.Errorf("CallTool() after re-enabling returned %q", text)
+	}
+	if names := listToolNames(t, session); !slices.Equal(names, []string{"flagged", "other"}) {
+		t.Errorf("tools after re-enabling = %v, want [flagged other]", names)
+	}
+}
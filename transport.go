@@ -4,8 +4,9 @@ package hypermcp
 import (
 	"context"
 	"fmt"
+	"os"
+	"strings"
 
-	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"go.uber.org/zap"
 )
 
@@ -17,6 +18,8 @@ import (
 //    - Client launches MCP server as a subprocess
 //    - Server reads JSON-RPC messages from stdin, writes to stdout
 //    - Messages are delimited by newlines
+//    - stdout is reserved for protocol messages: logs MUST go to stderr,
+//      otherwise they corrupt the JSON-RPC stream (see logging.go)
 //    - Recommended for most use cases
 //    - Clients SHOULD support stdio whenever possible
 //
@@ -27,7 +30,8 @@ import (
 //    - Suitable for servers that need to handle multiple concurrent client connections
 //
 // Note: The old HTTP+SSE transport is deprecated but servers can maintain backwards
-// compatibility by supporting both old and new transports.
+// compatibility by supporting both old and new transports. TransportSSE serves the
+// legacy transport for clients that have not yet moved to Streamable HTTP.
 
 // TransportType defines the type of transport to use.
 //
@@ -38,6 +42,8 @@ const (
 	// TransportStdio uses standard input/output for communication.
 	// This is the recommended transport for most MCP servers where the client
 	// launches the server as a subprocess.
+	//
+	// Stdout carries the protocol, so the server's logger must never write to it.
 	TransportStdio TransportType = "stdio"
 
 	// TransportStreamableHTTP uses HTTP-based transport for multiple client connections.
@@ -45,30 +51,116 @@ const (
 	// that need to handle multiple concurrent clients.
 	// Note: Not yet implemented in this library.
 	TransportStreamableHTTP TransportType = "streamable-http"
+
+	// TransportSSE uses the legacy HTTP+SSE transport (protocol version 2024-11-05).
+	// Clients open a long-lived GET event stream and POST messages to a per-session
+	// endpoint announced on that stream. The listen address and path are taken from
+	// Config.Transport.
+	//
+	// Deprecated: HTTP+SSE was superseded by Streamable HTTP in the MCP specification.
+	// Use it only to support older clients that cannot speak Streamable HTTP.
+	TransportSSE TransportType = "sse"
+
+	// TransportUnix listens on the Unix domain socket at
+	// Config.Transport.SocketPath, for local clients that want a persistent
+	// connection without launching the server as a subprocess. Each connection
+	// is an independent session framed like stdio, as newline-delimited
+	// JSON-RPC. The socket is readable and writable by its owner only, and is
+	// removed on shutdown.
+	TransportUnix TransportType = "unix"
 )
 
+// TransportEnvVar is the environment variable consulted by TransportFromEnv.
+const TransportEnvVar = "HYPERMCP_TRANSPORT"
+
+// TransportFromEnv returns the transport named by the HYPERMCP_TRANSPORT
+// environment variable, so a deployment can pick the transport without code
+// changes (for example HYPERMCP_TRANSPORT=streamable-http in a container).
+//
+// An unset or empty variable selects TransportStdio. The value is matched
+// case-insensitively against the TransportType constants; any other value
+// returns a *TransportError wrapping ErrUnknownTransport.
+func TransportFromEnv() (TransportType, error) {
+	value := strings.TrimSpace(os.Getenv(TransportEnvVar))
+	if value == "" {
+		return TransportStdio, nil
+	}
+
+	transportType := TransportType(strings.ToLower(value))
+	switch transportType {
+	case TransportStdio, TransportStreamableHTTP, TransportSSE, TransportUnix:
+		return transportType, nil
+	}
+	return "", NewTransportError(TransportType(value), fmt.Errorf("%w: %s=%q, want %q, %q, %q, or %q",
+		ErrUnknownTransport, TransportEnvVar, value, TransportStdio, TransportStreamableHTTP, TransportSSE, TransportUnix))
+}
+
+// RunFromEnv starts the MCP server with the transport selected by
+// TransportFromEnv, defaulting to stdio. It is RunWithTransport for binaries
+// whose transport is chosen at deployment time:
+//
+//	if err := hypermcp.RunFromEnv(ctx, srv, logger); err != nil {
+//	    logger.Fatal("server failed", zap.Error(err))
+//	}
+func RunFromEnv(ctx context.Context, srv *Server, logger *zap.Logger) error {
+	transportType, err := TransportFromEnv()
+	if err != nil {
+		return err
+	}
+	return RunWithTransport(ctx, srv, transportType, logger)
+}
+
 // RunWithTransport starts the MCP server with the specified transport.
 //
 // The function logs the selected transport and blocks until the context is canceled
-// or an error occurs. Currently only stdio transport is implemented.
+// or an error occurs. Stdio, the legacy SSE transport, and the Unix socket
+// transport are implemented; listening transports return nil once the context
+// is canceled and the listener has shut down.
+//
+// If ctx is already done, RunWithTransport returns a *TransportError wrapping
+// ctx.Err() without starting the transport.
 func RunWithTransport(ctx context.Context, srv *Server, transportType TransportType, logger *zap.Logger) error {
-	var transport mcp.Transport
+	if err := ctx.Err(); err != nil {
+		return NewTransportError(transportType, fmt.Errorf("not started: %w", err))
+	}
+	if srv != nil && srv.config.LogWriter != nil {
+		logger = redirectLogger(logger, srv.config.LogWriter)
+	}
+	run, err := transportRunner(srv, transportType)
+	if err != nil {
+		return err
+	}
+	return run(ctx, logger)
+}
 
+// transportRunner returns the function serving srv over transportType, or a
+// *TransportError if the transport is unknown, not supported, or not
+// configured correctly for srv.
+func transportRunner(srv *Server, transportType TransportType) (func(context.Context, *zap.Logger) error, error) {
 	switch transportType {
 	case TransportStdio:
-		logger.Info("using stdio transport (recommended)")
-		transport = &mcp.StdioTransport{}
+		if err := srv.config.validateTransport(transportType); err != nil {
+			return nil, NewTransportError(transportType, err)
+		}
+		return func(ctx context.Context, logger *zap.Logger) error {
+			logger.Info("using stdio transport (recommended)")
+			return srv.runIO(ctx, os.Stdin, os.Stdout, logger)
+		}, nil
+	case TransportSSE:
+		return func(ctx context.Context, logger *zap.Logger) error {
+			logger.Warn("using deprecated HTTP+SSE transport")
+			return runHTTPTransport(ctx, srv, transportType, logger)
+		}, nil
+	case TransportUnix:
+		if err := srv.config.validateTransport(transportType); err != nil {
+			return nil, NewTransportError(transportType, err)
+		}
+		return func(ctx context.Context, logger *zap.Logger) error {
+			return runUnixTransport(ctx, srv, logger)
+		}, nil
 	case TransportStreamableHTTP:
-		return NewTransportError(transportType, ErrTransportNotSupported)
+		return nil, NewTransportError(transportType, ErrTransportNotSupported)
 	default:
-		return NewTransportError(transportType, fmt.Errorf("unknown transport type"))
+		return nil, NewTransportError(transportType, ErrUnknownTransport)
 	}
-
-	logger.Info("server ready")
-
-	if err := srv.Run(ctx, transport); err != nil {
-		return fmt.Errorf("server run failed: %w", err)
-	}
-
-	return nil
 }
@@ -0,0 +1,170 @@
+package hypermcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultSSEPath is the URL path of the legacy SSE endpoint when
+	// TransportConfig.SSEPath is empty.
+	DefaultSSEPath = "/sse"
+
+	// httpShutdownTimeout bounds how long in-flight HTTP requests are given to
+	// finish once the run context is canceled.
+	httpShutdownTimeout = 5 * time.Second
+)
+
+// TransportConfig holds settings for network-based transports.
+//
+// It is ignored by the stdio transport.
+type TransportConfig struct {
+	// Addr is the TCP address the HTTP listener binds to, e.g. ":8080".
+	Addr string
+
+	// SocketPath is the Unix domain socket the unix transport listens on,
+	// e.g. "/run/my-server/mcp.sock". A socket left behind by a previous run
+	// is replaced.
+	SocketPath string
+
+	// SSEPath is the URL path serving the legacy HTTP+SSE endpoint.
+	// Defaults to DefaultSSEPath.
+	SSEPath string
+
+	// Auth, if configured, requires every request to present a valid bearer
+	// token or API key.
+	Auth AuthConfig
+
+	// IdleTimeout, if positive, closes a client session that sends no message
+	// for this long, releasing its resources and resource subscriptions. The
+	// clock starts when the session connects, so one that never sends a
+	// message is closed too, up to a quarter of the timeout late. It is
+	// paused while any of the session's requests are in flight. Clients that
+	// need long quiet periods should send pings.
+	IdleTimeout time.Duration
+}
+
+// ssePath returns the configured SSE path or the default.
+func (c TransportConfig) ssePath() string {
+	if c.SSEPath == "" {
+		return DefaultSSEPath
+	}
+	return c.SSEPath
+}
+
+// HTTPHandler returns an http.Handler serving the given network transport.
+//
+// RunWithTransport uses this handler internally. It is exported so servers can
+// mount MCP alongside their own routes or behind an existing http.Server.
+func (s *Server) HTTPHandler(transportType TransportType) (http.Handler, error) {
+	mux := http.NewServeMux()
+
+	switch transportType {
+	case TransportSSE:
+		getServer := func(*http.Request) *mcp.Server { return s.mcp }
+		sseHandler := mcp.NewSSEHandler(getServer, nil)
+		mux.Handle(s.config.Transport.ssePath(), s.trackSSEConnections(sseHandler))
+	default:
+		return nil, NewTransportError(transportType, ErrTransportNotSupported)
+	}
+
+	if auth := s.config.Transport.Auth; auth.enabled() {
+		return s.requireAuth(auth, mux), nil
+	}
+	return mux, nil
+}
+
+// trackSSEConnections counts SSE clients in the server metrics and exposes the
+// headers of each client's stream request to its handlers.
+//
+// Each SSE client holds one long-lived GET request (the event stream) for the
+// duration of its session, so the connection is open for as long as that
+// request is being served. POSTs carry individual messages (the SSE transport
+// does not accept JSON-RPC batches) and are recorded as such.
+func (s *Server) trackSSEConnections(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			if r.Method == http.MethodPost {
+				s.metrics.RecordMessages(1)
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		s.metrics.IncrementConnections()
+		defer s.metrics.DecrementActiveConnections()
+
+		// The session lives on the stream request's context, so its headers
+		// are visible to handlers through RequestMeta
+		next.ServeHTTP(w, r.WithContext(withConnectionHeader(r.Context(), r.Header)))
+	})
+}
+
+// runHTTPTransport listens on Config.Transport.Addr and serves the network transport
+// until ctx is canceled.
+func runHTTPTransport(ctx context.Context, srv *Server, transportType TransportType, logger *zap.Logger) error {
+	addr := srv.config.Transport.Addr
+	if addr == "" {
+		return NewTransportError(transportType, NewConfigError("Transport.Addr", fmt.Errorf("cannot be empty")))
+	}
+
+	handler, err := srv.HTTPHandler(transportType)
+	if err != nil {
+		return err
+	}
+
+	var lc net.ListenConfig
+	ln, err := lc.Listen(ctx, "tcp", addr)
+	if err != nil {
+		return NewTransportError(transportType, fmt.Errorf("listen on %s: %w", addr, err))
+	}
+
+	logger.Info("server ready", zap.String("addr", ln.Addr().String()))
+
+	return serveHTTP(ctx, ln, handler, logger)
+}
+
+// serveHTTP serves handler on ln until ctx is canceled, then shuts the listener down.
+//
+// Request contexts derive from ctx so long-lived streams (such as SSE event
+// streams) end promptly on cancellation instead of holding up the shutdown.
+func serveHTTP(ctx context.Context, ln net.Listener, handler http.Handler, logger *zap.Logger) error {
+	httpServer := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- httpServer.Serve(ln)
+	}()
+
+	select {
+	case err := <-errCh:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return fmt.Errorf("server run failed: %w", err)
+	case <-ctx.Done():
+	}
+
+	logger.Info("stopping http listener")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), httpShutdownTimeout)
+	defer cancel()
+
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		logger.Warn("http listener did not shut down cleanly", zap.Error(err))
+		return httpServer.Close()
+	}
+
+	return nil
+}
@@ -0,0 +1,210 @@
+package hypermcp
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap/zaptest"
+)
+
+func newSSETestServer(t *testing.T) *Server {
+	t.Helper()
+
+	srv, err := New(Config{
+		Name:         "test-server",
+		Version:      "1.0.0",
+		CacheEnabled: false,
+	}, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+
+	type echoInput struct {
+		Message string `json:"message"`
+	}
+	AddTool(srv, &mcp.Tool{
+		Name:        "echo",
+		Description: "Echoes the message",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input echoInput) (*mcp.CallToolResult, any, error) {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: input.Message}},
+		}, nil, nil
+	})
+
+	return srv
+}
+
+// connectSSEClient connects an MCP client to the SSE endpoint.
+func connectSSEClient(t *testing.T, endpoint string) *mcp.ClientSession {
+	t.Helper()
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "sse-client", Version: "1.0.0"}, nil)
+	session, err := client.Connect(context.Background(), &mcp.SSEClientTransport{Endpoint: endpoint}, nil)
+	if err != nil {
+		t.Fatalf("failed to connect sse client: %v", err)
+	}
+	t.Cleanup(func() { _ = session.Close() })
+
+	return session
+}
+
+func TestHTTPHandler_SSE(t *testing.T) {
+	srv := newSSETestServer(t)
+
+	handler, err := srv.HTTPHandler(TransportSSE)
+	if err != nil {
+		t.Fatalf("failed to build handler: %v", err)
+	}
+	httpServer := httptest.NewServer(handler)
+	t.Cleanup(httpServer.Close)
+
+	session := connectSSEClient(t, httpServer.URL+DefaultSSEPath)
+
+	res, err := session.CallTool(context.Background(), &mcp.CallToolParams{
+		Name:      "echo",
+		Arguments: map[string]any{"message": "hello over sse"},
+	})
+	if err != nil {
+		t.Fatalf("tool call failed: %v", err)
+	}
+
+	if len(res.Content) != 1 {
+		t.Fatalf("expected 1 content block, got %d", len(res.Content))
+	}
+	text, ok := res.Content[0].(*mcp.TextContent)
+	if !ok || text.Text != "hello over sse" {
+		t.Errorf("unexpected tool result: %+v", res.Content[0])
+	}
+}
+
+func TestHTTPHandler_CustomSSEPath(t *testing.T) {
+	srv := newSSETestServer(t)
+	srv.config.Transport.SSEPath = "/legacy/events"
+
+	handler, err := srv.HTTPHandler(TransportSSE)
+	if err != nil {
+		t.Fatalf("failed to build handler: %v", err)
+	}
+	httpServer := httptest.NewServer(handler)
+	t.Cleanup(httpServer.Close)
+
+	session := connectSSEClient(t, httpServer.URL+"/legacy/events")
+	if err := session.Ping(context.Background(), nil); err != nil {
+		t.Errorf("ping failed: %v", err)
+	}
+}
+
+func TestHTTPHandler_UnsupportedTransport(t *testing.T) {
+	srv := newSSETestServer(t)
+
+	_, err := srv.HTTPHandler(TransportStdio)
+	if !errors.Is(err, ErrTransportNotSupported) {
+		t.Errorf("expected ErrTransportNotSupported, got %v", err)
+	}
+}
+
+func TestRunWithTransport_SSEMissingAddr(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	srv := newSSETestServer(t)
+
+	err := RunWithTransport(context.Background(), srv, TransportSSE, logger)
+
+	var cfgErr *ConfigError
+	if !errors.As(err, &cfgErr) {
+		t.Fatalf("expected ConfigError, got %v", err)
+	}
+	if cfgErr.Field != "Transport.Addr" {
+		t.Errorf("expected field Transport.Addr, got %q", cfgErr.Field)
+	}
+}
+
+func TestServeHTTP_StopsOnCancel(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	srv := newSSETestServer(t)
+
+	handler, err := srv.HTTPHandler(TransportSSE)
+	if err != nil {
+		t.Fatalf("failed to build handler: %v", err)
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- serveHTTP(ctx, ln, handler, logger)
+	}()
+
+	// An open event stream must not prevent shutdown
+	session := connectSSEClient(t, "http://"+ln.Addr().String()+DefaultSSEPath)
+	if err := session.Ping(context.Background(), nil); err != nil {
+		t.Fatalf("ping failed: %v", err)
+	}
+
+	cancel()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("expected nil error after cancel, got %v", err)
+		}
+	case <-time.After(httpShutdownTimeout):
+		t.Fatal("serveHTTP did not return after cancel")
+	}
+}
+
+// waitForActiveConnections polls the metrics until the active connection gauge reaches want.
+func waitForActiveConnections(t *testing.T, srv *Server, want int64) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if srv.GetMetrics().ActiveConnections == want {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("expected %d active connections, got %d", want, srv.GetMetrics().ActiveConnections)
+}
+
+func TestHTTPHandler_SSEConnectionMetrics(t *testing.T) {
+	srv := newSSETestServer(t)
+
+	handler, err := srv.HTTPHandler(TransportSSE)
+	if err != nil {
+		t.Fatalf("failed to build handler: %v", err)
+	}
+	httpServer := httptest.NewServer(handler)
+	t.Cleanup(httpServer.Close)
+
+	first := connectSSEClient(t, httpServer.URL+DefaultSSEPath)
+	second := connectSSEClient(t, httpServer.URL+DefaultSSEPath)
+
+	// Messages posted by connected clients must not count as connections
+	if err := first.Ping(context.Background(), nil); err != nil {
+		t.Fatalf("ping failed: %v", err)
+	}
+	waitForActiveConnections(t, srv, 2)
+
+	if err := first.Close(); err != nil {
+		t.Fatalf("failed to close client: %v", err)
+	}
+	waitForActiveConnections(t, srv, 1)
+
+	if err := second.Close(); err != nil {
+		t.Fatalf("failed to close client: %v", err)
+	}
+	waitForActiveConnections(t, srv, 0)
+
+	if total := srv.GetMetrics().TotalConnections; total != 2 {
+		t.Errorf("expected 2 total connections, got %d", total)
+	}
+}
@@ -0,0 +1,168 @@
+package hypermcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap"
+)
+
+// errTransportWrite marks a run of runIO ended because writing failed.
+var errTransportWrite = errors.New("transport write failed")
+
+// runIO serves a single client over r and w, as the stdio transport does with
+// os.Stdin and os.Stdout, until ctx is canceled or the session ends.
+//
+// A failed write ends the session. If it failed because the client went away,
+// such as a broken pipe after the client exited mid-response, runIO returns
+// nil, as it does when the client closes the connection cleanly.
+func (s *Server) runIO(ctx context.Context, r io.ReadCloser, w io.Writer, logger *zap.Logger) error {
+	// Without a handler, writing to a closed stdout kills the process with
+	// SIGPIPE instead of failing the write
+	sigpipe := make(chan os.Signal, 1)
+	signal.Notify(sigpipe, syscall.SIGPIPE)
+	defer signal.Stop(sigpipe)
+
+	runCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	out := s.guardWrites(w, func(err error) {
+		cancel(fmt.Errorf("%w: %w", errTransportWrite, err))
+	})
+	var writer io.WriteCloser = nopWriteCloser{out}
+	if stdio := s.config.Stdio; stdio.WriteBufferSize > 0 {
+		writer = newBoundedWriter(out, stdio.WriteBufferSize, stdio.DropNotificationsWhenFull, s.metrics)
+	}
+	transport := s.newIOTransport(r, writer)
+
+	logger.Info("server ready")
+
+	// Stdio serves exactly one client for the lifetime of the process
+	s.metrics.IncrementConnections()
+	defer s.metrics.DecrementActiveConnections()
+
+	if err := s.Run(runCtx, transport); err != nil {
+		cause := context.Cause(runCtx)
+		if ctx.Err() != nil || !errors.Is(cause, errTransportWrite) {
+			return fmt.Errorf("server run failed: %w", err)
+		}
+		if !isDisconnect(cause) {
+			return fmt.Errorf("server run failed: %w", cause)
+		}
+		logger.Info("client disconnected, stopping transport", zap.Error(cause))
+	}
+
+	return nil
+}
+
+// newIOTransport returns a newline-delimited JSON transport over r and w that
+// records incoming message and batch counts. Closing the connection closes r
+// and w; pass a nopWriteCloser to keep w open, as mcp.StdioTransport does.
+func (s *Server) newIOTransport(r io.ReadCloser, w io.WriteCloser) mcp.Transport {
+	return &mcp.IOTransport{
+		Reader: &messageCountingReader{ReadCloser: r, metrics: s.metrics},
+		Writer: w,
+	}
+}
+
+// guardWrites returns w wrapped to log its first failed write, usually
+// because the client disconnected while a response was being written, and
+// pass the error to onWriteError. onWriteError should end the session: the
+// SDK stops answering calls after a failed write, but keeps reading until the
+// read side ends.
+func (s *Server) guardWrites(w io.Writer, onWriteError func(error)) io.Writer {
+	return &writeFailureWriter{Writer: w, onError: func(err error) {
+		s.log().Warn("transport write failed, closing session", zap.Error(err))
+		if onWriteError != nil {
+			onWriteError(err)
+		}
+	}}
+}
+
+// writeFailureWriter calls onError the first time a write fails.
+type writeFailureWriter struct {
+	io.Writer
+	onError func(error)
+	once    sync.Once
+}
+
+func (w *writeFailureWriter) Write(p []byte) (int, error) {
+	n, err := w.Writer.Write(p)
+	if err != nil {
+		w.once.Do(func() { w.onError(err) })
+	}
+	return n, err
+}
+
+// isDisconnect reports whether err means the peer of a connection went away,
+// rather than the server failing.
+func isDisconnect(err error) bool {
+	return errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, io.ErrClosedPipe) ||
+		errors.Is(err, net.ErrClosed) ||
+		errors.Is(err, io.EOF)
+}
+
+// nopWriteCloser is an io.WriteCloser with a no-op Close.
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+// messageCountingReader passively inspects a newline-delimited JSON-RPC stream
+// as it is read, recording each message or batch via Metrics.RecordMessages.
+// The bytes returned to the caller are unchanged.
+type messageCountingReader struct {
+	io.ReadCloser
+	metrics *Metrics
+	line    []byte // partial line carried over between reads
+}
+
+func (r *messageCountingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+
+	data := p[:n]
+	for {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			r.line = append(r.line, data...)
+			break
+		}
+		r.line = append(r.line, data[:i]...)
+		r.record(r.line)
+		r.line = r.line[:0]
+		data = data[i+1:]
+	}
+
+	return n, err
+}
+
+// record counts a single line of the stream.
+func (r *messageCountingReader) record(line []byte) {
+	line = bytes.TrimSpace(line)
+	if len(line) == 0 {
+		return
+	}
+	if line[0] != '[' {
+		r.metrics.RecordMessages(1)
+		return
+	}
+
+	// Malformed batches are rejected by the connection; don't count them
+	var batch []json.RawMessage
+	if err := json.Unmarshal(line, &batch); err != nil {
+		return
+	}
+	r.metrics.RecordMessages(len(batch))
+}
@@ -0,0 +1,211 @@
+package hypermcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestIOTransport_BatchMetrics(t *testing.T) {
+	srv, err := New(Config{
+		Name:    "test-server",
+		Version: "1.0.0",
+	}, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+
+	clientR, serverW := io.Pipe()
+	serverR, clientW := io.Pipe()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- srv.Run(ctx, srv.newIOTransport(serverR, nopWriteCloser{serverW}))
+	}()
+	t.Cleanup(func() {
+		cancel()
+		_ = clientW.Close()
+		<-done
+	})
+
+	responses := bufio.NewScanner(clientR)
+	send := func(line string) {
+		t.Helper()
+		if _, err := io.WriteString(clientW, line+"\n"); err != nil {
+			t.Fatalf("failed to write message: %v", err)
+		}
+	}
+	receive := func() json.RawMessage {
+		t.Helper()
+		if !responses.Scan() {
+			t.Fatalf("no response: %v", responses.Err())
+		}
+		return json.RawMessage(responses.Bytes())
+	}
+
+	// Batching is only allowed before protocol version 2025-06-18
+	send(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"raw-client","version":"1.0.0"}}}`)
+	receive()
+
+	send(`[{"jsonrpc":"2.0","id":2,"method":"ping"},{"jsonrpc":"2.0","id":3,"method":"ping"}]`)
+	var batchResponse []json.RawMessage
+	if err := json.Unmarshal(receive(), &batchResponse); err != nil {
+		t.Fatalf("expected a batch response: %v", err)
+	}
+	if len(batchResponse) != 2 {
+		t.Errorf("expected 2 responses in batch, got %d", len(batchResponse))
+	}
+
+	snapshot := srv.GetMetrics()
+	if snapshot.SingleMessages != 1 {
+		t.Errorf("expected 1 single message, got %d", snapshot.SingleMessages)
+	}
+	if snapshot.Batches != 1 {
+		t.Errorf("expected 1 batch, got %d", snapshot.Batches)
+	}
+	if snapshot.BatchedMessages != 2 {
+		t.Errorf("expected 2 batched messages, got %d", snapshot.BatchedMessages)
+	}
+	if snapshot.BatchSizes[0].UpperBound != 2 || snapshot.BatchSizes[0].Count != 1 {
+		t.Errorf("expected the batch in the first bucket, got %+v", snapshot.BatchSizes)
+	}
+}
+
+func TestMessageCountingReader_SplitReads(t *testing.T) {
+	metrics := newMetrics()
+	stream := "{\"id\":1}\n[{\"id\":2},{\"id\":3},{\"id\":4}]\r\n\n[not json\n"
+	r := &messageCountingReader{ReadCloser: io.NopCloser(&oneByteReader{data: []byte(stream)}), metrics: metrics}
+
+	out, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+	if string(out) != stream {
+		t.Errorf("reader altered the stream: %q", out)
+	}
+
+	snapshot := metrics.Snapshot()
+	if snapshot.SingleMessages != 1 || snapshot.Batches != 1 || snapshot.BatchedMessages != 3 {
+		t.Errorf("unexpected counts: single=%d batches=%d batched=%d",
+			snapshot.SingleMessages, snapshot.Batches, snapshot.BatchedMessages)
+	}
+}
+
+// oneByteReader returns its data one byte per Read call.
+type oneByteReader struct {
+	data []byte
+}
+
+func (r *oneByteReader) Read(p []byte) (int, error) {
+	if len(r.data) == 0 {
+		return 0, io.EOF
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+	p[0] = r.data[0]
+	r.data = r.data[1:]
+	return 1, nil
+}
+
+// failingWriter passes the first ok writes through to w, then fails with err.
+type failingWriter struct {
+	w   io.Writer
+	ok  int
+	err error
+}
+
+func (f *failingWriter) Write(p []byte) (int, error) {
+	if f.ok == 0 {
+		return 0, f.err
+	}
+	f.ok--
+	return f.w.Write(p)
+}
+
+func TestRunIO_WriteFailureDuringResponse(t *testing.T) {
+	tests := []struct {
+		name     string
+		writeErr error
+		stdio    StdioConfig
+		wantErr  bool
+	}{
+		{name: "client disconnected", writeErr: syscall.EPIPE, wantErr: false},
+		{name: "other write error", writeErr: errors.New("device full"), wantErr: true},
+		{name: "client disconnected with write buffer", writeErr: syscall.EPIPE, stdio: StdioConfig{WriteBufferSize: 4096}, wantErr: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, logs := observer.New(zapcore.InfoLevel)
+			logger := zap.New(core)
+			srv, err := New(Config{Name: "test-server", Version: "1.0.0", Stdio: tt.stdio}, logger)
+			if err != nil {
+				t.Fatalf("failed to create server: %v", err)
+			}
+			release := make(chan struct{})
+			AddTool(srv, &mcp.Tool{Name: "slow"}, func(ctx context.Context, req *mcp.CallToolRequest, input struct{}) (*mcp.CallToolResult, any, error) {
+				<-release
+				return TextResult("done"), nil, nil
+			})
+
+			clientR, serverW := io.Pipe()
+			serverR, clientW := io.Pipe()
+			defer func() { _ = clientW.Close() }()
+
+			// The initialize response is written; the tool response fails
+			writer := &failingWriter{w: serverW, ok: 1, err: tt.writeErr}
+			done := make(chan error, 1)
+			go func() {
+				done <- srv.runIO(context.Background(), serverR, writer, logger)
+			}()
+
+			send := func(line string) {
+				t.Helper()
+				if _, err := io.WriteString(clientW, line+"\n"); err != nil {
+					t.Fatalf("failed to write message: %v", err)
+				}
+			}
+			responses := bufio.NewScanner(clientR)
+			send(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18","capabilities":{},"clientInfo":{"name":"raw-client","version":"1.0.0"}}}`)
+			if !responses.Scan() {
+				t.Fatalf("no initialize response: %v", responses.Err())
+			}
+			send(`{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+			send(`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"slow","arguments":{}}}`)
+			close(release)
+
+			// The client never closes its side, so only the failed write ends the run
+			select {
+			case err := <-done:
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("runIO() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				if tt.wantErr && !errors.Is(err, tt.writeErr) {
+					t.Errorf("runIO() error = %v, want it to wrap %v", err, tt.writeErr)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("runIO did not return after the write failed")
+			}
+
+			if n := logs.FilterMessage("transport write failed, closing session").Len(); n != 1 {
+				t.Errorf("expected 1 write failure warning, got %d", n)
+			}
+			if active := srv.GetMetrics().ActiveConnections; active != 0 {
+				t.Errorf("active connections = %d, want 0", active)
+			}
+		})
+	}
+}
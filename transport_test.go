@@ -3,9 +3,14 @@ package hypermcp
 import (
 	"context"
 	"errors"
+	"os"
+	"strings"
 	"testing"
 
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 	"go.uber.org/zap/zaptest"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 func TestRunWithTransport_Stdio(t *testing.T) {
@@ -77,8 +82,76 @@ func TestRunWithTransport_UnknownTransport(t *testing.T) {
 
 	// Should return error for unknown transport
 	err = RunWithTransport(ctx, srv, TransportType("unknown"), logger)
-	if err == nil {
-		t.Error("expected error for unknown transport")
+	if !errors.Is(err, ErrUnknownTransport) {
+		t.Errorf("expected ErrUnknownTransport, got %v", err)
+	}
+}
+
+func TestTransportFromEnv(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		unset   bool
+		want    TransportType
+		wantErr bool
+	}{
+		{name: "unset", unset: true, want: TransportStdio},
+		{name: "empty", value: "", want: TransportStdio},
+		{name: "stdio", value: "stdio", want: TransportStdio},
+		{name: "streamable-http", value: "streamable-http", want: TransportStreamableHTTP},
+		{name: "sse", value: "sse", want: TransportSSE},
+		{name: "unix", value: "unix", want: TransportUnix},
+		{name: "case and space insensitive", value: " Streamable-HTTP\n", want: TransportStreamableHTTP},
+		{name: "unknown", value: "websocket", wantErr: true},
+		{name: "near miss", value: "streamable_http", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(TransportEnvVar, tt.value)
+			if tt.unset {
+				_ = os.Unsetenv(TransportEnvVar)
+			}
+
+			got, err := TransportFromEnv()
+			if tt.wantErr {
+				var transportErr *TransportError
+				if !errors.As(err, &transportErr) || !errors.Is(err, ErrUnknownTransport) {
+					t.Fatalf("TransportFromEnv() error = %v, want *TransportError wrapping ErrUnknownTransport", err)
+				}
+				if transportErr.Transport != TransportType(tt.value) {
+					t.Errorf("TransportError.Transport = %q, want %q", transportErr.Transport, tt.value)
+				}
+				if !strings.Contains(err.Error(), TransportEnvVar) {
+					t.Errorf("expected error to name %s, got %q", TransportEnvVar, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("TransportFromEnv() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("TransportFromEnv() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunFromEnv(t *testing.T) {
+	srv, err := New(Config{Name: "test-server", Version: "1.0.0"}, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+
+	t.Setenv(TransportEnvVar, "websocket")
+	if err := RunFromEnv(context.Background(), srv, zaptest.NewLogger(t)); !errors.Is(err, ErrUnknownTransport) {
+		t.Errorf("RunFromEnv() error = %v, want ErrUnknownTransport", err)
+	}
+
+	// The selected transport is handed to RunWithTransport
+	t.Setenv(TransportEnvVar, "streamable-http")
+	if err := RunFromEnv(context.Background(), srv, zaptest.NewLogger(t)); !errors.Is(err, ErrTransportNotSupported) {
+		t.Errorf("RunFromEnv() error = %v, want ErrTransportNotSupported", err)
 	}
 }
 
@@ -91,4 +164,46 @@ func TestTransportType_Constants(t *testing.T) {
 	if TransportStreamableHTTP != "streamable-http" {
 		t.Errorf("expected TransportStreamableHTTP to be 'streamable-http', got %q", TransportStreamableHTTP)
 	}
+
+	if TransportSSE != "sse" {
+		t.Errorf("expected TransportSSE to be 'sse', got %q", TransportSSE)
+	}
+}
+
+func TestRunWithTransport_CanceledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for _, transportType := range []TransportType{TransportStdio, TransportSSE, TransportUnix, TransportType("unknown")} {
+		t.Run(string(transportType), func(t *testing.T) {
+			socketPath := tempSocketPath(t)
+			srv, err := New(Config{
+				Name:      "test-server",
+				Version:   "1.0.0",
+				Transport: TransportConfig{Addr: "127.0.0.1:0", SocketPath: socketPath},
+			}, zaptest.NewLogger(t))
+			if err != nil {
+				t.Fatalf("failed to create server: %v", err)
+			}
+			core, logs := observer.New(zapcore.DebugLevel)
+
+			err = RunWithTransport(ctx, srv, transportType, zap.New(core))
+			var transportErr *TransportError
+			if !errors.As(err, &transportErr) || !errors.Is(err, context.Canceled) {
+				t.Fatalf("RunWithTransport() error = %v, want *TransportError wrapping context.Canceled", err)
+			}
+			if transportErr.Transport != transportType {
+				t.Errorf("TransportError.Transport = %q, want %q", transportErr.Transport, transportType)
+			}
+			if logs.Len() != 0 {
+				t.Errorf("expected nothing logged, got %v", logs.All())
+			}
+			if _, err := os.Stat(socketPath); !os.IsNotExist(err) {
+				t.Errorf("expected no socket to be created, stat error = %v", err)
+			}
+			if got := srv.GetMetrics().TotalConnections; got != 0 {
+				t.Errorf("TotalConnections = %d, want 0", got)
+			}
+		})
+	}
 }
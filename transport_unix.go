@@ -0,0 +1,156 @@
+package hypermcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// unixSocketMode restricts the socket to its owner, since the unix transport
+// has no authentication of its own.
+const unixSocketMode fs.FileMode = 0o600
+
+// runUnixTransport listens on Config.Transport.SocketPath and serves a session
+// per connection until ctx is canceled, then removes the socket file.
+func runUnixTransport(ctx context.Context, srv *Server, logger *zap.Logger) error {
+	path := srv.config.Transport.SocketPath
+
+	if err := removeStaleSocket(path); err != nil {
+		return NewTransportError(TransportUnix, err)
+	}
+
+	ln, err := listenUnix(ctx, path)
+	if err != nil {
+		return NewTransportError(TransportUnix, err)
+	}
+	defer func() {
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			logger.Warn("failed to remove socket file", zap.String("socket", path), zap.Error(err))
+		}
+	}()
+	defer func() { _ = ln.Close() }()
+
+	logger.Info("server ready", zap.String("socket", path))
+
+	return srv.serveUnix(ctx, ln, logger)
+}
+
+// listenUnix listens on a unix socket at path that only its owner can connect
+// to. The socket is created in a private directory beside path, restricted
+// there, then renamed into place, so it is never reachable with looser
+// permissions; restricting it after listening at path would leave a window in
+// which anyone could connect.
+func listenUnix(ctx context.Context, path string) (net.Listener, error) {
+	dir, err := os.MkdirTemp(filepath.Dir(path), ".hypermcp-")
+	if err != nil {
+		return nil, fmt.Errorf("create private directory for %s: %w", path, err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	tmp := filepath.Join(dir, "sock")
+	var lc net.ListenConfig
+	ln, err := lc.Listen(ctx, "unix", tmp)
+	if err != nil {
+		return nil, fmt.Errorf("listen on %s: %w", path, err)
+	}
+	// The socket file is removed from its final path by the caller
+	if ul, ok := ln.(*net.UnixListener); ok {
+		ul.SetUnlinkOnClose(false)
+	}
+
+	if err := os.Chmod(tmp, unixSocketMode); err != nil {
+		_ = ln.Close()
+		return nil, fmt.Errorf("restrict permissions of %s: %w", path, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = ln.Close()
+		return nil, fmt.Errorf("listen on %s: %w", path, err)
+	}
+	return ln, nil
+}
+
+// removeStaleSocket removes a socket file left behind by a server that did not
+// shut down cleanly. It refuses to remove anything that isn't a socket, or a
+// socket another server is still accepting connections on.
+func removeStaleSocket(path string) error {
+	info, err := os.Lstat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.Mode()&fs.ModeSocket == 0 {
+		return fmt.Errorf("%s already exists and is not a socket", path)
+	}
+	if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+		_ = conn.Close()
+		return fmt.Errorf("%s is in use by another server", path)
+	}
+	return os.Remove(path)
+}
+
+// serveUnix accepts connections on ln until ctx is canceled, serving each as
+// an independent MCP session, and waits for the sessions to end.
+func (s *Server) serveUnix(ctx context.Context, ln net.Listener, logger *zap.Logger) error {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	stop := context.AfterFunc(ctx, func() {
+		logger.Info("stopping unix socket listener")
+		_ = ln.Close()
+	})
+	defer stop()
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("server run failed: %w", err)
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.serveConn(ctx, conn)
+		}()
+	}
+}
+
+// serveConn runs one MCP session over conn, using the same newline-delimited
+// JSON framing as stdio, until the client disconnects or ctx is canceled.
+func (s *Server) serveConn(ctx context.Context, conn net.Conn) {
+	s.metrics.IncrementConnections()
+	defer s.metrics.DecrementActiveConnections()
+
+	// Closing the connection on a failed write ends the session, leaving
+	// other clients unaffected
+	writer := nopWriteCloser{s.guardWrites(conn, func(error) { _ = conn.Close() })}
+	session, err := s.mcp.Connect(ctx, s.newIOTransport(conn, writer), nil)
+	if err != nil {
+		_ = conn.Close()
+		s.log().Debug("failed to start unix socket session", zap.Error(err))
+		return
+	}
+
+	stop := context.AfterFunc(ctx, func() { _ = session.Close() })
+	defer stop()
+
+	if err := session.Wait(); err != nil && ctx.Err() == nil {
+		if isDisconnect(err) {
+			s.log().Debug("unix socket client disconnected", zap.Error(err))
+			return
+		}
+		s.log().Debug("unix socket session ended", zap.Error(err))
+	}
+}
@@ -0,0 +1,354 @@
+package hypermcp
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap/zaptest"
+)
+
+// tempSocketPath returns a socket path in a fresh temporary directory. Socket
+// paths are limited to about 100 bytes, which t.TempDir can exceed.
+func tempSocketPath(t *testing.T) string {
+	t.Helper()
+
+	dir, err := os.MkdirTemp("", "hypermcp")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	return filepath.Join(dir, "mcp.sock")
+}
+
+// startUnixServer runs srv on the unix transport until the test ends,
+// returning a channel receiving RunWithTransport's result and a func stopping
+// the server.
+func startUnixServer(t *testing.T, srv *Server) (<-chan error, context.CancelFunc) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		errCh <- RunWithTransport(ctx, srv, TransportUnix, zaptest.NewLogger(t))
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	// Wait for the listener to come up
+	path := srv.config.Transport.SocketPath
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if info, err := os.Stat(path); err == nil && info.Mode()&fs.ModeSocket != 0 {
+			return errCh, cancel
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("socket %s was not created", path)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// connectUnixClient connects an MCP client over the socket at path,
+// completing the initialize handshake.
+func connectUnixClient(t *testing.T, path string) *mcp.ClientSession {
+	t.Helper()
+
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		t.Fatalf("failed to dial %s: %v", path, err)
+	}
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "unix-client", Version: "1.0.0"}, nil)
+	session, err := client.Connect(context.Background(), &mcp.IOTransport{Reader: conn, Writer: conn}, nil)
+	if err != nil {
+		t.Fatalf("failed to connect client: %v", err)
+	}
+	t.Cleanup(func() { _ = session.Close() })
+	return session
+}
+
+func newUnixTestServer(t *testing.T, path string) *Server {
+	t.Helper()
+
+	srv, err := New(Config{
+		Name:      "test-server",
+		Version:   "1.0.0",
+		Transport: TransportConfig{SocketPath: path},
+	}, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	AddTool(srv, &mcp.Tool{Name: "echo", Description: "Echoes the message"},
+		func(ctx context.Context, req *mcp.CallToolRequest, input struct {
+			Message string `json:"message"`
+		}) (*mcp.CallToolResult, any, error) {
+			return TextResult(input.Message), nil, nil
+		})
+	return srv
+}
+
+func TestRunWithTransport_Unix(t *testing.T) {
+	path := tempSocketPath(t)
+	srv := newUnixTestServer(t, path)
+	errCh, stop := startUnixServer(t, srv)
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("failed to stat socket: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != unixSocketMode {
+		t.Errorf("socket permissions = %v, want %v", perm, unixSocketMode)
+	}
+	// The private directory the socket was created in is gone
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatalf("failed to read socket directory: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("socket directory holds %d entries, want only the socket", len(entries))
+	}
+
+	// Two clients hold independent sessions at once
+	first := connectUnixClient(t, path)
+	second := connectUnixClient(t, path)
+
+	if got := first.InitializeResult().ServerInfo.Name; got != "test-server" {
+		t.Errorf("handshake server name = %q, want test-server", got)
+	}
+	for _, tc := range []struct {
+		session *mcp.ClientSession
+		message string
+	}{{first, "hello"}, {second, "world"}} {
+		res, err := tc.session.CallTool(context.Background(), &mcp.CallToolParams{
+			Name:      "echo",
+			Arguments: map[string]any{"message": tc.message},
+		})
+		if err != nil {
+			t.Fatalf("tool call failed: %v", err)
+		}
+		if text := res.Content[0].(*mcp.TextContent).Text; text != tc.message {
+			t.Errorf("echo returned %q, want %q", text, tc.message)
+		}
+	}
+	if active := srv.GetMetrics().ActiveConnections; active != 2 {
+		t.Errorf("active connections = %d, want 2", active)
+	}
+
+	stop()
+	select {
+	case err := <-errCh:
+		if err != nil {
+			t.Fatalf("RunWithTransport returned error: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("RunWithTransport did not return after cancellation")
+	}
+
+	if _, err := os.Lstat(path); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected socket file to be removed on shutdown, got %v", err)
+	}
+	if active := srv.GetMetrics().ActiveConnections; active != 0 {
+		t.Errorf("active connections after shutdown = %d, want 0", active)
+	}
+}
+
+func TestRunWithTransport_UnixStaleSocket(t *testing.T) {
+	path := tempSocketPath(t)
+
+	// A socket file nobody is listening on, as left by a crashed server
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("failed to create stale socket: %v", err)
+	}
+	ln.(*net.UnixListener).SetUnlinkOnClose(false)
+	_ = ln.Close()
+
+	srv := newUnixTestServer(t, path)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = RunWithTransport(ctx, srv, TransportUnix, zaptest.NewLogger(t))
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	// The stale file exists from the start, so wait for the server to accept
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		conn, err := net.Dial("unix", path)
+		if err == nil {
+			_ = conn.Close()
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("server did not replace the stale socket: %v", err)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	connectUnixClient(t, path)
+}
+
+func TestRunWithTransport_UnixRefusesPath(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(t *testing.T, path string)
+	}{
+		{
+			name: "regular file",
+			setup: func(t *testing.T, path string) {
+				if err := os.WriteFile(path, []byte("data"), 0o600); err != nil {
+					t.Fatalf("failed to write file: %v", err)
+				}
+			},
+		},
+		{
+			name: "socket in use",
+			setup: func(t *testing.T, path string) {
+				ln, err := net.Listen("unix", path)
+				if err != nil {
+					t.Fatalf("failed to listen: %v", err)
+				}
+				t.Cleanup(func() { _ = ln.Close() })
+				go func() {
+					for {
+						conn, err := ln.Accept()
+						if err != nil {
+							return
+						}
+						_ = conn.Close()
+					}
+				}()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := tempSocketPath(t)
+			tt.setup(t, path)
+
+			srv := newUnixTestServer(t, path)
+			err := RunWithTransport(context.Background(), srv, TransportUnix, zaptest.NewLogger(t))
+
+			var transportErr *TransportError
+			if !errors.As(err, &transportErr) || transportErr.Transport != TransportUnix {
+				t.Fatalf("expected unix TransportError, got %v", err)
+			}
+			if _, err := os.Lstat(path); err != nil {
+				t.Errorf("expected %s to be left in place, got %v", path, err)
+			}
+		})
+	}
+}
+
+func TestConfig_ValidateForTransport_Unix(t *testing.T) {
+	tests := []struct {
+		name      string
+		transport TransportConfig
+		wantField string
+	}{
+		{name: "valid", transport: TransportConfig{SocketPath: "/tmp/mcp.sock"}},
+		{name: "missing path", transport: TransportConfig{}, wantField: "Transport.SocketPath"},
+		{
+			name:      "auth",
+			transport: TransportConfig{SocketPath: "/tmp/mcp.sock", Auth: AuthConfig{Tokens: []string{"secret"}}},
+			wantField: "Transport.Auth",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{Name: "test-server", Version: "1.0.0", Transport: tt.transport}
+			err := cfg.ValidateForTransport(TransportUnix)
+
+			if tt.wantField == "" {
+				if err != nil {
+					t.Fatalf("ValidateForTransport() error = %v", err)
+				}
+				return
+			}
+			var cfgErr *ConfigError
+			if !errors.As(err, &cfgErr) || cfgErr.Field != tt.wantField {
+				t.Errorf("ValidateForTransport() error = %v, want ConfigError for %s", err, tt.wantField)
+			}
+		})
+	}
+}
+
+func TestRunWithTransport_UnixClientDisconnectDuringResponse(t *testing.T) {
+	path := tempSocketPath(t)
+	srv := newUnixTestServer(t, path)
+	started := make(chan struct{})
+	release := make(chan struct{})
+	AddTool(srv, &mcp.Tool{Name: "slow"}, func(ctx context.Context, req *mcp.CallToolRequest, input struct{}) (*mcp.CallToolResult, any, error) {
+		close(started)
+		<-release
+		return TextResult(strings.Repeat("x", 1<<20)), nil, nil
+	})
+	errCh, _ := startUnixServer(t, srv)
+
+	// A raw client starts a call, then disconnects before the response
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		t.Fatalf("failed to dial %s: %v", path, err)
+	}
+	for _, line := range []string{
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18","capabilities":{},"clientInfo":{"name":"raw-client","version":"1.0.0"}}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"slow","arguments":{}}}`,
+	} {
+		if _, err := conn.Write([]byte(line + "\n")); err != nil {
+			t.Fatalf("failed to write message: %v", err)
+		}
+	}
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("tool call did not start")
+	}
+	_ = conn.Close()
+	close(release)
+
+	// The server keeps serving other clients
+	session := connectUnixClient(t, path)
+	res, err := session.CallTool(context.Background(), &mcp.CallToolParams{
+		Name:      "echo",
+		Arguments: map[string]any{"message": "still here"},
+	})
+	if err != nil {
+		t.Fatalf("tool call after disconnect failed: %v", err)
+	}
+	if text := res.Content[0].(*mcp.TextContent).Text; text != "still here" {
+		t.Errorf("echo returned %q, want %q", text, "still here")
+	}
+
+	// Only the new client remains connected
+	deadline := time.Now().Add(5 * time.Second)
+	for srv.GetMetrics().ActiveConnections != 1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("active connections = %d, want 1", srv.GetMetrics().ActiveConnections)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	select {
+	case err := <-errCh:
+		t.Fatalf("server stopped after a client disconnect: %v", err)
+	default:
+	}
+}
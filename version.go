@@ -1,16 +1,28 @@
 // Package hypermcp provides reusable MCP server infrastructure
 package hypermcp
 
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// VersionResourceURI is the URI of the resource registered by
+// AddVersionResource.
+const VersionResourceURI = "hypermcp://version"
+
 // ServerInfo holds version and build information for the MCP server.
 //
 // This struct can be populated at build time using ldflags:
 //
 //	go build -ldflags="-X main.version=1.0.0 -X main.commit=abc123 -X main.buildDate=2025-01-15"
 type ServerInfo struct {
-	Name      string
-	Version   string
-	Commit    string
-	BuildDate string
+	Name      string `json:"name"`
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	BuildDate string `json:"buildDate,omitempty"`
 }
 
 // String returns a formatted version string with commit and build date information.
@@ -19,3 +31,61 @@ type ServerInfo struct {
 func (si ServerInfo) String() string {
 	return si.Version + " (commit: " + si.Commit + ", built: " + si.BuildDate + ")"
 }
+
+// VersionReport is the content of the resource registered by
+// AddVersionResource.
+type VersionReport struct {
+	Server ServerInfo `json:"server"`
+
+	// Version is Server formatted by ServerInfo.String.
+	Version string `json:"version"`
+
+	Uptime        string  `json:"uptime"`
+	UptimeSeconds float64 `json:"uptimeSeconds"`
+
+	// Registered tools, and resources including templates, at the time of
+	// the read.
+	Tools     int `json:"tools"`
+	Resources int `json:"resources"`
+}
+
+// AddVersionResource registers a resource at VersionResourceURI reporting the
+// server's version and build information, its uptime, and how many tools and
+// resources it has registered, as JSON (see VersionReport). Build information
+// missing from the Config is filled in from the binary's VCS metadata, as in
+// Describe. Nothing is registered unless this is called.
+func (s *Server) AddVersionResource() {
+	s.AddResource(&mcp.Resource{
+		URI:         VersionResourceURI,
+		Name:        "version",
+		Description: "Server version, build information, uptime, and registration counts",
+		MIMEType:    "application/json",
+	}, func(_ context.Context, _ *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+		data, err := json.Marshal(s.versionReport())
+		if err != nil {
+			return nil, fmt.Errorf("encode version report: %w", err)
+		}
+		return &mcp.ReadResourceResult{
+			Contents: []*mcp.ResourceContents{{
+				URI:      VersionResourceURI,
+				MIMEType: "application/json",
+				Text:     string(data),
+			}},
+		}, nil
+	})
+}
+
+// versionReport returns the current VersionReport.
+func (s *Server) versionReport() VersionReport {
+	info := s.serverInfo()
+	uptime := s.metrics.clock.Now().Sub(s.metrics.startTime)
+	tools, resources, templates, _ := s.registry.snapshot()
+	return VersionReport{
+		Server:        info,
+		Version:       info.String(),
+		Uptime:        uptime.String(),
+		UptimeSeconds: uptime.Seconds(),
+		Tools:         len(tools),
+		Resources:     len(resources) + len(templates),
+	}
+}
@@ -1,6 +1,14 @@
 package hypermcp
 
-import "testing"
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap/zaptest"
+)
 
 func TestServerInfo_String(t *testing.T) {
 	tests := []struct {
@@ -81,3 +89,47 @@ func TestServerInfo_Fields(t *testing.T) {
 		t.Errorf("expected BuildDate to be '2025-10-11', got %q", info.BuildDate)
 	}
 }
+
+func TestServer_AddVersionResource(t *testing.T) {
+	clock := newFakeClock()
+	srv, err := New(Config{
+		Name:      "test-server",
+		Version:   "1.2.3",
+		Commit:    "abc123",
+		BuildDate: "2025-01-15",
+		Clock:     clock,
+	}, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	AddTool(srv, &mcp.Tool{Name: "echo"}, func(_ context.Context, _ *mcp.CallToolRequest, in EchoMessage) (*mcp.CallToolResult, EchoMessage, error) {
+		return nil, in, nil
+	})
+	srv.AddVersionResource()
+	clock.Advance(90 * time.Second)
+
+	session := connectTestClient(t, srv)
+	res, err := session.ReadResource(context.Background(), &mcp.ReadResourceParams{URI: VersionResourceURI})
+	if err != nil {
+		t.Fatalf("ReadResource() error = %v", err)
+	}
+	if len(res.Contents) != 1 || res.Contents[0].MIMEType != "application/json" {
+		t.Fatalf("expected one JSON content, got %+v", res.Contents)
+	}
+
+	var report VersionReport
+	if err := json.Unmarshal([]byte(res.Contents[0].Text), &report); err != nil {
+		t.Fatalf("decode version report: %v", err)
+	}
+	want := VersionReport{
+		Server:        ServerInfo{Name: "test-server", Version: "1.2.3", Commit: "abc123", BuildDate: "2025-01-15"},
+		Version:       "1.2.3 (commit: abc123, built: 2025-01-15)",
+		Uptime:        "1m30s",
+		UptimeSeconds: 90,
+		Tools:         1,
+		Resources:     1,
+	}
+	if report != want {
+		t.Errorf("version report = %+v, want %+v", report, want)
+	}
+}